@@ -0,0 +1,182 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.avito.ru/DO/moira"
+)
+
+// AuthorizationConfig defines roles of the users and the teams which own the triggers
+type AuthorizationConfig struct {
+	Enabled     bool
+	DefaultRole moira.Role            // role of the users who are not listed, viewer by default
+	Users       map[string]moira.Role // global roles of the users
+	Teams       []moira.Team          // teams can be stored in the database as well
+}
+
+// TeamStorage gives the teams stored in the database
+type TeamStorage interface {
+	GetTeams() ([]*moira.Team, error)
+}
+
+// Authorizer checks permissions of the users.
+// Unless authorization is enabled everyone is an editor and only super users are admins
+type Authorizer struct {
+	config     AuthorizationConfig
+	superUsers map[string]bool
+	storage    TeamStorage
+}
+
+// NewAuthorizer creates Authorizer, super users are admins regardless of the config
+func NewAuthorizer(config AuthorizationConfig, superUsers []string, storage TeamStorage) *Authorizer {
+	authorizer := &Authorizer{
+		config:     config,
+		superUsers: make(map[string]bool, len(superUsers)),
+		storage:    storage,
+	}
+	for _, login := range superUsers {
+		authorizer.superUsers[login] = true
+	}
+	return authorizer
+}
+
+// IsEnabled can tell whether or not the permissions are checked
+func (authorizer *Authorizer) IsEnabled() bool {
+	return authorizer.config.Enabled
+}
+
+// GetRole returns global role of the user
+func (authorizer *Authorizer) GetRole(login string) moira.Role {
+	if authorizer.superUsers[login] {
+		return moira.RoleAdmin
+	}
+	if !authorizer.config.Enabled {
+		return moira.RoleEditor
+	}
+	if role, ok := authorizer.config.Users[login]; ok {
+		return role
+	}
+	if authorizer.config.DefaultRole != "" {
+		return authorizer.config.DefaultRole
+	}
+	return moira.RoleViewer
+}
+
+// CheckRole returns 403 if the global role of the user doesn't include the given one
+func (authorizer *Authorizer) CheckRole(login string, role moira.Role) *ErrorResponse {
+	if !authorizer.config.Enabled {
+		return nil
+	}
+	if userRole := authorizer.GetRole(login); !userRole.Includes(role) {
+		return ErrorForbidden(fmt.Sprintf("User \"%s\" has role %s, but %s is required", login, userRole, role))
+	}
+	return nil
+}
+
+// CheckTrigger returns 403 if the user is not allowed to change the trigger:
+// the triggers owned by teams can be changed by editors of these teams, the other ones by global editors
+func (authorizer *Authorizer) CheckTrigger(login string, trigger *moira.Trigger) *ErrorResponse {
+	if !authorizer.config.Enabled {
+		return nil
+	}
+	role := authorizer.GetRole(login)
+	if role.Includes(moira.RoleAdmin) {
+		return nil
+	}
+
+	teams, err := authorizer.GetTeams()
+	if err != nil {
+		return ErrorInternalServer(err)
+	}
+
+	owners := make([]string, 0)
+	for _, team := range teams {
+		if !team.IsOwnerOf(trigger) {
+			continue
+		}
+		if team.Members[login].Includes(moira.RoleEditor) {
+			return nil
+		}
+		owners = append(owners, team.ID)
+	}
+
+	switch {
+	case len(owners) > 0:
+		return ErrorForbidden(fmt.Sprintf("Trigger is owned by %s, user \"%s\" must be an editor of the team", strings.Join(owners, ", "), login))
+	case !role.Includes(moira.RoleEditor):
+		return ErrorForbidden(fmt.Sprintf("User \"%s\" has role %s, but %s is required", login, role, moira.RoleEditor))
+	default:
+		return nil
+	}
+}
+
+// CheckTeam returns 400 if the team is given but doesn't exist
+func (authorizer *Authorizer) CheckTeam(teamID string) *ErrorResponse {
+	if teamID == "" {
+		return nil
+	}
+	teams, err := authorizer.GetTeams()
+	if err != nil {
+		return ErrorInternalServer(err)
+	}
+	for _, team := range teams {
+		if team.ID == teamID {
+			return nil
+		}
+	}
+	return ErrorInvalidRequest(fmt.Errorf("Team '%s' does not exist", teamID))
+}
+
+// GetTeams returns the teams from the config and the database, the config has precedence
+func (authorizer *Authorizer) GetTeams() ([]*moira.Team, error) {
+	result := make([]*moira.Team, 0, len(authorizer.config.Teams))
+	known := make(map[string]bool, len(authorizer.config.Teams))
+	for i := range authorizer.config.Teams {
+		result = append(result, &authorizer.config.Teams[i])
+		known[authorizer.config.Teams[i].ID] = true
+	}
+
+	if authorizer.storage != nil {
+		stored, err := authorizer.storage.GetTeams()
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range stored {
+			if !known[team.ID] {
+				result = append(result, team)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// IsConfigTeam can tell whether or not the team is defined in the config (such teams can't be changed via api)
+func (authorizer *Authorizer) IsConfigTeam(teamID string) bool {
+	for _, team := range authorizer.config.Teams {
+		if team.ID == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserTeams returns the roles of the user within the teams
+func (authorizer *Authorizer) GetUserTeams(login string) (map[string]moira.Role, error) {
+	teams, err := authorizer.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]moira.Role)
+	for _, team := range teams {
+		if role, ok := team.Members[login]; ok {
+			result[team.ID] = role
+		}
+	}
+	return result, nil
+}
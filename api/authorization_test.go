@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestAuthorizer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	config := AuthorizationConfig{
+		Enabled: true,
+		Users: map[string]moira.Role{
+			"editor": moira.RoleEditor,
+			"admin":  moira.RoleAdmin,
+		},
+		Teams: []moira.Team{{
+			ID:      "dba",
+			Tags:    []string{"db"},
+			Members: map[string]moira.Role{"dba-editor": moira.RoleEditor, "dba-viewer": moira.RoleViewer},
+		}},
+	}
+
+	Convey("Authorization is disabled", t, func() {
+		authorizer := NewAuthorizer(AuthorizationConfig{}, []string{"root"}, dataBase)
+		So(authorizer.GetRole("root"), ShouldEqual, moira.RoleAdmin)
+		So(authorizer.GetRole("anybody"), ShouldEqual, moira.RoleEditor)
+		So(authorizer.CheckRole("anybody", moira.RoleAdmin), ShouldBeNil)
+		So(authorizer.CheckTrigger("anybody", &moira.Trigger{Tags: []string{"db"}}), ShouldBeNil)
+	})
+
+	Convey("Global roles", t, func() {
+		authorizer := NewAuthorizer(config, []string{"root"}, dataBase)
+		So(authorizer.GetRole("root"), ShouldEqual, moira.RoleAdmin)
+		So(authorizer.GetRole("editor"), ShouldEqual, moira.RoleEditor)
+		So(authorizer.GetRole("anybody"), ShouldEqual, moira.RoleViewer)
+
+		So(authorizer.CheckRole("editor", moira.RoleEditor), ShouldBeNil)
+		errorResponse := authorizer.CheckRole("anybody", moira.RoleEditor)
+		So(errorResponse, ShouldNotBeNil)
+		So(errorResponse.HTTPStatusCode, ShouldEqual, http.StatusForbidden)
+
+		config := config
+		config.DefaultRole = moira.RoleEditor
+		So(NewAuthorizer(config, nil, dataBase).GetRole("anybody"), ShouldEqual, moira.RoleEditor)
+	})
+
+	Convey("Trigger ownership", t, func() {
+		authorizer := NewAuthorizer(config, nil, dataBase)
+		dataBase.EXPECT().GetTeams().Return([]*moira.Team{
+			{ID: "web", Members: map[string]moira.Role{"web-editor": moira.RoleEditor}},
+			{ID: "dba", Members: map[string]moira.Role{"web-editor": moira.RoleAdmin}}, // overridden by the config
+		}, nil).AnyTimes()
+
+		dbTrigger := &moira.Trigger{Tags: []string{"db", "prod"}}
+		webTrigger := &moira.Trigger{Tags: []string{"db"}, Team: "web"}
+		commonTrigger := &moira.Trigger{Tags: []string{"prod"}}
+
+		Convey("Admins can change any trigger", func() {
+			So(authorizer.CheckTrigger("admin", dbTrigger), ShouldBeNil)
+			So(authorizer.CheckTrigger("admin", webTrigger), ShouldBeNil)
+		})
+
+		Convey("Team editors can change the triggers of the team only", func() {
+			So(authorizer.CheckTrigger("dba-editor", dbTrigger), ShouldBeNil)
+			So(authorizer.CheckTrigger("dba-editor", webTrigger), ShouldNotBeNil)
+			So(authorizer.CheckTrigger("web-editor", webTrigger), ShouldBeNil)
+			So(authorizer.CheckTrigger("web-editor", dbTrigger), ShouldNotBeNil)
+			So(authorizer.CheckTrigger("dba-viewer", dbTrigger), ShouldNotBeNil)
+		})
+
+		Convey("Global editors can change the triggers without owners only", func() {
+			So(authorizer.CheckTrigger("editor", dbTrigger), ShouldNotBeNil)
+			So(authorizer.CheckTrigger("editor", commonTrigger), ShouldBeNil)
+			So(authorizer.CheckTrigger("dba-editor", commonTrigger), ShouldNotBeNil)
+		})
+
+		Convey("User teams", func() {
+			teams, err := authorizer.GetUserTeams("web-editor")
+			So(err, ShouldBeNil)
+			So(teams, ShouldResemble, map[string]moira.Role{"web": moira.RoleEditor})
+			So(authorizer.IsConfigTeam("dba"), ShouldBeTrue)
+			So(authorizer.IsConfigTeam("web"), ShouldBeFalse)
+		})
+
+		Convey("Team must exist", func() {
+			So(authorizer.CheckTeam(""), ShouldBeNil)
+			So(authorizer.CheckTeam("web"), ShouldBeNil)
+			So(authorizer.CheckTeam("dba"), ShouldBeNil)
+			So(authorizer.CheckTeam("qa"), ShouldResemble, ErrorInvalidRequest(fmt.Errorf("Team 'qa' does not exist")))
+		})
+	})
+
+	Convey("Teams can't be loaded", t, func() {
+		brokenDataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		authorizer := NewAuthorizer(config, nil, brokenDataBase)
+		brokenDataBase.EXPECT().GetTeams().Return(nil, fmt.Errorf("connection refused"))
+		errorResponse := authorizer.CheckTrigger("editor", &moira.Trigger{})
+		So(errorResponse, ShouldNotBeNil)
+		So(errorResponse.HTTPStatusCode, ShouldEqual, http.StatusInternalServerError)
+	})
+}
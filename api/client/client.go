@@ -0,0 +1,154 @@
+// Package client is Go client of Moira API, its methods are generated from api/openapi routes
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Config of the client
+type Config struct {
+	URL     string        // e.g. https://moira.example.com, without /api
+	Token   string        // API token, see /api/token
+	Login   string        // is sent as x-webauth-user header if api is not behind authenticating proxy
+	Timeout time.Duration // 30 seconds by default
+}
+
+// Client calls Moira API
+type Client struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// Error is returned when api responds with error status
+type Error struct {
+	StatusCode int
+	Status     string `json:"status"`
+	Text       string `json:"error"`
+}
+
+func (err *Error) Error() string {
+	if err.Text == "" {
+		return fmt.Sprintf("moira api: %d %s", err.StatusCode, err.Status)
+	}
+	return fmt.Sprintf("moira api: %d %s: %s", err.StatusCode, err.Status, err.Text)
+}
+
+// NewClient creates Client
+func NewClient(config Config) *Client {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return &Client{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// do sends the request and decodes the response, both request and response may be nil
+func (client *Client) do(ctx context.Context, method, path string, query url.Values, request, response interface{}) error {
+	requestURL := client.config.URL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	var body *bytes.Reader
+	if request != nil {
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+
+	httpRequest, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return err
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+	httpRequest.Header.Set("Accept", "application/json")
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	client.authorize(httpRequest)
+
+	httpResponse, err := client.httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+
+	if httpResponse.StatusCode >= http.StatusBadRequest {
+		return newError(httpResponse.StatusCode, responseBody)
+	}
+
+	if response == nil || len(responseBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// authorize sets the token or the login of the user
+func (client *Client) authorize(httpRequest *http.Request) {
+	if client.config.Token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+client.config.Token)
+	} else if client.config.Login != "" {
+		httpRequest.Header.Set("x-webauth-user", client.config.Login)
+	}
+}
+
+func newError(statusCode int, responseBody []byte) *Error {
+	apiError := &Error{}
+	_ = json.Unmarshal(responseBody, apiError)
+	apiError.StatusCode = statusCode
+	if apiError.Status == "" {
+		apiError.Status = http.StatusText(statusCode)
+	}
+	return apiError
+}
+
+func setStringParam(query url.Values, name, value string, required bool) {
+	if value != "" || required {
+		query.Set(name, value)
+	}
+}
+
+func setIntParam(query url.Values, name string, value int64, required bool) {
+	if value != 0 || required {
+		query.Set(name, strconv.FormatInt(value, 10))
+	}
+}
+
+func setBoolParam(query url.Values, name string, value, required bool) {
+	if value || required {
+		query.Set(name, strconv.FormatBool(value))
+	}
+}
+
+// setListParam sets the values as api expects, e.g. tags[0]=a&tags[1]=b
+func setListParam(query url.Values, name string, values []string, _ bool) {
+	for i, value := range values {
+		query.Set(fmt.Sprintf("%s[%d]", name, i), value)
+	}
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/api/handler"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestClient(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	config := &api.Config{SuperUsers: []string{"admin"}}
+	server := httptest.NewServer(handler.NewHandler(dataBase, nil, test_helpers.GetTestLogger(), config, nil, "test"))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(Config{URL: server.URL + "/", Login: "admin"})
+
+	Convey("Current user", t, func() {
+		user, err := client.GetUser(ctx)
+		So(err, ShouldBeNil)
+		So(user, ShouldResemble, &dto.User{Login: "admin", Role: moira.RoleAdmin})
+	})
+
+	Convey("Tags", t, func() {
+		dataBase.EXPECT().GetTagNames().Return([]string{"db", "web"}, nil)
+		tags, err := client.GetAllTags(ctx)
+		So(err, ShouldBeNil)
+		So(tags.TagNames, ShouldResemble, []string{"db", "web"})
+	})
+
+	Convey("Errors of api", t, func() {
+		dataBase.EXPECT().GetTrigger("unknown").Return(nil, database.ErrNil)
+		trigger, err := client.GetTrigger(ctx, "unknown")
+		So(trigger, ShouldBeNil)
+		So(err, ShouldHaveSameTypeAs, &Error{})
+		So(err.(*Error).StatusCode, ShouldEqual, http.StatusNotFound)
+		So(err.(*Error).Text, ShouldEqual, "Trigger not found")
+	})
+
+	Convey("API tokens", t, func() {
+		var saved *moira.APIToken
+		dataBase.EXPECT().SaveAPIToken(gomock.Any()).Do(func(token *moira.APIToken) { saved = token }).Return(nil)
+
+		token, err := client.CreateAPIToken(ctx, &dto.APIToken{
+			Name:   "terraform",
+			Login:  "terraform",
+			Scopes: []moira.APITokenScope{moira.APITokenScopeRead},
+		})
+		So(err, ShouldBeNil)
+		So(token.Token, ShouldNotBeEmpty)
+		So(token.Login, ShouldEqual, "terraform")
+
+		dataBase.EXPECT().GetAPITokenByHash(saved.Hash).Return(saved, nil).Times(2)
+		tokenClient := NewClient(Config{URL: server.URL, Token: token.Token})
+
+		user, err := tokenClient.GetUser(ctx)
+		So(err, ShouldBeNil)
+		So(user.Login, ShouldEqual, "terraform")
+
+		err = tokenClient.RemoveTrigger(ctx, "trigger")
+		So(err, ShouldNotBeNil)
+		So(err.(*Error).StatusCode, ShouldEqual, http.StatusForbidden)
+	})
+
+	Convey("Events stream", t, func() {
+		events := make(chan *moira.NotificationEvent)
+		dataBase.EXPECT().SubscribeNotificationEvents(gomock.Any()).Return((<-chan *moira.NotificationEvent)(events), nil)
+
+		now := time.Now().Unix()
+		replayed := &moira.NotificationEvent{ID: "replayed", TriggerID: "trigger", Timestamp: now, State: moira.ERROR}
+		dataBase.EXPECT().GetAllNotificationEvents(now, int64(math.MaxInt64)).Return([]*moira.NotificationEvent{
+			{ID: "last", TriggerID: "trigger", Timestamp: now, State: moira.ERROR},
+			replayed,
+		}, nil)
+
+		errStop := fmt.Errorf("stop")
+		received := make(chan *moira.NotificationEvent)
+		done := make(chan error)
+		go func() {
+			done <- client.StreamEvents(ctx, StreamFilter{States: []string{moira.ERROR}}, fmt.Sprintf("%d:last", now), func(id string, event *moira.NotificationEvent) error {
+				received <- event
+				if event.ID == "live" {
+					return errStop
+				}
+				return nil
+			})
+		}()
+
+		So(<-received, ShouldResemble, replayed)
+		events <- replayed
+		events <- &moira.NotificationEvent{ID: "ok", TriggerID: "trigger", Timestamp: now + 1, State: moira.OK}
+		events <- &moira.NotificationEvent{ID: "live", TriggerID: "trigger", Timestamp: now + 2, State: moira.ERROR}
+		So((<-received).ID, ShouldEqual, "live")
+		So(<-done, ShouldEqual, errStop)
+
+		err := client.StreamEvents(ctx, StreamFilter{States: []string{"BROKEN"}}, "", nil)
+		So(err.(*Error).StatusCode, ShouldEqual, http.StatusBadRequest)
+	})
+}
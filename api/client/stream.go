@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.avito.ru/DO/moira"
+)
+
+// maxStreamLine is the limit of the line of the events stream
+const maxStreamLine = 1024 * 1024
+
+// StreamFilter selects the events of the stream, empty conditions are not checked
+type StreamFilter struct {
+	Tags       []string // trigger must have all of them
+	TriggerIDs []string // event must belong to any of them
+	States     []string // event must have any of them
+}
+
+// StreamEventHandler is called for every event of the stream, ID is to be given to StreamEvents to resume the stream.
+// The stream is stopped if the handler returns error
+type StreamEventHandler func(id string, event *moira.NotificationEvent) error
+
+// StreamEvents calls GET /api/stream/events and handles the events as they come until the context is done,
+// the stream is closed by api or the handler fails. The stream is resumed after lastEventID if it is given
+func (client *Client) StreamEvents(ctx context.Context, filter StreamFilter, lastEventID string, handler StreamEventHandler) error {
+	query := url.Values{}
+	setListParam(query, "tags", filter.Tags, false)
+	setListParam(query, "triggers", filter.TriggerIDs, false)
+	setListParam(query, "states", filter.States, false)
+
+	requestURL := client.config.URL + "/api/stream/events"
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	httpRequest, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+	httpRequest.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		httpRequest.Header.Set("Last-Event-ID", lastEventID)
+	}
+	client.authorize(httpRequest)
+
+	// the stream lasts as long as the context does, so the timeout of the client is not applied
+	httpClient := &http.Client{Transport: client.httpClient.Transport}
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode >= http.StatusBadRequest {
+		responseBody, _ := ioutil.ReadAll(httpResponse.Body)
+		return newError(httpResponse.StatusCode, responseBody)
+	}
+
+	scanner := bufio.NewScanner(httpResponse.Body)
+	scanner.Buffer(make([]byte, 0, 4096), maxStreamLine)
+	var id, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data == "" {
+				continue
+			}
+			event := &moira.NotificationEvent{}
+			if err := json.Unmarshal([]byte(data), event); err != nil {
+				return fmt.Errorf("failed to decode event %s: %v", id, err)
+			}
+			if err := handler(id, event); err != nil {
+				return err
+			}
+			data = ""
+		case strings.HasPrefix(line, ":"):
+			// comment, e.g. heartbeat
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
@@ -2,23 +2,32 @@ package api
 
 import (
 	"go.avito.ru/DO/moira"
-	"go.avito.ru/DO/moira/netbox"
 	"go.avito.ru/DO/moira/sentry"
 )
 
 // Config for api configuration variables
 type Config struct {
+	Authorization      AuthorizationConfig
 	EnableCORS         bool
 	GrafanaPrefixes    []string
 	Listen             string
 	LimitLogger        moira.RateLimit
 	LimitMetrics       moira.RateLimit
-	Netbox             *netbox.Config
+	Inventory          moira.InventoryProvider
 	Sentry             sentry.Config
+	Slack              SlackConfig
 	SuperUsers         []string // those who can turn off __all__ notifications
 	TargetRewriteRules []RewriteRule
 }
 
+// SlackConfig is used to handle interactive actions of Slack messages and slash commands
+type SlackConfig struct {
+	APIToken      string
+	FrontURI      string
+	SigningSecret string
+	Users         map[string]string // Slack user ID -> Moira login, the other Slack users are rejected
+}
+
 // Rewriting rules for targets
 type RewriteRule struct {
 	From string `yaml:"from"`
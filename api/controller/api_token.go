@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/satori/go.uuid"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/database"
+)
+
+// GetAPITokens returns the tokens of the user, all the tokens are returned for admins
+func GetAPITokens(dataBase moira.Database, userLogin string, isAdmin bool) (*dto.APITokenList, *api.ErrorResponse) {
+	tokens, err := dataBase.GetAPITokens()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	result := &dto.APITokenList{List: make([]dto.APIToken, 0)}
+	for _, token := range tokens {
+		if isAdmin || token.Login == userLogin {
+			result.List = append(result.List, dto.CreateAPIToken(token))
+		}
+	}
+	sort.Slice(result.List, func(i, j int) bool {
+		return result.List[i].CreatedAt < result.List[j].CreatedAt
+	})
+	return result, nil
+}
+
+// CreateAPIToken creates the token for the user, admins can create the tokens of the services (any logins).
+// The token itself is returned only here, the database keeps its hash
+func CreateAPIToken(dataBase moira.Database, tokenDTO *dto.APIToken, userLogin string, isAdmin bool) *api.ErrorResponse {
+	if userLogin == "" {
+		return api.ErrorForbidden("Anonymous users can't create API tokens")
+	}
+	if tokenDTO.Login == "" {
+		tokenDTO.Login = userLogin
+	}
+	if tokenDTO.Login != userLogin && !isAdmin {
+		return api.ErrorForbidden(fmt.Sprintf("Only admins can create API tokens of the other users, not %s", userLogin))
+	}
+
+	value, hash, err := api.GenerateAPIToken()
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+
+	token := &moira.APIToken{
+		ID:        uuid.NewV4().String(),
+		Name:      tokenDTO.Name,
+		Login:     tokenDTO.Login,
+		Hash:      hash,
+		Scopes:    tokenDTO.Scopes,
+		CreatedAt: time.Now().Unix(),
+		ExpiresAt: tokenDTO.ExpiresAt,
+	}
+	if err := dataBase.SaveAPIToken(token); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+
+	*tokenDTO = dto.CreateAPIToken(token)
+	tokenDTO.Token = value
+	return nil
+}
+
+// RemoveAPIToken revokes the token, admins can revoke any token
+func RemoveAPIToken(dataBase moira.Database, tokenID, userLogin string, isAdmin bool) *api.ErrorResponse {
+	token, err := dataBase.GetAPIToken(tokenID)
+	if err == database.ErrNil {
+		return api.ErrorNotFound(fmt.Sprintf("API token with ID '%s' does not exist", tokenID))
+	}
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	if token.Login != userLogin && !isAdmin {
+		return api.ErrorForbidden("You have not permissions")
+	}
+
+	if err := dataBase.RemoveAPIToken(tokenID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
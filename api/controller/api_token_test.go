@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestGetAPITokens(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	tokens := []*moira.APIToken{
+		{ID: "2", Login: "terraform", Hash: "hash2", CreatedAt: 20},
+		{ID: "1", Login: "user", Hash: "hash1", CreatedAt: 10},
+	}
+
+	Convey("Users get their own tokens without hashes", t, func() {
+		dataBase.EXPECT().GetAPITokens().Return(tokens, nil)
+		actual, err := GetAPITokens(dataBase, "user", false)
+		So(err, ShouldBeNil)
+		So(actual.List, ShouldResemble, []dto.APIToken{{ID: "1", Login: "user", CreatedAt: 10}})
+	})
+
+	Convey("Admins get all tokens", t, func() {
+		dataBase.EXPECT().GetAPITokens().Return(tokens, nil)
+		actual, err := GetAPITokens(dataBase, "admin", true)
+		So(err, ShouldBeNil)
+		So(actual.List, ShouldHaveLength, 2)
+		So(actual.List[0].ID, ShouldEqual, "1")
+	})
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Token of the user", t, func() {
+		var saved *moira.APIToken
+		dataBase.EXPECT().SaveAPIToken(gomock.Any()).Do(func(token *moira.APIToken) { saved = token }).Return(nil)
+
+		tokenDTO := &dto.APIToken{Name: "ci", Scopes: []moira.APITokenScope{moira.APITokenScopeRead}}
+		So(CreateAPIToken(dataBase, tokenDTO, "user", false), ShouldBeNil)
+		So(tokenDTO.Login, ShouldEqual, "user")
+		So(tokenDTO.ID, ShouldEqual, saved.ID)
+		So(tokenDTO.Token, ShouldStartWith, "moira_")
+		So(saved.Hash, ShouldEqual, api.HashAPIToken(tokenDTO.Token))
+		So(saved.Hash, ShouldNotContainSubstring, tokenDTO.Token)
+	})
+
+	Convey("Only admins can create tokens of services", t, func() {
+		tokenDTO := &dto.APIToken{Name: "terraform", Login: "terraform", Scopes: []moira.APITokenScope{moira.APITokenScopeWriteTriggers}}
+		So(CreateAPIToken(dataBase, tokenDTO, "user", false), ShouldNotBeNil)
+
+		dataBase.EXPECT().SaveAPIToken(gomock.Any()).Return(nil)
+		So(CreateAPIToken(dataBase, tokenDTO, "admin", true), ShouldBeNil)
+		So(tokenDTO.Login, ShouldEqual, "terraform")
+	})
+
+	Convey("Anonymous users can't create tokens", t, func() {
+		So(CreateAPIToken(dataBase, &dto.APIToken{Name: "ci"}, "", true), ShouldNotBeNil)
+	})
+}
+
+func TestRemoveAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Unknown token", t, func() {
+		dataBase.EXPECT().GetAPIToken("1").Return(nil, database.ErrNil)
+		So(RemoveAPIToken(dataBase, "1", "user", false), ShouldResemble, api.ErrorNotFound("API token with ID '1' does not exist"))
+	})
+
+	Convey("Token of the other user", t, func() {
+		dataBase.EXPECT().GetAPIToken("1").Return(&moira.APIToken{ID: "1", Login: "other"}, nil).Times(2)
+		So(RemoveAPIToken(dataBase, "1", "user", false), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+
+		dataBase.EXPECT().RemoveAPIToken("1").Return(nil)
+		So(RemoveAPIToken(dataBase, "1", "admin", true), ShouldBeNil)
+	})
+}
@@ -59,6 +59,9 @@ func CreateContact(dataBase moira.Database, contact *dto.Contact, userLogin stri
 
 // UpdateContact updates notification contact for current user
 func UpdateContact(dataBase moira.Database, contactDTO dto.Contact, contactData moira.ContactData) (dto.Contact, *api.ErrorResponse) {
+	if contactData.Managed != nil {
+		return contactDTO, api.ErrorInvalidRequest(fmt.Errorf("Contact is managed by sync from %s, change it there", contactData.Managed.Source))
+	}
 	contactData.Type = contactDTO.Type
 	contactData.Value = contactDTO.Value
 	contactData.FallbackValue = contactDTO.FallbackValue
@@ -76,7 +79,23 @@ func UpdateContact(dataBase moira.Database, contactDTO dto.Contact, contactData
 }
 
 // RemoveContact deletes notification contact for current user and remove contactID from all subscriptions
-func RemoveContact(database moira.Database, contactID string, userLogin string) *api.ErrorResponse {
+func RemoveContact(dataBase moira.Database, contactID string, userLogin string) *api.ErrorResponse {
+	contactData, err := dataBase.GetContact(contactID)
+	if err != nil && err != database.ErrNil {
+		return api.ErrorInternalServer(err)
+	}
+	if contactData.Managed != nil {
+		return api.ErrorInvalidRequest(fmt.Errorf("Contact is managed by sync from %s, remove it there", contactData.Managed.Source))
+	}
+	return removeContact(dataBase, contactID, userLogin)
+}
+
+// RemoveManagedContact deletes the contact managed by declarative sync
+func RemoveManagedContact(dataBase moira.Database, contactID string, userLogin string) *api.ErrorResponse {
+	return removeContact(dataBase, contactID, userLogin)
+}
+
+func removeContact(database moira.Database, contactID string, userLogin string) *api.ErrorResponse {
 	subscriptionIDs, err := database.GetUserSubscriptionIDs(userLogin)
 	if err != nil {
 		return api.ErrorInternalServer(err)
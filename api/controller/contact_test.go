@@ -231,6 +231,7 @@ func TestRemoveContact(t *testing.T) {
 	contactID := uuid.NewV4().String()
 
 	Convey("Delete contact without user subscriptions", t, func() {
+		dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 		dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return(make([]string, 0), nil)
 		dataBase.EXPECT().GetSubscriptions(make([]string, 0)).Return(make([]*moira.SubscriptionData, 0), nil)
 		dataBase.EXPECT().RemoveContact(contactID).Return(nil)
@@ -245,6 +246,7 @@ func TestRemoveContact(t *testing.T) {
 			ID:       uuid.NewV4().String(),
 		}
 
+		dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 		dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return([]string{subscription.ID}, nil)
 		dataBase.EXPECT().GetSubscriptions([]string{subscription.ID}).Return([]*moira.SubscriptionData{subscription}, nil)
 		dataBase.EXPECT().RemoveContact(contactID).Return(nil)
@@ -261,6 +263,7 @@ func TestRemoveContact(t *testing.T) {
 		expectedSub := subscription
 		expectedSub.Contacts = make([]string, 0)
 
+		dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 		dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return([]string{subscription.ID}, nil)
 		dataBase.EXPECT().GetSubscriptions([]string{subscription.ID}).Return([]*moira.SubscriptionData{&subscription}, nil)
 		dataBase.EXPECT().RemoveContact(contactID).Return(nil)
@@ -269,15 +272,38 @@ func TestRemoveContact(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 
+	Convey("Managed contact is not deleted", t, func() {
+		dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID, Managed: &moira.ManagedBy{Source: "infra", Key: "ops"}}, nil)
+		err := RemoveContact(dataBase, contactID, userLogin)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Contact is managed by sync from infra, remove it there")))
+	})
+
+	Convey("Managed contact is deleted by sync", t, func() {
+		dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return(make([]string, 0), nil)
+		dataBase.EXPECT().GetSubscriptions(make([]string, 0)).Return(make([]*moira.SubscriptionData, 0), nil)
+		dataBase.EXPECT().RemoveContact(contactID).Return(nil)
+		dataBase.EXPECT().SaveSubscriptions(make([]*moira.SubscriptionData, 0)).Return(nil)
+		err := RemoveManagedContact(dataBase, contactID, userLogin)
+		So(err, ShouldBeNil)
+	})
+
 	Convey("Error tests", t, func() {
+		Convey("GetContact", func() {
+			expectedError := fmt.Errorf("Oooops! Can not read contact")
+			dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{}, expectedError)
+			err := RemoveContact(dataBase, contactID, userLogin)
+			So(err, ShouldResemble, api.ErrorInternalServer(expectedError))
+		})
 		Convey("GetUserSubscriptionIDs", func() {
 			expectedError := fmt.Errorf("Oooops! Can not read user subscription ids")
+			dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 			dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return(nil, expectedError)
 			err := RemoveContact(dataBase, contactID, userLogin)
 			So(err, ShouldResemble, api.ErrorInternalServer(expectedError))
 		})
 		Convey("GetSubscriptions", func() {
 			expectedError := fmt.Errorf("Oooops! Can not read user subscriptions")
+			dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 			dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return(make([]string, 0), nil)
 			dataBase.EXPECT().GetSubscriptions(make([]string, 0)).Return(nil, expectedError)
 			err := RemoveContact(dataBase, contactID, userLogin)
@@ -285,6 +311,7 @@ func TestRemoveContact(t *testing.T) {
 		})
 		Convey("RemoveContact", func() {
 			expectedError := fmt.Errorf("Oooops! Can not delete contact")
+			dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 			dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return(make([]string, 0), nil)
 			dataBase.EXPECT().GetSubscriptions(make([]string, 0)).Return(make([]*moira.SubscriptionData, 0), nil)
 			dataBase.EXPECT().RemoveContact(contactID).Return(expectedError)
@@ -293,6 +320,7 @@ func TestRemoveContact(t *testing.T) {
 		})
 		Convey("SaveSubscriptions", func() {
 			expectedError := fmt.Errorf("Oooops! Can not write subscriptions")
+			dataBase.EXPECT().GetContact(contactID).Return(moira.ContactData{ID: contactID}, nil)
 			dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return(make([]string, 0), nil)
 			dataBase.EXPECT().GetSubscriptions(make([]string, 0)).Return(make([]*moira.SubscriptionData, 0), nil)
 			dataBase.EXPECT().RemoveContact(contactID).Return(nil)
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"fmt"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+)
+
+// GetInventoryHost returns metadata of the host known to CMDB
+func GetInventoryHost(provider moira.InventoryProvider, name string) (*dto.InventoryHost, *api.ErrorResponse) {
+	if provider == nil {
+		return nil, api.ErrorNotFound("inventory provider is not configured")
+	}
+
+	host, err := provider.HostInfo(name)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	if host == nil {
+		return nil, api.ErrorNotFound(fmt.Sprintf("host %s not found", name))
+	}
+	return (*dto.InventoryHost)(host), nil
+}
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"fmt"
+
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/dto"
@@ -21,3 +23,81 @@ func GetMaintenanceTrigger(database moira.Database, id string) (dto.Maintenance,
 	}
 	return dto.Maintenance(maintenance), nil
 }
+
+// GetSilentUpcoming lists occurrences of scheduled silent patterns within [from, to)
+func GetSilentUpcoming(database moira.Database, from, to int64) (*dto.SilentUpcoming, *api.ErrorResponse) {
+	metrics, err := database.GetOrCreateMaintenanceSilent(moira.SPTMetric)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	tags, err := database.GetOrCreateMaintenanceSilent(moira.SPTTag)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return &dto.SilentUpcoming{
+		Metrics: metrics.Upcoming(from, to),
+		Tags:    tags.Upcoming(from, to),
+	}, nil
+}
+
+// GetTriggerMaintenanceSchedule lists scheduled windows of the trigger and their occurrences within [from, to)
+func GetTriggerMaintenanceSchedule(database moira.Database, triggerID string, from, to int64) (*dto.MaintenanceSchedule, *api.ErrorResponse) {
+	maintenance, err := database.GetOrCreateMaintenanceTrigger(triggerID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return &dto.MaintenanceSchedule{
+		Windows:  maintenance.Windows(),
+		Upcoming: maintenance.Upcoming(from, to),
+	}, nil
+}
+
+// AddTriggerMaintenanceWindow schedules new maintenance window for the trigger
+func AddTriggerMaintenanceWindow(database moira.Database, triggerID string, window *dto.MaintenanceWindow) *api.ErrorResponse {
+	if err := database.AcquireTriggerMaintenanceLock(triggerID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	defer database.DeleteTriggerMaintenanceLock(triggerID)
+
+	maintenance, err := database.GetOrCreateMaintenanceTrigger(triggerID)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+
+	window.ID = moira.NewStrID()
+	maintenance.AddWindow(window.MaintenanceWindow)
+
+	if err = database.SetMaintenanceTrigger(triggerID, maintenance); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// RemoveTriggerMaintenanceWindow cancels scheduled maintenance window of the trigger
+func RemoveTriggerMaintenanceWindow(database moira.Database, triggerID, windowID string) *api.ErrorResponse {
+	if err := database.AcquireTriggerMaintenanceLock(triggerID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	defer database.DeleteTriggerMaintenanceLock(triggerID)
+
+	maintenance, err := database.GetOrCreateMaintenanceTrigger(triggerID)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+
+	found := false
+	for _, window := range maintenance.Windows() {
+		if window.ID == windowID {
+			found = maintenance.DelWindow(window.Metric, window.ID)
+			break
+		}
+	}
+	if !found {
+		return api.ErrorNotFound(fmt.Sprintf("maintenance window %s not found", windowID))
+	}
+
+	if err = database.SetMaintenanceTrigger(triggerID, maintenance); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
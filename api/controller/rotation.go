@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/database"
+)
+
+// GetRotations returns all the on call rotations
+func GetRotations(dataBase moira.Database) (*dto.RotationList, *api.ErrorResponse) {
+	rotations, err := dataBase.GetOnCallRotations()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	result := &dto.RotationList{List: make([]moira.OnCallRotation, 0, len(rotations))}
+	for _, rotation := range rotations {
+		result.List = append(result.List, *rotation)
+	}
+	sort.Slice(result.List, func(i, j int) bool {
+		return result.List[i].ID < result.List[j].ID
+	})
+	return result, nil
+}
+
+// GetRotation returns the on call rotation
+func GetRotation(dataBase moira.Database, rotationID string) (*dto.Rotation, *api.ErrorResponse) {
+	rotation, err := dataBase.GetOnCallRotation(rotationID)
+	if err == database.ErrNil {
+		return nil, api.ErrorNotFound(fmt.Sprintf("Rotation '%s' doesn't exist", rotationID))
+	}
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return &dto.Rotation{OnCallRotation: *rotation}, nil
+}
+
+// SaveRotation creates or replaces the on call rotation
+func SaveRotation(dataBase moira.Database, rotation *dto.Rotation) *api.ErrorResponse {
+	if err := dataBase.SaveOnCallRotation(&rotation.OnCallRotation); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// rotationContactPrefix is the value of the contact resolved to the person on call by the rotation, duty_moira:<rotation id>
+const rotationContactPrefix = "duty_moira:"
+
+// RemoveRotation removes the on call rotation unless subscriptions or contacts target it
+func RemoveRotation(dataBase moira.Database, rotationID string) *api.ErrorResponse {
+	subscriptions, err := dataBase.GetAllSubscriptions()
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+
+	targeting := make([]string, 0)
+	for _, subscription := range subscriptions {
+		if subscription != nil && isRotationTargeted(subscription, rotationID) {
+			targeting = append(targeting, subscription.ID)
+		}
+	}
+	if len(targeting) > 0 {
+		return api.ErrorInvalidRequest(fmt.Errorf(
+			"Rotation '%s' is targeted by subscriptions %s", rotationID, strings.Join(targeting, ", "),
+		))
+	}
+
+	contacts, err := dataBase.GetAllContacts()
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	for _, contact := range contacts {
+		if contact != nil && contact.Value == rotationContactPrefix+rotationID {
+			targeting = append(targeting, contact.ID)
+		}
+	}
+	if len(targeting) > 0 {
+		return api.ErrorInvalidRequest(fmt.Errorf(
+			"Rotation '%s' is targeted by contacts %s", rotationID, strings.Join(targeting, ", "),
+		))
+	}
+
+	if err := dataBase.RemoveOnCallRotation(rotationID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// GetRotationOnCall tells who is on call by the rotation at ts
+func GetRotationOnCall(dataBase moira.Database, rotationID string, ts int64) (*dto.RotationOnCall, *api.ErrorResponse) {
+	rotation, errorResponse := GetRotation(dataBase, rotationID)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	main, backup, until := rotation.GetOnCall(ts)
+	return &dto.RotationOnCall{
+		Timestamp: ts,
+		Main:      main,
+		Backup:    backup,
+		Until:     until,
+	}, nil
+}
+
+// CheckSubscriptionRotations makes sure that all the rotations targeted by the subscription exist
+func CheckSubscriptionRotations(dataBase moira.Database, subscription *moira.SubscriptionData) *api.ErrorResponse {
+	checked := make(map[string]bool)
+	for _, rotationID := range getTargetedRotations(subscription) {
+		if checked[rotationID] {
+			continue
+		}
+		checked[rotationID] = true
+
+		_, err := dataBase.GetOnCallRotation(rotationID)
+		if err == database.ErrNil {
+			return api.ErrorInvalidRequest(fmt.Errorf("Rotation '%s' doesn't exist", rotationID))
+		}
+		if err != nil {
+			return api.ErrorInternalServer(err)
+		}
+	}
+	return nil
+}
+
+// getTargetedRotations returns the rotations of the subscription and of its escalations
+func getTargetedRotations(subscription *moira.SubscriptionData) []string {
+	rotations := subscription.Rotations
+	for _, escalation := range subscription.Escalations {
+		rotations = append(rotations[:len(rotations):len(rotations)], escalation.Rotations...)
+	}
+	return rotations
+}
+
+func isRotationTargeted(subscription *moira.SubscriptionData, rotationID string) bool {
+	for _, id := range getTargetedRotations(subscription) {
+		if id == rotationID {
+			return true
+		}
+	}
+	return false
+}
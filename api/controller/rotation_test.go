@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestRemoveRotation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Rotation targeted by escalation can't be removed", t, func() {
+		dataBase.EXPECT().GetAllSubscriptions().Return([]*moira.SubscriptionData{
+			{ID: "other", Rotations: []string{"other"}},
+			{ID: "escalated", Escalations: []moira.EscalationData{{Rotations: []string{"team"}}}},
+		}, nil)
+		err := RemoveRotation(dataBase, "team")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Rotation 'team' is targeted by subscriptions escalated")))
+	})
+
+	Convey("Rotation targeted by contact can't be removed", t, func() {
+		dataBase.EXPECT().GetAllSubscriptions().Return([]*moira.SubscriptionData{}, nil)
+		dataBase.EXPECT().GetAllContacts().Return([]*moira.ContactData{
+			{ID: "other", Type: "telegram", Value: "duty_moira:other"},
+			{ID: "on-call", Type: "telegram", Value: "duty_moira:team"},
+		}, nil)
+		err := RemoveRotation(dataBase, "team")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Rotation 'team' is targeted by contacts on-call")))
+	})
+
+	Convey("Rotation is removed", t, func() {
+		dataBase.EXPECT().GetAllSubscriptions().Return([]*moira.SubscriptionData{{ID: "other", Rotations: []string{"other"}}}, nil)
+		dataBase.EXPECT().GetAllContacts().Return([]*moira.ContactData{{ID: "other", Type: "telegram", Value: "duty_moira:other"}}, nil)
+		dataBase.EXPECT().RemoveOnCallRotation("team").Return(nil)
+		So(RemoveRotation(dataBase, "team"), ShouldBeNil)
+	})
+}
+
+func TestGetRotationOnCall(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Unknown rotation", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(nil, database.ErrNil)
+		onCall, err := GetRotationOnCall(dataBase, "team", 0)
+		So(err, ShouldResemble, api.ErrorNotFound("Rotation 'team' doesn't exist"))
+		So(onCall, ShouldBeNil)
+	})
+
+	Convey("Main and backup participants", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(&moira.OnCallRotation{
+			ID:     "team",
+			Layers: []moira.OnCallLayer{{Participants: []moira.OnCallParticipant{{Login: "alice"}, {Login: "bob"}}}},
+		}, nil)
+		onCall, err := GetRotationOnCall(dataBase, "team", 60)
+		So(err, ShouldBeNil)
+		So(onCall.Main.Login, ShouldEqual, "alice")
+		So(onCall.Backup.Login, ShouldEqual, "bob")
+		So(onCall.Until, ShouldEqual, 7*24*60*60)
+	})
+}
+
+func TestCheckSubscriptionRotations(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Rotations of the subscription and of its escalations exist", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(&moira.OnCallRotation{ID: "team"}, nil)
+		dataBase.EXPECT().GetOnCallRotation("backup").Return(&moira.OnCallRotation{ID: "backup"}, nil)
+		So(CheckSubscriptionRotations(dataBase, &moira.SubscriptionData{
+			Rotations:   []string{"team"},
+			Escalations: []moira.EscalationData{{Rotations: []string{"team", "backup"}}},
+		}), ShouldBeNil)
+	})
+
+	Convey("Unknown rotation of the escalation", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(&moira.OnCallRotation{ID: "team"}, nil)
+		dataBase.EXPECT().GetOnCallRotation("tema").Return(nil, database.ErrNil)
+		err := CheckSubscriptionRotations(dataBase, &moira.SubscriptionData{
+			Rotations:   []string{"team"},
+			Escalations: []moira.EscalationData{{Rotations: []string{"tema"}}},
+		})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Rotation 'tema' doesn't exist")))
+	})
+}
@@ -10,7 +10,7 @@ import (
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/dto"
-	"go.avito.ru/DO/moira/netbox"
+	"go.avito.ru/DO/moira/silencer"
 )
 
 const prefixDC = "dc_"
@@ -45,37 +45,13 @@ func (spm *SilentPatternManager) GetSilentPatterns(database moira.Database, patt
 }
 
 func (spm *SilentPatternManager) CreateSilentPatterns(dataBase moira.Database, rawSilentPatterns *dto.SilentPatternList, login string) error {
-	client := netbox.CreateClient(spm.config.Netbox)
-	errorMessages := make([]string, 0, 100)
-	now := time.Now().Unix()
-	silentPatterns := make(map[moira.SilentPatternType][]*moira.SilentPatternData)
-
-	silentPatterns[moira.SPTMetric] = make([]*moira.SilentPatternData, 0, 100)
-	silentPatterns[moira.SPTTag] = make([]*moira.SilentPatternData, 0, 100)
-
-	for _, rawSilentPattern := range rawSilentPatterns.List {
-		parsedPatterns, err := parsePatternString(rawSilentPattern.Pattern, client)
-		if err != nil {
-			errorMessages = append(errorMessages, err.Error())
-			continue
-		}
-
-		patternType := rawSilentPattern.Type
-		for _, parsedPattern := range parsedPatterns {
-			silentPatterns[patternType] = append(silentPatterns[patternType], &moira.SilentPatternData{
-				Login:   login,
-				Pattern: parsedPattern,
-				Created: now,
-				Until:   rawSilentPattern.Until,
-				Type:    patternType,
-			})
-		}
-	}
-
-	if err := joinErrorMessages(errorMessages); err != nil {
+	silentPatterns, err := spm.buildSilentPatterns(rawSilentPatterns, login)
+	if err != nil {
 		return err
 	}
 
+	errorMessages := make([]string, 0, 100)
+
 	for spt, spl := range silentPatterns {
 		if len(spl) == 0 {
 			continue
@@ -104,9 +80,17 @@ func (spm *SilentPatternManager) UpdateSilentPatterns(dataBase moira.Database, r
 	for _, rsp := range rawSilentPatterns.List {
 		rsp.Created = now
 		rsp.Login = login
+		rsp.Managed = nil
+		if rsp.IsMatcher() {
+			rsp.Pattern = rsp.Matcher.String()
+		}
 		silentPatterns[rsp.Type] = append(silentPatterns[rsp.Type], rsp)
 	}
 
+	if err := checkSilentPatternsNotManaged(dataBase, silentPatterns); err != nil {
+		return err
+	}
+
 	for spt, spl := range silentPatterns {
 		if len(spl) == 0 {
 			continue
@@ -142,6 +126,9 @@ func (spm *SilentPatternManager) RemoveSilentPatterns(dataBase moira.Database, r
 	if err := joinErrorMessages(errorMessages); err != nil {
 		return err
 	}
+	if err := checkSilentPatternsNotManaged(dataBase, silentPatterns); err != nil {
+		return err
+	}
 
 	for spt, spl := range silentPatterns {
 		if len(spl) == 0 {
@@ -160,6 +147,162 @@ func (spm *SilentPatternManager) RemoveSilentPatterns(dataBase moira.Database, r
 	return joinErrorMessages(errorMessages)
 }
 
+// checkSilentPatternsNotManaged rejects the changes of the silent patterns which are managed by declarative sync
+func checkSilentPatternsNotManaged(dataBase moira.Database, silentPatterns map[moira.SilentPatternType][]*moira.SilentPatternData) error {
+	for spt, spl := range silentPatterns {
+		if len(spl) == 0 {
+			continue
+		}
+
+		existing, err := dataBase.GetSilentPatternsTyped(spt)
+		if err != nil {
+			return err
+		}
+		managed := make(map[string]*moira.ManagedBy, len(existing))
+		for _, sp := range existing {
+			if sp != nil && sp.Managed != nil {
+				managed[sp.ID] = sp.Managed
+			}
+		}
+		for _, sp := range spl {
+			if managedBy, ok := managed[sp.ID]; ok {
+				return fmt.Errorf("Silent pattern %s is managed by sync from %s, change it there", sp.ID, managedBy.Source)
+			}
+		}
+	}
+	return nil
+}
+
+// PreviewSilentPatterns finds currently firing triggers and metrics which would be muted by the given silent patterns
+func (spm *SilentPatternManager) PreviewSilentPatterns(dataBase moira.Database, rawSilentPatterns *dto.SilentPatternList) (*dto.SilentPreview, error) {
+	silentPatterns, err := spm.buildSilentPatterns(rawSilentPatterns, "")
+	if err != nil {
+		return nil, err
+	}
+
+	triggerIDs, err := dataBase.GetTriggerCheckIDs(nil, true)
+	if err != nil {
+		return nil, err
+	}
+	triggerChecks, err := dataBase.GetTriggerChecks(triggerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	isMatched := func(subject silencer.Subject) bool {
+		for _, spl := range silentPatterns {
+			for _, sp := range spl {
+				if silencer.IsPatternMatched(sp, subject) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	result := &dto.SilentPreview{Triggers: make([]*dto.SilentPreviewTrigger, 0)}
+	for _, triggerCheck := range triggerChecks {
+		if triggerCheck == nil || triggerCheck.LastCheck == nil {
+			continue
+		}
+
+		lastCheck := triggerCheck.LastCheck
+		previewTrigger := &dto.SilentPreviewTrigger{
+			ID:      triggerCheck.ID,
+			Name:    triggerCheck.Name,
+			State:   lastCheck.State,
+			Metrics: make(map[string]string),
+		}
+
+		subject := silencer.Subject{
+			TriggerID: triggerCheck.ID,
+			Tags:      triggerCheck.Tags,
+			State:     lastCheck.State,
+		}
+		previewTrigger.Muted = lastCheck.State != moira.OK && isMatched(subject)
+
+		for metric, metricState := range lastCheck.Metrics {
+			if metricState.State == moira.OK {
+				continue
+			}
+			subject.Metric, subject.State = metric, metricState.State
+			if isMatched(subject) {
+				previewTrigger.Metrics[metric] = metricState.State
+			}
+		}
+
+		if previewTrigger.Muted || len(previewTrigger.Metrics) > 0 {
+			result.Triggers = append(result.Triggers, previewTrigger)
+		}
+	}
+	return result, nil
+}
+
+// buildSilentPatterns turns raw silent patterns given by user to the ones which can be saved
+func (spm *SilentPatternManager) buildSilentPatterns(rawSilentPatterns *dto.SilentPatternList, login string) (map[moira.SilentPatternType][]*moira.SilentPatternData, error) {
+	errorMessages := make([]string, 0, 100)
+	now := time.Now().Unix()
+	silentPatterns := make(map[moira.SilentPatternType][]*moira.SilentPatternData)
+
+	silentPatterns[moira.SPTMetric] = make([]*moira.SilentPatternData, 0, 100)
+	silentPatterns[moira.SPTTag] = make([]*moira.SilentPatternData, 0, 100)
+	silentPatterns[moira.SPTMatcher] = make([]*moira.SilentPatternData, 0, 100)
+
+	for _, rawSilentPattern := range rawSilentPatterns.List {
+		built, err := BuildSilentPatterns(rawSilentPattern, login, now, spm.config.Inventory)
+		if err != nil {
+			errorMessages = append(errorMessages, err.Error())
+			continue
+		}
+		patternType := rawSilentPattern.Type
+		silentPatterns[patternType] = append(silentPatterns[patternType], built...)
+	}
+
+	if err := joinErrorMessages(errorMessages); err != nil {
+		return nil, err
+	}
+	return silentPatterns, nil
+}
+
+// BuildSilentPatterns validates the raw silent pattern given by user and turns it to the ones which can be saved:
+// ranges, racks and rack groups are expanded, matchers get their readable patterns.
+// Both api and declarative sync build the patterns this way
+func BuildSilentPatterns(rawSilentPattern *moira.SilentPatternData, login string, now int64, inventory moira.InventoryProvider) ([]*moira.SilentPatternData, error) {
+	if err := dto.CheckSilentPattern(rawSilentPattern); err != nil {
+		return nil, err
+	}
+
+	var parsedPatterns []string
+	if rawSilentPattern.IsMatcher() {
+		parsedPatterns = []string{rawSilentPattern.Matcher.String()}
+	} else {
+		var err error
+		if parsedPatterns, err = parsePatternString(rawSilentPattern.Pattern, inventory); err != nil {
+			return nil, err
+		}
+	}
+
+	// the patterns are managed by declarative sync only if created by it, so neither ID nor Managed is taken
+	result := make([]*moira.SilentPatternData, 0, len(parsedPatterns))
+	for _, parsedPattern := range parsedPatterns {
+		result = append(result, &moira.SilentPatternData{
+			Login:   login,
+			Pattern: parsedPattern,
+			Created: now,
+			Until:   rawSilentPattern.Until,
+			Type:    rawSilentPattern.Type,
+
+			Start:      rawSilentPattern.Start,
+			Recurrence: rawSilentPattern.Recurrence,
+
+			Comment: rawSilentPattern.Comment,
+			Ticket:  rawSilentPattern.Ticket,
+			Matcher: rawSilentPattern.Matcher,
+		})
+	}
+	return result, nil
+}
+
 func joinErrorMessages(messages []string) error {
 	if len(messages) > 0 {
 		return fmt.Errorf(strings.Join(messages, "\n"))
@@ -167,10 +310,10 @@ func joinErrorMessages(messages []string) error {
 	return nil
 }
 
-func parsePatternString(pattern string, client *netbox.Client) (result []string, err error) {
+func parsePatternString(pattern string, inventory moira.InventoryProvider) (result []string, err error) {
 	var parser = &patternParser{
-		client:  client,
-		pattern: pattern,
+		inventory: inventory,
+		pattern:   pattern,
 	}
 
 	defer func() {
@@ -197,8 +340,8 @@ func parsePatternString(pattern string, client *netbox.Client) (result []string,
 }
 
 type patternParser struct {
-	client  *netbox.Client
-	pattern string
+	inventory moira.InventoryProvider
+	pattern   string
 }
 
 // expandPatternsRange expands patterns range given as string like "avi-rabbitmq0[1-5]"
@@ -261,99 +404,30 @@ func (parser *patternParser) expandPatternsRange() []string {
 // expandRack expands single rack given as string
 // to the slice of strings containing single pattern each
 func (parser *patternParser) expandRack() []string {
-	if parser.client == nil {
-		panic(errors.New("Netbox client must be enabled and set"))
-	}
-
-	pattern := parser.pattern[len(prefixRack):]
-	racks := parser.client.RackList(&pattern)
-
-	// there should be exactly one rack with this name
-	if len(racks.List) != 1 {
-		panic(errors.New(fmt.Sprintf("Could not find rack with name \"%s\" (%d found)", pattern, len(racks.List))))
-	}
-
-	// obtaining device list for this rack
-	devices := parser.client.DeviceList(nil, &racks.List[0].Id)
-	return parser.saturateDeviceList(devices)
+	return parser.expandGroup(moira.InventoryGroupRack, parser.pattern[len(prefixRack):])
 }
 
 // expandRackGroup expands single rack group given as string
 // to the slice of strings containing single pattern each
 func (parser *patternParser) expandRackGroup() []string {
-	if parser.client == nil {
-		panic(errors.New("Netbox client must be enabled and set"))
-	}
-
-	pattern := parser.pattern[len(prefixDC):]
-	rackGroups := parser.client.RackGroupList(&pattern)
-
-	// there should be exactly one rack group with this name
-	if len(rackGroups.List) != 1 {
-		panic(errors.New(fmt.Sprintf("Could not find rack group with name \"%s\" (%d found)", pattern, len(rackGroups.List))))
-	}
-
-	// obtaining device list for this rack group
-	devices := parser.client.DeviceList(&rackGroups.List[0].Id, nil)
-	return parser.saturateDeviceList(devices)
+	return parser.expandGroup(moira.InventoryGroupRackGroup, parser.pattern[len(prefixDC):])
 }
 
-// pickFromDeviceList processes device list and returns only ids and names from it
-func (parser *patternParser) pickFromDeviceList(devices netbox.DeviceList) (ids []int, names []string) {
-	ids = make([]int, 0, len(devices.List))
-	names = make([]string, 0, len(devices.List))
-	qty := 0
-
-	// will process only devices with this roles
-	considerableRoles := map[string]bool{
-		"server": true,
-		"switch": true,
+// expandGroup requests names of hosts and their containers of the group from inventory provider
+func (parser *patternParser) expandGroup(kind moira.InventoryGroupKind, name string) []string {
+	if parser.inventory == nil {
+		panic(errors.New("Inventory provider must be enabled and set"))
 	}
 
-	for _, device := range devices.List {
-		if device.Role == nil || !considerableRoles[device.Role.Slug] {
-			continue
-		}
-
-		ids = append(ids, device.Id)
-		names = append(names, device.Name)
-		qty++
+	result, err := parser.inventory.GroupHosts(kind, name)
+	if err != nil {
+		panic(err)
 	}
 
-	if qty == 0 {
+	if len(result) == 0 {
 		// no devices found - it is not exactly an error but we make it that way
 		// so that user can notice he's adding an empty list
 		panic(errors.New(fmt.Sprintf("0 devices will be added for pattern \"%s\"", parser.pattern)))
 	}
-
-	return
-}
-
-// saturateDeviceList requests nested containers from device list and
-// returns flattened list of all names (both containers' and devices' names)
-func (parser *patternParser) saturateDeviceList(devices netbox.DeviceList) []string {
-	ids, names := parser.pickFromDeviceList(devices)
-	containers := parser.client.ContainerList(ids)
-
-	totalQty := len(names) + len(containers.List)
-	result := make([]string, 0, totalQty)
-	usedNames := make(map[string]bool, totalQty) // avoid duplicated names in result
-
-	// devices' names
-	for _, name := range names {
-		if !usedNames[name] {
-			result = append(result, name)
-			usedNames[name] = true
-		}
-	}
-
-	// containers' names
-	for _, container := range containers.List {
-		if !usedNames[container.Name] {
-			result = append(result, container.Name)
-			usedNames[container.Name] = true
-		}
-	}
-
 	return result
 }
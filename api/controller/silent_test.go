@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestPreviewSilentPatterns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	manager := CreateSilentPatternManager(api.Config{})
+
+	triggerChecks := []*moira.TriggerCheck{
+		{
+			Trigger: moira.Trigger{ID: "mysql", Name: "MySQL", Tags: []string{"prod", "mysql"}},
+			LastCheck: &moira.CheckData{
+				State: moira.OK,
+				Metrics: map[string]*moira.MetricState{
+					"db.mysql01.cpu": {State: moira.ERROR},
+					"db.mysql02.cpu": {State: moira.WARN},
+					"db.mysql03.cpu": {State: moira.OK},
+				},
+			},
+		},
+		{
+			Trigger: moira.Trigger{ID: "nginx", Name: "Nginx", Tags: []string{"prod", "nginx"}},
+			LastCheck: &moira.CheckData{
+				State:   moira.NODATA,
+				Metrics: map[string]*moira.MetricState{},
+			},
+		},
+		nil,
+	}
+
+	Convey("Matcher of tags and states", t, func() {
+		dataBase.EXPECT().GetTriggerCheckIDs(nil, true).Return([]string{"mysql", "nginx", "deleted"}, nil)
+		dataBase.EXPECT().GetTriggerChecks([]string{"mysql", "nginx", "deleted"}).Return(triggerChecks, nil)
+
+		preview, err := manager.PreviewSilentPatterns(dataBase, &dto.SilentPatternList{List: []*moira.SilentPatternData{{
+			Type:    moira.SPTMatcher,
+			Matcher: &moira.SilentMatcher{Tags: []string{"mysql"}, States: []string{moira.ERROR}},
+			Comment: "migration",
+		}}})
+		So(err, ShouldBeNil)
+		So(preview.Triggers, ShouldResemble, []*dto.SilentPreviewTrigger{{
+			ID:      "mysql",
+			Name:    "MySQL",
+			State:   moira.OK,
+			Metrics: map[string]string{"db.mysql01.cpu": moira.ERROR},
+		}})
+	})
+
+	Convey("Tag pattern mutes the whole trigger", t, func() {
+		dataBase.EXPECT().GetTriggerCheckIDs(nil, true).Return([]string{"mysql", "nginx", "deleted"}, nil)
+		dataBase.EXPECT().GetTriggerChecks([]string{"mysql", "nginx", "deleted"}).Return(triggerChecks, nil)
+
+		preview, err := manager.PreviewSilentPatterns(dataBase, &dto.SilentPatternList{List: []*moira.SilentPatternData{{
+			Type:    moira.SPTTag,
+			Pattern: "nginx",
+			Comment: "migration",
+		}}})
+		So(err, ShouldBeNil)
+		So(preview.Triggers, ShouldResemble, []*dto.SilentPreviewTrigger{{
+			ID:      "nginx",
+			Name:    "Nginx",
+			State:   moira.NODATA,
+			Muted:   true,
+			Metrics: map[string]string{},
+		}})
+	})
+}
+
+func TestManagedSilentPatterns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	manager := CreateSilentPatternManager(api.Config{})
+	existing := []*moira.SilentPatternData{
+		{ID: "manual", Type: moira.SPTMetric, Pattern: "db.mysql01"},
+		{ID: "synced", Type: moira.SPTMetric, Pattern: "db.mysql02", Managed: &moira.ManagedBy{Source: "infra", Key: "mysql02"}},
+	}
+
+	Convey("Managed pattern is not updated", t, func() {
+		dataBase.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return(existing, nil)
+		err := manager.UpdateSilentPatterns(dataBase, &dto.SilentPatternList{List: []*moira.SilentPatternData{
+			{ID: "synced", Type: moira.SPTMetric, Pattern: "db.mysql02", Until: 100},
+		}}, "user")
+		So(err, ShouldResemble, fmt.Errorf("Silent pattern synced is managed by sync from infra, change it there"))
+	})
+
+	Convey("Pattern can't be made managed by update", t, func() {
+		pattern := &moira.SilentPatternData{ID: "manual", Type: moira.SPTMetric, Pattern: "db.mysql01", Managed: &moira.ManagedBy{Source: "infra", Key: "mysql01"}}
+		dataBase.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return(existing, nil)
+		dataBase.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+		dataBase.EXPECT().SaveSilentPatterns(moira.SPTMetric, pattern).Return(nil)
+		dataBase.EXPECT().UnlockSilentPatterns(moira.SPTMetric).Return(nil)
+		err := manager.UpdateSilentPatterns(dataBase, &dto.SilentPatternList{List: []*moira.SilentPatternData{pattern}}, "user")
+		So(err, ShouldBeNil)
+		So(pattern.Managed, ShouldBeNil)
+	})
+
+	Convey("Managed pattern is not removed", t, func() {
+		dataBase.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return(existing, nil)
+		err := manager.RemoveSilentPatterns(dataBase, &dto.SilentPatternList{List: []*moira.SilentPatternData{
+			{ID: "manual", Type: moira.SPTMetric},
+			{ID: "synced", Type: moira.SPTMetric},
+		}})
+		So(err, ShouldResemble, fmt.Errorf("Silent pattern synced is managed by sync from infra, change it there"))
+	})
+
+	Convey("Created pattern is never managed", t, func() {
+		dataBase.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+		dataBase.EXPECT().SaveSilentPatterns(moira.SPTMetric, gomock.Any()).Do(func(_ moira.SilentPatternType, patterns ...*moira.SilentPatternData) {
+			So(patterns, ShouldHaveLength, 1)
+			So(patterns[0].ID, ShouldBeEmpty)
+			So(patterns[0].Managed, ShouldBeNil)
+		}).Return(nil)
+		dataBase.EXPECT().UnlockSilentPatterns(moira.SPTMetric).Return(nil)
+		err := manager.CreateSilentPatterns(dataBase, &dto.SilentPatternList{List: []*moira.SilentPatternData{
+			{ID: "synced", Type: moira.SPTMetric, Pattern: "db.mysql02", Comment: "reboot", Managed: &moira.ManagedBy{Source: "infra", Key: "mysql02"}},
+		}}, "user")
+		So(err, ShouldBeNil)
+	})
+}
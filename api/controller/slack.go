@@ -0,0 +1,305 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/logging"
+	slack_sender "go.avito.ru/DO/moira/senders/slack"
+)
+
+const slackSilentComment = "Silenced from Slack"
+
+// SlackAction is the action taken by Slack user on the message sent by Moira
+type SlackAction struct {
+	Target   slack_sender.ActionTarget
+	ActionID string
+	Option   string   // selected option of maintenance and silence menus
+	Metrics  []string // metrics the message is about
+	Login    string
+}
+
+// HandleSlackActions applies the block actions on behalf of the users allowed to change the triggers
+// and updates the original message and its thread dashboard
+func HandleSlackActions(database moira.Database, authorizer *api.Authorizer, config api.SlackConfig, callback *slack.InteractionCallback) *api.ErrorResponse {
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+	case slack.InteractionTypeInteractionMessage:
+		return handleLegacySlackAck(database, authorizer, config, callback)
+	default:
+		return api.ErrorInvalidRequest(fmt.Errorf("unsupported interaction type '%s'", callback.Type))
+	}
+
+	login := GetSlackLogin(config, callback.User)
+	if login == "" {
+		return api.ErrorForbidden(fmt.Sprintf("Slack user %s is not mapped to Moira login", callback.User.ID))
+	}
+	messageTs := callback.Container.MessageTs
+	if messageTs == "" {
+		messageTs = callback.Message.Timestamp
+	}
+
+	for _, blockAction := range callback.ActionCallback.BlockActions {
+		target, err := slack_sender.ParseActionTarget(blockAction.BlockID)
+		if err != nil {
+			// the message might have blocks which are handled by other apps
+			continue
+		}
+
+		action := &SlackAction{
+			Target:   target,
+			ActionID: blockAction.ActionID,
+			Option:   blockAction.SelectedOption.Value,
+			Login:    login,
+		}
+		if value := slack_sender.FindActionValue(callback.Message.Attachments, blockAction.BlockID); value != nil {
+			action.Metrics = value.Metrics
+		}
+
+		dashboardTs, dashboard := getSlackThreadDashboard(database, target, messageTs)
+		if len(action.Metrics) == 0 {
+			action.Metrics = getFailedDashboardMetrics(dashboard)
+		}
+
+		trigger, errorResponse := GetTrigger(database, target.TriggerID)
+		if errorResponse != nil {
+			return errorResponse
+		}
+		if errorResponse := authorizer.CheckTrigger(login, trigger.ToMoiraTrigger()); errorResponse != nil {
+			return errorResponse
+		}
+
+		logger := logging.GetLogger(target.TriggerID)
+		note, errorResponse := ApplySlackAction(database, action)
+		logger.InfoE(
+			fmt.Sprintf("Slack user %s has taken action %s on trigger id %s with err = %v", login, action.ActionID, target.TriggerID, errorResponse),
+			map[string]interface{}{
+				"action": action,
+				"user":   login,
+			},
+		)
+		if errorResponse != nil {
+			return errorResponse
+		}
+
+		if config.APIToken == "" {
+			continue
+		}
+		client := slack_sender.NewSlack(config.APIToken, logger)
+
+		attachments := slack_sender.MarkActionDone(callback.Message.Attachments, blockAction.BlockID, action.ActionID, note)
+		_, _, _, err = client.SendMessage(
+			callback.Channel.ID,
+			slack.MsgOptionUpdate(messageTs),
+			slack.MsgOptionText(callback.Message.Text, false),
+			slack.MsgOptionAttachments(attachments...),
+		)
+		if err != nil {
+			logger.ErrorF("Failed to update Slack message %s in %s: %v", messageTs, callback.Channel.ID, err)
+		}
+
+		if dashboard != nil {
+			text := slack_sender.RenderDashboardForSlack(dashboard, 0) + "\n" + note
+			_, _, _, _ = client.UpdateMessage(callback.Channel.ID, dashboardTs, text, true)
+		}
+	}
+	return nil
+}
+
+// handleLegacySlackAck acknowledges escalations by the ack button attachment of the messages sent before the actions blocks
+func handleLegacySlackAck(database moira.Database, authorizer *api.Authorizer, config api.SlackConfig, callback *slack.InteractionCallback) *api.ErrorResponse {
+	triggerID, value, err := slack_sender.ParseLegacyAck(callback.CallbackID, callback.ActionCallback.AttachmentActions)
+	if err != nil {
+		return api.ErrorInvalidRequest(err)
+	}
+
+	login := GetSlackLogin(config, callback.User)
+	if login == "" {
+		return api.ErrorForbidden(fmt.Sprintf("Slack user %s is not mapped to Moira login", callback.User.ID))
+	}
+	trigger, errorResponse := GetTrigger(database, triggerID)
+	if errorResponse != nil {
+		return errorResponse
+	}
+	if errorResponse := authorizer.CheckTrigger(login, trigger.ToMoiraTrigger()); errorResponse != nil {
+		return errorResponse
+	}
+
+	action := &SlackAction{
+		Target:   slack_sender.ActionTarget{TriggerID: triggerID},
+		ActionID: slack_sender.ActionAck,
+		Metrics:  value.Metrics,
+		Login:    login,
+	}
+	logger := logging.GetLogger(triggerID)
+	note, errorResponse := ApplySlackAction(database, action)
+	logger.InfoE(
+		fmt.Sprintf("Slack user %s has taken action %s on trigger id %s with err = %v", login, action.ActionID, triggerID, errorResponse),
+		map[string]interface{}{
+			"action": action,
+			"user":   login,
+		},
+	)
+	if errorResponse != nil || config.APIToken == "" {
+		return errorResponse
+	}
+
+	messageTs := callback.MessageTs
+	if messageTs == "" {
+		messageTs = callback.OriginalMessage.Timestamp
+	}
+	client := slack_sender.NewSlack(config.APIToken, logger)
+	attachments := slack_sender.MarkLegacyAckDone(callback.OriginalMessage.Attachments, callback.CallbackID, note)
+	_, _, _, err = client.SendMessage(
+		callback.Channel.ID,
+		slack.MsgOptionUpdate(messageTs),
+		slack.MsgOptionText(callback.OriginalMessage.Text, false),
+		slack.MsgOptionAttachments(attachments...),
+	)
+	if err != nil {
+		logger.ErrorF("Failed to update Slack message %s in %s: %v", messageTs, callback.Channel.ID, err)
+	}
+	return nil
+}
+
+// ApplySlackAction acknowledges escalations, sets maintenance or creates silent patterns
+// and returns the note about what has been done
+func ApplySlackAction(database moira.Database, action *SlackAction) (string, *api.ErrorResponse) {
+	triggerID := action.Target.TriggerID
+
+	if action.ActionID == slack_sender.ActionAck {
+		if len(action.Metrics) == 0 {
+			if errorResponse := AckEscalations(database, triggerID); errorResponse != nil {
+				return "", errorResponse
+			}
+		} else {
+			if err := database.AckEscalationsBatch(triggerID, action.Metrics, false); err != nil {
+				return "", api.ErrorInternalServer(err)
+			}
+			for _, metric := range action.Metrics {
+				if err := database.AckUnacknowledgedMessages(triggerID, metric); err != nil {
+					return "", api.ErrorInternalServer(err)
+				}
+			}
+		}
+		return fmt.Sprintf("Acknowledged by %s", action.Login), nil
+	}
+
+	scope, duration, err := slack_sender.ParseActionOption(action.Option)
+	if err != nil {
+		return "", api.ErrorInvalidRequest(err)
+	}
+	if scope == slack_sender.ActionScopeMetrics && len(action.Metrics) == 0 {
+		return "", api.ErrorInvalidRequest(fmt.Errorf("metrics of the message are unknown"))
+	}
+
+	subject := "The trigger"
+	if scope == slack_sender.ActionScopeMetrics {
+		subject = fmt.Sprintf("%d metric(s)", len(action.Metrics))
+	}
+	until := time.Now().Unix() + duration
+
+	switch action.ActionID {
+	case slack_sender.ActionMaintenance:
+		var errorResponse *api.ErrorResponse
+		if scope == slack_sender.ActionScopeTrigger {
+			errorResponse = SetTriggerMaintenance(database, triggerID, until)
+		} else {
+			update := make(dto.MetricsMaintenance, len(action.Metrics))
+			for _, metric := range action.Metrics {
+				update[metric] = until
+			}
+			errorResponse = SetMetricsMaintenance(database, triggerID, update)
+		}
+		if errorResponse != nil {
+			return "", errorResponse
+		}
+		return fmt.Sprintf("%s put into maintenance for %s by %s", subject, slack_sender.FormatActionDuration(duration), action.Login), nil
+
+	case slack_sender.ActionSilence:
+		if err := saveSlackSilentPatterns(database, action, scope, until); err != nil {
+			return "", api.ErrorInternalServer(err)
+		}
+		return fmt.Sprintf("%s silenced for %s by %s", subject, slack_sender.FormatActionDuration(duration), action.Login), nil
+
+	default:
+		return "", api.ErrorInvalidRequest(fmt.Errorf("unknown action '%s'", action.ActionID))
+	}
+}
+
+// saveSlackSilentPatterns silences either the whole trigger (via matcher) or the metrics of the message
+func saveSlackSilentPatterns(database moira.Database, action *SlackAction, scope string, until int64) error {
+	now := time.Now().Unix()
+	newPattern := func(spt moira.SilentPatternType, pattern string) *moira.SilentPatternData {
+		return &moira.SilentPatternData{
+			Login:   action.Login,
+			Pattern: pattern,
+			Created: now,
+			Until:   until,
+			Type:    spt,
+			Comment: slackSilentComment,
+		}
+	}
+
+	var (
+		spt moira.SilentPatternType
+		spl []*moira.SilentPatternData
+	)
+	if scope == slack_sender.ActionScopeTrigger {
+		matcher := &moira.SilentMatcher{TriggerID: action.Target.TriggerID}
+		pattern := newPattern(moira.SPTMatcher, matcher.String())
+		pattern.Matcher = matcher
+
+		spt, spl = moira.SPTMatcher, []*moira.SilentPatternData{pattern}
+	} else {
+		spt, spl = moira.SPTMetric, make([]*moira.SilentPatternData, 0, len(action.Metrics))
+		for _, metric := range action.Metrics {
+			spl = append(spl, newPattern(moira.SPTMetric, metric))
+		}
+	}
+
+	if err := database.LockSilentPatterns(spt); err != nil {
+		return err
+	}
+	defer database.UnlockSilentPatterns(spt)
+
+	return database.SaveSilentPatterns(spt, spl...)
+}
+
+// GetSlackLogin maps Slack user to Moira login, the users who are not mapped are not known to Moira
+// since Slack usernames can be chosen by anyone
+func GetSlackLogin(config api.SlackConfig, user slack.User) string {
+	return config.Users[user.ID]
+}
+
+// getSlackThreadDashboard finds the dashboard posted to the thread of the message
+func getSlackThreadDashboard(database moira.Database, target slack_sender.ActionTarget, messageTs string) (string, moira.SlackDashboard) {
+	threads, err := database.GetSlackThreadLinks(target.Contact, target.TriggerID)
+	if err != nil {
+		return "", nil
+	}
+	dashboardTs, ok := threads[messageTs]
+	if !ok {
+		return "", nil
+	}
+	dashboard, err := database.GetSlackDashboard(target.Contact, dashboardTs)
+	if err != nil || len(dashboard) == 0 {
+		return "", nil
+	}
+	return dashboardTs, dashboard
+}
+
+func getFailedDashboardMetrics(dashboard moira.SlackDashboard) []string {
+	result := make([]string, 0, len(dashboard))
+	for metric, isOK := range dashboard {
+		if !isOK {
+			result = append(result, metric)
+		}
+	}
+	return result
+}
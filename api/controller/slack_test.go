@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/slack-go/slack"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	slack_sender "go.avito.ru/DO/moira/senders/slack"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestApplySlackAction(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	target := slack_sender.ActionTarget{TriggerID: "trigger-id", Contact: "#alerts"}
+	metrics := []string{"metric1", "metric2"}
+
+	Convey("Ack escalations of the message metrics", t, func() {
+		database.EXPECT().AckEscalationsBatch(target.TriggerID, metrics, false).Return(nil)
+		database.EXPECT().AckUnacknowledgedMessages(target.TriggerID, "metric1").Return(nil)
+		database.EXPECT().AckUnacknowledgedMessages(target.TriggerID, "metric2").Return(nil)
+
+		note, err := ApplySlackAction(database, &SlackAction{Target: target, ActionID: slack_sender.ActionAck, Metrics: metrics, Login: "user"})
+		So(err, ShouldBeNil)
+		So(note, ShouldEqual, "Acknowledged by user")
+	})
+
+	Convey("Maintenance of the whole trigger", t, func() {
+		maintenance := moira.NewMaintenance()
+		database.EXPECT().AcquireTriggerMaintenanceLock(target.TriggerID).Return(nil)
+		database.EXPECT().DeleteTriggerMaintenanceLock(target.TriggerID)
+		database.EXPECT().GetMaintenanceTrigger(target.TriggerID).Return(maintenance, nil)
+		database.EXPECT().SetMaintenanceTrigger(target.TriggerID, maintenance).Return(nil)
+
+		note, err := ApplySlackAction(database, &SlackAction{Target: target, ActionID: slack_sender.ActionMaintenance, Option: "trigger:3600", Login: "user"})
+		So(err, ShouldBeNil)
+		So(note, ShouldEqual, "The trigger put into maintenance for 1 hour by user")
+
+		maintained, _ := maintenance.Get(moira.WildcardMetric, 0)
+		So(maintained, ShouldBeTrue)
+	})
+
+	Convey("Silence of the message metrics", t, func() {
+		database.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+		database.EXPECT().UnlockSilentPatterns(moira.SPTMetric)
+		database.EXPECT().SaveSilentPatterns(moira.SPTMetric, gomock.Any(), gomock.Any()).Do(
+			func(_ moira.SilentPatternType, spl ...*moira.SilentPatternData) {
+				So(spl[0].Pattern, ShouldEqual, "metric1")
+				So(spl[1].Pattern, ShouldEqual, "metric2")
+				So(spl[0].Login, ShouldEqual, "user")
+				So(spl[0].Comment, ShouldEqual, slackSilentComment)
+			},
+		).Return(nil)
+
+		note, err := ApplySlackAction(database, &SlackAction{Target: target, ActionID: slack_sender.ActionSilence, Option: "metrics:86400", Metrics: metrics, Login: "user"})
+		So(err, ShouldBeNil)
+		So(note, ShouldEqual, "2 metric(s) silenced for 1 day by user")
+	})
+
+	Convey("Silence of the whole trigger is made by matcher", t, func() {
+		database.EXPECT().LockSilentPatterns(moira.SPTMatcher).Return(nil)
+		database.EXPECT().UnlockSilentPatterns(moira.SPTMatcher)
+		database.EXPECT().SaveSilentPatterns(moira.SPTMatcher, gomock.Any()).Do(
+			func(_ moira.SilentPatternType, spl ...*moira.SilentPatternData) {
+				So(spl[0].Matcher, ShouldResemble, &moira.SilentMatcher{TriggerID: target.TriggerID})
+				So(spl[0].Pattern, ShouldEqual, spl[0].Matcher.String())
+			},
+		).Return(nil)
+
+		_, err := ApplySlackAction(database, &SlackAction{Target: target, ActionID: slack_sender.ActionSilence, Option: "trigger:3600", Login: "user"})
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Invalid actions", t, func() {
+		_, err := ApplySlackAction(database, &SlackAction{Target: target, ActionID: slack_sender.ActionSilence, Option: "metrics:3600"})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(err.Err))
+
+		_, err = ApplySlackAction(database, &SlackAction{Target: target, ActionID: slack_sender.ActionMaintenance, Option: "forever"})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(err.Err))
+
+		_, err = ApplySlackAction(database, &SlackAction{Target: target, ActionID: "unknown", Option: "trigger:3600"})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(err.Err))
+	})
+}
+
+func TestHandleSlackActions(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	authorizer := api.NewAuthorizer(api.AuthorizationConfig{}, nil, database)
+	config := api.SlackConfig{Users: map[string]string{"U1": "user"}}
+	triggerID := "13b3f1c6-4a3b-4d3e-8a7e-0d6e2f5c9a11"
+
+	Convey("Legacy ack button acknowledges escalations of the message metrics", t, func() {
+		database.EXPECT().GetTrigger(triggerID).Return(&moira.Trigger{ID: triggerID}, nil)
+		database.EXPECT().GetTriggerThrottling(triggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		database.EXPECT().TriggerHasPendingEscalations(triggerID, false).Return(false, nil)
+		database.EXPECT().GetTriggers(gomock.Any()).Return(nil, nil)
+		database.EXPECT().AckEscalationsBatch(triggerID, []string{"metric1"}, false).Return(nil)
+		database.EXPECT().AckUnacknowledgedMessages(triggerID, "metric1").Return(nil)
+
+		callback := &slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: "trigger_escalation_ack:" + triggerID,
+			User:       slack.User{ID: "U1"},
+			ActionCallback: slack.ActionCallbacks{AttachmentActions: []*slack.AttachmentAction{
+				{Name: "trigger_id", Value: `{"metrics": ["metric1"]}`},
+			}},
+		}
+		So(HandleSlackActions(database, authorizer, config, callback), ShouldBeNil)
+	})
+
+	Convey("Slack user who is not mapped is rejected", t, func() {
+		callback := &slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: "trigger_escalation_ack:" + triggerID,
+			User:       slack.User{ID: "U2", Name: "user"},
+		}
+		err := HandleSlackActions(database, authorizer, config, callback)
+		So(err, ShouldResemble, api.ErrorForbidden(err.ErrorText))
+
+		callback.Type = slack.InteractionTypeBlockActions
+		err = HandleSlackActions(database, authorizer, config, callback)
+		So(err, ShouldResemble, api.ErrorForbidden(err.ErrorText))
+	})
+
+	Convey("Other interactions are not supported", t, func() {
+		err := HandleSlackActions(database, authorizer, config, &slack.InteractionCallback{Type: slack.InteractionTypeDialogSubmission})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(err.Err))
+
+		err = HandleSlackActions(database, authorizer, config, &slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: "other-app",
+		})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(err.Err))
+	})
+}
+
+func TestGetSlackLogin(t *testing.T) {
+	Convey("Slack user is mapped to Moira login", t, func() {
+		config := api.SlackConfig{Users: map[string]string{"U1": "jdoe"}}
+		So(GetSlackLogin(config, slack.User{ID: "U1", Name: "john"}), ShouldEqual, "jdoe")
+	})
+
+	Convey("Slack user who is not mapped is not known to Moira", t, func() {
+		config := api.SlackConfig{Users: map[string]string{"U1": "jdoe"}}
+		So(GetSlackLogin(config, slack.User{ID: "U2", Name: "jdoe"}), ShouldBeEmpty)
+		So(GetSlackLogin(config, slack.User{ID: "U3"}), ShouldBeEmpty)
+	})
+}
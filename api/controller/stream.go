@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"math"
+	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/stream"
+	"go.avito.ru/DO/moira/database"
+)
+
+// maxReplayPeriod limits the events which are sent again to the client resuming the stream
+const maxReplayPeriod = 24 * time.Hour
+
+// GetEventsToReplay returns the events which have been emitted since the event with given stream ID.
+// The events are resumed by their timestamps, so the events which come late with older timestamps are not replayed.
+// The order of the events of the same second is not kept by the database, so all of them but the last event itself
+// are sent again, the client tells the ones it has already got by their IDs
+func GetEventsToReplay(dataBase moira.Database, filter *stream.Filter, lastEventID string) ([]*moira.NotificationEvent, *api.ErrorResponse) {
+	timestamp, eventID, err := stream.ParseEventID(lastEventID)
+	if err != nil {
+		return nil, api.ErrorInvalidRequest(err)
+	}
+
+	from := timestamp
+	if oldest := time.Now().Add(-maxReplayPeriod).Unix(); from < oldest {
+		from = oldest
+	}
+	events, err := dataBase.GetAllNotificationEvents(from, math.MaxInt64)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	result := make([]*moira.NotificationEvent, 0, len(events))
+	triggerTags := make(map[string][]string)
+	for _, event := range events {
+		if event.Timestamp == timestamp && event.ID == eventID {
+			continue
+		}
+		var tags []string
+		if filter.NeedsTags() {
+			var ok bool
+			if tags, ok = triggerTags[event.TriggerID]; !ok {
+				trigger, err := dataBase.GetTrigger(event.TriggerID)
+				switch {
+				case err == nil:
+					tags = trigger.Tags
+				case err != database.ErrNil:
+					return nil, api.ErrorInternalServer(err)
+				}
+				triggerTags[event.TriggerID] = tags
+			}
+		}
+		if filter.IsMatched(event, tags) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/stream"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestGetEventsToReplay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	defer mockCtrl.Finish()
+
+	now := time.Now().Unix()
+	events := []*moira.NotificationEvent{
+		{ID: "a", TriggerID: "trigger-1", Timestamp: now - 10, State: moira.ERROR},
+		{ID: "b", TriggerID: "trigger-2", Timestamp: now - 10, State: moira.OK},
+		{ID: "c", TriggerID: "trigger-1", Timestamp: now - 5, State: moira.OK},
+		{ID: "d", TriggerID: "trigger-3", Timestamp: now, State: moira.ERROR},
+	}
+
+	Convey("Invalid event id", t, func() {
+		result, err := GetEventsToReplay(dataBase, &stream.Filter{}, "unknown")
+		So(result, ShouldBeNil)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("invalid event id 'unknown'")))
+	})
+
+	Convey("Events after the last one", t, func() {
+		dataBase.EXPECT().GetAllNotificationEvents(now-10, int64(math.MaxInt64)).Return(events, nil)
+		result, err := GetEventsToReplay(dataBase, &stream.Filter{}, stream.GetEventID(events[0]))
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, events[1:])
+	})
+
+	Convey("Events of the same second are sent again but the last one", t, func() {
+		dataBase.EXPECT().GetAllNotificationEvents(now-10, int64(math.MaxInt64)).Return(events, nil)
+		result, err := GetEventsToReplay(dataBase, &stream.Filter{}, stream.GetEventID(events[1]))
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, []*moira.NotificationEvent{events[0], events[2], events[3]})
+	})
+
+	Convey("Last event is not found", t, func() {
+		dataBase.EXPECT().GetAllNotificationEvents(now-10, int64(math.MaxInt64)).Return(events, nil)
+		result, err := GetEventsToReplay(dataBase, &stream.Filter{}, fmt.Sprintf("%d:removed", now-10))
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, events)
+	})
+
+	Convey("Old events are not replayed", t, func() {
+		dataBase.EXPECT().GetAllNotificationEvents(gomock.Any(), int64(math.MaxInt64)).Do(func(from, to int64) {
+			So(from, ShouldBeGreaterThanOrEqualTo, now-int64(maxReplayPeriod.Seconds()))
+		}).Return(events, nil)
+		_, err := GetEventsToReplay(dataBase, &stream.Filter{}, "100:old")
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Events are filtered", t, func() {
+		dataBase.EXPECT().GetAllNotificationEvents(now-10, int64(math.MaxInt64)).Return(events, nil)
+		dataBase.EXPECT().GetTrigger("trigger-1").Return(&moira.Trigger{ID: "trigger-1", Tags: []string{"db", "prod"}}, nil)
+		dataBase.EXPECT().GetTrigger("trigger-2").Return(&moira.Trigger{ID: "trigger-2", Tags: []string{"db"}}, nil)
+		dataBase.EXPECT().GetTrigger("trigger-3").Return(nil, database.ErrNil)
+
+		filter := &stream.Filter{Tags: []string{"db", "prod"}, States: []string{moira.OK}}
+		result, err := GetEventsToReplay(dataBase, filter, fmt.Sprintf("%d:", now-10))
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, []*moira.NotificationEvent{events[2]})
+	})
+
+	Convey("Database errors", t, func() {
+		dataBase.EXPECT().GetAllNotificationEvents(now-10, int64(math.MaxInt64)).Return(nil, fmt.Errorf("oops"))
+		_, err := GetEventsToReplay(dataBase, &stream.Filter{}, stream.GetEventID(events[0]))
+		So(err, ShouldResemble, api.ErrorInternalServer(fmt.Errorf("oops")))
+
+		dataBase.EXPECT().GetAllNotificationEvents(now-10, int64(math.MaxInt64)).Return(events, nil)
+		dataBase.EXPECT().GetTrigger("trigger-2").Return(nil, fmt.Errorf("oops"))
+		_, err = GetEventsToReplay(dataBase, &stream.Filter{Tags: []string{"db"}}, stream.GetEventID(events[0]))
+		So(err, ShouldResemble, api.ErrorInternalServer(fmt.Errorf("oops")))
+	})
+}
@@ -176,7 +176,11 @@ func CreateSubscription(dataBase moira.Database, userLogin string, subscription
 	}
 
 	subscription.User = userLogin
+	subscription.Managed = nil
 	data := moira.SubscriptionData(*subscription)
+	if errorResponse := CheckSubscriptionRotations(dataBase, &data); errorResponse != nil {
+		return errorResponse
+	}
 	if err := dataBase.SaveSubscription(&data); err != nil {
 		return api.ErrorInternalServer(err)
 	}
@@ -185,9 +189,21 @@ func CreateSubscription(dataBase moira.Database, userLogin string, subscription
 
 // UpdateSubscription updates existing subscription
 func UpdateSubscription(dataBase moira.Database, subscriptionID string, userLogin string, subscription *dto.Subscription) *api.ErrorResponse {
+	existing, err := dataBase.GetSubscription(subscriptionID)
+	if err != nil && err != database.ErrNil {
+		return api.ErrorInternalServer(err)
+	}
+	if existing.Managed != nil {
+		return api.ErrorInvalidRequest(fmt.Errorf("Subscription is managed by sync from %s, change it there", existing.Managed.Source))
+	}
+
 	subscription.ID = subscriptionID
 	subscription.User = userLogin
+	subscription.Managed = nil
 	data := moira.SubscriptionData(*subscription)
+	if errorResponse := CheckSubscriptionRotations(dataBase, &data); errorResponse != nil {
+		return errorResponse
+	}
 	if err := dataBase.SaveSubscription(&data); err != nil {
 		return api.ErrorInternalServer(err)
 	}
@@ -195,7 +211,23 @@ func UpdateSubscription(dataBase moira.Database, subscriptionID string, userLogi
 }
 
 // RemoveSubscription deletes subscription
-func RemoveSubscription(database moira.Database, subscriptionID string) *api.ErrorResponse {
+func RemoveSubscription(dataBase moira.Database, subscriptionID string) *api.ErrorResponse {
+	existing, err := dataBase.GetSubscription(subscriptionID)
+	if err != nil && err != database.ErrNil {
+		return api.ErrorInternalServer(err)
+	}
+	if existing.Managed != nil {
+		return api.ErrorInvalidRequest(fmt.Errorf("Subscription is managed by sync from %s, remove it there", existing.Managed.Source))
+	}
+	return removeSubscription(dataBase, subscriptionID)
+}
+
+// RemoveManagedSubscription deletes the subscription managed by declarative sync
+func RemoveManagedSubscription(dataBase moira.Database, subscriptionID string) *api.ErrorResponse {
+	return removeSubscription(dataBase, subscriptionID)
+}
+
+func removeSubscription(database moira.Database, subscriptionID string) *api.ErrorResponse {
 	if err := database.RemoveSubscription(subscriptionID); err != nil {
 		return api.ErrorInternalServer(err)
 	}
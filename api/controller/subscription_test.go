@@ -75,6 +75,7 @@ func TestUpdateSubscription(t *testing.T) {
 			ID:   subscriptionID,
 			User: userLogin,
 		}
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(subscription, nil)
 		dataBase.EXPECT().SaveSubscription(&subscription).Return(nil)
 		err := UpdateSubscription(dataBase, subscriptionID, userLogin, subscriptionDTO)
 		So(err, ShouldBeNil)
@@ -90,12 +91,24 @@ func TestUpdateSubscription(t *testing.T) {
 			User: userLogin,
 		}
 		err := fmt.Errorf("Oooops")
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(moira.SubscriptionData{}, database.ErrNil)
 		dataBase.EXPECT().SaveSubscription(&subscription).Return(err)
 		actual := UpdateSubscription(dataBase, subscriptionID, userLogin, subscriptionDTO)
 		So(actual, ShouldResemble, api.ErrorInternalServer(err))
 		So(subscriptionDTO.User, ShouldResemble, userLogin)
 		So(subscriptionDTO.ID, ShouldResemble, subscriptionID)
 	})
+
+	Convey("Managed subscription can't be updated", t, func() {
+		subscriptionDTO := &dto.Subscription{}
+		subscriptionID := uuid.NewV4().String()
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(moira.SubscriptionData{
+			ID:      subscriptionID,
+			Managed: &moira.ManagedBy{Source: "alerts-repo", Key: "team-ops"},
+		}, nil)
+		actual := UpdateSubscription(dataBase, subscriptionID, userLogin, subscriptionDTO)
+		So(actual, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Subscription is managed by sync from alerts-repo, change it there")))
+	})
 }
 
 func TestRemoveSubscription(t *testing.T) {
@@ -105,6 +118,7 @@ func TestRemoveSubscription(t *testing.T) {
 	id := uuid.NewV4().String()
 
 	Convey("Success", t, func() {
+		database.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{ID: id}, nil)
 		database.EXPECT().RemoveSubscription(id).Return(nil)
 		err := RemoveSubscription(database, id)
 		So(err, ShouldBeNil)
@@ -112,10 +126,23 @@ func TestRemoveSubscription(t *testing.T) {
 
 	Convey("Error", t, func() {
 		expected := fmt.Errorf("Oooops! Can not remove subscription")
+		database.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{ID: id}, nil)
 		database.EXPECT().RemoveSubscription(id).Return(expected)
 		err := RemoveSubscription(database, id)
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
+
+	Convey("Managed subscription is not removed", t, func() {
+		database.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{ID: id, Managed: &moira.ManagedBy{Source: "infra", Key: "ops"}}, nil)
+		err := RemoveSubscription(database, id)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Subscription is managed by sync from infra, remove it there")))
+	})
+
+	Convey("Managed subscription is removed by sync", t, func() {
+		database.EXPECT().RemoveSubscription(id).Return(nil)
+		err := RemoveManagedSubscription(database, id)
+		So(err, ShouldBeNil)
+	})
 }
 
 func TestSendTestNotification(t *testing.T) {
@@ -182,6 +209,13 @@ func TestCreateSubscription(t *testing.T) {
 		So(expected, ShouldResemble, api.ErrorInternalServer(err))
 	})
 
+	Convey("Subscription to unknown rotation", t, func() {
+		subscription := dto.Subscription{Rotations: []string{"team"}}
+		dataBase.EXPECT().GetOnCallRotation("team").Return(nil, database.ErrNil)
+		err := CreateSubscription(dataBase, login, &subscription)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Rotation 'team' doesn't exist")))
+	})
+
 	Convey("Error save subscription", t, func() {
 		subscription := dto.Subscription{ID: ""}
 		expected := fmt.Errorf("Oooops! Can not create subscription")
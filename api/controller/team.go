@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"fmt"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+)
+
+// GetTeams returns the teams from both the config and the database
+func GetTeams(authorizer *api.Authorizer) (*dto.TeamList, *api.ErrorResponse) {
+	teams, err := authorizer.GetTeams()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	result := &dto.TeamList{List: make([]dto.Team, 0, len(teams))}
+	for _, team := range teams {
+		result.List = append(result.List, dto.Team{
+			Team:     *team,
+			ReadOnly: authorizer.IsConfigTeam(team.ID),
+		})
+	}
+	return result, nil
+}
+
+// SaveTeam creates or replaces the team stored in the database
+func SaveTeam(database moira.Database, authorizer *api.Authorizer, team *dto.Team) *api.ErrorResponse {
+	if authorizer.IsConfigTeam(team.ID) {
+		return api.ErrorForbidden(fmt.Sprintf("Team '%s' is defined in the config and can't be changed", team.ID))
+	}
+	if err := database.SaveTeam(&team.Team); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// RemoveTeam removes the team stored in the database, the triggers owned by it explicitly lose their owner
+func RemoveTeam(database moira.Database, authorizer *api.Authorizer, teamID string) *api.ErrorResponse {
+	if authorizer.IsConfigTeam(teamID) {
+		return api.ErrorForbidden(fmt.Sprintf("Team '%s' is defined in the config and can't be removed", teamID))
+	}
+	if err := database.RemoveTeam(teamID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
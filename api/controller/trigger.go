@@ -11,6 +11,8 @@ import (
 	"go.avito.ru/DO/moira/checker"
 	"go.avito.ru/DO/moira/database"
 	"go.avito.ru/DO/moira/logging"
+	"go.avito.ru/DO/moira/metrics"
+	"go.avito.ru/DO/moira/silencer"
 	"go.avito.ru/DO/moira/target"
 )
 
@@ -29,6 +31,15 @@ func UpdateTrigger(
 		}
 		return nil, api.ErrorInternalServer(err)
 	}
+	if prevTrigger.Template != nil {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger is an instance of template %s, change the template or detach the trigger from it", prevTrigger.Template.ID))
+	}
+	if prevTrigger.Managed != nil {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger is managed by sync from %s, change it there", prevTrigger.Managed.Source))
+	}
+	// the triggers are linked to the templates by template api only
+	trigger.Template = nil
+	trigger.Managed = nil
 
 	logging.GetLogger(triggerID).InfoE("About to call saveTrigger (update)", map[string]interface{}{
 		"prev": prevTrigger,
@@ -37,6 +48,18 @@ func UpdateTrigger(
 	return saveTrigger(dataBase, triggerInheritanceDatabase, trigger.ToMoiraTrigger(), triggerID, timeSeriesNames)
 }
 
+// SaveManagedTrigger creates or updates the trigger managed by declarative sync,
+// the trigger must be checked the same way api checks it (see dto.Trigger.Check)
+func SaveManagedTrigger(
+	dataBase moira.Database,
+	triggerInheritanceDatabase moira.TriggerInheritanceDatabase,
+	trigger *moira.Trigger,
+	timeSeriesNames map[string]bool,
+) *api.ErrorResponse {
+	_, err := saveTrigger(dataBase, triggerInheritanceDatabase, trigger, trigger.ID, timeSeriesNames)
+	return err
+}
+
 // saveTrigger create or update trigger data and update trigger metrics in last state
 func saveTrigger(
 	db moira.Database,
@@ -140,7 +163,18 @@ func RemoveTrigger(database moira.Database, triggerID string) *api.ErrorResponse
 		"prev": prevTrigger,
 		"err":  err,
 	})
+	if err == nil && prevTrigger != nil && prevTrigger.Managed != nil {
+		return api.ErrorInvalidRequest(fmt.Errorf("Trigger is managed by sync from %s, remove it there", prevTrigger.Managed.Source))
+	}
+	return removeTrigger(database, triggerID)
+}
 
+// RemoveManagedTrigger deletes the trigger managed by declarative sync
+func RemoveManagedTrigger(database moira.Database, triggerID string) *api.ErrorResponse {
+	return removeTrigger(database, triggerID)
+}
+
+func removeTrigger(database moira.Database, triggerID string) *api.ErrorResponse {
 	if err := database.RemoveTrigger(triggerID); err != nil {
 		return api.ErrorInternalServer(err)
 	}
@@ -326,6 +360,56 @@ func GetTriggerMetrics(dataBase moira.Database, from, to int64, triggerID string
 	return triggerMetrics, nil
 }
 
+// ExplainTrigger runs the check of the trigger read-only and tells what the check does step by step
+func ExplainTrigger(dataBase moira.Database, triggerID string) (*dto.TriggerExplanation, *api.ErrorResponse) {
+	trigger, err := dataBase.GetTrigger(triggerID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Trigger %s not found", triggerID))
+		}
+		return nil, api.ErrorInternalServer(err)
+	}
+	if trigger.IsPullType {
+		// remote sources are configured in checker only
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Pull trigger can be explained by checker only: moira-checker -t %s -explain", triggerID))
+	}
+
+	triggerChecker := checker.TriggerChecker{
+		TriggerID: triggerID,
+		Database:  dataBase,
+		Config:    &checker.Config{},
+		Statsd:    metrics.NewCheckerMetrics(),
+	}
+	if err := triggerChecker.InitTriggerChecker(); err != nil {
+		if err == checker.ErrTriggerNotExists {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Trigger %s not found", triggerID))
+		}
+		return nil, api.ErrorInternalServer(err)
+	}
+	// api doesn't run the silencer, the patterns are loaded for every explanation
+	silencer.NewSilencer(dataBase, nil).Update()
+	return (*dto.TriggerExplanation)(triggerChecker.Explain()), nil
+}
+
+// GetTriggerMetricsForecast gets projections of trigger metrics made by the last check of forecast trigger
+func GetTriggerMetricsForecast(dataBase moira.Database, triggerID string) (dto.TriggerMetricsForecast, *api.ErrorResponse) {
+	lastCheck, err := dataBase.GetTriggerLastCheck(triggerID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Trigger check for %s not found", triggerID))
+		}
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	result := make(dto.TriggerMetricsForecast, len(lastCheck.Metrics))
+	for metric, state := range lastCheck.Metrics {
+		if state.Forecast != nil {
+			result[metric] = state.Forecast
+		}
+	}
+	return result, nil
+}
+
 func AckEscalations(database moira.Database, triggerID string) *api.ErrorResponse {
 	lastCheck, err := database.GetTriggerLastCheck(triggerID)
 	if err != nil {
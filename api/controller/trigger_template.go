@@ -0,0 +1,349 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/satori/go.uuid"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/database"
+)
+
+// GetTriggerTemplates returns all trigger templates sorted by name
+func GetTriggerTemplates(dataBase moira.Database) (*dto.TriggerTemplateList, *api.ErrorResponse) {
+	templates, err := dataBase.GetTriggerTemplates()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	result := &dto.TriggerTemplateList{List: make([]moira.TriggerTemplate, 0, len(templates))}
+	for _, template := range templates {
+		result.List = append(result.List, *template)
+	}
+	return result, nil
+}
+
+// GetTriggerTemplate returns trigger template by its ID
+func GetTriggerTemplate(dataBase moira.Database, templateID string) (*dto.TriggerTemplate, *api.ErrorResponse) {
+	template, err := getTriggerTemplate(dataBase, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TriggerTemplate{TriggerTemplate: *template}, nil
+}
+
+// CreateTriggerTemplate saves new template, it has no instances yet
+func CreateTriggerTemplate(dataBase moira.Database, template *dto.TriggerTemplate) *api.ErrorResponse {
+	if template.ID == "" {
+		template.ID = uuid.NewV4().String()
+	} else {
+		_, err := dataBase.GetTriggerTemplate(template.ID)
+		if err == nil {
+			return api.ErrorInvalidRequest(fmt.Errorf("Trigger template with this ID already exists"))
+		}
+		if err != database.ErrNil {
+			return api.ErrorInternalServer(err)
+		}
+	}
+	if err := dataBase.SaveTriggerTemplate(&template.TriggerTemplate); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// PreviewTriggerTemplate returns the changes of the instances which the new version of the template would make
+func PreviewTriggerTemplate(dataBase moira.Database, config api.Config, template *dto.TriggerTemplate) (*dto.TemplateDiffList, *api.ErrorResponse) {
+	if _, err := getTriggerTemplate(dataBase, template.ID); err != nil {
+		return nil, err
+	}
+	instances, err := getTemplateTriggers(dataBase, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.TemplateDiffList{List: make([]dto.TemplateInstanceDiff, 0, len(instances))}
+	for _, instance := range instances {
+		diff := dto.TemplateInstanceDiff{
+			TriggerID: instance.ID,
+			Name:      instance.Name,
+			Changes:   make([]dto.TemplateFieldChange, 0),
+		}
+		rendered, _, renderErr := renderTemplateInstance(dataBase, config, &template.TriggerTemplate, instance, instance.Template.Params)
+		if renderErr != nil {
+			diff.Error = renderErr.Error()
+		} else {
+			diff.Changes = getTriggerChanges(instance, rendered)
+		}
+		result.List = append(result.List, diff)
+	}
+	return result, nil
+}
+
+// UpdateTriggerTemplate saves the template and renders all its instances again,
+// nothing is changed if any instance can't be rendered or the user is not allowed to change it
+func UpdateTriggerTemplate(
+	dataBase moira.Database,
+	triggerInheritanceDatabase moira.TriggerInheritanceDatabase,
+	config api.Config,
+	authorizer *api.Authorizer,
+	login string,
+	template *dto.TriggerTemplate,
+) (*dto.TemplateDiffList, *api.ErrorResponse) {
+	if _, err := getTriggerTemplate(dataBase, template.ID); err != nil {
+		return nil, err
+	}
+	instances, errorResponse := getTemplateTriggers(dataBase, template.ID)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	type renderedInstance struct {
+		trigger         *moira.Trigger
+		timeSeriesNames map[string]bool
+	}
+	rendered := make([]renderedInstance, 0, len(instances))
+	diff := &dto.TemplateDiffList{List: make([]dto.TemplateInstanceDiff, 0, len(instances))}
+	for _, instance := range instances {
+		trigger, timeSeriesNames, err := renderTemplateInstance(dataBase, config, &template.TriggerTemplate, instance, instance.Template.Params)
+		if err != nil {
+			return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger %s: %v", instance.ID, err))
+		}
+		if err := checkTemplateInstance(authorizer, login, instance, trigger); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, renderedInstance{trigger: trigger, timeSeriesNames: timeSeriesNames})
+		diff.List = append(diff.List, dto.TemplateInstanceDiff{
+			TriggerID: instance.ID,
+			Name:      instance.Name,
+			Changes:   getTriggerChanges(instance, trigger),
+		})
+	}
+
+	if err := dataBase.SaveTriggerTemplate(&template.TriggerTemplate); err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	for _, instance := range rendered {
+		if _, err := saveTrigger(dataBase, triggerInheritanceDatabase, instance.trigger, instance.trigger.ID, instance.timeSeriesNames); err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+// RemoveTriggerTemplate removes the template, the templates with instances can't be removed
+func RemoveTriggerTemplate(dataBase moira.Database, templateID string) *api.ErrorResponse {
+	triggerIDs, err := dataBase.GetTemplateTriggerIDs(templateID)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	if len(triggerIDs) > 0 {
+		return api.ErrorInvalidRequest(fmt.Errorf("Trigger template has %d instances, remove or detach them first", len(triggerIDs)))
+	}
+	if err := dataBase.RemoveTriggerTemplate(templateID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// GetTemplateInstances returns the triggers instantiated from the template
+func GetTemplateInstances(dataBase moira.Database, templateID string) (*dto.TemplateInstanceList, *api.ErrorResponse) {
+	if _, err := getTriggerTemplate(dataBase, templateID); err != nil {
+		return nil, err
+	}
+	instances, err := getTemplateTriggers(dataBase, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.TemplateInstanceList{List: make([]dto.TemplateInstance, 0, len(instances))}
+	for _, instance := range instances {
+		result.List = append(result.List, dto.TemplateInstance{
+			TriggerID: instance.ID,
+			Name:      instance.Name,
+			Params:    instance.Template.Params,
+		})
+	}
+	return result, nil
+}
+
+// SaveTemplateInstance creates new trigger from the template or changes the parameters of the existing instance
+func SaveTemplateInstance(
+	dataBase moira.Database,
+	triggerInheritanceDatabase moira.TriggerInheritanceDatabase,
+	config api.Config,
+	authorizer *api.Authorizer,
+	login string,
+	templateID string,
+	instance *dto.TemplateInstance,
+) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
+	template, errorResponse := getTriggerTemplate(dataBase, templateID)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	var existing *moira.Trigger
+	if instance.TriggerID == "" {
+		instance.TriggerID = uuid.NewV4().String()
+	} else {
+		trigger, err := dataBase.GetTrigger(instance.TriggerID)
+		switch {
+		case err == database.ErrNil:
+		case err != nil:
+			return nil, api.ErrorInternalServer(err)
+		case trigger.Template == nil || trigger.Template.ID != templateID:
+			return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger with this ID exists and is not an instance of the template"))
+		default:
+			existing = trigger
+		}
+	}
+
+	base := existing
+	if base == nil {
+		base = &moira.Trigger{ID: instance.TriggerID}
+	}
+	trigger, timeSeriesNames, err := renderTemplateInstance(dataBase, config, template, base, instance.Params)
+	if err != nil {
+		return nil, api.ErrorInvalidRequest(err)
+	}
+	if err := checkTemplateInstance(authorizer, login, existing, trigger); err != nil {
+		return nil, err
+	}
+
+	response, errorResponse := saveTrigger(dataBase, triggerInheritanceDatabase, trigger, instance.TriggerID, timeSeriesNames)
+	if response != nil && existing == nil {
+		response.Message = "trigger created"
+	}
+	return response, errorResponse
+}
+
+// DetachTemplateInstance removes the link between the trigger and its template, the trigger becomes an ordinary one
+func DetachTemplateInstance(dataBase moira.Database, templateID, triggerID string) *api.ErrorResponse {
+	trigger, err := dataBase.GetTrigger(triggerID)
+	if err == database.ErrNil {
+		return api.ErrorNotFound(fmt.Sprintf("Trigger with ID = '%s' does not exists", triggerID))
+	}
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	if trigger.Template == nil || trigger.Template.ID != templateID {
+		return api.ErrorNotFound(fmt.Sprintf("Trigger %s is not an instance of template %s", trigger.ID, templateID))
+	}
+	trigger.Template = nil
+	if err := dataBase.SaveTrigger(trigger.ID, trigger); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+func getTriggerTemplate(dataBase moira.Database, templateID string) (*moira.TriggerTemplate, *api.ErrorResponse) {
+	template, err := dataBase.GetTriggerTemplate(templateID)
+	if err == database.ErrNil {
+		return nil, api.ErrorNotFound(fmt.Sprintf("Trigger template with ID = '%s' does not exists", templateID))
+	}
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return template, nil
+}
+
+// getTemplateTriggers returns the instances of the template sorted by name
+func getTemplateTriggers(dataBase moira.Database, templateID string) ([]*moira.Trigger, *api.ErrorResponse) {
+	triggerIDs, err := dataBase.GetTemplateTriggerIDs(templateID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	triggers, err := dataBase.GetTriggers(triggerIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	result := make([]*moira.Trigger, 0, len(triggers))
+	for _, trigger := range triggers {
+		// the trigger might have been detached or removed in the meantime
+		if trigger != nil && trigger.Template != nil && trigger.Template.ID == templateID {
+			result = append(result, trigger)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// renderTemplateInstance renders the template into the instance and validates the result as if it was given to api
+func renderTemplateInstance(
+	dataBase moira.Database,
+	config api.Config,
+	template *moira.TriggerTemplate,
+	instance *moira.Trigger,
+	params map[string]string,
+) (*moira.Trigger, map[string]bool, error) {
+	trigger, err := template.Render(instance, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	model := &dto.Trigger{TriggerModel: dto.CreateTriggerModel(trigger)}
+	timeSeriesNames, err := model.Check(dataBase, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return model.ToMoiraTrigger(), timeSeriesNames, nil
+}
+
+// checkTemplateInstance makes sure that the user is allowed to change the instance as the template does
+func checkTemplateInstance(authorizer *api.Authorizer, login string, existing, rendered *moira.Trigger) *api.ErrorResponse {
+	if existing != nil {
+		if err := authorizer.CheckTrigger(login, existing); err != nil {
+			return err
+		}
+	}
+	if err := authorizer.CheckTeam(rendered.Team); err != nil {
+		return err
+	}
+	return authorizer.CheckTrigger(login, rendered)
+}
+
+// getTriggerChanges compares the fields of the triggers as api represents them
+func getTriggerChanges(old, new *moira.Trigger) []dto.TemplateFieldChange {
+	oldFields, newFields := getTriggerFields(old), getTriggerFields(new)
+	fields := make([]string, 0, len(oldFields)+len(newFields))
+	for field := range oldFields {
+		fields = append(fields, field)
+	}
+	for field := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	result := make([]dto.TemplateFieldChange, 0)
+	for _, field := range fields {
+		if !reflect.DeepEqual(oldFields[field], newFields[field]) {
+			result = append(result, dto.TemplateFieldChange{
+				Field: field,
+				Old:   oldFields[field],
+				New:   newFields[field],
+			})
+		}
+	}
+	return result
+}
+
+func getTriggerFields(trigger *moira.Trigger) map[string]interface{} {
+	model := dto.CreateTriggerModel(trigger)
+	result := make(map[string]interface{})
+	bytes, err := json.Marshal(&model)
+	if err == nil {
+		_ = json.Unmarshal(bytes, &result)
+	}
+	return result
+}
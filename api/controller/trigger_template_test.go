@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestTriggerTemplates(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	authorizer := api.NewAuthorizer(api.AuthorizationConfig{}, nil, nil)
+	config := api.Config{}
+
+	template := moira.TriggerTemplate{
+		ID:     "http-errors",
+		Name:   "HTTP errors",
+		Params: []string{"service", "warn"},
+		Trigger: moira.TemplateTrigger{
+			Name:       "{{service}}: 5xx",
+			Targets:    []string{"services.{{service}}.5xx"},
+			WarnValue:  "{{warn}}",
+			ErrorValue: "100",
+			Tags:       []string{"http", "{{service}}"},
+		},
+	}
+	warnValue, errorValue, expression := 10.0, 100.0, ""
+	instance := &moira.Trigger{
+		ID:         "api-errors",
+		Name:       "api: 5xx",
+		Targets:    []string{"services.api.5xx"},
+		WarnValue:  &warnValue,
+		ErrorValue: &errorValue,
+		Tags:       []string{"http", "api"},
+		Expression: &expression,
+		Patterns:   []string{"services.api.5xx"},
+		Template:   &moira.TriggerTemplateLink{ID: "http-errors", Params: map[string]string{"service": "api", "warn": "10"}},
+	}
+
+	expectSave := func(trigger *moira.Trigger) {
+		dataBase.EXPECT().GetPatternMetrics(trigger.Patterns[0]).Return([]string{}, nil)
+		dataBase.EXPECT().AcquireTriggerCheckLock(trigger.ID)
+		dataBase.EXPECT().DeleteTriggerCheckLock(trigger.ID)
+		dataBase.EXPECT().GetOrCreateTriggerLastCheck(trigger.ID).Return(&moira.CheckData{}, nil)
+		dataBase.EXPECT().SaveTrigger(trigger.ID, trigger).Return(nil)
+		dataBase.EXPECT().SetTriggerLastCheck(trigger.ID, gomock.Any()).Return(nil)
+	}
+
+	Convey("Create template", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(nil, database.ErrNil)
+		dataBase.EXPECT().SaveTriggerTemplate(&template).Return(nil)
+		So(CreateTriggerTemplate(dataBase, &dto.TriggerTemplate{TriggerTemplate: template}), ShouldBeNil)
+
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		err := CreateTriggerTemplate(dataBase, &dto.TriggerTemplate{TriggerTemplate: template})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger template with this ID already exists")))
+	})
+
+	Convey("Create instance", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTrigger("api-errors").Return(nil, database.ErrNil)
+		expectSave(instance)
+
+		response, err := SaveTemplateInstance(dataBase, nil, config, authorizer, "user", "http-errors", &dto.TemplateInstance{
+			TriggerID: "api-errors",
+			Params:    map[string]string{"service": "api", "warn": "10"},
+		})
+		So(err, ShouldBeNil)
+		So(response, ShouldResemble, &dto.SaveTriggerResponse{ID: "api-errors", Message: "trigger created"})
+	})
+
+	Convey("Invalid parameters of instance", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		_, err := SaveTemplateInstance(dataBase, nil, config, authorizer, "user", "http-errors", &dto.TemplateInstance{
+			Params: map[string]string{"service": "api"},
+		})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("parameter 'warn' is required")))
+	})
+
+	Convey("Ordinary trigger can't become an instance", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTrigger("other").Return(&moira.Trigger{ID: "other"}, nil)
+		_, err := SaveTemplateInstance(dataBase, nil, config, authorizer, "user", "http-errors", &dto.TemplateInstance{
+			TriggerID: "other",
+			Params:    map[string]string{"service": "api", "warn": "10"},
+		})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger with this ID exists and is not an instance of the template")))
+	})
+
+	Convey("Get instances", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors", "detached", "removed"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"api-errors", "detached", "removed"}).Return([]*moira.Trigger{instance, {ID: "detached"}, nil}, nil)
+
+		instances, err := GetTemplateInstances(dataBase, "http-errors")
+		So(err, ShouldBeNil)
+		So(instances, ShouldResemble, &dto.TemplateInstanceList{List: []dto.TemplateInstance{
+			{TriggerID: "api-errors", Name: "api: 5xx", Params: instance.Template.Params},
+		}})
+	})
+
+	updated := template
+	updated.Trigger.ErrorValue = "200"
+	updated.Trigger.Tags = []string{"http", "{{service}}", "prod"}
+
+	Convey("Preview changes of template", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"api-errors"}).Return([]*moira.Trigger{instance}, nil)
+		dataBase.EXPECT().GetPatternMetrics("services.api.5xx").Return([]string{}, nil)
+
+		diff, err := PreviewTriggerTemplate(dataBase, config, &dto.TriggerTemplate{TriggerTemplate: updated})
+		So(err, ShouldBeNil)
+		So(diff, ShouldResemble, &dto.TemplateDiffList{List: []dto.TemplateInstanceDiff{{
+			TriggerID: "api-errors",
+			Name:      "api: 5xx",
+			Changes: []dto.TemplateFieldChange{
+				{Field: "error_value", Old: 100.0, New: 200.0},
+				{Field: "tags", Old: []interface{}{"http", "api"}, New: []interface{}{"http", "api", "prod"}},
+			},
+		}}})
+	})
+
+	Convey("Update template", t, func() {
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"api-errors"}).Return([]*moira.Trigger{instance}, nil)
+		dataBase.EXPECT().SaveTriggerTemplate(&updated).Return(nil)
+
+		rendered := *instance
+		newErrorValue := 200.0
+		rendered.ErrorValue = &newErrorValue
+		rendered.Tags = []string{"http", "api", "prod"}
+		expectSave(&rendered)
+
+		diff, err := UpdateTriggerTemplate(dataBase, nil, config, authorizer, "user", &dto.TriggerTemplate{TriggerTemplate: updated})
+		So(err, ShouldBeNil)
+		So(diff.List, ShouldHaveLength, 1)
+		So(diff.List[0].Changes, ShouldHaveLength, 2)
+	})
+
+	Convey("Update keeps the fields of the instances which the template doesn't describe", t, func() {
+		tuned := *instance
+		tuned.CheckInterval = 300
+		tuned.Priority = moira.TriggerPriorityHigh
+		tuned.Flapping = &moira.FlappingSettings{Threshold: 3, Window: 3600}
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"api-errors"}).Return([]*moira.Trigger{&tuned}, nil)
+		dataBase.EXPECT().SaveTriggerTemplate(&updated).Return(nil)
+
+		rendered := tuned
+		newErrorValue := 200.0
+		rendered.ErrorValue = &newErrorValue
+		rendered.Tags = []string{"http", "api", "prod"}
+		expectSave(&rendered)
+
+		diff, err := UpdateTriggerTemplate(dataBase, nil, config, authorizer, "user", &dto.TriggerTemplate{TriggerTemplate: updated})
+		So(err, ShouldBeNil)
+		So(diff.List[0].Changes, ShouldHaveLength, 2)
+	})
+
+	Convey("Template can't be updated if any instance is broken", t, func() {
+		broken := template
+		broken.Trigger.WarnValue = "{{warn}}x"
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"api-errors"}).Return([]*moira.Trigger{instance}, nil)
+
+		_, err := UpdateTriggerTemplate(dataBase, nil, config, authorizer, "user", &dto.TriggerTemplate{TriggerTemplate: broken})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger api-errors: warn_value: '10x' is not a number")))
+	})
+
+	Convey("Instances are checked by authorizer", t, func() {
+		authorizer := api.NewAuthorizer(api.AuthorizationConfig{Enabled: true}, nil, nil)
+		dataBase.EXPECT().GetTriggerTemplate("http-errors").Return(&template, nil)
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"api-errors"}).Return([]*moira.Trigger{instance}, nil)
+		dataBase.EXPECT().GetPatternMetrics("services.api.5xx").Return([]string{}, nil)
+
+		_, err := UpdateTriggerTemplate(dataBase, nil, config, authorizer, "viewer", &dto.TriggerTemplate{TriggerTemplate: updated})
+		So(err, ShouldResemble, api.ErrorForbidden("User \"viewer\" has role viewer, but editor is required"))
+	})
+
+	Convey("Detach instance", t, func() {
+		dataBase.EXPECT().GetTrigger("api-errors").Return(instance, nil)
+		detached := *instance
+		detached.Template = nil
+		dataBase.EXPECT().SaveTrigger("api-errors", &detached).Return(nil)
+		So(DetachTemplateInstance(dataBase, "http-errors", "api-errors"), ShouldBeNil)
+
+		dataBase.EXPECT().GetTrigger("other").Return(&moira.Trigger{ID: "other"}, nil)
+		err := DetachTemplateInstance(dataBase, "http-errors", "other")
+		So(err, ShouldResemble, api.ErrorNotFound("Trigger other is not an instance of template http-errors"))
+	})
+
+	Convey("Remove template", t, func() {
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{"api-errors"}, nil)
+		err := RemoveTriggerTemplate(dataBase, "http-errors")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger template has 1 instances, remove or detach them first")))
+
+		dataBase.EXPECT().GetTemplateTriggerIDs("http-errors").Return([]string{}, nil)
+		dataBase.EXPECT().RemoveTriggerTemplate("http-errors").Return(nil)
+		So(RemoveTriggerTemplate(dataBase, "http-errors"), ShouldBeNil)
+	})
+}
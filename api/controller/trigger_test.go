@@ -46,6 +46,14 @@ func TestUpdateTrigger(t *testing.T) {
 		So(resp, ShouldBeNil)
 	})
 
+	Convey("Instance of template", t, func() {
+		trigger := dto.TriggerModel{ID: uuid.NewV4().String()}
+		dataBase.EXPECT().GetTrigger(trigger.ID).Return(&moira.Trigger{ID: trigger.ID, Template: &moira.TriggerTemplateLink{ID: "template"}}, nil)
+		resp, err := UpdateTrigger(dataBase, nil, &trigger, trigger.ID, make(map[string]bool))
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger is an instance of template template, change the template or detach the trigger from it")))
+		So(resp, ShouldBeNil)
+	})
+
 	Convey("Get trigger error", t, func() {
 		trigger := dto.TriggerModel{ID: uuid.NewV4().String()}
 		expected := fmt.Errorf("Soo bad trigger")
@@ -264,6 +272,19 @@ func TestRemoveTrigger(t *testing.T) {
 		err := RemoveTrigger(dataBase, triggerID)
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
+
+	Convey("Managed trigger is not removed", t, func() {
+		dataBase.EXPECT().GetTrigger(triggerID).Return(&moira.Trigger{ID: triggerID, Managed: &moira.ManagedBy{Source: "infra", Key: "cpu"}}, nil)
+		err := RemoveTrigger(dataBase, triggerID)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger is managed by sync from infra, remove it there")))
+	})
+
+	Convey("Managed trigger is removed by sync", t, func() {
+		dataBase.EXPECT().RemoveTrigger(triggerID).Return(nil)
+		dataBase.EXPECT().RemoveTriggerLastCheck(triggerID).Return(nil)
+		err := RemoveManagedTrigger(dataBase, triggerID)
+		So(err, ShouldBeNil)
+	})
 }
 
 func TestGetTriggerThrottling(t *testing.T) {
@@ -567,3 +588,31 @@ func TestGetTriggerMetrics(t *testing.T) {
 	})
 
 }
+
+func TestGetTriggerMetricsForecast(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	triggerID := uuid.NewV4().String()
+
+	Convey("Only metrics with forecast are returned", t, func() {
+		eta := int64(3600)
+		forecast := &moira.MetricForecast{Threshold: 100, Slope: 0.01, ETA: &eta}
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(&moira.CheckData{
+			Metrics: map[string]*moira.MetricState{
+				"disk.sda": {State: moira.WARN, Forecast: forecast},
+				"disk.sdb": {State: moira.OK},
+			},
+		}, nil)
+		actual, err := GetTriggerMetricsForecast(dataBase, triggerID)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, dto.TriggerMetricsForecast{"disk.sda": forecast})
+	})
+
+	Convey("No last check", t, func() {
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(nil, database.ErrNil)
+		_, err := GetTriggerMetricsForecast(dataBase, triggerID)
+		So(err, ShouldResemble, api.ErrorNotFound(fmt.Sprintf("Trigger check for %s not found", triggerID)))
+	})
+}
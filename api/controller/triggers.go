@@ -30,6 +30,10 @@ func CreateTrigger(
 			return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger with this ID already exists"))
 		}
 	}
+	// the triggers are linked to the templates by template api only
+	trigger.Template = nil
+	// and they are managed by declarative sync only if created by it
+	trigger.Managed = nil
 	resp, err := saveTrigger(dataBase, triggerInheritanceDatabase, trigger.ToMoiraTrigger(), trigger.ID, timeSeriesNames)
 	if resp != nil {
 		resp.Message = "trigger created"
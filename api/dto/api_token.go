@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.avito.ru/DO/moira"
+)
+
+type APIToken struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	Login     string                `json:"login"` // owner of the token, either user or service
+	Scopes    []moira.APITokenScope `json:"scopes"`
+	CreatedAt int64                 `json:"created_at"`
+	ExpiresAt int64                 `json:"expires_at"`      // 0 means that the token never expires
+	Token     string                `json:"token,omitempty"` // is returned only once, when the token is created
+}
+
+func (token *APIToken) Bind(_ *http.Request) error {
+	if token.Name == "" {
+		return fmt.Errorf("token name is required")
+	}
+	if len(token.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range token.Scopes {
+		if !scope.IsValid() {
+			return fmt.Errorf("unknown scope '%s'", scope)
+		}
+	}
+	if token.ExpiresAt != 0 && token.ExpiresAt <= time.Now().Unix() {
+		return fmt.Errorf("expiration time must be in the future")
+	}
+	return nil
+}
+
+func (*APIToken) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func CreateAPIToken(token *moira.APIToken) APIToken {
+	return APIToken{
+		ID:        token.ID,
+		Name:      token.Name,
+		Login:     token.Login,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+		ExpiresAt: token.ExpiresAt,
+	}
+}
+
+type APITokenList struct {
+	List []APIToken `json:"list"`
+}
+
+func (*APITokenList) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
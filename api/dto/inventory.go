@@ -0,0 +1,13 @@
+package dto
+
+import (
+	"net/http"
+
+	"go.avito.ru/DO/moira"
+)
+
+type InventoryHost moira.InventoryHost
+
+func (*InventoryHost) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
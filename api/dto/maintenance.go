@@ -11,3 +11,39 @@ type Maintenance moira.Maintenance
 func (Maintenance) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
+
+// MaintenanceWindow is scheduled maintenance window of the trigger
+type MaintenanceWindow struct {
+	moira.MaintenanceWindow
+}
+
+func (window *MaintenanceWindow) Bind(_ *http.Request) error {
+	if window.Metric == "" {
+		window.Metric = moira.WildcardMetric
+	}
+	return window.Validate()
+}
+
+func (*MaintenanceWindow) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// MaintenanceSchedule contains scheduled windows and their occurrences within the requested range
+type MaintenanceSchedule struct {
+	Windows  []moira.MaintenanceWindow     `json:"windows"`
+	Upcoming []moira.MaintenanceOccurrence `json:"upcoming"`
+}
+
+func (*MaintenanceSchedule) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// SilentUpcoming contains upcoming occurrences of scheduled silent patterns
+type SilentUpcoming struct {
+	Metrics []moira.MaintenanceOccurrence `json:"metrics"`
+	Tags    []moira.MaintenanceOccurrence `json:"tags"`
+}
+
+func (*SilentUpcoming) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"net/http"
+
+	"go.avito.ru/DO/moira"
+)
+
+type Rotation struct {
+	moira.OnCallRotation
+}
+
+func (rotation *Rotation) Bind(_ *http.Request) error {
+	return rotation.Validate()
+}
+
+func (*Rotation) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+type RotationList struct {
+	List []moira.OnCallRotation `json:"list"`
+}
+
+func (*RotationList) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// RotationOnCall tells who is on call by the rotation at Timestamp
+type RotationOnCall struct {
+	Timestamp int64                    `json:"timestamp"`
+	Main      *moira.OnCallParticipant `json:"main"`
+	Backup    *moira.OnCallParticipant `json:"backup"`
+	Until     int64                    `json:"until"` // when the duty of the main one ends
+}
+
+func (*RotationOnCall) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
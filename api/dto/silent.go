@@ -3,6 +3,7 @@ package dto
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"go.avito.ru/DO/moira"
 )
@@ -16,15 +17,69 @@ type SilentPatternList struct {
 func (silentPatternList *SilentPatternList) Bind(r *http.Request) error {
 	if len(silentPatternList.List) == 0 {
 		return fmt.Errorf("SilentPatternList must not be empty")
-	} else {
-		return nil
 	}
+	return nil
 }
 
 func (silentPatternList *SilentPatternList) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// NewSilentPatternList is the list of silent patterns which are going to be created or updated
+type NewSilentPatternList struct {
+	SilentPatternList
+}
+
+func (newSilentPatternList *NewSilentPatternList) Bind(r *http.Request) error {
+	if err := newSilentPatternList.SilentPatternList.Bind(r); err != nil {
+		return err
+	}
+	for _, silentPattern := range newSilentPatternList.List {
+		if err := CheckSilentPattern(silentPattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckSilentPattern validates silent pattern given by user
+func CheckSilentPattern(silentPattern *moira.SilentPatternData) error {
+	switch silentPattern.Type {
+	case moira.SPTMetric, moira.SPTTag:
+		if silentPattern.Pattern == "" {
+			return fmt.Errorf("SilentPattern must have pattern")
+		}
+	case moira.SPTMatcher:
+		if silentPattern.Matcher == nil {
+			return fmt.Errorf("SilentPattern of matcher type must have matcher")
+		}
+		if err := silentPattern.Matcher.Validate(); err != nil {
+			return fmt.Errorf("SilentPattern matcher: %v", err)
+		}
+	default:
+		return fmt.Errorf("Unknown SilentPattern type %d", silentPattern.Type)
+	}
+
+	if silentPattern.Comment == "" {
+		return fmt.Errorf("SilentPattern %s must have comment", silentPattern.Pattern)
+	}
+	if silentPattern.Ticket != "" {
+		if ticketURL, err := url.ParseRequestURI(silentPattern.Ticket); err != nil || ticketURL.Host == "" {
+			return fmt.Errorf("SilentPattern %s has invalid ticket URL", silentPattern.Pattern)
+		}
+	}
+
+	if silentPattern.Start > 0 && silentPattern.Start >= silentPattern.Until {
+		return fmt.Errorf("SilentPattern %s must end after it starts", silentPattern.Pattern)
+	}
+	if silentPattern.Recurrence != nil {
+		if err := silentPattern.Recurrence.Validate(); err != nil {
+			return fmt.Errorf("SilentPattern %s: %v", silentPattern.Pattern, err)
+		}
+	}
+	return nil
+}
+
 func (silentPattern *SilentPattern) Bind(r *http.Request) error {
 	if len(silentPattern.Pattern) == 0 {
 		return fmt.Errorf("SilentPattern must have pattern")
@@ -35,3 +90,21 @@ func (silentPattern *SilentPattern) Bind(r *http.Request) error {
 func (silentPattern *SilentPattern) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
+
+// SilentPreview lists currently firing triggers and metrics which would be muted by the proposed silent patterns
+type SilentPreview struct {
+	Triggers []*SilentPreviewTrigger `json:"triggers"`
+}
+
+func (*SilentPreview) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// SilentPreviewTrigger is the trigger which would be muted entirely (Muted is set) or partially (by Metrics)
+type SilentPreviewTrigger struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	State   string            `json:"state"`
+	Muted   bool              `json:"muted"`
+	Metrics map[string]string `json:"metrics"` // metric name -> its current state
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+)
+
+func TestCheckSilentPattern(t *testing.T) {
+	Convey("Test check silent pattern", t, func() {
+		Convey("Comment is mandatory", func() {
+			So(CheckSilentPattern(&moira.SilentPatternData{Pattern: "host"}), ShouldBeError, "SilentPattern host must have comment")
+			So(CheckSilentPattern(&moira.SilentPatternData{Pattern: "host", Comment: "reboot"}), ShouldBeNil)
+		})
+		Convey("Ticket must be URL", func() {
+			sp := &moira.SilentPatternData{Pattern: "host", Comment: "reboot", Ticket: "OPS-1"}
+			So(CheckSilentPattern(sp), ShouldBeError, "SilentPattern host has invalid ticket URL")
+			sp.Ticket = "https://tracker.example.com/OPS-1"
+			So(CheckSilentPattern(sp), ShouldBeNil)
+		})
+		Convey("Matcher", func() {
+			sp := &moira.SilentPatternData{Type: moira.SPTMatcher, Comment: "migration"}
+			So(CheckSilentPattern(sp), ShouldBeError, "SilentPattern of matcher type must have matcher")
+			sp.Matcher = &moira.SilentMatcher{}
+			So(CheckSilentPattern(sp), ShouldBeError, "SilentPattern matcher: matcher must have at least one condition")
+			sp.Matcher = &moira.SilentMatcher{MetricRegex: "(", States: []string{moira.ERROR}}
+			So(CheckSilentPattern(sp), ShouldNotBeNil)
+			sp.Matcher = &moira.SilentMatcher{States: []string{"BROKEN"}}
+			So(CheckSilentPattern(sp), ShouldBeError, "SilentPattern matcher: unknown state 'BROKEN'")
+			sp.Matcher = &moira.SilentMatcher{MetricRegex: `^db\.`, Tags: []string{"prod", "mysql"}, States: []string{moira.WARN}}
+			So(CheckSilentPattern(sp), ShouldBeNil)
+			So(sp.Matcher.String(), ShouldEqual, `metric=~"^db\\." tags=mysql,prod state=WARN`)
+		})
+	})
+}
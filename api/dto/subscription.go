@@ -22,8 +22,8 @@ func (subscription *Subscription) Bind(r *http.Request) error {
 	if len(subscription.Tags) == 0 {
 		return fmt.Errorf("Subscription must have tags")
 	}
-	if len(subscription.Contacts) == 0 {
-		return fmt.Errorf("Subscription must have contacts")
+	if len(subscription.Contacts) == 0 && len(subscription.Rotations) == 0 {
+		return fmt.Errorf("Subscription must have contacts or rotations")
 	}
 	return nil
 }
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"net/http"
+
+	"go.avito.ru/DO/moira"
+)
+
+type Team struct {
+	moira.Team
+	ReadOnly bool `json:"readOnly"` // teams from the config can't be changed via api
+}
+
+func (team *Team) Bind(_ *http.Request) error {
+	return team.Validate()
+}
+
+func (*Team) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+type TeamList struct {
+	List []Team `json:"list"`
+}
+
+func (*TeamList) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
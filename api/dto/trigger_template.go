@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.avito.ru/DO/moira"
+)
+
+type TriggerTemplate struct {
+	moira.TriggerTemplate
+}
+
+// Bind validates the template, the parameters are detected by the placeholders unless they are given
+func (template *TriggerTemplate) Bind(_ *http.Request) error {
+	if len(template.Params) == 0 {
+		template.Params = moira.GetTemplateParams(&template.Trigger)
+	}
+	return template.Validate()
+}
+
+func (*TriggerTemplate) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+type TriggerTemplateList struct {
+	List []moira.TriggerTemplate `json:"list"`
+}
+
+func (*TriggerTemplateList) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// TemplateInstance is the trigger instantiated from the template
+type TemplateInstance struct {
+	TriggerID string            `json:"trigger_id"` // generated when the instance is created unless it is given
+	Name      string            `json:"name"`
+	Params    map[string]string `json:"params"`
+}
+
+func (instance *TemplateInstance) Bind(_ *http.Request) error {
+	if instance.Params == nil {
+		return fmt.Errorf("params are required")
+	}
+	return nil
+}
+
+func (*TemplateInstance) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+type TemplateInstanceList struct {
+	List []TemplateInstance `json:"list"`
+}
+
+func (*TemplateInstanceList) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// TemplateFieldChange is the change of the field of the trigger, the values are given as in TriggerModel
+type TemplateFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// TemplateInstanceDiff describes how the instance is changed by the template
+type TemplateInstanceDiff struct {
+	TriggerID string                `json:"trigger_id"`
+	Name      string                `json:"name"`
+	Changes   []TemplateFieldChange `json:"changes"`
+	Error     string                `json:"error,omitempty"` // the instance can't be rendered
+}
+
+type TemplateDiffList struct {
+	List []TemplateInstanceDiff `json:"list"`
+}
+
+func (*TemplateDiffList) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
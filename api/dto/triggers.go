@@ -16,6 +16,8 @@ import (
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/middleware"
+	"go.avito.ru/DO/moira/checker"
+	"go.avito.ru/DO/moira/database"
 	"go.avito.ru/DO/moira/expression"
 	"go.avito.ru/DO/moira/target"
 )
@@ -49,24 +51,35 @@ type Trigger struct {
 
 // TriggerModel is moira.Trigger api representation
 type TriggerModel struct {
-	ID              string              `json:"id"`
-	Name            string              `json:"name"`
-	Desc            *string             `json:"desc,omitempty"`
-	Targets         []string            `json:"targets"`
-	Parents         []string            `json:"parents,omitempty"`
-	WarnValue       *float64            `json:"warn_value"`
-	ErrorValue      *float64            `json:"error_value"`
-	Tags            []string            `json:"tags"`
-	TTLState        *string             `json:"ttl_state,omitempty"`
-	TTL             int64               `json:"ttl,omitempty"`
-	Schedule        *moira.ScheduleData `json:"sched,omitempty"`
-	Expression      string              `json:"expression"`
-	Patterns        []string            `json:"patterns"`
-	IsPullType      bool                `json:"is_pull_type"`
-	Dashboard       string              `json:"dashboard"`
-	PendingInterval int64               `json:"pending_interval"`
-	Maintenance     int64               `json:"maintenance"`
-	Saturation      []moira.Saturation  `json:"saturation,omitempty"`
+	ID              string                     `json:"id"`
+	Name            string                     `json:"name"`
+	Desc            *string                    `json:"desc,omitempty"`
+	Targets         []string                   `json:"targets"`
+	Parents         []string                   `json:"parents,omitempty"`
+	WarnValue       *float64                   `json:"warn_value"`
+	ErrorValue      *float64                   `json:"error_value"`
+	Tags            []string                   `json:"tags"`
+	TTLState        *string                    `json:"ttl_state,omitempty"`
+	TTL             int64                      `json:"ttl,omitempty"`
+	Schedule        *moira.ScheduleData        `json:"sched,omitempty"`
+	Expression      string                     `json:"expression"`
+	Patterns        []string                   `json:"patterns"`
+	IsPullType      bool                       `json:"is_pull_type"`
+	Source          string                     `json:"source,omitempty"`
+	Dashboard       string                     `json:"dashboard"`
+	PendingInterval int64                      `json:"pending_interval"`
+	Maintenance     int64                      `json:"maintenance"`
+	Saturation      []moira.Saturation         `json:"saturation,omitempty"`
+	SLO             *moira.SLOSettings         `json:"slo,omitempty"`
+	Flapping        *moira.FlappingSettings    `json:"flapping,omitempty"`
+	Forecast        *moira.ForecastSettings    `json:"forecast,omitempty"`
+	Team            string                     `json:"team,omitempty"`
+	Template        *moira.TriggerTemplateLink `json:"template,omitempty"` // is set for the instances of templates only
+	CheckInterval   int64                      `json:"check_interval,omitempty"`
+	Priority        moira.TriggerPriority      `json:"priority,omitempty"`
+	NoData          *moira.NoDataPolicy        `json:"nodata,omitempty"`
+	Quorum          *moira.QuorumSettings      `json:"quorum,omitempty"`
+	Managed         *moira.ManagedBy           `json:"managed,omitempty"`
 }
 
 // ToMoiraTrigger transforms TriggerModel to moira.Trigger
@@ -86,9 +99,20 @@ func (model *TriggerModel) ToMoiraTrigger() *moira.Trigger {
 		Expression:      &model.Expression,
 		Patterns:        model.Patterns,
 		IsPullType:      model.IsPullType,
+		Source:          model.Source,
 		Dashboard:       model.Dashboard,
 		PendingInterval: model.PendingInterval,
 		Saturation:      model.Saturation,
+		SLO:             model.SLO,
+		Flapping:        model.Flapping,
+		Forecast:        model.Forecast,
+		Team:            model.Team,
+		Template:        model.Template,
+		CheckInterval:   model.CheckInterval,
+		Priority:        model.Priority,
+		NoData:          model.NoData,
+		Quorum:          model.Quorum,
+		Managed:         model.Managed,
 	}
 }
 
@@ -109,43 +133,109 @@ func CreateTriggerModel(trigger *moira.Trigger) TriggerModel {
 		Expression:      moira.UseString(trigger.Expression),
 		Patterns:        trigger.Patterns,
 		IsPullType:      trigger.IsPullType,
+		Source:          trigger.Source,
 		Dashboard:       trigger.Dashboard,
 		PendingInterval: trigger.PendingInterval,
 		Saturation:      trigger.Saturation,
+		SLO:             trigger.SLO,
+		Flapping:        trigger.Flapping,
+		Forecast:        trigger.Forecast,
+		Team:            trigger.Team,
+		Template:        trigger.Template,
+		CheckInterval:   trigger.CheckInterval,
+		Priority:        trigger.Priority,
+		NoData:          trigger.NoData,
+		Quorum:          trigger.Quorum,
+		Managed:         trigger.Managed,
 	}
 }
 
 func (trigger *Trigger) Bind(request *http.Request) error {
-	config := middleware.GetConfig(request)
+	timeSeriesNames, err := trigger.Check(middleware.GetDatabase(request), middleware.GetConfig(request))
+	if err != nil {
+		return err
+	}
+	middleware.SetTimeSeriesNames(request, timeSeriesNames)
+	return nil
+}
+
+// Check validates the trigger, resolves its patterns and returns the names of the time series of the main target
+func (trigger *Trigger) Check(dataBase moira.Database, config api.Config) (map[string]bool, error) {
+	var metricsTTL int64
+	if trigger.SLO != nil || trigger.Forecast != nil {
+		var err error
+		if metricsTTL, err = getMetricsTTL(dataBase); err != nil {
+			return nil, err
+		}
+	}
+
+	isSLO := trigger.SLO != nil
+	if isSLO {
+		if err := checkSLO(trigger.SLO, trigger.IsPullType, metricsTTL); err != nil {
+			return nil, err
+		}
+		// SLO targets are stored as regular ones so that patterns could be resolved
+		trigger.Targets = []string{trigger.SLO.GoodTarget, trigger.SLO.TotalTarget}
+	}
 
 	if len(trigger.Targets) == 0 {
-		return fmt.Errorf("targets is required")
+		return nil, fmt.Errorf("targets is required")
 	}
 	rewrittenTargets, err := rewriteTargets(trigger.Targets, config.TargetRewriteRules)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	trigger.Targets = rewrittenTargets
+	if isSLO {
+		trigger.SLO.GoodTarget, trigger.SLO.TotalTarget = trigger.Targets[0], trigger.Targets[1]
+	}
 	if len(trigger.Tags) == 0 {
-		return fmt.Errorf("tags is required")
+		return nil, fmt.Errorf("tags is required")
 	}
 	reservedTagsFound := checkTriggerTags(trigger.Tags)
 	if len(reservedTagsFound) > 0 {
 		forbiddenTags := strings.Join(reservedTagsFound, ", ")
-		return fmt.Errorf("forbidden tags: %s", forbiddenTags)
+		return nil, fmt.Errorf("forbidden tags: %s", forbiddenTags)
 	}
 	if trigger.Name == "" {
-		return fmt.Errorf("trigger name is required")
+		return nil, fmt.Errorf("trigger name is required")
 	}
-	if trigger.WarnValue == nil && trigger.Expression == "" {
-		return fmt.Errorf("warn_value is required")
+	if trigger.Source != "" && !trigger.IsPullType {
+		return nil, fmt.Errorf("source can be set for pull triggers only")
 	}
-	if trigger.ErrorValue == nil && trigger.Expression == "" {
-		return fmt.Errorf("error_value is required")
+	if trigger.CheckInterval < 0 {
+		return nil, fmt.Errorf("check_interval can't be negative")
+	}
+	if !trigger.Priority.IsValid() {
+		return nil, fmt.Errorf("priority must be one of %v", moira.TriggerPriorities)
+	}
+	if trigger.NoData != nil {
+		if err := trigger.NoData.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if trigger.Quorum != nil {
+		if err := checkQuorum(trigger.Quorum, isSLO); err != nil {
+			return nil, err
+		}
+	}
+	if trigger.Flapping != nil && (trigger.Flapping.Threshold <= 0 || trigger.Flapping.Window <= 0) {
+		return nil, fmt.Errorf("flapping: threshold and window must be positive")
+	}
+	if trigger.Forecast != nil {
+		if err := checkForecast(trigger, metricsTTL); err != nil {
+			return nil, err
+		}
+	}
+	if trigger.WarnValue == nil && trigger.Expression == "" && !isSLO {
+		return nil, fmt.Errorf("warn_value is required")
+	}
+	if trigger.ErrorValue == nil && trigger.Expression == "" && !isSLO {
+		return nil, fmt.Errorf("error_value is required")
 	}
 	dashboard, err := resolveDashboard(trigger.Dashboard, config.GrafanaPrefixes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	trigger.Dashboard = dashboard
 
@@ -157,11 +247,114 @@ func (trigger *Trigger) Bind(request *http.Request) error {
 		Expression:              &trigger.Expression,
 	}
 
-	if err := resolvePatterns(request, trigger, &triggerExpression); err != nil {
-		return err
+	timeSeriesNames, err := resolvePatterns(dataBase, trigger, &triggerExpression)
+	if err != nil {
+		return nil, err
+	}
+	if isSLO {
+		// SLO triggers don't use expression at all
+		return timeSeriesNames, nil
 	}
 	if _, err := triggerExpression.Evaluate(); err != nil {
-		return err
+		return nil, err
+	}
+	return timeSeriesNames, nil
+}
+
+func checkQuorum(quorum *moira.QuorumSettings, isSLO bool) error {
+	if isSLO {
+		return fmt.Errorf("quorum can't be set for SLO trigger")
+	}
+	if quorum.Count < 0 {
+		return fmt.Errorf("quorum: count can't be negative")
+	}
+	if quorum.Percent < 0 || quorum.Percent > 100 {
+		return fmt.Errorf("quorum: percent must be between 0 and 100")
+	}
+	if quorum.Count == 0 && quorum.Percent == 0 {
+		return fmt.Errorf("quorum: either count or percent is required")
+	}
+	return nil
+}
+
+// getMetricsTTL returns the seconds the values of push metrics are kept for as the checkers set it, zero if it is unknown yet
+func getMetricsTTL(dataBase moira.Database) (int64, error) {
+	metricsTTL, err := dataBase.GetMetricsTTL()
+	if err == database.ErrNil {
+		return 0, nil
+	}
+	return metricsTTL, err
+}
+
+// checkSLO validates SLO settings, the windows of push triggers can't be longer than metrics are kept (if metricsTTL is known)
+func checkSLO(slo *moira.SLOSettings, isPullType bool, metricsTTL int64) error {
+	if slo.GoodTarget == "" || slo.TotalTarget == "" {
+		return fmt.Errorf("slo: good_target and total_target are required")
+	}
+	if slo.Objective <= 0 || slo.Objective >= 100 {
+		return fmt.Errorf("slo: objective must be between 0 and 100 exclusively")
+	}
+	if slo.Period < 0 {
+		return fmt.Errorf("slo: period can't be negative")
+	}
+	for i, window := range slo.Windows {
+		if window.LongWindow <= 0 || window.ShortWindow <= 0 {
+			return fmt.Errorf("slo: windows of alert #%d must be positive", i+1)
+		}
+		if window.ShortWindow > window.LongWindow {
+			return fmt.Errorf("slo: short window of alert #%d is longer than long one", i+1)
+		}
+		if window.BurnRate <= 0 {
+			return fmt.Errorf("slo: burn rate of alert #%d must be positive", i+1)
+		}
+		if window.State != moira.WARN && window.State != moira.ERROR {
+			return fmt.Errorf("slo: state of alert #%d must be either %s or %s", i+1, moira.WARN, moira.ERROR)
+		}
+	}
+
+	if isPullType || metricsTTL <= 0 {
+		return nil
+	}
+	for i, window := range slo.GetWindows() {
+		if window.LongWindow <= metricsTTL {
+			continue
+		}
+		if len(slo.Windows) == 0 {
+			return fmt.Errorf("slo: default windows are up to %ds long, but metrics are kept for %ds only, set the windows explicitly", window.LongWindow, metricsTTL)
+		}
+		return fmt.Errorf("slo: long window of alert #%d is %ds, but metrics are kept for %ds only", i+1, window.LongWindow, metricsTTL)
+	}
+	if slo.Period > metricsTTL {
+		return fmt.Errorf("slo: period is %ds, but metrics are kept for %ds only", slo.Period, metricsTTL)
+	}
+	return nil
+}
+
+// checkForecast validates forecast settings, the lookback of push triggers can't be longer than metrics are kept (if metricsTTL is known)
+func checkForecast(trigger *Trigger, metricsTTL int64) error {
+	forecast := trigger.Forecast
+	if trigger.SLO != nil || trigger.Expression != "" {
+		return fmt.Errorf("forecast: can't be used along with slo or expression")
+	}
+	if trigger.ErrorValue == nil {
+		return fmt.Errorf("forecast: error_value is required")
+	}
+	if forecast.Lookback <= 0 || forecast.ErrorHorizon <= 0 {
+		return fmt.Errorf("forecast: lookback and error_horizon must be positive")
+	}
+	if !trigger.IsPullType && metricsTTL > 0 && forecast.Lookback > metricsTTL {
+		return fmt.Errorf("forecast: lookback is %ds, but metrics are kept for %ds only", forecast.Lookback, metricsTTL)
+	}
+	if forecast.WarnHorizon != 0 && forecast.WarnHorizon < forecast.ErrorHorizon {
+		return fmt.Errorf("forecast: warn_horizon can't be shorter than error_horizon")
+	}
+	switch forecast.GetModel() {
+	case moira.ForecastModelLinear, moira.ForecastModelHolt:
+	default:
+		return fmt.Errorf("forecast: unknown model '%s'", forecast.Model)
+	}
+	if forecast.Alpha < 0 || forecast.Alpha > 1 || forecast.Beta < 0 || forecast.Beta > 1 {
+		return fmt.Errorf("forecast: alpha and beta must be between 0 and 1")
 	}
 	return nil
 }
@@ -267,17 +460,16 @@ func rewriteDashboardURL(u url.URL) (string, error) {
 	return u.String(), nil
 }
 
-func resolvePatterns(request *http.Request, trigger *Trigger, expressionValues *expression.TriggerExpression) error {
+func resolvePatterns(dataBase moira.Database, trigger *Trigger, expressionValues *expression.TriggerExpression) (map[string]bool, error) {
 	now := time.Now().Unix()
 	targetNum := 1
 	trigger.Patterns = make([]string, 0)
 	timeSeriesNames := make(map[string]bool)
 
 	for _, tar := range trigger.Targets {
-		database := middleware.GetDatabase(request)
-		result, err := target.EvaluateTarget(database, tar, now-600, now, false)
+		result, err := target.EvaluateTarget(dataBase, tar, now-600, now, false)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		trigger.Patterns = append(trigger.Patterns, result.Patterns...)
@@ -293,8 +485,7 @@ func resolvePatterns(request *http.Request, trigger *Trigger, expressionValues *
 		}
 		targetNum++
 	}
-	middleware.SetTimeSeriesNames(request, timeSeriesNames)
-	return nil
+	return timeSeriesNames, nil
 }
 
 func checkTriggerTags(tags []string) []string {
@@ -372,6 +563,20 @@ func (*TriggerMetrics) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// TriggerExplanation tells step by step what the check of the trigger does, nothing is saved by such a check
+type TriggerExplanation checker.Explanation
+
+func (*TriggerExplanation) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// TriggerMetricsForecast contains projections of the forecast trigger's metrics
+type TriggerMetricsForecast map[string]*moira.MetricForecast
+
+func (*TriggerMetricsForecast) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
 type AckMetricEscalationsRequest struct {
 	Metrics []string `json:"metrics"`
 }
@@ -2,11 +2,16 @@
 package dto
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
 )
 
 var rewriteRules = []api.RewriteRule{
@@ -66,3 +71,75 @@ func TestRewriteTargets(t *testing.T) {
 		)
 	})
 }
+
+func TestCheckSLO(t *testing.T) {
+	const hour = 3600
+	window := moira.SLOBurnRateAlert{LongWindow: hour, ShortWindow: 300, BurnRate: 14.4, State: moira.ERROR}
+	newSLO := func(windows ...moira.SLOBurnRateAlert) *moira.SLOSettings {
+		return &moira.SLOSettings{GoodTarget: "good", TotalTarget: "total", Objective: 99.9, Windows: windows}
+	}
+
+	Convey("Windows of push triggers fit metrics TTL", t, func() {
+		So(checkSLO(newSLO(window), false, hour), ShouldBeNil)
+		So(checkSLO(newSLO(window), false, hour-1), ShouldNotBeNil)
+
+		slo := newSLO(window)
+		slo.Period = 2 * hour
+		So(checkSLO(slo, false, hour), ShouldNotBeNil)
+	})
+
+	Convey("Default windows are too long for default TTL", t, func() {
+		So(checkSLO(newSLO(), false, hour), ShouldNotBeNil)
+		So(checkSLO(newSLO(), false, 3*24*hour), ShouldBeNil)
+	})
+
+	Convey("Pull triggers and unknown TTL are not limited", t, func() {
+		So(checkSLO(newSLO(), true, hour), ShouldBeNil)
+		So(checkSLO(newSLO(), false, 0), ShouldBeNil)
+	})
+}
+
+func TestCheckForecast(t *testing.T) {
+	const hour = 3600
+	errorValue := float64(90)
+	newTrigger := func(lookback int64, isPullType bool) *Trigger {
+		return &Trigger{TriggerModel: TriggerModel{
+			ErrorValue: &errorValue,
+			IsPullType: isPullType,
+			Forecast:   &moira.ForecastSettings{Lookback: lookback, ErrorHorizon: hour},
+		}}
+	}
+
+	Convey("Lookback of push triggers fits metrics TTL", t, func() {
+		So(checkForecast(newTrigger(hour, false), hour), ShouldBeNil)
+		So(checkForecast(newTrigger(2*hour, false), hour), ShouldNotBeNil)
+		So(checkForecast(newTrigger(2*hour, true), hour), ShouldBeNil)
+		So(checkForecast(newTrigger(2*hour, false), 0), ShouldBeNil)
+	})
+}
+
+func TestGetMetricsTTL(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Metrics TTL is the one set by the checkers", t, func() {
+		dataBase.EXPECT().GetMetricsTTL().Return(int64(10800), nil)
+		metricsTTL, err := getMetricsTTL(dataBase)
+		So(err, ShouldBeNil)
+		So(metricsTTL, ShouldEqual, 10800)
+	})
+
+	Convey("Windows aren't limited until the checkers set metrics TTL", t, func() {
+		dataBase.EXPECT().GetMetricsTTL().Return(int64(0), database.ErrNil)
+		metricsTTL, err := getMetricsTTL(dataBase)
+		So(err, ShouldBeNil)
+		So(metricsTTL, ShouldEqual, 0)
+	})
+
+	Convey("Errors of the database", t, func() {
+		dataBase.EXPECT().GetMetricsTTL().Return(int64(0), fmt.Errorf("no connection"))
+		_, err := getMetricsTTL(dataBase)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -8,8 +8,10 @@ import (
 )
 
 type User struct {
-	Login       string `json:"login"`
-	IsSuperUser bool   `json:"isSuperUser"`
+	Login       string                `json:"login"`
+	IsSuperUser bool                  `json:"isSuperUser"`
+	Role        moira.Role            `json:"role,omitempty"`
+	Teams       map[string]moira.Role `json:"teams,omitempty"`
 }
 
 type UserSettings struct {
@@ -60,6 +60,15 @@ func ErrorNotFound(errorText string) *ErrorResponse {
 	}
 }
 
+// ErrorUnauthorized return 401 with given error text
+func ErrorUnauthorized(errorText string) *ErrorResponse {
+	return &ErrorResponse{
+		HTTPStatusCode: 401,
+		StatusText:     "Unauthorized",
+		ErrorText:      errorText,
+	}
+}
+
 // ErrorForbidden return 403 with given error text
 func ErrorForbidden(errorText string) *ErrorResponse {
 	return &ErrorResponse{
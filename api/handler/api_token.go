@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/api/middleware"
+)
+
+// apiToken manages API tokens, these requests can't be made with tokens themselves
+func apiToken(router chi.Router) {
+	router.Get("/", getAPITokens)
+	router.Put("/", createAPIToken)
+	router.Delete("/{tokenId}", removeAPIToken)
+}
+
+func getAPITokens(writer http.ResponseWriter, request *http.Request) {
+	userLogin := middleware.GetLogin(request)
+	tokens, err := controller.GetAPITokens(database, userLogin, isAdmin(userLogin))
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, tokens); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func createAPIToken(writer http.ResponseWriter, request *http.Request) {
+	token := &dto.APIToken{}
+	if err := render.Bind(request, token); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	userLogin := middleware.GetLogin(request)
+	if err := controller.CreateAPIToken(database, token, userLogin, isAdmin(userLogin)); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("API token created", map[string]interface{}{
+		"id":     token.ID,
+		"name":   token.Name,
+		"login":  token.Login,
+		"scopes": token.Scopes,
+	})
+
+	if err := render.Render(writer, request, token); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func removeAPIToken(writer http.ResponseWriter, request *http.Request) {
+	tokenID := chi.URLParam(request, "tokenId")
+	userLogin := middleware.GetLogin(request)
+	if err := controller.RemoveAPIToken(database, tokenID, userLogin, isAdmin(userLogin)); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("API token revoked", tokenID)
+}
+
+func isAdmin(userLogin string) bool {
+	return authorizer.GetRole(userLogin).Includes(moira.RoleAdmin)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/middleware"
+	moira_database "go.avito.ru/DO/moira/database"
+)
+
+// requireRole is middleware which rejects the requests of the users whose global role doesn't include the given one
+func requireRole(role moira.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if err := authorizer.CheckRole(middleware.GetLogin(request), role); err != nil {
+				_ = render.Render(writer, request, err)
+				return
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// triggerEditor is middleware which rejects the requests of the users who are not allowed to change the trigger
+func triggerEditor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !authorizer.IsEnabled() {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		triggerID := middleware.GetTriggerID(request)
+		trigger, err := database.GetTrigger(triggerID)
+		switch {
+		case err == moira_database.ErrNil:
+			// the trigger is to be created, it has no owner yet
+			trigger = &moira.Trigger{ID: triggerID}
+		case err != nil:
+			_ = render.Render(writer, request, api.ErrorInternalServer(err))
+			return
+		}
+
+		if err := authorizer.CheckTrigger(middleware.GetLogin(request), trigger); err != nil {
+			_ = render.Render(writer, request, err)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// checkTriggerOwnership makes sure that the user is allowed to save the trigger as it is given,
+// so that nobody can hand the trigger over to a team without being its editor
+func checkTriggerOwnership(request *http.Request, trigger *moira.Trigger) *api.ErrorResponse {
+	if err := authorizer.CheckTeam(trigger.Team); err != nil {
+		return err
+	}
+	return authorizer.CheckTrigger(middleware.GetLogin(request), trigger)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/controller"
 	"go.avito.ru/DO/moira/api/dto"
@@ -20,9 +21,7 @@ func globalSettings(router chi.Router) {
 
 func checkPermissions(request *http.Request) *api.ErrorResponse {
 	userName := middleware.GetLogin(request)
-	isSuperUser := superUsers[userName]
-
-	if !isSuperUser {
+	if !authorizer.GetRole(userName).Includes(moira.RoleAdmin) {
 		errMessage := fmt.Sprintf("User \"%s\" is forbidden to request or modify global settings", userName)
 		return api.ErrorForbidden(errMessage)
 	} else {
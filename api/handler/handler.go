@@ -12,11 +12,14 @@ import (
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	moira_middle "go.avito.ru/DO/moira/api/middleware"
+	"go.avito.ru/DO/moira/api/stream"
 )
 
 var database moira.Database
 var triggerInheritanceDatabase moira.TriggerInheritanceDatabase
 var superUsers = make(map[string]bool)
+var authorizer *api.Authorizer
+var eventsHub *stream.Hub
 
 const contactKey moira_middle.ContextKey = "contact"
 const subscriptionKey moira_middle.ContextKey = "subscription"
@@ -32,10 +35,16 @@ func NewHandler(
 ) http.Handler {
 	database = db
 	triggerInheritanceDatabase = triggerInheritanceDb
+	authorizer = api.NewAuthorizer(config.Authorization, config.SuperUsers, db)
+	eventsHub = stream.NewHub(db, log)
+	for _, userLogin := range config.SuperUsers {
+		superUsers[userLogin] = true
+	}
 
 	router := chi.NewRouter()
 	router.Use(render.SetContentType(render.ContentTypeJSON))
 	router.Use(moira_middle.UserContext)
+	router.Use(moira_middle.APITokenContext(db))
 	router.Use(moira_middle.RequestLogger(log))
 	router.Use(moira_middle.AppVersion(appVersion))
 	router.Use(middleware.NoCache)
@@ -47,28 +56,32 @@ func NewHandler(
 		router.Use(moira_middle.DatabaseContext(database))
 		router.Use(moira_middle.ConfigContext(*config))
 		router.Get("/config", webConfig(configFileContent))
+		router.Get("/openapi.json", openAPISpec())
 		router.Route("/user", user)
 		router.Route("/trigger", triggers)
+		router.Route("/template", triggerTemplate)
 		router.Route("/tag", tag)
 		router.Route("/pattern", pattern)
 		router.Route("/event", event)
+		router.Route("/stream", eventStream)
 		router.Route("/contact", contact)
 		router.Route("/subscription", subscription)
+		router.Route("/rotation", rotation)
 		router.Route("/notification", notification)
 		router.Route("/silent-pattern", silent)
 		router.Route("/global-settings", globalSettings)
 		router.Route("/stats/metrics", metricStats)
 		router.Route("/maintenance", maintenance)
+		router.Route("/inventory", inventory)
+		router.Route("/slack", slackInteractivity)
+		router.Route("/team", team)
+		router.Route("/token", apiToken)
 	})
 
 	if config.EnableCORS {
 		return cors.AllowAll().Handler(router)
 	}
 
-	for _, userLogin := range config.SuperUsers {
-		superUsers[userLogin] = true
-	}
-
 	return router
 }
 
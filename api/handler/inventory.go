@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/middleware"
+)
+
+// inventory exposes CMDB data of the configured inventory provider
+func inventory(router chi.Router) {
+	router.Get("/hosts/{name}", getInventoryHost)
+}
+
+func getInventoryHost(writer http.ResponseWriter, request *http.Request) {
+	provider := middleware.GetConfig(request).Inventory
+	host, err := controller.GetInventoryHost(provider, chi.URLParam(request, "name"))
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, host); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
@@ -16,6 +18,7 @@ import (
 func maintenance(router chi.Router) {
 	router.Get("/metrics", getMaintenanceMetrics)
 	router.Get("/tags", getMaintenanceTags)
+	router.Get("/upcoming", getMaintenanceUpcoming)
 	router.Route("/trigger/{id}", func(router chi.Router) {
 		router.Get("/", getMaintenanceTrigger)
 	})
@@ -64,3 +67,47 @@ func getMaintenanceTrigger(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 }
+
+// getMaintenanceUpcoming lists upcoming occurrences of scheduled silent patterns
+func getMaintenanceUpcoming(writer http.ResponseWriter, request *http.Request) {
+	from, to, err := getUpcomingRange(request)
+	if err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	upcoming, errorResponse := controller.GetSilentUpcoming(database, from, to)
+	if errorResponse != nil {
+		_ = render.Render(writer, request, errorResponse)
+		return
+	}
+
+	if err := render.Render(writer, request, upcoming); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+// getUpcomingRange parses optional "from" and "to" unix timestamps, the next week is used by default
+func getUpcomingRange(request *http.Request) (from, to int64, err error) {
+	const defaultRange = 7 * 24 * 60 * 60
+
+	from = time.Now().Unix()
+	if fromStr := request.URL.Query().Get("from"); fromStr != "" {
+		if from, err = strconv.ParseInt(fromStr, 10, 64); err != nil {
+			return 0, 0, errors.Errorf("Can not parse from: %s", fromStr)
+		}
+	}
+
+	to = from + defaultRange
+	if toStr := request.URL.Query().Get("to"); toStr != "" {
+		if to, err = strconv.ParseInt(toStr, 10, 64); err != nil {
+			return 0, 0, errors.Errorf("Can not parse to: %s", toStr)
+		}
+	}
+
+	if to <= from {
+		return 0, 0, errors.New("'to' must be greater than 'from'")
+	}
+	return from, to, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/controller"
 	"go.avito.ru/DO/moira/api/middleware"
@@ -15,7 +16,7 @@ import (
 
 func notification(router chi.Router) {
 	router.Get("/", getNotification)
-	router.Delete("/", deleteNotification)
+	router.With(requireRole(moira.RoleAdmin)).Delete("/", deleteNotification)
 }
 
 func getNotification(writer http.ResponseWriter, request *http.Request) {
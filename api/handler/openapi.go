@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/openapi"
+)
+
+// openAPISpec serves OpenAPI specification of api, it is generated from the same routes as api/client
+func openAPISpec() http.HandlerFunc {
+	spec, err := openapi.Generate()
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if err != nil {
+			_ = render.Render(writer, request, api.ErrorInternalServer(err))
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write(spec)
+	})
+}
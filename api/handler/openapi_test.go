@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/openapi"
+)
+
+// TestOpenAPIRoutes fails if the routes of the handler differ from the routes described in api/openapi
+func TestOpenAPIRoutes(t *testing.T) {
+	Convey("Every route is described in the specification", t, func() {
+		handler := NewHandler(nil, nil, nil, &api.Config{}, nil, "")
+
+		registered := make([]string, 0)
+		walkRoutes("", handler.(chi.Routes), &registered)
+		sort.Strings(registered)
+
+		described := make([]string, 0, len(openapi.Routes))
+		for _, route := range openapi.Routes {
+			described = append(described, route.Method+" "+route.Path)
+		}
+		sort.Strings(described)
+
+		So(registered, ShouldResemble, described)
+	})
+}
+
+// walkRoutes lists the routes as "METHOD /path", trailing slashes of the subrouters are trimmed
+func walkRoutes(prefix string, routes chi.Routes, result *[]string) {
+	for _, route := range routes.Routes() {
+		pattern := prefix + route.Pattern
+		if route.SubRoutes != nil {
+			walkRoutes(strings.TrimSuffix(pattern, "/*"), route.SubRoutes, result)
+			continue
+		}
+		for method := range route.Handlers {
+			*result = append(*result, method+" "+strings.TrimSuffix(pattern, "/"))
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/controller"
 	"go.avito.ru/DO/moira/api/middleware"
@@ -14,7 +15,7 @@ import (
 
 func pattern(router chi.Router) {
 	router.Get("/", getAllPatterns)
-	router.Delete("/{pattern}", deletePattern)
+	router.With(requireRole(moira.RoleAdmin)).Delete("/{pattern}", deletePattern)
 }
 
 func getAllPatterns(writer http.ResponseWriter, request *http.Request) {
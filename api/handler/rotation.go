@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/api/middleware"
+)
+
+func rotation(router chi.Router) {
+	router.Get("/", getRotations)
+	router.With(requireRole(moira.RoleEditor)).Put("/", saveRotation)
+	router.Route("/{rotationId}", func(router chi.Router) {
+		router.Get("/", getRotation)
+		router.With(requireRole(moira.RoleEditor)).Delete("/", removeRotation)
+		router.Get("/on-call", getRotationOnCall)
+	})
+}
+
+func getRotations(writer http.ResponseWriter, request *http.Request) {
+	rotations, err := controller.GetRotations(database)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, rotations); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func getRotation(writer http.ResponseWriter, request *http.Request) {
+	rotation, err := controller.GetRotation(database, chi.URLParam(request, "rotationId"))
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, rotation); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func saveRotation(writer http.ResponseWriter, request *http.Request) {
+	rotation := &dto.Rotation{}
+	if err := render.Bind(request, rotation); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	if err := controller.SaveRotation(database, rotation); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Rotation saved", rotation)
+
+	if err := render.Render(writer, request, rotation); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func removeRotation(writer http.ResponseWriter, request *http.Request) {
+	rotationID := chi.URLParam(request, "rotationId")
+	if err := controller.RemoveRotation(database, rotationID); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Rotation removed", rotationID)
+}
+
+func getRotationOnCall(writer http.ResponseWriter, request *http.Request) {
+	ts := time.Now().Unix()
+	if tsStr := request.URL.Query().Get("ts"); tsStr != "" {
+		var err error
+		if ts, err = strconv.ParseInt(tsStr, 10, 64); err != nil {
+			_ = render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("ts must be unix timestamp")))
+			return
+		}
+	}
+
+	onCall, err := controller.GetRotationOnCall(database, chi.URLParam(request, "rotationId"), ts)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, onCall); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
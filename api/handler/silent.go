@@ -16,9 +16,10 @@ import (
 
 func silent(router chi.Router) {
 	router.Get("/", getSilentPatterns)
-	router.Put("/", createSilentPattern)
-	router.Post("/", updateSilentPattern)
-	router.Delete("/", removeSilentPattern)
+	router.With(requireRole(moira.RoleEditor)).Put("/", createSilentPattern)
+	router.With(requireRole(moira.RoleEditor)).Post("/", updateSilentPattern)
+	router.With(requireRole(moira.RoleEditor)).Delete("/", removeSilentPattern)
+	router.Post("/preview", previewSilentPattern)
 }
 
 func createSilentPatternManager(request *http.Request) *controller.SilentPatternManager {
@@ -46,7 +47,7 @@ func getSilentPatterns(writer http.ResponseWriter, request *http.Request) {
 }
 
 func createSilentPattern(writer http.ResponseWriter, request *http.Request) {
-	silentPatterns := &dto.SilentPatternList{}
+	silentPatterns := &dto.NewSilentPatternList{}
 	if err := render.Bind(request, silentPatterns); err != nil {
 		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
 		return
@@ -55,7 +56,7 @@ func createSilentPattern(writer http.ResponseWriter, request *http.Request) {
 	login := middleware.GetLogin(request)
 	manager := createSilentPatternManager(request)
 
-	if err := manager.CreateSilentPatterns(database, silentPatterns, login); err != nil {
+	if err := manager.CreateSilentPatterns(database, &silentPatterns.SilentPatternList, login); err != nil {
 		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
 		return
 	}
@@ -67,7 +68,7 @@ func createSilentPattern(writer http.ResponseWriter, request *http.Request) {
 }
 
 func updateSilentPattern(writer http.ResponseWriter, request *http.Request) {
-	silentPatterns := &dto.SilentPatternList{}
+	silentPatterns := &dto.NewSilentPatternList{}
 	if err := render.Bind(request, silentPatterns); err != nil {
 		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
 		return
@@ -76,7 +77,7 @@ func updateSilentPattern(writer http.ResponseWriter, request *http.Request) {
 	login := middleware.GetLogin(request)
 	manager := createSilentPatternManager(request)
 
-	if err := manager.UpdateSilentPatterns(database, silentPatterns, login); err != nil {
+	if err := manager.UpdateSilentPatterns(database, &silentPatterns.SilentPatternList, login); err != nil {
 		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
 		return
 	}
@@ -106,3 +107,24 @@ func removeSilentPattern(writer http.ResponseWriter, request *http.Request) {
 	}
 	middleware.GetLoggerEntry(request).InfoE("Silent pattern(s) deleted", silentPatterns)
 }
+
+// previewSilentPattern shows what would be muted by the silent patterns if they were created
+func previewSilentPattern(writer http.ResponseWriter, request *http.Request) {
+	silentPatterns := &dto.NewSilentPatternList{}
+	if err := render.Bind(request, silentPatterns); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	manager := createSilentPatternManager(request)
+	preview, err := manager.PreviewSilentPatterns(database, &silentPatterns.SilentPatternList)
+	if err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(writer, request, preview); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
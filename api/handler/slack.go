@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/slack-go/slack"
+
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/middleware"
+	"go.avito.ru/DO/moira/chatops"
+	"go.avito.ru/DO/moira/logging"
+)
+
+func slackInteractivity(router chi.Router) {
+	router.Post("/actions", handleSlackActions)
+	router.Post("/commands", handleSlackCommand)
+}
+
+// handleSlackActions handles interactive actions of the messages sent by Moira, see https://api.slack.com/interactivity/handling
+func handleSlackActions(writer http.ResponseWriter, request *http.Request) {
+	config := middleware.GetConfig(request).Slack
+
+	body, errorResponse := readSlackRequest(request, config.SigningSecret)
+	if errorResponse != nil {
+		_ = render.Render(writer, request, errorResponse)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	callback := &slack.InteractionCallback{}
+	if err := json.Unmarshal([]byte(form.Get("payload")), callback); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("failed to parse payload: %v", err)))
+		return
+	}
+
+	if err := controller.HandleSlackActions(database, authorizer, config, callback); err != nil {
+		_ = render.Render(writer, request, err)
+	}
+}
+
+// handleSlackCommand answers slash command, e.g. "/moira status <tag>", see https://api.slack.com/interactivity/slash-commands
+func handleSlackCommand(writer http.ResponseWriter, request *http.Request) {
+	config := middleware.GetConfig(request).Slack
+
+	body, errorResponse := readSlackRequest(request, config.SigningSecret)
+	if errorResponse != nil {
+		_ = render.Render(writer, request, errorResponse)
+		return
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	command, err := slack.SlashCommandParse(request)
+	if err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	login := controller.GetSlackLogin(config, slack.User{ID: command.UserID, Name: command.UserName})
+	if login == "" {
+		answer := fmt.Sprintf("Your Slack user %s is not mapped to Moira login, please ask Moira administrators to add it", command.UserID)
+		render.JSON(writer, request, &slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: answer})
+		return
+	}
+	answer := chatops.NewExecutor(database, authorizer, config.FrontURI).Execute(login, command.Text)
+
+	logging.GetLogger("").InfoE(fmt.Sprintf("Slack user %s has run command %s %s", login, command.Command, command.Text), map[string]interface{}{
+		"user": login,
+	})
+	render.JSON(writer, request, &slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: answer})
+}
+
+// readSlackRequest reads the body of the request which must be signed by Slack
+func readSlackRequest(request *http.Request, signingSecret string) ([]byte, *api.ErrorResponse) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, api.ErrorInvalidRequest(err)
+	}
+	if err := verifySlackRequest(request.Header, body, signingSecret); err != nil {
+		return nil, api.ErrorForbidden(err.Error())
+	}
+	return body, nil
+}
+
+// verifySlackRequest makes sure that the request has been signed by Slack
+func verifySlackRequest(header http.Header, body []byte, signingSecret string) error {
+	if signingSecret == "" {
+		return fmt.Errorf("Slack signing secret is not configured")
+	}
+	verifier, err := slack.NewSecretsVerifier(header, signingSecret)
+	if err != nil {
+		return err
+	}
+	if _, err := verifier.Write(body); err != nil {
+		return err
+	}
+	return verifier.Ensure()
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/stream"
+)
+
+// heartbeatInterval keeps the idle connections open through the proxies
+const heartbeatInterval = 30 * time.Second
+
+func eventStream(router chi.Router) {
+	router.Get("/events", streamEvents)
+}
+
+// streamEvents sends the events as server-sent events, the stream is resumed from Last-Event-ID header
+// (or lastEventId parameter) if it is given
+func streamEvents(writer http.ResponseWriter, request *http.Request) {
+	filter := &stream.Filter{
+		Tags:       getRequestTags(request),
+		TriggerIDs: getRequestList(request, "triggers"),
+		States:     getRequestList(request, "states"),
+	}
+	if err := filter.Validate(); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		render.Render(writer, request, api.ErrorInternalServer(fmt.Errorf("Streaming is not supported")))
+		return
+	}
+
+	// subscribe before the replay so that no event is lost in between
+	subscriber, err := eventsHub.Subscribe(filter)
+	if err != nil {
+		render.Render(writer, request, api.ErrorInternalServer(err))
+		return
+	}
+	defer eventsHub.Unsubscribe(subscriber)
+
+	replayed := make([]*moira.NotificationEvent, 0)
+	lastEventID := request.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = request.FormValue("lastEventId")
+	}
+	if lastEventID != "" {
+		var errorResponse *api.ErrorResponse
+		if replayed, errorResponse = controller.GetEventsToReplay(database, filter, lastEventID); errorResponse != nil {
+			render.Render(writer, request, errorResponse)
+			return
+		}
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+
+	sent := make(map[string]bool, len(replayed))
+	for _, event := range replayed {
+		if err := writeStreamEvent(writer, event); err != nil {
+			return
+		}
+		sent[stream.GetEventID(event)] = true
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case event, ok := <-subscriber.Events():
+			if !ok {
+				// the client is to reconnect and resume the stream
+				return
+			}
+			if sent[stream.GetEventID(event)] {
+				continue
+			}
+			if err := writeStreamEvent(writer, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeStreamEvent(writer http.ResponseWriter, event *moira.NotificationEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "id: %s\ndata: %s\n\n", stream.GetEventID(event), data)
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/controller"
 	"go.avito.ru/DO/moira/api/middleware"
@@ -16,7 +17,7 @@ func tag(router chi.Router) {
 	router.Get("/stats", getAllTagsAndSubscriptions)
 	router.Route("/{tag}", func(router chi.Router) {
 		router.Use(middleware.TagContext)
-		router.Delete("/", removeTag)
+		router.With(requireRole(moira.RoleAdmin)).Delete("/", removeTag)
 	})
 }
 
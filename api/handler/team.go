@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/api/middleware"
+)
+
+func team(router chi.Router) {
+	router.Get("/", getTeams)
+	router.With(requireRole(moira.RoleAdmin)).Put("/", saveTeam)
+	router.With(requireRole(moira.RoleAdmin)).Delete("/{teamId}", removeTeam)
+}
+
+func getTeams(writer http.ResponseWriter, request *http.Request) {
+	teams, err := controller.GetTeams(authorizer)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, teams); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func saveTeam(writer http.ResponseWriter, request *http.Request) {
+	team := &dto.Team{}
+	if err := render.Bind(request, team); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	if err := controller.SaveTeam(database, authorizer, team); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Team saved", team)
+
+	if err := render.Render(writer, request, team); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func removeTeam(writer http.ResponseWriter, request *http.Request) {
+	teamID := chi.URLParam(request, "teamId")
+	if err := controller.RemoveTeam(database, authorizer, teamID); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Team removed", teamID)
+}
@@ -20,22 +20,29 @@ import (
 
 func trigger(router chi.Router) {
 	router.Use(middleware.TriggerContext)
-	router.Put("/", updateTrigger)
+	router.With(triggerEditor).Put("/", updateTrigger)
 	router.Get("/", getTrigger)
-	router.Delete("/", removeTrigger)
+	router.With(triggerEditor).Delete("/", removeTrigger)
 	router.Get("/state", getTriggerState)
+	router.Get("/explain", explainTrigger)
 	router.Route("/throttling", func(router chi.Router) {
 		router.Get("/", getTriggerThrottling)
-		router.Delete("/", deleteThrottling)
+		router.With(triggerEditor).Delete("/", deleteThrottling)
 	})
 	router.Route("/metrics", func(router chi.Router) {
 		router.With(middleware.DateRange("-10minutes", "now")).Get("/", getTriggerMetrics)
-		router.Delete("/", deleteTriggerMetric)
+		router.With(triggerEditor).Delete("/", deleteTriggerMetric)
+		router.Get("/forecast", getTriggerMetricsForecast)
 	})
-	router.Put("/maintenance", setMetricsMaintenance)
-	router.Put("/triggerMaintenance", setTriggerMaintenance)
-	router.Delete("/escalations", ackEscalations)
-	router.Post("/ackEscalations", ackMetricEscalations)
+	router.With(triggerEditor).Put("/maintenance", setMetricsMaintenance)
+	router.With(triggerEditor).Put("/triggerMaintenance", setTriggerMaintenance)
+	router.Route("/maintenance/windows", func(router chi.Router) {
+		router.Get("/", getTriggerMaintenanceSchedule)
+		router.With(triggerEditor).Post("/", addTriggerMaintenanceWindow)
+		router.With(triggerEditor).Delete("/{windowID}", removeTriggerMaintenanceWindow)
+	})
+	router.With(triggerEditor).Delete("/escalations", ackEscalations)
+	router.With(triggerEditor).Post("/ackEscalations", ackMetricEscalations)
 
 	router.Post("/_unacknowledgedMessages", getUnacknowledgedMessages)
 }
@@ -54,6 +61,10 @@ func updateTrigger(writer http.ResponseWriter, request *http.Request) {
 		}
 		return
 	}
+	if err := checkTriggerOwnership(request, trigger.ToMoiraTrigger()); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
 
 	timeSeriesNames := middleware.GetTimeSeriesNames(request)
 	response, err := controller.UpdateTrigger(
@@ -169,6 +180,30 @@ func getTriggerMetrics(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
+func explainTrigger(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	explanation, err := controller.ExplainTrigger(database, triggerID)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, explanation); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func getTriggerMetricsForecast(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	forecast, err := controller.GetTriggerMetricsForecast(database, triggerID)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, &forecast); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
 func deleteTriggerMetric(writer http.ResponseWriter, request *http.Request) {
 	triggerID := middleware.GetTriggerID(request)
 	metricName := request.URL.Query().Get("name")
@@ -238,6 +273,71 @@ func setTriggerMaintenance(writer http.ResponseWriter, request *http.Request) {
 	)
 }
 
+func getTriggerMaintenanceSchedule(writer http.ResponseWriter, request *http.Request) {
+	from, to, err := getUpcomingRange(request)
+	if err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	triggerID := middleware.GetTriggerID(request)
+	schedule, errorResponse := controller.GetTriggerMaintenanceSchedule(database, triggerID, from, to)
+	if errorResponse != nil {
+		_ = render.Render(writer, request, errorResponse)
+		return
+	}
+	if err := render.Render(writer, request, schedule); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func addTriggerMaintenanceWindow(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	userLogin := middleware.GetLogin(request)
+
+	window := &dto.MaintenanceWindow{}
+	if err := render.Bind(request, window); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	if err := controller.AddTriggerMaintenanceWindow(database, triggerID, window); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+
+	logging.GetLogger(triggerID).InfoE(
+		fmt.Sprintf("User %s has scheduled maintenance window for trigger id %s", userLogin, triggerID),
+		map[string]interface{}{
+			"window": window,
+			"user":   userLogin,
+		},
+	)
+
+	if err := render.Render(writer, request, window); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func removeTriggerMaintenanceWindow(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	userLogin := middleware.GetLogin(request)
+	windowID := chi.URLParam(request, "windowID")
+
+	if err := controller.RemoveTriggerMaintenanceWindow(database, triggerID, windowID); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+
+	logging.GetLogger(triggerID).InfoE(
+		fmt.Sprintf("User %s has removed maintenance window for trigger id %s", userLogin, triggerID),
+		map[string]interface{}{
+			"window_id": windowID,
+			"user":      userLogin,
+		},
+	)
+}
+
 func ackEscalations(writer http.ResponseWriter, request *http.Request) {
 	triggerID := middleware.GetTriggerID(request)
 	logger := logging.GetLogger(triggerID)
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/dto"
+	"go.avito.ru/DO/moira/api/middleware"
+)
+
+func triggerTemplate(router chi.Router) {
+	router.Get("/", getTriggerTemplates)
+	router.With(requireRole(moira.RoleEditor)).Put("/", createTriggerTemplate)
+	router.Route("/{templateId}", func(router chi.Router) {
+		router.Get("/", getTriggerTemplate)
+		router.With(requireRole(moira.RoleEditor)).Put("/", updateTriggerTemplate)
+		router.With(requireRole(moira.RoleEditor)).Delete("/", removeTriggerTemplate)
+		router.Post("/preview", previewTriggerTemplate)
+		router.Get("/instance", getTemplateInstances)
+		router.Put("/instance", saveTemplateInstance)
+		router.With(middleware.TriggerContext, triggerEditor).Delete("/instance/{triggerId}", detachTemplateInstance)
+	})
+}
+
+func getTriggerTemplates(writer http.ResponseWriter, request *http.Request) {
+	templates, err := controller.GetTriggerTemplates(database)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, templates); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func createTriggerTemplate(writer http.ResponseWriter, request *http.Request) {
+	template := &dto.TriggerTemplate{}
+	if err := render.Bind(request, template); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	if err := controller.CreateTriggerTemplate(database, template); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Trigger template created", template)
+
+	if err := render.Render(writer, request, template); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func getTriggerTemplate(writer http.ResponseWriter, request *http.Request) {
+	template, err := controller.GetTriggerTemplate(database, chi.URLParam(request, "templateId"))
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, template); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+// previewTriggerTemplate shows how the given version of the template would change the instances
+func previewTriggerTemplate(writer http.ResponseWriter, request *http.Request) {
+	template := &dto.TriggerTemplate{}
+	if err := render.Bind(request, template); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	template.ID = chi.URLParam(request, "templateId")
+
+	diff, err := controller.PreviewTriggerTemplate(database, middleware.GetConfig(request), template)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, diff); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+// updateTriggerTemplate saves the template and renders its instances again, the changes of the instances are returned
+func updateTriggerTemplate(writer http.ResponseWriter, request *http.Request) {
+	template := &dto.TriggerTemplate{}
+	if err := render.Bind(request, template); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	template.ID = chi.URLParam(request, "templateId")
+
+	diff, err := controller.UpdateTriggerTemplate(
+		database, triggerInheritanceDatabase, middleware.GetConfig(request),
+		authorizer, middleware.GetLogin(request), template,
+	)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Trigger template updated", map[string]interface{}{
+		"template": template,
+		"diff":     diff,
+	})
+
+	if err := render.Render(writer, request, diff); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func removeTriggerTemplate(writer http.ResponseWriter, request *http.Request) {
+	templateID := chi.URLParam(request, "templateId")
+	if err := controller.RemoveTriggerTemplate(database, templateID); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Trigger template removed", templateID)
+}
+
+func getTemplateInstances(writer http.ResponseWriter, request *http.Request) {
+	instances, err := controller.GetTemplateInstances(database, chi.URLParam(request, "templateId"))
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, instances); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+// saveTemplateInstance creates the trigger from the template or changes the parameters of the instance
+func saveTemplateInstance(writer http.ResponseWriter, request *http.Request) {
+	instance := &dto.TemplateInstance{}
+	if err := render.Bind(request, instance); err != nil {
+		_ = render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	response, err := controller.SaveTemplateInstance(
+		database, triggerInheritanceDatabase, middleware.GetConfig(request),
+		authorizer, middleware.GetLogin(request), chi.URLParam(request, "templateId"), instance,
+	)
+	if err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Trigger template instance saved", instance)
+
+	if err := render.Render(writer, request, response); err != nil {
+		_ = render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+// detachTemplateInstance makes the instance an ordinary trigger
+func detachTemplateInstance(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	if err := controller.DetachTemplateInstance(database, chi.URLParam(request, "templateId"), triggerID); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
+	middleware.GetLoggerEntry(request).InfoE("Trigger detached from template", triggerID)
+}
@@ -50,6 +50,10 @@ func createTrigger(writer http.ResponseWriter, request *http.Request) {
 		}
 		return
 	}
+	if err := checkTriggerOwnership(request, trigger.ToMoiraTrigger()); err != nil {
+		_ = render.Render(writer, request, err)
+		return
+	}
 
 	timeSeriesNames := middleware.GetTimeSeriesNames(request)
 	response, err := controller.CreateTrigger(database, triggerInheritanceDatabase, &trigger.TriggerModel, timeSeriesNames)
@@ -101,17 +105,19 @@ func getOnlyProblemsFlag(request *http.Request) bool {
 }
 
 func getRequestTags(request *http.Request) []string {
-	var filterTags []string
-	i := 0
-	for {
-		tag := request.FormValue(fmt.Sprintf("tags[%v]", i))
-		if tag == "" {
-			break
+	return getRequestList(request, "tags")
+}
+
+// getRequestList returns the values given as name[0], name[1], ...
+func getRequestList(request *http.Request, name string) []string {
+	var values []string
+	for i := 0; ; i++ {
+		value := request.FormValue(fmt.Sprintf("%s[%d]", name, i))
+		if value == "" {
+			return values
 		}
-		filterTags = append(filterTags, tag)
-		i++
+		values = append(values, value)
 	}
-	return filterTags
 }
 
 func getTriggerName(request *http.Request) string {
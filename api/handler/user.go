@@ -6,6 +6,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/api/controller"
 	"go.avito.ru/DO/moira/api/dto"
@@ -19,7 +20,8 @@ func user(router chi.Router) {
 
 func getUserName(writer http.ResponseWriter, request *http.Request) {
 	userLogin := middleware.GetLogin(request)
-	if err := render.Render(writer, request, &dto.User{Login: userLogin}); err != nil {
+	user := &dto.User{Login: userLogin, Role: authorizer.GetRole(userLogin)}
+	if err := render.Render(writer, request, user); err != nil {
 		render.Render(writer, request, api.ErrorRender(err))
 		return
 	}
@@ -33,8 +35,22 @@ func getUserSettings(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	userSettings.Role = authorizer.GetRole(userLogin)
+	if userSettings.Teams, err = getUserTeams(userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
 	if err := render.Render(writer, request, userSettings); err != nil {
 		render.Render(writer, request, api.ErrorRender(err))
 		return
 	}
 }
+
+func getUserTeams(userLogin string) (map[string]moira.Role, *api.ErrorResponse) {
+	teams, err := authorizer.GetUserTeams(userLogin)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return teams, nil
+}
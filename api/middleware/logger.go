@@ -124,6 +124,14 @@ type responseWriterWithBody struct {
 	body bytes.Buffer
 }
 
+// Flush sends the buffered data to the client, the body is not kept any more since it is a stream
+func (w *responseWriterWithBody) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	w.body.Reset()
+}
+
 func (w *responseWriterWithBody) Write(buf []byte) (int, error) {
 	n, err := w.ResponseWriter.Write(buf)
 	_, err2 := w.body.Write(buf[:n])
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/database"
+)
+
+const bearerPrefix = "Bearer "
+
+var apiTokenKey ContextKey = "apiToken"
+
+// APITokenContext resolves "Authorization: Bearer" API token into the login of its owner,
+// so that the token replaces x-webauth-user header set by authenticating proxy.
+// The requests which are not allowed by the scopes of the token are rejected
+func APITokenContext(storage moira.Database) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			header := request.Header.Get("Authorization")
+			if !strings.HasPrefix(header, bearerPrefix) {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			token, err := storage.GetAPITokenByHash(api.HashAPIToken(strings.TrimSpace(strings.TrimPrefix(header, bearerPrefix))))
+			switch {
+			case err == database.ErrNil:
+				render.Render(writer, request, api.ErrorUnauthorized("Unknown API token"))
+				return
+			case err != nil:
+				render.Render(writer, request, api.ErrorInternalServer(err))
+				return
+			case token.IsExpired(time.Now().Unix()):
+				render.Render(writer, request, api.ErrorUnauthorized(fmt.Sprintf("API token %s is expired", token.ID)))
+				return
+			}
+
+			scope, ok := getRequiredScope(request)
+			if !ok {
+				render.Render(writer, request, api.ErrorForbidden("The request is not allowed for API tokens"))
+				return
+			}
+			if !token.HasScope(scope) {
+				render.Render(writer, request, api.ErrorForbidden(fmt.Sprintf("API token %s has no scope %s", token.ID, scope)))
+				return
+			}
+
+			ctx := context.WithValue(request.Context(), loginKey, token.Login)
+			ctx = context.WithValue(ctx, apiTokenKey, token)
+			next.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
+}
+
+// GetAPIToken gets API token the request is authenticated with, nil is returned if there is no token
+func GetAPIToken(request *http.Request) *moira.APIToken {
+	token, _ := request.Context().Value(apiTokenKey).(*moira.APIToken)
+	return token
+}
+
+// getRequiredScope returns the scope of the token the request needs, false is returned
+// if the request can't be made with API token at all (e.g. management of the tokens)
+func getRequiredScope(request *http.Request) (moira.APITokenScope, bool) {
+	path := request.URL.Path
+	if strings.HasPrefix(path, "/api/token") {
+		return "", false
+	}
+
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return moira.APITokenScopeRead, true
+	}
+	switch {
+	case strings.HasPrefix(path, "/api/trigger"), strings.HasPrefix(path, "/api/template"):
+		return moira.APITokenScopeWriteTriggers, true
+	case strings.HasPrefix(path, "/api/silent-pattern"):
+		return moira.APITokenScopeWriteSilences, true
+	default:
+		return "", false
+	}
+}
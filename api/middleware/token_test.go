@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestAPITokenContext(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	var login string
+	handler := UserContext(APITokenContext(dataBase)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		login = GetLogin(request)
+	})))
+
+	serve := func(method, path, token string) int {
+		login = ""
+		request := httptest.NewRequest(method, path, nil)
+		request.Header.Set("x-webauth-user", "proxy-user")
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	Convey("Requests without tokens are passed as is", t, func() {
+		So(serve(http.MethodGet, "/api/trigger", ""), ShouldEqual, http.StatusOK)
+		So(login, ShouldEqual, "proxy-user")
+	})
+
+	Convey("Unknown token", t, func() {
+		dataBase.EXPECT().GetAPITokenByHash(api.HashAPIToken("unknown")).Return(nil, database.ErrNil)
+		So(serve(http.MethodGet, "/api/trigger", "unknown"), ShouldEqual, http.StatusUnauthorized)
+	})
+
+	Convey("Expired token", t, func() {
+		dataBase.EXPECT().GetAPITokenByHash(api.HashAPIToken("expired")).Return(&moira.APIToken{
+			ID: "1", Login: "ci", Scopes: []moira.APITokenScope{moira.APITokenScopeRead}, ExpiresAt: time.Now().Unix() - 1,
+		}, nil)
+		So(serve(http.MethodGet, "/api/trigger", "expired"), ShouldEqual, http.StatusUnauthorized)
+	})
+
+	Convey("Scopes of the token", t, func() {
+		dataBase.EXPECT().GetAPITokenByHash(api.HashAPIToken("token")).Return(&moira.APIToken{
+			ID: "1", Login: "terraform", Scopes: []moira.APITokenScope{moira.APITokenScopeRead, moira.APITokenScopeWriteTriggers},
+		}, nil).AnyTimes()
+
+		So(serve(http.MethodGet, "/api/trigger", "token"), ShouldEqual, http.StatusOK)
+		So(login, ShouldEqual, "terraform")
+		So(serve(http.MethodPut, "/api/trigger/t1", "token"), ShouldEqual, http.StatusOK)
+		So(serve(http.MethodPut, "/api/template/tpl/instance", "token"), ShouldEqual, http.StatusOK)
+		So(serve(http.MethodPut, "/api/silent-pattern", "token"), ShouldEqual, http.StatusForbidden)
+		So(serve(http.MethodDelete, "/api/tag/db", "token"), ShouldEqual, http.StatusForbidden)
+		So(serve(http.MethodGet, "/api/token", "token"), ShouldEqual, http.StatusForbidden)
+		So(login, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// clientPackage is the import path of the generated client
+const clientPackage = "go.avito.ru/DO/moira/api/client"
+
+var clientTemplate = template.Must(template.New("client").Parse(`// Code generated by go generate; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+{{range .Imports}}
+	"{{.}}"{{end}}
+)
+{{range .Methods}}
+// {{.Name}} calls {{.Method}} {{.Path}}: {{.Summary}}
+func (client *Client) {{.Name}}(ctx context.Context{{range .Args}}, {{.Name}} {{.Type}}{{end}}) {{if .Response}}({{.Response}}, error){{else}}error{{end}} {
+	query := url.Values{}{{range .Query}}
+	{{.Setter}}(query, "{{.Name}}", {{.GoName}}, {{.Required}}){{end}}
+{{if .Response}}
+	response := {{.ResponseInit}}
+	if err := client.do(ctx, http.Method{{.MethodName}}, {{.PathExpr}}, query, {{.Request}}, {{.ResponseArg}}); err != nil {
+		return nil, err
+	}
+	return response, nil
+{{- else}}
+	return client.do(ctx, http.Method{{.MethodName}}, {{.PathExpr}}, query, {{.Request}}, nil)
+{{- end}}
+}
+{{end}}`))
+
+type clientMethod struct {
+	Name         string
+	Method       string
+	MethodName   string
+	Path         string
+	PathExpr     string
+	Summary      string
+	Args         []clientArg
+	Query        []clientQuery
+	Request      string
+	Response     string
+	ResponseInit string
+	ResponseArg  string
+}
+
+type clientArg struct {
+	Name string
+	Type string
+}
+
+type clientQuery struct {
+	Name     string
+	GoName   string
+	Setter   string
+	Required bool
+}
+
+// GenerateClient returns the source of Go client of api
+func GenerateClient() ([]byte, error) {
+	imports := make(map[string]bool)
+	methods := make([]clientMethod, 0, len(Routes))
+
+	for _, route := range Routes {
+		if route.External || route.Stream {
+			continue
+		}
+		method := clientMethod{
+			Name:       route.OperationID,
+			Method:     route.Method,
+			MethodName: strings.Title(strings.ToLower(route.Method)),
+			Path:       route.Path,
+			Summary:    strings.ToLower(route.Summary[:1]) + route.Summary[1:],
+			Request:    "nil",
+		}
+
+		pathExpr := `"` + route.Path + `"`
+		for _, name := range GetPathParams(route.Path) {
+			goName := getGoName(name)
+			method.Args = append(method.Args, clientArg{Name: goName, Type: "string"})
+			pathExpr = strings.Replace(pathExpr, "{"+name+"}", `"+url.PathEscape(`+goName+`)+"`, 1)
+		}
+		method.PathExpr = strings.TrimSuffix(pathExpr, `+""`)
+
+		for _, param := range route.Query {
+			goName := param.GoName
+			if goName == "" {
+				goName = getGoName(param.Name)
+			}
+			method.Args = append(method.Args, clientArg{Name: goName, Type: string(param.Type)})
+			method.Query = append(method.Query, clientQuery{
+				Name:     param.Name,
+				GoName:   goName,
+				Setter:   getQuerySetter(param.Type),
+				Required: param.Required,
+			})
+		}
+
+		if route.Request != nil {
+			requestType := reflect.TypeOf(route.Request)
+			typeName := addImport(imports, requestType)
+			if requestType.Kind() == reflect.Struct {
+				typeName = "*" + typeName
+			}
+			method.Args = append(method.Args, clientArg{Name: "request", Type: typeName})
+			method.Request = "request"
+		}
+
+		if route.Response != nil {
+			responseType := reflect.TypeOf(route.Response)
+			typeName := addImport(imports, responseType)
+			if responseType.Kind() == reflect.Struct {
+				method.Response = "*" + typeName
+				method.ResponseInit = "&" + typeName + "{}"
+				method.ResponseArg = "response"
+			} else {
+				method.Response = typeName
+				method.ResponseInit = typeName + "{}"
+				method.ResponseArg = "&response"
+			}
+		}
+		methods = append(methods, method)
+	}
+
+	buffer := &bytes.Buffer{}
+	err := clientTemplate.Execute(buffer, map[string]interface{}{
+		"Imports": sortedKeys(imports),
+		"Methods": methods,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format client: %v", err)
+	}
+	return result, nil
+}
+
+// getGoName makes the name of the argument idiomatic, e.g. triggerId -> triggerID
+func getGoName(name string) string {
+	if strings.HasSuffix(name, "Id") {
+		name = strings.TrimSuffix(name, "Id") + "ID"
+	}
+	if token.IsKeyword(name) {
+		name += "Param"
+	}
+	return name
+}
+
+func getQuerySetter(paramType ParamType) string {
+	switch paramType {
+	case ParamInt:
+		return "setIntParam"
+	case ParamBool:
+		return "setBoolParam"
+	case ParamList:
+		return "setListParam"
+	default:
+		return "setStringParam"
+	}
+}
+
+// addImport returns the name of the type in the client package and registers the package it belongs to
+func addImport(imports map[string]bool, valueType reflect.Type) string {
+	var register func(valueType reflect.Type)
+	register = func(valueType reflect.Type) {
+		if pkgPath := valueType.PkgPath(); pkgPath != "" && valueType.Name() != "" {
+			if pkgPath != clientPackage {
+				imports[pkgPath] = true
+			}
+			return
+		}
+		switch valueType.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			register(valueType.Elem())
+		case reflect.Map:
+			register(valueType.Key())
+			register(valueType.Elem())
+		}
+	}
+	register(valueType)
+	return valueType.String()
+}
@@ -0,0 +1,32 @@
+// Command generate writes OpenAPI specification and Go client of api, it is run by go generate in api/openapi
+package main
+
+import (
+	"io/ioutil"
+	"log"
+
+	"go.avito.ru/DO/moira/api/openapi"
+)
+
+const (
+	specPath   = "openapi.json"
+	clientPath = "../client/client_gen.go"
+)
+
+func main() {
+	spec, err := openapi.Generate()
+	if err != nil {
+		log.Fatalf("Failed to generate specification: %v", err)
+	}
+	if err := ioutil.WriteFile(specPath, spec, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", specPath, err)
+	}
+
+	client, err := openapi.GenerateClient()
+	if err != nil {
+		log.Fatalf("Failed to generate client: %v", err)
+	}
+	if err := ioutil.WriteFile(clientPath, client, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %v", clientPath, err)
+	}
+}
@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"net/http"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/dto"
+)
+
+// Route describes the operation of api, the spec and the client are generated from these descriptions
+type Route struct {
+	Method      string
+	Path        string // without trailing slash, path parameters are given in braces, e.g. /api/trigger/{triggerId}
+	OperationID string // name of the client method (capitalized)
+	Summary     string
+	Query       []Param
+	Request     interface{} // value of the request body type, nil means that there is no body
+	Response    interface{} // value of the response type, nil means that the response is empty
+	External    bool        // the operation is called by third party (e.g. Slack), the client doesn't support it
+	Stream      bool        // the response is a stream of server-sent events of Response type, the client method is written by hand
+}
+
+// Param is query parameter of the route
+type Param struct {
+	Name        string
+	GoName      string // name of the argument of the client method, Name is used by default
+	Type        ParamType
+	Description string
+	Required    bool
+}
+
+// ParamType is Go type of the parameter
+type ParamType string
+
+const (
+	ParamString ParamType = "string"
+	ParamInt    ParamType = "int64"
+	ParamBool   ParamType = "bool"
+	ParamList   ParamType = "[]string" // passed as name[0], name[1], ...
+)
+
+var (
+	paramPage         = Param{Name: "p", GoName: "page", Type: ParamInt, Description: "Page number, starting from 0"}
+	paramSize         = Param{Name: "size", Type: ParamInt, Description: "Page size"}
+	paramTags         = Param{Name: "tags", Type: ParamList, Description: "Tags, passed as tags[0], tags[1], ..."}
+	paramOnlyProblems = Param{Name: "onlyProblems", Type: ParamBool, Description: "Only the triggers which are not OK"}
+	paramFrom         = Param{Name: "from", Type: ParamInt, Description: "Unix timestamp, now by default"}
+	paramTo           = Param{Name: "to", Type: ParamInt, Description: "Unix timestamp"}
+)
+
+// Routes are all the routes registered by api handler
+var Routes = []Route{
+	// common
+	{Method: http.MethodGet, Path: "/api/config", OperationID: "GetWebConfig", Summary: "Get config of web UI", Response: map[string]interface{}{}},
+	{Method: http.MethodGet, Path: "/api/openapi.json", OperationID: "GetOpenAPISpec", Summary: "Get this specification", Response: map[string]interface{}{}},
+
+	// user
+	{Method: http.MethodGet, Path: "/api/user", OperationID: "GetUser", Summary: "Get current user", Response: dto.User{}},
+	{Method: http.MethodGet, Path: "/api/user/settings", OperationID: "GetUserSettings", Summary: "Get contacts and subscriptions of current user", Response: dto.UserSettings{}},
+
+	// triggers
+	{Method: http.MethodGet, Path: "/api/trigger", OperationID: "GetAllTriggers", Summary: "Get all triggers", Response: dto.TriggersList{}},
+	{Method: http.MethodPut, Path: "/api/trigger", OperationID: "CreateTrigger", Summary: "Create trigger", Request: dto.Trigger{}, Response: dto.SaveTriggerResponse{}},
+	{
+		Method: http.MethodGet, Path: "/api/trigger/page", OperationID: "GetTriggersPage", Summary: "Get page of triggers",
+		Query: []Param{
+			paramPage, paramSize, paramTags, paramOnlyProblems,
+			{Name: "triggerName", Type: ParamString, Description: "Part of trigger name"},
+		},
+		Response: dto.TriggersList{},
+	},
+	{Method: http.MethodGet, Path: "/api/trigger/{triggerId}", OperationID: "GetTrigger", Summary: "Get trigger", Response: dto.Trigger{}},
+	{Method: http.MethodPut, Path: "/api/trigger/{triggerId}", OperationID: "UpdateTrigger", Summary: "Update trigger", Request: dto.Trigger{}, Response: dto.SaveTriggerResponse{}},
+	{Method: http.MethodDelete, Path: "/api/trigger/{triggerId}", OperationID: "RemoveTrigger", Summary: "Remove trigger"},
+	{Method: http.MethodGet, Path: "/api/trigger/{triggerId}/state", OperationID: "GetTriggerState", Summary: "Get last check of trigger", Response: dto.TriggerCheck{}},
+	{Method: http.MethodGet, Path: "/api/trigger/{triggerId}/explain", OperationID: "ExplainTrigger", Summary: "Explain read-only check of trigger", Response: dto.TriggerExplanation{}},
+	{Method: http.MethodGet, Path: "/api/trigger/{triggerId}/throttling", OperationID: "GetTriggerThrottling", Summary: "Get throttling of trigger", Response: dto.ThrottlingResponse{}},
+	{Method: http.MethodDelete, Path: "/api/trigger/{triggerId}/throttling", OperationID: "DeleteTriggerThrottling", Summary: "Reset throttling of trigger"},
+	{
+		Method: http.MethodGet, Path: "/api/trigger/{triggerId}/metrics", OperationID: "GetTriggerMetrics", Summary: "Get values of trigger metrics",
+		Query: []Param{
+			{Name: "from", Type: ParamString, Description: "Graphite date, -10minutes by default"},
+			{Name: "to", Type: ParamString, Description: "Graphite date, now by default"},
+		},
+		Response: dto.TriggerMetrics{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/trigger/{triggerId}/metrics", OperationID: "DeleteTriggerMetric", Summary: "Remove metric from last check of trigger",
+		Query: []Param{{Name: "name", Type: ParamString, Description: "Metric name", Required: true}},
+	},
+	{Method: http.MethodGet, Path: "/api/trigger/{triggerId}/metrics/forecast", OperationID: "GetTriggerMetricsForecast", Summary: "Get forecast of trigger metrics", Response: dto.TriggerMetricsForecast{}},
+	{Method: http.MethodPut, Path: "/api/trigger/{triggerId}/maintenance", OperationID: "SetMetricsMaintenance", Summary: "Set maintenance of trigger metrics", Request: dto.MetricsMaintenance{}},
+	{Method: http.MethodPut, Path: "/api/trigger/{triggerId}/triggerMaintenance", OperationID: "SetTriggerMaintenance", Summary: "Set maintenance of trigger", Request: dto.TriggerMaintenance{}},
+	{
+		Method: http.MethodGet, Path: "/api/trigger/{triggerId}/maintenance/windows", OperationID: "GetTriggerMaintenanceSchedule", Summary: "Get maintenance windows of trigger",
+		Query:    []Param{paramFrom, paramTo},
+		Response: dto.MaintenanceSchedule{},
+	},
+	{Method: http.MethodPost, Path: "/api/trigger/{triggerId}/maintenance/windows", OperationID: "AddTriggerMaintenanceWindow", Summary: "Add maintenance window of trigger", Request: dto.MaintenanceWindow{}, Response: dto.MaintenanceWindow{}},
+	{Method: http.MethodDelete, Path: "/api/trigger/{triggerId}/maintenance/windows/{windowID}", OperationID: "RemoveTriggerMaintenanceWindow", Summary: "Remove maintenance window of trigger"},
+	{Method: http.MethodDelete, Path: "/api/trigger/{triggerId}/escalations", OperationID: "AckEscalations", Summary: "Acknowledge escalations of trigger"},
+	{Method: http.MethodPost, Path: "/api/trigger/{triggerId}/ackEscalations", OperationID: "AckMetricEscalations", Summary: "Acknowledge escalations of trigger metrics", Request: dto.AckMetricEscalationsRequest{}},
+	{Method: http.MethodPost, Path: "/api/trigger/{triggerId}/_unacknowledgedMessages", OperationID: "GetUnacknowledgedMessages", Summary: "Get unacknowledged messages of trigger metrics", Request: dto.UnacknowledgedMessagesRequest{}, Response: dto.UnacknowledgedMessages{}},
+
+	// trigger templates
+	{Method: http.MethodGet, Path: "/api/template", OperationID: "GetTriggerTemplates", Summary: "Get trigger templates", Response: dto.TriggerTemplateList{}},
+	{Method: http.MethodPut, Path: "/api/template", OperationID: "CreateTriggerTemplate", Summary: "Create trigger template", Request: dto.TriggerTemplate{}, Response: dto.TriggerTemplate{}},
+	{Method: http.MethodGet, Path: "/api/template/{templateId}", OperationID: "GetTriggerTemplate", Summary: "Get trigger template", Response: dto.TriggerTemplate{}},
+	{Method: http.MethodPut, Path: "/api/template/{templateId}", OperationID: "UpdateTriggerTemplate", Summary: "Update trigger template and render its instances again", Request: dto.TriggerTemplate{}, Response: dto.TemplateDiffList{}},
+	{Method: http.MethodDelete, Path: "/api/template/{templateId}", OperationID: "RemoveTriggerTemplate", Summary: "Remove trigger template without instances"},
+	{Method: http.MethodPost, Path: "/api/template/{templateId}/preview", OperationID: "PreviewTriggerTemplate", Summary: "Get changes of instances which the new version of template would make", Request: dto.TriggerTemplate{}, Response: dto.TemplateDiffList{}},
+	{Method: http.MethodGet, Path: "/api/template/{templateId}/instance", OperationID: "GetTemplateInstances", Summary: "Get triggers instantiated from template", Response: dto.TemplateInstanceList{}},
+	{Method: http.MethodPut, Path: "/api/template/{templateId}/instance", OperationID: "SaveTemplateInstance", Summary: "Create trigger from template or change parameters of instance", Request: dto.TemplateInstance{}, Response: dto.SaveTriggerResponse{}},
+	{Method: http.MethodDelete, Path: "/api/template/{templateId}/instance/{triggerId}", OperationID: "DetachTemplateInstance", Summary: "Detach trigger from template"},
+
+	// tags and patterns
+	{Method: http.MethodGet, Path: "/api/tag", OperationID: "GetAllTags", Summary: "Get all tags", Response: dto.TagsData{}},
+	{Method: http.MethodGet, Path: "/api/tag/stats", OperationID: "GetTagsStatistics", Summary: "Get tags with their triggers and subscriptions", Response: dto.TagsStatistics{}},
+	{Method: http.MethodDelete, Path: "/api/tag/{tag}", OperationID: "RemoveTag", Summary: "Remove tag", Response: dto.MessageResponse{}},
+	{Method: http.MethodGet, Path: "/api/pattern", OperationID: "GetAllPatterns", Summary: "Get all patterns", Response: dto.PatternList{}},
+	{Method: http.MethodDelete, Path: "/api/pattern/{pattern}", OperationID: "DeletePattern", Summary: "Remove pattern"},
+
+	// events and notifications
+	{Method: http.MethodGet, Path: "/api/event/{triggerId}", OperationID: "GetTriggerEvents", Summary: "Get events of trigger", Query: []Param{paramPage, paramSize}, Response: dto.EventsList{}},
+	{
+		Method: http.MethodGet, Path: "/api/stream/events", OperationID: "StreamEvents", Summary: "Stream events of triggers as they come",
+		Query: []Param{
+			paramTags,
+			{Name: "triggers", Type: ParamList, Description: "IDs of triggers, passed as triggers[0], triggers[1], ..."},
+			{Name: "states", Type: ParamList, Description: "States of events, passed as states[0], states[1], ..."},
+			{Name: "lastEventId", Type: ParamString, Description: "ID of the last received event to resume the stream, Last-Event-ID header has precedence"},
+		},
+		Response: moira.NotificationEvent{},
+		Stream:   true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/notification", OperationID: "GetNotifications", Summary: "Get scheduled notifications",
+		Query: []Param{
+			{Name: "start", Type: ParamInt, Description: "Index of the first notification", Required: true},
+			{Name: "end", Type: ParamInt, Description: "Index of the last notification", Required: true},
+		},
+		Response: dto.NotificationsList{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/notification", OperationID: "DeleteNotification", Summary: "Remove scheduled notification",
+		Query:    []Param{{Name: "id", Type: ParamString, Description: "Notification key", Required: true}},
+		Response: dto.NotificationDeleteResponse{},
+	},
+
+	// contacts and subscriptions
+	{Method: http.MethodGet, Path: "/api/contact", OperationID: "GetAllContacts", Summary: "Get all contacts", Response: dto.ContactList{}},
+	{Method: http.MethodPut, Path: "/api/contact", OperationID: "CreateContact", Summary: "Create contact of current user", Request: dto.Contact{}, Response: dto.Contact{}},
+	{Method: http.MethodPut, Path: "/api/contact/{contactId}", OperationID: "UpdateContact", Summary: "Update contact", Request: dto.Contact{}, Response: dto.Contact{}},
+	{Method: http.MethodDelete, Path: "/api/contact/{contactId}", OperationID: "RemoveContact", Summary: "Remove contact"},
+	{Method: http.MethodPost, Path: "/api/contact/{contactId}/test", OperationID: "SendTestContactNotification", Summary: "Send test notification to contact"},
+	{Method: http.MethodGet, Path: "/api/subscription", OperationID: "GetUserSubscriptions", Summary: "Get subscriptions of current user", Response: dto.SubscriptionList{}},
+	{
+		Method: http.MethodGet, Path: "/api/subscription/search", OperationID: "SearchSubscriptions", Summary: "Find subscriptions by contact value",
+		Query:    []Param{{Name: "contact", Type: ParamString, Description: "Contact value", Required: true}},
+		Response: dto.SubscriptionFilteredList{},
+	},
+	{Method: http.MethodPut, Path: "/api/subscription", OperationID: "CreateSubscription", Summary: "Create subscription of current user", Request: dto.Subscription{}, Response: dto.Subscription{}},
+	{Method: http.MethodPut, Path: "/api/subscription/{subscriptionId}", OperationID: "UpdateSubscription", Summary: "Update subscription", Request: dto.Subscription{}, Response: dto.Subscription{}},
+	{Method: http.MethodDelete, Path: "/api/subscription/{subscriptionId}", OperationID: "RemoveSubscription", Summary: "Remove subscription"},
+	{Method: http.MethodPut, Path: "/api/subscription/{subscriptionId}/test", OperationID: "SendTestNotification", Summary: "Send test notification to subscription"},
+	{Method: http.MethodGet, Path: "/api/rotation", OperationID: "GetRotations", Summary: "Get on call rotations", Response: dto.RotationList{}},
+	{Method: http.MethodPut, Path: "/api/rotation", OperationID: "SaveRotation", Summary: "Create or replace on call rotation", Request: dto.Rotation{}, Response: dto.Rotation{}},
+	{Method: http.MethodGet, Path: "/api/rotation/{rotationId}", OperationID: "GetRotation", Summary: "Get on call rotation", Response: dto.Rotation{}},
+	{Method: http.MethodDelete, Path: "/api/rotation/{rotationId}", OperationID: "RemoveRotation", Summary: "Remove on call rotation which is not targeted by subscriptions"},
+	{
+		Method: http.MethodGet, Path: "/api/rotation/{rotationId}/on-call", OperationID: "GetRotationOnCall", Summary: "Get who is on call by the rotation",
+		Query:    []Param{{Name: "ts", Type: ParamInt, Description: "Unix timestamp, now by default"}},
+		Response: dto.RotationOnCall{},
+	},
+
+	// silent patterns and maintenance
+	{
+		Method: http.MethodGet, Path: "/api/silent-pattern", OperationID: "GetSilentPatterns", Summary: "Get silent patterns",
+		Query:    []Param{{Name: "type", GoName: "patternType", Type: ParamInt, Description: "Type of silent patterns: 0 - metric, 1 - tag, 2 - matcher"}},
+		Response: dto.SilentPatternList{},
+	},
+	{Method: http.MethodPut, Path: "/api/silent-pattern", OperationID: "CreateSilentPatterns", Summary: "Create silent patterns", Request: dto.NewSilentPatternList{}},
+	{Method: http.MethodPost, Path: "/api/silent-pattern", OperationID: "UpdateSilentPatterns", Summary: "Update silent patterns", Request: dto.NewSilentPatternList{}},
+	{Method: http.MethodDelete, Path: "/api/silent-pattern", OperationID: "RemoveSilentPatterns", Summary: "Remove silent patterns", Request: dto.SilentPatternList{}},
+	{Method: http.MethodPost, Path: "/api/silent-pattern/preview", OperationID: "PreviewSilentPatterns", Summary: "Get triggers and metrics which would be silenced", Request: dto.NewSilentPatternList{}, Response: dto.SilentPreview{}},
+	{Method: http.MethodGet, Path: "/api/maintenance/metrics", OperationID: "GetMaintenanceMetrics", Summary: "Get maintenance of metrics", Response: dto.Maintenance{}},
+	{Method: http.MethodGet, Path: "/api/maintenance/tags", OperationID: "GetMaintenanceTags", Summary: "Get maintenance of tags", Response: dto.Maintenance{}},
+	{Method: http.MethodGet, Path: "/api/maintenance/upcoming", OperationID: "GetMaintenanceUpcoming", Summary: "Get upcoming silent patterns", Query: []Param{paramFrom, paramTo}, Response: dto.SilentUpcoming{}},
+	{Method: http.MethodGet, Path: "/api/maintenance/trigger/{id}", OperationID: "GetMaintenanceTrigger", Summary: "Get maintenance of trigger", Response: dto.Maintenance{}},
+
+	// administration
+	{Method: http.MethodGet, Path: "/api/global-settings", OperationID: "GetGlobalSettings", Summary: "Get global settings", Response: dto.GlobalSettings{}},
+	{Method: http.MethodPut, Path: "/api/global-settings", OperationID: "SetGlobalSettings", Summary: "Set global settings", Request: dto.GlobalSettings{}},
+	{Method: http.MethodGet, Path: "/api/team", OperationID: "GetTeams", Summary: "Get teams", Response: dto.TeamList{}},
+	{Method: http.MethodPut, Path: "/api/team", OperationID: "SaveTeam", Summary: "Create or replace team", Request: dto.Team{}, Response: dto.Team{}},
+	{Method: http.MethodDelete, Path: "/api/team/{teamId}", OperationID: "RemoveTeam", Summary: "Remove team"},
+	{Method: http.MethodGet, Path: "/api/token", OperationID: "GetAPITokens", Summary: "Get API tokens", Response: dto.APITokenList{}},
+	{Method: http.MethodPut, Path: "/api/token", OperationID: "CreateAPIToken", Summary: "Create API token, the token is returned only once", Request: dto.APIToken{}, Response: dto.APIToken{}},
+	{Method: http.MethodDelete, Path: "/api/token/{tokenId}", OperationID: "RemoveAPIToken", Summary: "Revoke API token"},
+
+	// statistics and inventory
+	{
+		Method: http.MethodGet, Path: "/api/stats/metrics", OperationID: "GetMetricStats", Summary: "Get statistics of trigger metrics",
+		Query: []Param{
+			{Name: "intervalLength", Type: ParamInt, Description: "Length of the interval in seconds", Required: true},
+			paramOnlyProblems, paramTags,
+		},
+		Response: dto.MetricStats{},
+	},
+	{Method: http.MethodGet, Path: "/api/inventory/hosts/{name}", OperationID: "GetInventoryHost", Summary: "Get host from inventory", Response: dto.InventoryHost{}},
+
+	// Slack
+	{Method: http.MethodPost, Path: "/api/slack/actions", OperationID: "HandleSlackActions", Summary: "Handle interactive actions of Slack messages", External: true},
+	{Method: http.MethodPost, Path: "/api/slack/commands", OperationID: "HandleSlackCommand", Summary: "Handle Slack slash command", External: true},
+}
+
+// errorResponse is the type of all error responses
+var errorResponse = api.ErrorResponse{}
+
+// enums are the values of the named types which are listed in the spec
+var enums = map[interface{}][]interface{}{
+	moira.Role(""):          {moira.RoleViewer, moira.RoleEditor, moira.RoleAdmin},
+	moira.APITokenScope(""): {moira.APITokenScopeRead, moira.APITokenScopeWriteTriggers, moira.APITokenScopeWriteSilences},
+}
@@ -0,0 +1,313 @@
+// Package openapi describes Moira API: OpenAPI 3 specification and Go client are generated from Routes
+package openapi
+
+//go:generate go run ./generate
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	specVersion = "3.0.3"
+	apiVersion  = "1.0"
+)
+
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+type object = map[string]interface{}
+
+// Generate returns the specification in JSON
+func Generate() ([]byte, error) {
+	builder := &specBuilder{
+		schemas: make(object),
+		enums:   make(map[reflect.Type][]interface{}, len(enums)),
+	}
+	for value, values := range enums {
+		builder.enums[reflect.TypeOf(value)] = values
+	}
+
+	paths := make(object)
+	for _, route := range Routes {
+		path, ok := paths[route.Path].(object)
+		if !ok {
+			path = make(object)
+			paths[route.Path] = path
+		}
+		path[strings.ToLower(route.Method)] = builder.operation(route)
+	}
+
+	spec := object{
+		"openapi": specVersion,
+		"info": object{
+			"title":   "Moira API",
+			"version": apiVersion,
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": builder.schemas,
+			"securitySchemes": object{
+				"proxyUser": object{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "x-webauth-user",
+					"description": "Login of the user set by authenticating proxy",
+				},
+				"apiToken": object{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "API token, see /api/token",
+				},
+			},
+		},
+		"security": []object{{"proxyUser": []string{}}, {"apiToken": []string{}}},
+	}
+
+	result, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(result, '\n'), nil
+}
+
+// GetPathParams returns the names of path parameters of the route
+func GetPathParams(path string) []string {
+	matches := pathParamRegex.FindAllStringSubmatch(path, -1)
+	result := make([]string, 0, len(matches))
+	for _, match := range matches {
+		result = append(result, match[1])
+	}
+	return result
+}
+
+type specBuilder struct {
+	schemas object
+	enums   map[reflect.Type][]interface{}
+}
+
+func (builder *specBuilder) operation(route Route) object {
+	parameters := make([]object, 0)
+	for _, name := range GetPathParams(route.Path) {
+		parameters = append(parameters, object{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   object{"type": "string"},
+		})
+	}
+	for _, param := range route.Query {
+		parameter := object{
+			"name":     param.Name,
+			"in":       "query",
+			"required": param.Required,
+			"schema":   paramSchema(param.Type),
+		}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	okResponse := object{"description": "OK"}
+	if route.Response != nil {
+		contentType := "application/json"
+		if route.Stream {
+			contentType = "text/event-stream"
+		}
+		okResponse["content"] = object{
+			contentType: object{"schema": builder.schema(reflect.TypeOf(route.Response))},
+		}
+	}
+
+	result := object{
+		"operationId": lowerFirst(route.OperationID),
+		"summary":     route.Summary,
+		"tags":        []string{getTag(route.Path)},
+		"responses": object{
+			"200": okResponse,
+			"default": object{
+				"description": "Error",
+				"content": object{
+					"application/json": object{"schema": builder.schema(reflect.TypeOf(errorResponse))},
+				},
+			},
+		},
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+
+	switch {
+	case route.Request != nil:
+		result["requestBody"] = object{
+			"required": true,
+			"content": object{
+				"application/json": object{"schema": builder.schema(reflect.TypeOf(route.Request))},
+			},
+		}
+	case route.External && route.Method == http.MethodPost:
+		result["requestBody"] = object{
+			"content": object{
+				"application/x-www-form-urlencoded": object{"schema": object{"type": "object"}},
+			},
+		}
+	}
+	return result
+}
+
+// schema returns the schema of Go type, named structs are put to components
+func (builder *specBuilder) schema(valueType reflect.Type) object {
+	if values, ok := builder.enums[valueType]; ok {
+		result := builder.schema(basicType(valueType.Kind()))
+		result["enum"] = values
+		return result
+	}
+	if valueType == reflect.TypeOf(time.Time{}) {
+		return object{"type": "string", "format": "date-time"}
+	}
+
+	switch valueType.Kind() {
+	case reflect.Ptr:
+		result := builder.schema(valueType.Elem())
+		if _, isRef := result["$ref"]; isRef {
+			return object{"allOf": []object{result}, "nullable": true}
+		}
+		result["nullable"] = true
+		return result
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		format := "int64"
+		if size := valueType.Bits(); size <= 32 {
+			format = "int32"
+		}
+		return object{"type": "integer", "format": format}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number", "format": "double"}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if valueType.Elem().Kind() == reflect.Uint8 {
+			return object{"type": "string", "format": "byte"}
+		}
+		return object{"type": "array", "items": builder.schema(valueType.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": builder.schema(valueType.Elem())}
+	case reflect.Struct:
+		return builder.structSchema(valueType)
+	default:
+		// interface{} means any value
+		return object{}
+	}
+}
+
+func (builder *specBuilder) structSchema(valueType reflect.Type) object {
+	name := getSchemaName(valueType)
+	ref := object{"$ref": "#/components/schemas/" + name}
+	if name == "" {
+		return builder.structProperties(valueType)
+	}
+	if _, ok := builder.schemas[name]; !ok {
+		// the placeholder lets the types refer to themselves
+		builder.schemas[name] = object{}
+		builder.schemas[name] = builder.structProperties(valueType)
+	}
+	return ref
+}
+
+func (builder *specBuilder) structProperties(valueType reflect.Type) object {
+	properties := make(object)
+	builder.addProperties(valueType, properties)
+	return object{"type": "object", "properties": properties}
+}
+
+// addProperties adds the fields of the struct to the properties, embedded structs are flattened as encoding/json does
+func (builder *specBuilder) addProperties(valueType reflect.Type, properties object) {
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				builder.addProperties(embedded, properties)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported field
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = builder.schema(field.Type)
+	}
+}
+
+// getSchemaName returns the name of the component, e.g. dto.Trigger
+func getSchemaName(valueType reflect.Type) string {
+	if valueType.Name() == "" {
+		return ""
+	}
+	pkgPath := strings.Split(valueType.PkgPath(), "/")
+	return pkgPath[len(pkgPath)-1] + "." + valueType.Name()
+}
+
+func basicType(kind reflect.Kind) reflect.Type {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	default:
+		return reflect.TypeOf("")
+	}
+}
+
+func paramSchema(paramType ParamType) object {
+	switch paramType {
+	case ParamInt:
+		return object{"type": "integer", "format": "int64"}
+	case ParamBool:
+		return object{"type": "boolean"}
+	case ParamList:
+		return object{"type": "array", "items": object{"type": "string"}}
+	default:
+		return object{"type": "string"}
+	}
+}
+
+// getTag groups the operations by the first part of the path, e.g. /api/trigger/{triggerId} -> trigger
+func getTag(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	return strings.TrimSuffix(parts[0], ".json")
+}
+
+func lowerFirst(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToLower(value[:1]) + value[1:]
+}
+
+// sortedKeys is used to generate stable output
+func sortedKeys(values map[string]bool) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// the generated files must be updated by go generate whenever routes or dto change
+func TestGeneratedFiles(t *testing.T) {
+	Convey("Specification is up to date", t, func() {
+		expected, err := Generate()
+		So(err, ShouldBeNil)
+		actual, err := ioutil.ReadFile("openapi.json")
+		So(err, ShouldBeNil)
+		So(string(actual), ShouldEqual, string(expected))
+	})
+
+	Convey("Client is up to date", t, func() {
+		expected, err := GenerateClient()
+		So(err, ShouldBeNil)
+		actual, err := ioutil.ReadFile("../client/client_gen.go")
+		So(err, ShouldBeNil)
+		So(string(actual), ShouldEqual, string(expected))
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	spec, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(spec, &document); err != nil {
+		t.Fatal(err)
+	}
+	paths := document["paths"].(map[string]interface{})
+	schemas := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	Convey("Every route is described", t, func() {
+		for _, route := range Routes {
+			path, ok := paths[route.Path].(map[string]interface{})
+			So(ok, ShouldBeTrue)
+			So(path, ShouldContainKey, strings.ToLower(route.Method))
+		}
+	})
+
+	Convey("Path parameters", t, func() {
+		operation := paths["/api/trigger/{triggerId}/maintenance/windows/{windowID}"].(map[string]interface{})["delete"].(map[string]interface{})
+		So(operation["parameters"], ShouldHaveLength, 2)
+		So(GetPathParams("/api/trigger/{triggerId}/maintenance/windows/{windowID}"), ShouldResemble, []string{"triggerId", "windowID"})
+	})
+
+	Convey("Schemas follow json tags of dto", t, func() {
+		trigger := schemas["dto.Trigger"].(map[string]interface{})["properties"].(map[string]interface{})
+		So(trigger, ShouldContainKey, "name")       // embedded TriggerModel
+		So(trigger, ShouldContainKey, "throttling") // own field
+		So(trigger["_parent_triggers"], ShouldResemble, map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"allOf":    []interface{}{map[string]interface{}{"$ref": "#/components/schemas/dto.Trigger"}},
+				"nullable": true,
+			},
+		})
+
+		token := schemas["dto.APIToken"].(map[string]interface{})["properties"].(map[string]interface{})
+		So(token["scopes"].(map[string]interface{})["items"].(map[string]interface{})["enum"], ShouldHaveLength, 3)
+	})
+}
@@ -0,0 +1,209 @@
+// Package stream delivers notification events to the clients of api as soon as the checker emits them
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/tomb.v2"
+
+	"go.avito.ru/DO/moira"
+)
+
+// subscriberBuffer is the number of events which can wait for the slow client,
+// the client is disconnected if it falls further behind (it can resume the stream later)
+const subscriberBuffer = 100
+
+// Filter selects the events of interest, empty conditions are not checked
+type Filter struct {
+	Tags       []string // trigger must have all of them
+	TriggerIDs []string // event must belong to any of them
+	States     []string // event must have any of them
+}
+
+// Validate checks the states of the filter
+func (filter *Filter) Validate() error {
+	for _, state := range filter.States {
+		if !moira.IsEventState(state) {
+			return fmt.Errorf("unknown state '%s'", state)
+		}
+	}
+	return nil
+}
+
+// NeedsTags can tell whether or not the tags of the trigger are required to match the event
+func (filter *Filter) NeedsTags() bool {
+	return len(filter.Tags) > 0
+}
+
+// IsMatched can tell whether or not the event of the trigger with given tags passes the filter
+func (filter *Filter) IsMatched(event *moira.NotificationEvent, tags []string) bool {
+	if len(filter.TriggerIDs) > 0 && !containsString(filter.TriggerIDs, event.TriggerID) {
+		return false
+	}
+	if len(filter.States) > 0 && !containsString(filter.States, event.State) {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !containsString(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetEventID returns the ID of the event in the stream, the client gives it back to resume the stream
+func GetEventID(event *moira.NotificationEvent) string {
+	return fmt.Sprintf("%d:%s", event.Timestamp, event.ID)
+}
+
+// ParseEventID returns the timestamp and the ID of the event given by GetEventID
+func ParseEventID(streamID string) (int64, string, error) {
+	parts := strings.SplitN(streamID, ":", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("invalid event id '%s'", streamID)
+	}
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid event id '%s'", streamID)
+	}
+	return timestamp, parts[1], nil
+}
+
+// Subscriber receives the events which pass its filter
+type Subscriber struct {
+	filter *Filter
+	events chan *moira.NotificationEvent
+}
+
+// Events returns the channel of the events, it is closed when the subscriber can't keep up with the events
+// or the subscription to the database is over
+func (subscriber *Subscriber) Events() <-chan *moira.NotificationEvent {
+	return subscriber.events
+}
+
+// Hub shares the only subscription to the events of the checker between all the clients of api
+type Hub struct {
+	database moira.Database
+	logger   moira.Logger
+	tomb     tomb.Tomb
+
+	mutex       sync.Mutex
+	running     bool
+	subscribers map[*Subscriber]bool
+}
+
+// NewHub creates Hub, it subscribes to the database as soon as the first client comes
+func NewHub(database moira.Database, logger moira.Logger) *Hub {
+	return &Hub{
+		database:    database,
+		logger:      logger,
+		subscribers: make(map[*Subscriber]bool),
+	}
+}
+
+// Subscribe adds the subscriber with given filter
+func (hub *Hub) Subscribe(filter *Filter) (*Subscriber, error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if !hub.running {
+		events, err := hub.database.SubscribeNotificationEvents(&hub.tomb)
+		if err != nil {
+			return nil, err
+		}
+		hub.running = true
+		go hub.broadcast(events)
+	}
+
+	subscriber := &Subscriber{
+		filter: filter,
+		events: make(chan *moira.NotificationEvent, subscriberBuffer),
+	}
+	hub.subscribers[subscriber] = true
+	return subscriber, nil
+}
+
+// Unsubscribe removes the subscriber, it is safe to call it for the subscriber which has been disconnected
+func (hub *Hub) Unsubscribe(subscriber *Subscriber) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.remove(subscriber)
+}
+
+func (hub *Hub) broadcast(events <-chan *moira.NotificationEvent) {
+	for event := range events {
+		hub.publish(event)
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.running = false
+	for subscriber := range hub.subscribers {
+		hub.remove(subscriber)
+	}
+}
+
+// publish loads the tags of the trigger before taking the lock, so the database doesn't hold up the subscribers,
+// the subscriber which comes meanwhile may miss the event if it filters by the tags
+func (hub *Hub) publish(event *moira.NotificationEvent) {
+	var tags []string
+	if hub.needsTags() {
+		tags = hub.getTriggerTags(event.TriggerID)
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for subscriber := range hub.subscribers {
+		if !subscriber.filter.IsMatched(event, tags) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			hub.logger.WarnF("Subscriber of events stream falls behind, disconnecting")
+			hub.remove(subscriber)
+		}
+	}
+}
+
+func (hub *Hub) needsTags() bool {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for subscriber := range hub.subscribers {
+		if subscriber.filter.NeedsTags() {
+			return true
+		}
+	}
+	return false
+}
+
+func (hub *Hub) getTriggerTags(triggerID string) []string {
+	trigger, err := hub.database.GetTrigger(triggerID)
+	if err != nil {
+		hub.logger.ErrorF("Failed to get trigger %s to filter events stream: %v", triggerID, err)
+		return nil
+	}
+	return trigger.Tags
+}
+
+// remove must be called under the lock
+func (hub *Hub) remove(subscriber *Subscriber) {
+	if hub.subscribers[subscriber] {
+		delete(hub.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
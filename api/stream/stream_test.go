@@ -0,0 +1,123 @@
+package stream
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestFilter(t *testing.T) {
+	event := &moira.NotificationEvent{TriggerID: "trigger", State: moira.ERROR}
+
+	Convey("Empty filter matches everything", t, func() {
+		So((&Filter{}).IsMatched(event, nil), ShouldBeTrue)
+	})
+
+	Convey("All conditions must hold", t, func() {
+		filter := &Filter{Tags: []string{"db", "prod"}, TriggerIDs: []string{"other", "trigger"}, States: []string{moira.WARN, moira.ERROR}}
+		So(filter.IsMatched(event, []string{"db", "prod", "mysql"}), ShouldBeTrue)
+		So(filter.IsMatched(event, []string{"db"}), ShouldBeFalse)
+		So(filter.IsMatched(&moira.NotificationEvent{TriggerID: "trigger", State: moira.OK}, []string{"db", "prod"}), ShouldBeFalse)
+		So(filter.IsMatched(&moira.NotificationEvent{TriggerID: "unknown", State: moira.ERROR}, []string{"db", "prod"}), ShouldBeFalse)
+	})
+
+	Convey("States are validated", t, func() {
+		So((&Filter{States: []string{moira.OK, moira.NODATA}}).Validate(), ShouldBeNil)
+		So((&Filter{States: []string{"BROKEN"}}).Validate(), ShouldResemble, fmt.Errorf("unknown state 'BROKEN'"))
+	})
+}
+
+func TestEventID(t *testing.T) {
+	Convey("ID of event is parsed back", t, func() {
+		id := GetEventID(&moira.NotificationEvent{ID: "trigger:1500000000:metric", Timestamp: 1500000000})
+		So(id, ShouldEqual, "1500000000:trigger:1500000000:metric")
+
+		timestamp, eventID, err := ParseEventID(id)
+		So(err, ShouldBeNil)
+		So(timestamp, ShouldEqual, 1500000000)
+		So(eventID, ShouldEqual, "trigger:1500000000:metric")
+	})
+
+	Convey("Invalid IDs", t, func() {
+		for _, id := range []string{"", "trigger", "abc:trigger"} {
+			_, _, err := ParseEventID(id)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestHub(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	hub := NewHub(dataBase, test_helpers.GetTestLogger())
+
+	events := make(chan *moira.NotificationEvent)
+	var all *Subscriber
+
+	Convey("Events are delivered to the subscribers", t, func() {
+		dataBase.EXPECT().SubscribeNotificationEvents(gomock.Any()).Return((<-chan *moira.NotificationEvent)(events), nil)
+		// the trigger is loaded without the lock, so the hub can be used meanwhile
+		dataBase.EXPECT().GetTrigger("trigger-1").Do(func(string) {
+			hub.Unsubscribe(&Subscriber{})
+		}).Return(&moira.Trigger{ID: "trigger-1", Tags: []string{"db"}}, nil)
+		dataBase.EXPECT().GetTrigger("trigger-2").Return(&moira.Trigger{ID: "trigger-2", Tags: []string{"web"}}, nil)
+
+		var err error
+		all, err = hub.Subscribe(&Filter{})
+		So(err, ShouldBeNil)
+		db, err := hub.Subscribe(&Filter{Tags: []string{"db"}})
+		So(err, ShouldBeNil)
+
+		first := &moira.NotificationEvent{TriggerID: "trigger-1"}
+		second := &moira.NotificationEvent{TriggerID: "trigger-2"}
+		events <- first
+		events <- second
+
+		So(<-all.Events(), ShouldEqual, first)
+		So(<-all.Events(), ShouldEqual, second)
+		So(<-db.Events(), ShouldEqual, first)
+
+		hub.Unsubscribe(db)
+		hub.Unsubscribe(db)
+		_, ok := <-db.Events()
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Slow subscriber is disconnected", t, func() {
+		slow, err := hub.Subscribe(&Filter{})
+		So(err, ShouldBeNil)
+		// the last event waits for the previous one to be published
+		for i := 0; i <= subscriberBuffer+1; i++ {
+			events <- &moira.NotificationEvent{TriggerID: "trigger-1"}
+		}
+		received := 0
+		for range slow.Events() {
+			received++
+		}
+		So(received, ShouldEqual, subscriberBuffer)
+	})
+
+	Convey("Subscribers are disconnected when the subscription is over", t, func() {
+		close(events)
+		_, ok := <-all.Events()
+		for ok {
+			_, ok = <-all.Events()
+		}
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Subscription fails", t, func() {
+		hub := NewHub(dataBase, test_helpers.GetTestLogger())
+		dataBase.EXPECT().SubscribeNotificationEvents(gomock.Any()).Return(nil, fmt.Errorf("oops"))
+		subscriber, err := hub.Subscribe(&Filter{})
+		So(subscriber, ShouldBeNil)
+		So(err, ShouldResemble, fmt.Errorf("oops"))
+	})
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// apiTokenPrefix makes the tokens easy to recognize, e.g. by secret scanners
+const apiTokenPrefix = "moira_"
+
+// GenerateAPIToken returns new random API token and its hash which is to be stored
+func GenerateAPIToken() (token, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token = apiTokenPrefix + hex.EncodeToString(secret)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns hash of the token, tokens themselves are never stored
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,366 @@
+package chatops
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+)
+
+// Command is the prefix of chat commands, e.g. "/moira status db"
+const Command = "/moira"
+
+const (
+	maxListedTriggers = 20
+	maxListedMetrics  = 10
+	maxListedSilences = 20
+)
+
+const usage = `Usage:
+/moira status <tag> - triggers with the tag which are not OK
+/moira trigger <id> - state of the trigger
+/moira maint <id> <duration> - maintenance of the trigger, e.g. 2h, 1d or off
+/moira silences - active silent patterns`
+
+// Executor answers chat commands using the same controllers as api does
+type Executor struct {
+	database   moira.Database
+	authorizer *api.Authorizer
+	frontURI   string
+}
+
+// NewExecutor creates Executor, front URI is used to link the triggers (optional),
+// permissions are not checked unless authorizer is given
+func NewExecutor(database moira.Database, authorizer *api.Authorizer, frontURI string) *Executor {
+	if authorizer == nil {
+		authorizer = api.NewAuthorizer(api.AuthorizationConfig{}, nil, database)
+	}
+	return &Executor{
+		database:   database,
+		authorizer: authorizer,
+		frontURI:   strings.TrimSuffix(frontURI, "/"),
+	}
+}
+
+// Execute runs the command on behalf of Moira user and returns the answer,
+// the text may either start with Command or not
+func (executor *Executor) Execute(login, text string) string {
+	if err := executor.checkUser(login); err != nil {
+		return err.Error()
+	}
+
+	args := strings.Fields(text)
+	if len(args) > 0 && isCommand(args[0]) {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return usage
+	}
+
+	var (
+		answer string
+		err    error
+	)
+	switch args[0] {
+	case "status":
+		answer, err = executor.status(args[1:])
+	case "trigger":
+		answer, err = executor.trigger(args[1:])
+	case "maint":
+		answer, err = executor.maintenance(login, args[1:])
+	case "silences":
+		answer, err = executor.silences()
+	default:
+		return usage
+	}
+
+	if err != nil {
+		return fmt.Sprintf("Failed: %v", err)
+	}
+	return answer
+}
+
+// IsCommand can tell whether or not the chat message is addressed to Moira
+func IsCommand(text string) bool {
+	args := strings.Fields(text)
+	return len(args) > 0 && isCommand(args[0])
+}
+
+// isCommand also accepts commands addressed to the bot explicitly, e.g. "/moira@moira_bot"
+func isCommand(arg string) bool {
+	return arg == Command || strings.HasPrefix(arg, Command+"@")
+}
+
+// checkUser makes sure that the chat user is mapped to Moira user who has some contacts
+func (executor *Executor) checkUser(login string) error {
+	if login == "" {
+		return fmt.Errorf("You are not known to Moira, please add this messenger to your contacts first")
+	}
+	contactIDs, err := executor.database.GetUserContactIDs(login)
+	if err != nil {
+		return fmt.Errorf("Failed to check user %s: %v", login, err)
+	}
+	if len(contactIDs) == 0 {
+		return fmt.Errorf("User %s is not known to Moira, please add some contacts first", login)
+	}
+	return nil
+}
+
+func (executor *Executor) status(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("tag is required: /moira status <tag>")
+	}
+	tag := args[0]
+
+	page, errorResponse := controller.GetTriggerPage(executor.database, 0, maxListedTriggers, true, []string{tag}, "")
+	if errorResponse != nil {
+		return "", getError(errorResponse)
+	}
+	if len(page.List) == 0 {
+		return fmt.Sprintf("All triggers with tag %s are OK", tag), nil
+	}
+
+	answer := strings.Builder{}
+	answer.WriteString(fmt.Sprintf("%d trigger(s) with tag %s are not OK:", *page.Total, tag))
+	for _, triggerCheck := range page.List {
+		state := ""
+		if triggerCheck.LastCheck != nil {
+			state = triggerCheck.LastCheck.State
+		}
+		answer.WriteString(fmt.Sprintf("\n%s %s %s", state, triggerCheck.Name, executor.triggerLink(triggerCheck.ID)))
+	}
+	if total := int(*page.Total); total > len(page.List) {
+		answer.WriteString(fmt.Sprintf("\n...and %d more", total-len(page.List)))
+	}
+	return answer.String(), nil
+}
+
+func (executor *Executor) trigger(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("trigger ID is required: /moira trigger <id>")
+	}
+	triggerID := args[0]
+
+	trigger, errorResponse := controller.GetTrigger(executor.database, triggerID)
+	if errorResponse != nil {
+		return "", getError(errorResponse)
+	}
+	lastCheck, errorResponse := controller.GetTriggerLastCheck(executor.database, triggerID)
+	if errorResponse != nil {
+		return "", getError(errorResponse)
+	}
+
+	if lastCheck.CheckData == nil {
+		return fmt.Sprintf("%s %s has not been checked yet", trigger.Name, executor.triggerLink(triggerID)), nil
+	}
+
+	answer := strings.Builder{}
+	answer.WriteString(fmt.Sprintf("%s %s %s", lastCheck.State, trigger.Name, executor.triggerLink(triggerID)))
+	if maintained, until := lastCheck.Maintenance.Get(moira.WildcardMetric, time.Now().Unix()); maintained {
+		answer.WriteString(fmt.Sprintf("\nUnder maintenance until %s", formatTime(until)))
+	}
+
+	metrics := make([]string, 0, len(lastCheck.Metrics))
+	for metric, state := range lastCheck.Metrics {
+		if state.State != moira.OK {
+			metrics = append(metrics, metric)
+		}
+	}
+	sort.Strings(metrics)
+	for i, metric := range metrics {
+		if i == maxListedMetrics {
+			answer.WriteString(fmt.Sprintf("\n...and %d more metrics", len(metrics)-i))
+			break
+		}
+		state := lastCheck.Metrics[metric]
+		value := "-"
+		if state.Value != nil {
+			value = strconv.FormatFloat(*state.Value, 'f', -1, 64)
+		}
+		answer.WriteString(fmt.Sprintf("\n%s %s = %s", state.State, metric, value))
+	}
+	return answer.String(), nil
+}
+
+func (executor *Executor) maintenance(login string, args []string) (string, error) {
+	if len(args) != 2 {
+		return "", fmt.Errorf("trigger ID and duration are required: /moira maint <id> <duration>")
+	}
+	triggerID := args[0]
+
+	var until int64
+	if args[1] != "off" {
+		duration, err := parseDuration(args[1])
+		if err != nil {
+			return "", err
+		}
+		until = time.Now().Add(duration).Unix()
+	}
+
+	trigger, errorResponse := controller.GetTrigger(executor.database, triggerID)
+	if errorResponse != nil {
+		return "", getError(errorResponse)
+	}
+	if err := executor.checkTrigger(login, trigger.ToMoiraTrigger()); err != nil {
+		return "", err
+	}
+	if errorResponse := controller.SetTriggerMaintenance(executor.database, triggerID, until); errorResponse != nil {
+		return "", getError(errorResponse)
+	}
+
+	if until == 0 {
+		return fmt.Sprintf("Maintenance of trigger %s is turned off by %s", triggerID, login), nil
+	}
+	return fmt.Sprintf("Trigger %s is under maintenance until %s (set by %s)", triggerID, formatTime(until), login), nil
+}
+
+// checkTrigger makes sure that the user is allowed to change the trigger from the chat:
+// the permissions are checked if authorization is enabled, otherwise the user must be subscribed to the trigger
+func (executor *Executor) checkTrigger(login string, trigger *moira.Trigger) error {
+	if executor.authorizer.IsEnabled() {
+		if errorResponse := executor.authorizer.CheckTrigger(login, trigger); errorResponse != nil {
+			return getError(errorResponse)
+		}
+		return nil
+	}
+	if executor.authorizer.GetRole(login).Includes(moira.RoleAdmin) {
+		return nil
+	}
+	return executor.checkSubscribed(login, trigger.Tags)
+}
+
+// checkSubscribed makes sure that the user is subscribed to the trigger with the tags,
+// only those who get notifications of the trigger are allowed to change it from the chat
+func (executor *Executor) checkSubscribed(login string, triggerTags []string) error {
+	subscriptionIDs, err := executor.database.GetUserSubscriptionIDs(login)
+	if err != nil {
+		return fmt.Errorf("failed to check subscriptions of user %s: %v", login, err)
+	}
+	subscriptions, err := executor.database.GetSubscriptions(subscriptionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check subscriptions of user %s: %v", login, err)
+	}
+
+	tags := make(map[string]bool, len(triggerTags))
+	for _, tag := range triggerTags {
+		tags[tag] = true
+	}
+	for _, subscription := range subscriptions {
+		if subscription == nil || !subscription.Enabled || len(subscription.Tags) == 0 {
+			continue
+		}
+		matched := true
+		for _, tag := range subscription.Tags {
+			if !tags[tag] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("user %s is not subscribed to the trigger", login)
+}
+
+func (executor *Executor) silences() (string, error) {
+	manager := controller.CreateSilentPatternManager(api.Config{})
+	now := time.Now().Unix()
+
+	active := make([]*moira.SilentPatternData, 0)
+	for _, spt := range []moira.SilentPatternType{moira.SPTMetric, moira.SPTTag, moira.SPTMatcher} {
+		patterns, err := manager.GetSilentPatterns(executor.database, spt)
+		if err != nil {
+			return "", err
+		}
+		for _, pattern := range patterns.List {
+			if pattern.Until > now && pattern.Start <= now {
+				active = append(active, pattern)
+			}
+		}
+	}
+	if len(active) == 0 {
+		return "There are no active silent patterns", nil
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Until < active[j].Until
+	})
+
+	answer := strings.Builder{}
+	answer.WriteString(fmt.Sprintf("%d active silent pattern(s):", len(active)))
+	for i, pattern := range active {
+		if i == maxListedSilences {
+			answer.WriteString(fmt.Sprintf("\n...and %d more", len(active)-i))
+			break
+		}
+		answer.WriteString(fmt.Sprintf("\n%s until %s by %s", pattern.Pattern, formatTime(pattern.Until), pattern.Login))
+		if pattern.Comment != "" {
+			answer.WriteString(": " + pattern.Comment)
+		}
+	}
+	return answer.String(), nil
+}
+
+func (executor *Executor) triggerLink(triggerID string) string {
+	if executor.frontURI == "" {
+		return "(" + triggerID + ")"
+	}
+	return fmt.Sprintf("%s/trigger/%s", executor.frontURI, triggerID)
+}
+
+// GetLoginByContact finds Moira user who owns the contact, e.g. telegram "@username"
+func GetLoginByContact(database moira.Database, contactType, value string) (string, error) {
+	contacts, err := database.GetAllContacts()
+	if err != nil {
+		return "", err
+	}
+	for _, contact := range contacts {
+		if contact != nil && contact.Type == contactType && contact.Value == value {
+			return contact.User, nil
+		}
+	}
+	return "", nil
+}
+
+// parseDuration is time.ParseDuration which also understands days and weeks, e.g. "1d" or "2w"
+func parseDuration(value string) (time.Duration, error) {
+	multiplier := time.Duration(0)
+	switch {
+	case strings.HasSuffix(value, "d"):
+		multiplier = 24 * time.Hour
+	case strings.HasSuffix(value, "w"):
+		multiplier = 7 * 24 * time.Hour
+	}
+
+	var (
+		duration time.Duration
+		err      error
+	)
+	if multiplier != 0 {
+		var count int64
+		count, err = strconv.ParseInt(value[:len(value)-1], 10, 64)
+		duration = time.Duration(count) * multiplier
+	} else {
+		duration, err = time.ParseDuration(value)
+	}
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration '%s', use e.g. 30m, 2h or 1d", value)
+	}
+	return duration, nil
+}
+
+func formatTime(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
+
+func getError(errorResponse *api.ErrorResponse) error {
+	if errorResponse.Err != nil {
+		return errorResponse.Err
+	}
+	return fmt.Errorf(errorResponse.ErrorText)
+}
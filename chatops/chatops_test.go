@@ -0,0 +1,152 @@
+package chatops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestExecute(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	executor := NewExecutor(dataBase, nil, "https://moira.example.com/")
+	const login = "user"
+
+	Convey("Unknown users are not allowed to run commands", t, func() {
+		So(executor.Execute("", "/moira silences"), ShouldContainSubstring, "not known to Moira")
+
+		dataBase.EXPECT().GetUserContactIDs("stranger").Return([]string{}, nil)
+		So(executor.Execute("stranger", "/moira silences"), ShouldContainSubstring, "not known to Moira")
+	})
+
+	Convey("Known user", t, func() {
+		dataBase.EXPECT().GetUserContactIDs(login).Return([]string{"contact"}, nil).AnyTimes()
+
+		Convey("Usage", func() {
+			So(executor.Execute(login, "/moira"), ShouldEqual, usage)
+			So(executor.Execute(login, "/moira@moira_bot help"), ShouldEqual, usage)
+		})
+
+		Convey("Status of the tag", func() {
+			dataBase.EXPECT().GetTriggerCheckIDs([]string{"db"}, true).Return([]string{"t1"}, nil)
+			dataBase.EXPECT().GetTriggerChecks([]string{"t1"}).Return([]*moira.TriggerCheck{{
+				Trigger:   moira.Trigger{ID: "t1", Name: "Replication lag"},
+				LastCheck: &moira.CheckData{State: moira.ERROR},
+			}}, nil)
+
+			So(executor.Execute(login, "status db"), ShouldEqual,
+				"1 trigger(s) with tag db are not OK:\nERROR Replication lag https://moira.example.com/trigger/t1")
+		})
+
+		Convey("Maintenance", func() {
+			Convey("Unknown trigger", func() {
+				dataBase.EXPECT().GetTrigger("t1").Return(nil, database.ErrNil)
+				So(executor.Execute(login, "/moira maint t1 2h"), ShouldEqual, "Failed: Trigger not found")
+			})
+
+			Convey("Invalid duration", func() {
+				So(executor.Execute(login, "/moira maint t1 forever"), ShouldStartWith, "Failed: invalid duration")
+			})
+
+			Convey("Forbidden", func() {
+				executor := NewExecutor(dataBase, api.NewAuthorizer(api.AuthorizationConfig{Enabled: true}, nil, dataBase), "")
+				dataBase.EXPECT().GetTrigger("t1").Return(&moira.Trigger{ID: "t1"}, nil)
+				dataBase.EXPECT().GetTriggerThrottling("t1").Return(time.Time{}, time.Time{})
+				dataBase.EXPECT().TriggerHasPendingEscalations("t1", false).Return(false, nil)
+				dataBase.EXPECT().GetTriggers(gomock.Any()).Return(nil, nil)
+				dataBase.EXPECT().GetTeams().Return(nil, nil)
+
+				So(executor.Execute(login, "/moira maint t1 1d"), ShouldEqual, "Failed: User \"user\" has role viewer, but editor is required")
+			})
+
+			Convey("Not subscribed while authorization is disabled", func() {
+				dataBase.EXPECT().GetTrigger("t1").Return(&moira.Trigger{ID: "t1", Tags: []string{"db", "prod"}}, nil)
+				dataBase.EXPECT().GetTriggerThrottling("t1").Return(time.Time{}, time.Time{})
+				dataBase.EXPECT().TriggerHasPendingEscalations("t1", false).Return(false, nil)
+				dataBase.EXPECT().GetTriggers(gomock.Any()).Return(nil, nil)
+				dataBase.EXPECT().GetUserSubscriptionIDs(login).Return([]string{"s1", "s2"}, nil)
+				dataBase.EXPECT().GetSubscriptions([]string{"s1", "s2"}).Return([]*moira.SubscriptionData{
+					{ID: "s1", Enabled: true, Tags: []string{"db", "dev"}},
+					{ID: "s2", Enabled: false, Tags: []string{"db"}},
+				}, nil)
+
+				So(executor.Execute(login, "/moira maint t1 1d"), ShouldEqual, "Failed: user user is not subscribed to the trigger")
+			})
+
+			Convey("Super user is not required to be subscribed", func() {
+				executor := NewExecutor(dataBase, api.NewAuthorizer(api.AuthorizationConfig{}, []string{login}, dataBase), "")
+				dataBase.EXPECT().GetTrigger("t1").Return(&moira.Trigger{ID: "t1"}, nil)
+				dataBase.EXPECT().GetTriggerThrottling("t1").Return(time.Time{}, time.Time{})
+				dataBase.EXPECT().TriggerHasPendingEscalations("t1", false).Return(false, nil)
+				dataBase.EXPECT().GetTriggers(gomock.Any()).Return(nil, nil)
+				dataBase.EXPECT().AcquireTriggerMaintenanceLock("t1").Return(nil)
+				dataBase.EXPECT().DeleteTriggerMaintenanceLock("t1")
+				dataBase.EXPECT().GetMaintenanceTrigger("t1").Return(moira.NewMaintenance(), nil)
+				dataBase.EXPECT().SetMaintenanceTrigger("t1", gomock.Any()).Return(nil)
+
+				So(executor.Execute(login, "/moira maint t1 off"), ShouldEqual, "Maintenance of trigger t1 is turned off by user")
+			})
+
+			Convey("Success", func() {
+				maintenance := moira.NewMaintenance()
+				dataBase.EXPECT().GetTrigger("t1").Return(&moira.Trigger{ID: "t1", Tags: []string{"db", "prod"}}, nil)
+				dataBase.EXPECT().GetUserSubscriptionIDs(login).Return([]string{"s1"}, nil)
+				dataBase.EXPECT().GetSubscriptions([]string{"s1"}).Return([]*moira.SubscriptionData{{ID: "s1", Enabled: true, Tags: []string{"db"}}}, nil)
+				dataBase.EXPECT().GetTriggerThrottling("t1").Return(time.Time{}, time.Time{})
+				dataBase.EXPECT().TriggerHasPendingEscalations("t1", false).Return(false, nil)
+				dataBase.EXPECT().GetTriggers(gomock.Any()).Return(nil, nil)
+				dataBase.EXPECT().AcquireTriggerMaintenanceLock("t1").Return(nil)
+				dataBase.EXPECT().DeleteTriggerMaintenanceLock("t1")
+				dataBase.EXPECT().GetMaintenanceTrigger("t1").Return(maintenance, nil)
+				dataBase.EXPECT().SetMaintenanceTrigger("t1", maintenance).Return(nil)
+
+				So(executor.Execute(login, "/moira maint t1 1d"), ShouldStartWith, "Trigger t1 is under maintenance until")
+				maintained, until := maintenance.Get(moira.WildcardMetric, time.Now().Unix())
+				So(maintained, ShouldBeTrue)
+				So(until, ShouldBeGreaterThan, time.Now().Add(23*time.Hour).Unix())
+			})
+		})
+
+		Convey("Silences", func() {
+			now := time.Now().Unix()
+			dataBase.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return([]*moira.SilentPatternData{
+				{Pattern: "db01", Login: "admin", Until: now + 3600, Comment: "Disk replacement"},
+				{Pattern: "db02", Login: "admin", Until: now - 3600},
+			}, nil)
+			dataBase.EXPECT().GetSilentPatternsTyped(moira.SPTTag).Return([]*moira.SilentPatternData{}, nil)
+			dataBase.EXPECT().GetSilentPatternsTyped(moira.SPTMatcher).Return([]*moira.SilentPatternData{}, nil)
+
+			So(executor.Execute(login, "/moira silences"), ShouldEqual,
+				"1 active silent pattern(s):\ndb01 until "+formatTime(now+3600)+" by admin: Disk replacement")
+		})
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	Convey("Durations", t, func() {
+		for value, expected := range map[string]time.Duration{
+			"30m": 30 * time.Minute,
+			"2h":  2 * time.Hour,
+			"1d":  24 * time.Hour,
+			"2w":  14 * 24 * time.Hour,
+		} {
+			duration, err := parseDuration(value)
+			So(err, ShouldBeNil)
+			So(duration, ShouldEqual, expected)
+		}
+
+		for _, value := range []string{"", "d", "-1h", "0", "xd"} {
+			_, err := parseDuration(value)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
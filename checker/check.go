@@ -87,6 +87,11 @@ func (triggerChecker *TriggerChecker) handleTrigger() (moira.CheckData, error) {
 		Version:           triggerChecker.lastCheck.Version,
 	}
 
+	// SLO triggers are evaluated in their own way
+	if triggerChecker.trigger.IsSLO() {
+		return triggerChecker.handleSLOTrigger(checkData)
+	}
+
 	// getting time series: either from graphite or from DB
 	if triggerChecker.trigger.IsPullType {
 		triggerTimeSeries, err = triggerChecker.getRemoteTimeSeries(triggerChecker.From, triggerChecker.Until)
@@ -100,9 +105,11 @@ func (triggerChecker *TriggerChecker) handleTrigger() (moira.CheckData, error) {
 		}
 	}
 
+	triggerChecker.explainTimeSeries(triggerTimeSeries)
 	hasOnlyWildcards := triggerTimeSeries.hasOnlyWildcards()
 	triggerChecker.traceHandledData(triggerTimeSeries, hasOnlyWildcards)
 	if hasOnlyWildcards {
+		triggerChecker.explain(ExplainWildcards, "", 0, "All the series are wildcards: the patterns never received metrics", nil)
 		return checkData, ErrTriggerHasOnlyWildcards{}
 	}
 
@@ -130,6 +137,7 @@ func (triggerChecker *TriggerChecker) handleTrigger() (moira.CheckData, error) {
 			if deleteMetric {
 				triggerChecker.logger.InfoF("[TriggerID:%s] Remove metric: '%s'", triggerChecker.TriggerID, timeSeries.Name)
 				delete(checkData.Metrics, timeSeries.Name)
+				triggerChecker.notifyMetricRemoved(timeSeries.Name, metricState)
 
 				if !patternMetricsRemoved {
 					err = triggerChecker.Database.RemovePatternsMetrics(triggerChecker.trigger.Patterns)
@@ -179,12 +187,15 @@ func (triggerChecker *TriggerChecker) handleTrigger() (moira.CheckData, error) {
 			},
 		)
 
+		triggerChecker.explainNoData(metricName, noDataState, deleteMetric)
+
 		if isForced {
 			deleteForcedNotifications = append(deleteForcedNotifications, metricName)
 		}
 
 		if deleteMetric {
 			metricsToDelete = append(metricsToDelete, metricName)
+			triggerChecker.notifyMetricRemoved(metricName, metricState)
 			continue
 		}
 
@@ -225,6 +236,9 @@ func (triggerChecker *TriggerChecker) handleTrigger() (moira.CheckData, error) {
 		deleteForcedNotifications = rest
 	}
 
+	if checkingError == nil && triggerChecker.trigger.IsQuorum() {
+		return triggerChecker.handleQuorum(checkData)
+	}
 	return checkData, checkingError
 }
 
@@ -233,13 +247,16 @@ func (triggerChecker *TriggerChecker) handleErrorCheck(checkData moira.CheckData
 	case ErrTriggerHasNoTimeSeries:
 		triggerChecker.logger.DebugF("Trigger %s: %s", triggerChecker.TriggerID, checkingError.Error())
 		checkData.State = triggerChecker.ttlState
+		if triggerChecker.keepState && triggerChecker.lastCheck.State != "" {
+			checkData.State = triggerChecker.lastCheck.State
+		}
 		checkData.Message = checkingError.Error()
 		if triggerChecker.ttl == 0 || triggerChecker.ttlState == moira.DEL {
 			return checkData, nil
 		}
 	case ErrTriggerHasOnlyWildcards:
 		triggerChecker.logger.DebugF("Trigger %s: %s", triggerChecker.TriggerID, checkingError.Error())
-		if len(checkData.Metrics) == 0 && triggerChecker.ttlState != moira.OK && triggerChecker.ttlState != moira.DEL {
+		if len(checkData.Metrics) == 0 && triggerChecker.ttlState != moira.OK && triggerChecker.ttlState != moira.DEL && !triggerChecker.keepState {
 			checkData.State = moira.NODATA
 			checkData.Message = checkingError.Error()
 			if triggerChecker.ttl == 0 || triggerChecker.ttlState == moira.DEL {
@@ -253,6 +270,11 @@ func (triggerChecker *TriggerChecker) handleErrorCheck(checkData moira.CheckData
 	case ErrWrongTriggerTarget, ErrTriggerHasSameTimeSeriesNames:
 		checkData.State = moira.EXCEPTION
 		checkData.Message = checkingError.Error()
+	case ErrRemoteSource:
+		triggerChecker.Statsd.CheckError.Increment()
+		triggerChecker.logger.WarnF("Trigger %s: %s", triggerChecker.TriggerID, checkingError.Error())
+		checkData.State = moira.EXCEPTION
+		checkData.Message = checkingError.Error()
 	default:
 		triggerChecker.Statsd.CheckError.Increment()
 		triggerChecker.logger.ErrorF("Trigger %s check failed: %s", triggerChecker.TriggerID, checkingError.Error())
@@ -269,13 +291,19 @@ func (triggerChecker *TriggerChecker) checkTimeSeries(
 	emptyTimestampValue := int64(timeSeries.StartTime) - moira.MaxI64(triggerChecker.ttl, 3600)
 	lastState = triggerChecker.lastCheck.GetOrCreateMetricState(timeSeries.Name, emptyTimestampValue)
 
-	metricStates, err := triggerChecker.getTimeSeriesStepsStates(triggerTimeSeries, timeSeries, lastState)
-	if err != nil {
-		triggerChecker.logger.ErrorF(
-			"getTimeSeriesStepsStates for trigger_id %s caused an error %v, time_series = %s",
-			triggerChecker.TriggerID, err, timeSeries.Name,
-		)
-		return
+	var metricStates []*moira.MetricState
+	if triggerChecker.trigger.IsForecast() {
+		// forecast trigger projects the whole lookback window at once
+		metricStates = triggerChecker.getForecastStates(timeSeries, lastState)
+	} else {
+		metricStates, err = triggerChecker.getTimeSeriesStepsStates(triggerTimeSeries, timeSeries, lastState)
+		if err != nil {
+			triggerChecker.logger.ErrorF(
+				"getTimeSeriesStepsStates for trigger_id %s caused an error %v, time_series = %s",
+				triggerChecker.TriggerID, err, timeSeries.Name,
+			)
+			return
+		}
 	}
 	triggerChecker.traceMetricStates(metricStates, lastState, timeSeries)
 
@@ -299,6 +327,7 @@ func (triggerChecker *TriggerChecker) checkTimeSeries(
 	}
 
 	noDataState, deleteMetric := triggerChecker.checkForNoData(lastState)
+	triggerChecker.explainNoData(timeSeries.Name, noDataState, deleteMetric)
 	if deleteMetric {
 		return
 	}
@@ -317,6 +346,10 @@ func (triggerChecker *TriggerChecker) checkTimeSeries(
 }
 
 func (triggerChecker *TriggerChecker) checkForNoData(lastState *moira.MetricState) (noDataState *moira.MetricState, deleteMetric bool) {
+	if triggerChecker.removeAfter > 0 && lastState.Timestamp+triggerChecker.removeAfter < triggerChecker.lastCheck.Timestamp {
+		return nil, true
+	}
+
 	if triggerChecker.ttl == 0 {
 		return nil, false
 	}
@@ -329,6 +362,10 @@ func (triggerChecker *TriggerChecker) checkForNoData(lastState *moira.MetricStat
 		return nil, true
 	}
 
+	if triggerChecker.keepState {
+		return nil, false
+	}
+
 	noDataState = &moira.MetricState{
 		State:      triggerChecker.getNullState(),
 		Timestamp:  triggerChecker.CheckStarted,
@@ -339,6 +376,45 @@ func (triggerChecker *TriggerChecker) checkForNoData(lastState *moira.MetricStat
 	return noDataState, false
 }
 
+// notifyMetricRemoved pushes the event which tells that the metric without data is removed
+// and unlinks the events of the child triggers from the metric, the triggers without the nodata policy remove metrics silently.
+// The event of the metric which was OK only removes it from the dashboards: it would change nothing else but look like a recovery
+func (triggerChecker *TriggerChecker) notifyMetricRemoved(metric string, lastState *moira.MetricState) {
+	if !triggerChecker.notifyRemoved {
+		return
+	}
+
+	// the metrics of the quorum trigger are never alerted separately
+	if !triggerChecker.trigger.IsQuorum() && !triggerChecker.isHandleMetricDisabled(triggerChecker.CheckStarted, metric, "handleTrigger:removeMetric") {
+		message := fmt.Sprintf("Metric is removed: no data for %ds", triggerChecker.lastCheck.Timestamp-lastState.Timestamp)
+		event := &moira.NotificationEvent{
+			TriggerID:     triggerChecker.TriggerID,
+			State:         moira.OK,
+			OldState:      lastState.State,
+			OldValue:      lastState.Value,
+			Timestamp:     triggerChecker.CheckStarted,
+			Metric:        metric,
+			Message:       &message,
+			Batch:         triggerChecker.eventsBatch,
+			MetricRemoved: true,
+			DashboardOnly: lastState.State == moira.OK,
+		}
+		err := triggerChecker.Database.PushNotificationEvent(event)
+		triggerChecker.logger.InfoE(fmt.Sprintf("Pushed notification event (metric removed), err = %v", err), event)
+	}
+
+	triggerChildren, err := triggerChecker.Database.GetChildEvents(triggerChecker.TriggerID, metric)
+	if err != nil {
+		triggerChecker.logger.ErrorF("Could not get child events of removed metric %s of trigger %s: %v", metric, triggerChecker.TriggerID, err)
+		return
+	}
+	for childTriggerID, childMetrics := range triggerChildren {
+		if err := triggerChecker.Database.DeleteChildEvents(triggerChecker.TriggerID, metric, childTriggerID, childMetrics); err != nil {
+			triggerChecker.logger.ErrorF("Could not delete child events of removed metric %s of trigger %s: %v", metric, triggerChecker.TriggerID, err)
+		}
+	}
+}
+
 // cleanupMaintenanceMetrics leaves only those of maintenance metrics which haven't expired yet
 func (triggerChecker *TriggerChecker) cleanupMaintenanceMetrics() {
 	maintenanceMetrics := make(map[string]int64, len(triggerChecker.lastCheck.MaintenanceMetric))
@@ -374,6 +450,7 @@ func (triggerChecker *TriggerChecker) getTimeSeriesState(triggerTimeSeries *trig
 	}
 	triggerExpression, noEmptyValues := triggerTimeSeries.getExpressionValues(timeSeries, valueTimestamp)
 	if !noEmptyValues {
+		triggerChecker.explain(ExplainExpression, timeSeries.Name, valueTimestamp, "Some of the targets have no value, the timestamp is skipped", nil)
 		return nil, nil
 	}
 	triggerChecker.logger.DebugF(
@@ -388,6 +465,21 @@ func (triggerChecker *TriggerChecker) getTimeSeriesState(triggerTimeSeries *trig
 	triggerExpression.Expression = triggerChecker.trigger.Expression
 
 	expressionState, err := triggerExpression.Evaluate()
+	if triggerChecker.explanation != nil {
+		message := fmt.Sprintf("State is %s", expressionState)
+		if err != nil {
+			message = fmt.Sprintf("Expression failed: %v", err)
+		}
+		triggerChecker.explain(ExplainExpression, timeSeries.Name, valueTimestamp, message, map[string]interface{}{
+			"main_value":        triggerExpression.MainTargetValue,
+			"additional_values": triggerExpression.AdditionalTargetsValues,
+			"warn_value":        triggerExpression.WarnValue,
+			"error_value":       triggerExpression.ErrorValue,
+			"previous_state":    triggerExpression.PreviousState,
+			"expression":        moira.UseString(triggerExpression.Expression),
+			"state":             expressionState,
+		})
+	}
 	if err != nil {
 		return nil, err
 	}
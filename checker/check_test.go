@@ -370,6 +370,58 @@ func TestCheckForNODATA(t *testing.T) {
 	})
 }
 
+func TestCheckForNODATAPolicy(t *testing.T) {
+	lastState := &moira.MetricState{
+		State:     moira.ERROR,
+		Timestamp: 399,
+	}
+
+	Convey("Keep state", t, func() {
+		triggerChecker := TriggerChecker{
+			ttl:       600,
+			ttlState:  moira.NODATA,
+			keepState: true,
+			lastCheck: &moira.CheckData{Timestamp: 1000},
+		}
+		currentState, deleteMetric := triggerChecker.checkForNoData(lastState)
+		So(deleteMetric, ShouldBeFalse)
+		So(currentState, ShouldBeNil)
+	})
+
+	Convey("Remove after", t, func() {
+		triggerChecker := TriggerChecker{
+			ttlState:    moira.NODATA,
+			removeAfter: 1200,
+			lastCheck:   &moira.CheckData{Timestamp: 1000},
+		}
+		Convey("Not yet", func() {
+			currentState, deleteMetric := triggerChecker.checkForNoData(lastState)
+			So(deleteMetric, ShouldBeFalse)
+			So(currentState, ShouldBeNil)
+		})
+		Convey("Without data for too long", func() {
+			triggerChecker.lastCheck.Timestamp = 1700
+			currentState, deleteMetric := triggerChecker.checkForNoData(lastState)
+			So(deleteMetric, ShouldBeTrue)
+			So(currentState, ShouldBeNil)
+		})
+	})
+
+	Convey("State of the action", t, func() {
+		for action, expected := range map[moira.NoDataAction]string{
+			"":                       moira.NODATA,
+			moira.NoDataActionAlert:  moira.NODATA,
+			moira.NoDataActionOK:     moira.OK,
+			moira.NoDataActionRemove: moira.DEL,
+			moira.NoDataActionKeep:   moira.NODATA,
+		} {
+			state, keep := getNoDataState(action)
+			So(state, ShouldEqual, expected)
+			So(keep, ShouldEqual, action == moira.NoDataActionKeep)
+		}
+	})
+}
+
 func TestCheckErrors(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -759,6 +811,93 @@ func TestHandleTrigger(t *testing.T) {
 			Score:             0,
 		})
 	})
+
+	Convey("No data too long and the nodata policy removes the metric", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+		lastCheck.Timestamp = 3657
+		lastCheck.Metrics[metric] = &moira.MetricState{
+			IsNoData:       true,
+			State:          moira.NODATA,
+			EventTimestamp: lastCheck.Timestamp,
+			Timestamp:      lastCheck.Timestamp,
+		}
+
+		triggerChecker := makeTriggerChecker(dataBase)
+		triggerChecker.From = 4217
+		triggerChecker.Until = 4267
+		triggerChecker.lastCheck.Timestamp = 4267
+		triggerChecker.ttlState = moira.DEL
+		triggerChecker.notifyRemoved = true
+
+		dataBase.EXPECT().GetPatternMetrics(pattern).Return([]string{metric}, nil)
+		dataBase.EXPECT().GetMetricRetention(metric).Return(retention, nil)
+		dataBase.EXPECT().GetMetricsValues([]string{metric}, triggerChecker.From, triggerChecker.Until).Return(dataList, nil)
+		dataBase.EXPECT().RemoveMetricsValues([]string{metric}, triggerChecker.Until-triggerChecker.Config.MetricsTTLSeconds)
+		message := "Metric is removed: no data for 610s"
+		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+			TriggerID:     triggerChecker.TriggerID,
+			State:         moira.OK,
+			OldState:      moira.NODATA,
+			Metric:        metric,
+			Message:       &message,
+			MetricRemoved: true,
+		}).Return(nil)
+		dataBase.EXPECT().GetChildEvents(triggerChecker.TriggerID, metric).Return(map[string][]string{"ChildId": {"child.metric"}}, nil)
+		dataBase.EXPECT().DeleteChildEvents(triggerChecker.TriggerID, metric, "ChildId", []string{"child.metric"}).Return(nil)
+		dataBase.EXPECT().RemovePatternsMetrics(triggerChecker.trigger.Patterns).Return(nil)
+		checkData, err := triggerChecker.handleTrigger()
+		So(err, ShouldBeNil)
+		So(checkData, ShouldResemble, moira.CheckData{
+			MaintenanceMetric: map[string]int64{},
+			Metrics:           make(map[string]*moira.MetricState),
+			Timestamp:         triggerChecker.Until,
+			State:             moira.OK,
+			Score:             0,
+		})
+	})
+
+	Convey("Metric which was OK is removed from the dashboards only", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+		lastCheck.Timestamp = 3657
+		lastCheck.Metrics[metric] = &moira.MetricState{
+			State:          moira.OK,
+			EventTimestamp: lastCheck.Timestamp,
+			Timestamp:      lastCheck.Timestamp,
+		}
+
+		triggerChecker := makeTriggerChecker(dataBase)
+		triggerChecker.From = 4217
+		triggerChecker.Until = 4267
+		triggerChecker.lastCheck.Timestamp = 4267
+		triggerChecker.ttlState = moira.DEL
+		triggerChecker.notifyRemoved = true
+
+		dataBase.EXPECT().GetPatternMetrics(pattern).Return([]string{metric}, nil)
+		dataBase.EXPECT().GetMetricRetention(metric).Return(retention, nil)
+		dataBase.EXPECT().GetMetricsValues([]string{metric}, triggerChecker.From, triggerChecker.Until).Return(dataList, nil)
+		dataBase.EXPECT().RemoveMetricsValues([]string{metric}, triggerChecker.Until-triggerChecker.Config.MetricsTTLSeconds)
+		message := "Metric is removed: no data for 610s"
+		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+			TriggerID:     triggerChecker.TriggerID,
+			State:         moira.OK,
+			OldState:      moira.OK,
+			Metric:        metric,
+			Message:       &message,
+			MetricRemoved: true,
+			DashboardOnly: true,
+		}).Return(nil)
+		dataBase.EXPECT().GetChildEvents(triggerChecker.TriggerID, metric).Return(map[string][]string{}, nil)
+		dataBase.EXPECT().RemovePatternsMetrics(triggerChecker.trigger.Patterns).Return(nil)
+		checkData, err := triggerChecker.handleTrigger()
+		So(err, ShouldBeNil)
+		So(checkData.Metrics, ShouldBeEmpty)
+	})
 }
 
 func TestHandleErrorCheck(t *testing.T) {
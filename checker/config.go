@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"go.avito.ru/DO/moira"
-	"go.avito.ru/DO/moira/netbox"
 	"go.avito.ru/DO/moira/sentry"
 )
 
@@ -16,14 +15,16 @@ type Config struct {
 	CheckInterval               time.Duration
 	PullInterval                time.Duration
 	PullURL                     string
+	RemoteSources               map[string]*RemoteSource
 	MetricsTTLSeconds           int64
 	StopCheckingIntervalSeconds int64
 	MaxParallelChecks           int
 	MaxParallelPullChecks       int
 	MaxParallelTagsChecks       int
+	CheckQueueSize              int // checks of the triggers waiting for the free checkers, the excess is shed
 	LogFile                     string
 	LogLevel                    string
-	Netbox                      *netbox.Config
+	Inventory                   moira.InventoryProvider
 	LimitLogger                 moira.RateLimit
 	LimitMetrics                moira.RateLimit
 	Sentry                      sentry.Config
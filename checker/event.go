@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/silencer"
 )
 
 var (
@@ -53,6 +54,7 @@ func (triggerChecker *TriggerChecker) compareChecks(
 		triggerChecker.trigger.PendingInterval,
 		lastCheck.Suppressed,
 	)
+	triggerChecker.explainDecision(moira.WildcardMetric, currEventTs, currCheck.State, lastCheck.State, needSend, keepPending, message)
 	if !keepPending {
 		currCheck.IsPending = false
 	}
@@ -110,6 +112,10 @@ func (triggerChecker *TriggerChecker) compareChecks(
 	}
 	if event.Message == nil {
 		event.Message = &currCheck.Message
+	} else if triggerChecker.trigger.IsQuorum() && currCheck.Message != "" {
+		// the bad metrics of the quorum trigger are listed after the reason of the event
+		eventMessage := fmt.Sprintf("%s\n%s", *event.Message, currCheck.Message)
+		event.Message = &eventMessage
 	}
 	err := triggerChecker.Database.PushNotificationEvent(event)
 	triggerChecker.logger.InfoE(fmt.Sprintf("Pushed notification event (check), err = %v", err), event)
@@ -152,6 +158,7 @@ func (triggerChecker *TriggerChecker) compareStates(
 		triggerChecker.trigger.PendingInterval,
 		lastState.Suppressed,
 	)
+	triggerChecker.explainDecision(metric, currEventTs, currState.State, lastState.State, needSend, keepPending, message)
 	if !keepPending {
 		currState.IsPending = false
 	}
@@ -168,6 +175,23 @@ func (triggerChecker *TriggerChecker) compareStates(
 		}
 	}
 
+	// flapping metric gets the sole FLAPPING event and then stays silent until it is stable again
+	eventState, eventOldState := currState.State, lastState.State
+	switch triggerChecker.updateFlapping(currState, lastState, keepPending) {
+	case flappingStarted:
+		flappingMessage := triggerChecker.flappingStartedMessage(currState)
+		needSend, eventState, message = true, moira.FLAPPING, &flappingMessage
+		triggerChecker.explain(ExplainDecision, metric, currState.Timestamp, "Metric started flapping, FLAPPING event is sent", nil)
+	case flappingStopped:
+		needSend, eventOldState, message = true, moira.FLAPPING, &flappingStoppedMessage
+		triggerChecker.explain(ExplainDecision, metric, currState.Timestamp, "Metric stopped flapping, the event is sent", nil)
+	default:
+		if currState.IsFlapping {
+			needSend = false
+			triggerChecker.explain(ExplainDecision, metric, currState.Timestamp, "Metric is flapping, no event is sent", nil)
+		}
+	}
+
 	if !needSend {
 		if keepPending {
 			// keepPending means that the state of a metric has changed
@@ -204,13 +228,40 @@ func (triggerChecker *TriggerChecker) compareStates(
 		return currState, nil
 	}
 
+	// the metrics of the quorum trigger are alerted all together by handleQuorum
+	if triggerChecker.trigger.IsQuorum() {
+		triggerChecker.explain(ExplainDecision, metric, currState.Timestamp, "Quorum trigger doesn't send the events of the metrics", nil)
+		currState.EventTimestamp = currEventTs
+		return currState, nil
+	}
+
+	// silent matchers might concern the state of the event, which is known only now
+	if triggerChecker.silencer != nil {
+		subject := triggerChecker.getSilencerSubject(metric, eventState)
+		if triggerChecker.silencer.IsSubjectSilenced(subject, currState.Timestamp) {
+			triggerChecker.explain(ExplainDisabled, metric, currState.Timestamp,
+				fmt.Sprintf("Event %s is silenced by silent matcher, the state is suppressed", eventState), nil)
+			triggerChecker.logger.InfoE(
+				fmt.Sprintf("Event of metric '%s' (trigger %s) is silenced", metric, triggerChecker.TriggerID),
+				subject,
+			)
+			currState.Suppressed = true
+			return currState, nil
+		}
+	}
+
+	// the state itself might carry the explanation (e.g. SLO error budget)
+	if message == nil && currState.Message != "" {
+		message = &currState.Message
+	}
+
 	// pushing the new event and memorizing its timestamp
 	currState.EventTimestamp = currEventTs
 	event := &moira.NotificationEvent{
 		IsForceSent:    needForceSend,
 		TriggerID:      triggerID,
-		State:          currState.State,
-		OldState:       lastState.State,
+		State:          eventState,
+		OldState:       eventOldState,
 		Value:          currState.Value,
 		OldValue:       lastState.Value,
 		Timestamp:      currEventTs,
@@ -223,7 +274,7 @@ func (triggerChecker *TriggerChecker) compareStates(
 	triggerChecker.logger.InfoE(fmt.Sprintf("Pushed notification event (state), err = %v", err), event)
 
 	// if current state has changed to OK then force assign this state to its child triggers
-	if currState.State == moira.OK {
+	if eventState == moira.OK {
 		triggerChildren, err := triggerChecker.Database.GetChildEvents(triggerID, metric)
 		if err != nil {
 			triggerChecker.logger.ErrorE(
@@ -283,6 +334,10 @@ func (triggerChecker *TriggerChecker) isHandleMetricDisabled(ts int64, metric, d
 
 	defer func() {
 		if disabled {
+			triggerChecker.explain(ExplainDisabled, metric, ts, reason, map[string]interface{}{
+				"code":  code,
+				"extra": dataExtra,
+			})
 			triggerChecker.logger.InfoE(
 				fmt.Sprintf("Handling of metric '%s' (trigger %s) is disabled", metric, triggerChecker.TriggerID),
 				map[string]interface{}{
@@ -317,6 +372,12 @@ func (triggerChecker *TriggerChecker) isHandleMetricDisabled(ts int64, metric, d
 			reason = "Handling is disabled due to tags being silent"
 			return true
 		}
+
+		if triggerChecker.silencer.IsSubjectSilenced(triggerChecker.getSilencerSubject(metric, ""), ts) {
+			code = moira.EventMutedSilent
+			reason = fmt.Sprintf("Handling is disabled due to silent matcher of metric %s", metric)
+			return true
+		}
 	}
 
 	// check maintenance for this metric and for the whole trigger as well
@@ -337,6 +398,19 @@ func (triggerChecker *TriggerChecker) isHandleMetricDisabled(ts int64, metric, d
 	return false
 }
 
+// getSilencerSubject describes the metric (or the whole trigger) of this trigger for the silencer
+func (triggerChecker *TriggerChecker) getSilencerSubject(metric, state string) silencer.Subject {
+	if metric == moira.WildcardMetric {
+		metric = ""
+	}
+	return silencer.Subject{
+		TriggerID: triggerChecker.TriggerID,
+		Tags:      triggerChecker.trigger.Tags,
+		Metric:    metric,
+		State:     state,
+	}
+}
+
 // needSendEvent can tell whether event should be sent right now, put on hold (pending) or neither
 // it can't be both
 func needSendEvent(
@@ -0,0 +1,206 @@
+package checker
+
+import (
+	"fmt"
+	"math"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/target"
+)
+
+// Kinds of the steps of the explained check
+const (
+	ExplainSeries     = "series"     // the time series fetched for the target
+	ExplainWildcards  = "wildcards"  // the trigger has got only the series which never received metrics
+	ExplainExpression = "expression" // the state of the metric calculated for the timestamp
+	ExplainNoData     = "nodata"     // the metric has no data for longer than ttl
+	ExplainDecision   = "decision"   // whether the event is sent, put on hold or neither
+	ExplainDisabled   = "disabled"   // the handling is disabled by schedule, silent patterns or maintenance
+	ExplainSkipped    = "skipped"    // the change of the database the check would make
+)
+
+// Explanation tells step by step what the check of the trigger does, it is made by the read-only check
+type Explanation struct {
+	TriggerID string                     `json:"trigger_id"`
+	From      int64                      `json:"from"`
+	Until     int64                      `json:"until"`
+	Steps     []*ExplanationStep         `json:"steps"`
+	Events    []*moira.NotificationEvent `json:"events"`               // the events the check would push
+	CheckData *moira.CheckData           `json:"check_data,omitempty"` // the last check the check would save
+	Error     string                     `json:"error,omitempty"`
+}
+
+// ExplanationStep is the single step of the check
+type ExplanationStep struct {
+	Kind      string                 `json:"kind"`
+	Metric    string                 `json:"metric,omitempty"`
+	Timestamp int64                  `json:"timestamp,omitempty"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// Explain runs the check of the initialized trigger checker read-only:
+// nothing is saved and no events are pushed, the steps of the check are returned instead.
+// The silent patterns are taken from the silencer, it must be started or updated by the caller
+func (triggerChecker *TriggerChecker) Explain() *Explanation {
+	explanation := &Explanation{
+		TriggerID: triggerChecker.TriggerID,
+		From:      triggerChecker.From,
+		Until:     triggerChecker.Until,
+		Steps:     make([]*ExplanationStep, 0),
+		Events:    make([]*moira.NotificationEvent, 0),
+	}
+
+	triggerChecker.explanation = explanation
+	triggerChecker.Database = &readOnlyDatabase{Database: triggerChecker.Database, explanation: explanation}
+	if err := triggerChecker.Check(); err != nil {
+		explanation.Error = err.Error()
+	}
+	return explanation
+}
+
+// explain adds the step to the explanation if the check is explained
+func (triggerChecker *TriggerChecker) explain(kind, metric string, timestamp int64, message string, data map[string]interface{}) {
+	if triggerChecker.explanation == nil {
+		return
+	}
+	triggerChecker.explanation.Steps = append(triggerChecker.explanation.Steps, &ExplanationStep{
+		Kind:      kind,
+		Metric:    metric,
+		Timestamp: timestamp,
+		Message:   message,
+		Data:      data,
+	})
+}
+
+// explainTimeSeries adds the fetched series of every target to the explanation
+func (triggerChecker *TriggerChecker) explainTimeSeries(triggerTimeSeries *triggerTimeSeries) {
+	if triggerChecker.explanation == nil {
+		return
+	}
+
+	targets := [][]*target.TimeSeries{triggerTimeSeries.Main}
+	for _, timeSeries := range triggerTimeSeries.Additional {
+		targets = append(targets, []*target.TimeSeries{timeSeries})
+	}
+	for i, targetTimeSeries := range targets {
+		targetName := fmt.Sprintf("t%d", i+1)
+		if len(targetTimeSeries) == 0 {
+			triggerChecker.explain(ExplainSeries, "", 0, fmt.Sprintf("Target %s has no series", targetName), nil)
+		}
+		for _, timeSeries := range targetTimeSeries {
+			triggerChecker.explain(ExplainSeries, timeSeries.Name, int64(timeSeries.StartTime), fmt.Sprintf(
+				"Target %s: %d values from %d to %d with retention %ds",
+				targetName, len(timeSeries.Values), timeSeries.StartTime, timeSeries.StopTime, timeSeries.StepTime,
+			), map[string]interface{}{
+				"target":    targetName,
+				"start":     timeSeries.StartTime,
+				"stop":      timeSeries.StopTime,
+				"retention": timeSeries.StepTime,
+				"wildcard":  timeSeries.Wildcard,
+				"values":    getExplainedValues(timeSeries),
+			})
+		}
+	}
+}
+
+// explainNoData adds the result of checkForNoData to the explanation
+func (triggerChecker *TriggerChecker) explainNoData(metric string, noDataState *moira.MetricState, deleteMetric bool) {
+	switch {
+	case deleteMetric:
+		triggerChecker.explain(ExplainNoData, metric, triggerChecker.CheckStarted, "No data for too long, the metric is removed", map[string]interface{}{
+			"ttl":          triggerChecker.ttl,
+			"remove_after": triggerChecker.removeAfter,
+		})
+	case noDataState != nil:
+		triggerChecker.explain(ExplainNoData, metric, triggerChecker.CheckStarted,
+			fmt.Sprintf("No data for longer than ttl %ds, the state is %s", triggerChecker.ttl, noDataState.State), nil)
+	}
+}
+
+// explainDecision adds the result of needSendEvent to the explanation
+func (triggerChecker *TriggerChecker) explainDecision(
+	metric string, timestamp int64, currentState, lastState string,
+	needSend, keepPending bool, message *string,
+) {
+	if triggerChecker.explanation == nil {
+		return
+	}
+
+	var decision string
+	switch {
+	case needSend:
+		decision = "the event is sent"
+	case keepPending:
+		decision = fmt.Sprintf("the event is pending for %ds", triggerChecker.trigger.PendingInterval)
+	default:
+		decision = "no event"
+	}
+	triggerChecker.explain(ExplainDecision, metric, timestamp, fmt.Sprintf("%s -> %s: %s", lastState, currentState, decision), map[string]interface{}{
+		"need_send":    needSend,
+		"keep_pending": keepPending,
+		"message":      moira.UseString(message),
+	})
+}
+
+// getExplainedValues returns the values of the series, the absent values are nil
+func getExplainedValues(timeSeries *target.TimeSeries) []*float64 {
+	values := make([]*float64, len(timeSeries.Values))
+	for i := range timeSeries.Values {
+		value := timeSeries.GetTimestampValue(int64(timeSeries.StartTime) + int64(i)*int64(timeSeries.StepTime))
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			values[i] = &value
+		}
+	}
+	return values
+}
+
+// readOnlyDatabase keeps the explained check from changing anything, the changes are added to the explanation instead
+type readOnlyDatabase struct {
+	moira.Database
+	explanation *Explanation
+}
+
+func (db *readOnlyDatabase) skip(message string, args ...interface{}) error {
+	db.explanation.Steps = append(db.explanation.Steps, &ExplanationStep{
+		Kind:    ExplainSkipped,
+		Message: fmt.Sprintf(message, args...),
+	})
+	return nil
+}
+
+// SetTriggerLastCheck keeps the last check in the explanation
+func (db *readOnlyDatabase) SetTriggerLastCheck(triggerID string, checkData *moira.CheckData) error {
+	db.explanation.CheckData = checkData
+	return nil
+}
+
+// PushNotificationEvent keeps the event in the explanation
+func (db *readOnlyDatabase) PushNotificationEvent(event *moira.NotificationEvent) error {
+	db.explanation.Events = append(db.explanation.Events, event)
+	return nil
+}
+
+func (db *readOnlyDatabase) AddTriggerForcedNotification(triggerID string, metrics []string, time int64) error {
+	return db.skip("Add forced notifications of metrics %v of trigger %s at %d", metrics, triggerID, time)
+}
+
+func (db *readOnlyDatabase) DeleteTriggerForcedNotification(triggerID string, metric string) error {
+	return db.skip("Delete forced notification of metric %s", metric)
+}
+
+func (db *readOnlyDatabase) DeleteTriggerForcedNotifications(triggerID string, metrics []string) error {
+	return db.skip("Delete forced notifications of metrics %v", metrics)
+}
+
+func (db *readOnlyDatabase) DeleteChildEvents(parentTriggerID, parentMetric string, childTriggerID string, childMetrics []string) error {
+	return db.skip("Delete links of child events %v of trigger %s from metric %s", childMetrics, childTriggerID, parentMetric)
+}
+
+func (db *readOnlyDatabase) RemovePatternsMetrics(patterns []string) error {
+	return db.skip("Remove metrics of patterns %v", patterns)
+}
+
+func (db *readOnlyDatabase) RemoveMetricsValues(metrics []string, toTime int64) error {
+	return db.skip("Remove values of metrics %v older than %d", metrics, toTime)
+}
@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/target"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestReadOnlyDatabase(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	explanation := &Explanation{Steps: make([]*ExplanationStep, 0), Events: make([]*moira.NotificationEvent, 0)}
+	readOnly := &readOnlyDatabase{Database: dataBase, explanation: explanation}
+
+	Convey("Changes are kept in the explanation instead of the database", t, func() {
+		checkData := &moira.CheckData{State: moira.OK, Timestamp: 67}
+		event := &moira.NotificationEvent{TriggerID: "SuperId", Metric: "super.puper.metric", State: moira.WARN}
+
+		So(readOnly.SetTriggerLastCheck("SuperId", checkData), ShouldBeNil)
+		So(readOnly.PushNotificationEvent(event), ShouldBeNil)
+		So(readOnly.RemoveMetricsValues([]string{"super.puper.metric"}, 17), ShouldBeNil)
+		So(readOnly.DeleteTriggerForcedNotification("SuperId", "super.puper.metric"), ShouldBeNil)
+
+		So(explanation.CheckData, ShouldEqual, checkData)
+		So(explanation.Events, ShouldResemble, []*moira.NotificationEvent{event})
+		So(len(explanation.Steps), ShouldEqual, 2)
+		So(explanation.Steps[0].Kind, ShouldEqual, ExplainSkipped)
+		So(explanation.Steps[1].Kind, ShouldEqual, ExplainSkipped)
+	})
+
+	Convey("Reads go to the database", t, func() {
+		dataBase.EXPECT().GetPatternMetrics("super.puper.pattern").Return([]string{"super.puper.metric"}, nil)
+		metrics, err := readOnly.GetPatternMetrics("super.puper.pattern")
+		So(err, ShouldBeNil)
+		So(metrics, ShouldResemble, []string{"super.puper.metric"})
+	})
+}
+
+func TestExplainSteps(t *testing.T) {
+	test_helpers.InitTestLogging()
+
+	makeTriggerChecker := func(explanation *Explanation) *TriggerChecker {
+		return &TriggerChecker{
+			TriggerID:   "SuperId",
+			Until:       67,
+			logger:      test_helpers.GetTestLogger(),
+			trigger:     &moira.Trigger{PendingInterval: 60},
+			ttl:         600,
+			explanation: explanation,
+		}
+	}
+
+	Convey("Nothing is explained by the regular check", t, func() {
+		triggerChecker := makeTriggerChecker(nil)
+		triggerChecker.explain(ExplainNoData, "super.puper.metric", 67, "No data", nil)
+		triggerChecker.explainDecision("super.puper.metric", 67, moira.WARN, moira.OK, true, false, nil)
+		So(triggerChecker.explanation, ShouldBeNil)
+	})
+
+	Convey("Decisions are explained", t, func() {
+		explanation := &Explanation{Steps: make([]*ExplanationStep, 0)}
+		triggerChecker := makeTriggerChecker(explanation)
+
+		triggerChecker.explainDecision("super.puper.metric", 67, moira.WARN, moira.OK, true, false, nil)
+		triggerChecker.explainDecision("super.puper.metric", 67, moira.ERROR, moira.WARN, false, true, nil)
+		triggerChecker.explainDecision("super.puper.metric", 67, moira.OK, moira.OK, false, false, nil)
+
+		So(len(explanation.Steps), ShouldEqual, 3)
+		So(explanation.Steps[0].Message, ShouldEqual, "OK -> WARN: the event is sent")
+		So(explanation.Steps[1].Message, ShouldEqual, "WARN -> ERROR: the event is pending for 60s")
+		So(explanation.Steps[2].Message, ShouldEqual, "OK -> OK: no event")
+		So(explanation.Steps[0].Kind, ShouldEqual, ExplainDecision)
+	})
+
+	Convey("Series are explained with absent values", t, func() {
+		explanation := &Explanation{Steps: make([]*ExplanationStep, 0)}
+		triggerChecker := makeTriggerChecker(explanation)
+
+		timeSeries := &target.TimeSeries{}
+		timeSeries.Name = "super.puper.metric"
+		timeSeries.StartTime = 17
+		timeSeries.StopTime = 47
+		timeSeries.StepTime = 10
+		timeSeries.Values = []float64{1, 0, 3}
+		timeSeries.IsAbsent = []bool{false, true, false}
+
+		triggerChecker.explainTimeSeries(&triggerTimeSeries{
+			Main:       []*target.TimeSeries{timeSeries},
+			Additional: []*target.TimeSeries{},
+		})
+
+		So(len(explanation.Steps), ShouldEqual, 1)
+		step := explanation.Steps[0]
+		So(step.Kind, ShouldEqual, ExplainSeries)
+		So(step.Metric, ShouldEqual, "super.puper.metric")
+		values := step.Data["values"].([]*float64)
+		So(len(values), ShouldEqual, 3)
+		So(*values[0], ShouldEqual, 1)
+		So(values[1], ShouldBeNil)
+		So(*values[2], ShouldEqual, 3)
+	})
+}
@@ -0,0 +1,71 @@
+package checker
+
+import (
+	"fmt"
+
+	"go.avito.ru/DO/moira"
+)
+
+var flappingStoppedMessage = "Metric is not flapping anymore"
+
+// flappingTransition is the change of the metric's flapping flag made by the check
+type flappingTransition int
+
+const (
+	flappingUnchanged flappingTransition = iota
+	flappingStarted
+	flappingStopped
+)
+
+// updateFlapping records the state change of the metric (if there is one) and re-evaluates its flapping flag
+// the change is taken into account only if it is not put on hold by the pending interval
+func (triggerChecker *TriggerChecker) updateFlapping(
+	currState *moira.MetricState,
+	lastState *moira.MetricState,
+	keepPending bool,
+) flappingTransition {
+	if !triggerChecker.trigger.IsFlappingDetectionEnabled() {
+		currState.StateChanges = nil
+		currState.IsFlapping = false
+		if lastState.IsFlapping {
+			return flappingStopped
+		}
+		return flappingUnchanged
+	}
+
+	var (
+		settings = triggerChecker.trigger.Flapping
+		since    = currState.Timestamp - settings.Window
+		changes  = make([]int64, 0, len(lastState.StateChanges)+1)
+	)
+
+	for _, ts := range lastState.StateChanges {
+		if ts > since {
+			changes = append(changes, ts)
+		}
+	}
+	if currState.State != lastState.State && !keepPending {
+		changes = append(changes, currState.Timestamp)
+	}
+	currState.StateChanges = changes
+
+	switch {
+	case !lastState.IsFlapping && len(changes) > settings.Threshold:
+		currState.IsFlapping = true
+		return flappingStarted
+	case lastState.IsFlapping && !keepPending && len(changes) <= settings.Threshold/2:
+		currState.IsFlapping = false
+		return flappingStopped
+	default:
+		currState.IsFlapping = lastState.IsFlapping
+		return flappingUnchanged
+	}
+}
+
+// flappingStartedMessage explains why the metric is considered flapping
+func (triggerChecker *TriggerChecker) flappingStartedMessage(state *moira.MetricState) string {
+	return fmt.Sprintf(
+		"Metric has changed its state %d times during the last %s, notifications are suppressed until it is stable",
+		len(state.StateChanges), formatWindow(triggerChecker.trigger.Flapping.Window),
+	)
+}
@@ -0,0 +1,90 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestFlapping(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	triggerChecker := TriggerChecker{
+		TriggerID: "FlappingId",
+		Database:  dataBase,
+		logger:    test_helpers.GetTestLogger(),
+		trigger: &moira.Trigger{
+			Flapping: &moira.FlappingSettings{Threshold: 2, Window: 600},
+		},
+	}
+
+	var events []*moira.NotificationEvent
+	dataBase.EXPECT().PushNotificationEvent(gomock.Any()).DoAndReturn(func(event *moira.NotificationEvent) error {
+		events = append(events, event)
+		return nil
+	}).AnyTimes()
+	dataBase.EXPECT().GetChildEvents("FlappingId", "m1").Return(nil, nil).AnyTimes()
+
+	check := func(lastState *moira.MetricState, state string, ts int64) *moira.MetricState {
+		currState := &moira.MetricState{State: state, Timestamp: ts}
+		newState, err := triggerChecker.compareStates("m1", currState, lastState, false)
+		So(err, ShouldBeNil)
+		return newState
+	}
+
+	Convey("Metric starts flapping, stays silent and then becomes stable", t, func() {
+		events = nil
+		state := &moira.MetricState{State: moira.OK, Timestamp: 0}
+
+		state = check(state, moira.ERROR, 60)
+		state = check(state, moira.OK, 120)
+		So(events, ShouldHaveLength, 2)
+		So(state.IsFlapping, ShouldBeFalse)
+		So(state.StateChanges, ShouldResemble, []int64{60, 120})
+
+		state = check(state, moira.ERROR, 180)
+		So(state.IsFlapping, ShouldBeTrue)
+		So(events, ShouldHaveLength, 3)
+		So(events[2].State, ShouldEqual, moira.FLAPPING)
+		So(events[2].OldState, ShouldEqual, moira.OK)
+		So(*events[2].Message, ShouldEqual, "Metric has changed its state 3 times during the last 10m, notifications are suppressed until it is stable")
+
+		state = check(state, moira.OK, 240)
+		state = check(state, moira.ERROR, 300)
+		So(state.IsFlapping, ShouldBeTrue)
+		So(state.State, ShouldEqual, moira.ERROR)
+		So(events, ShouldHaveLength, 3)
+
+		// changes at 240 and 300 are still inside the window
+		state = check(state, moira.ERROR, 780)
+		So(state.IsFlapping, ShouldBeTrue)
+		So(events, ShouldHaveLength, 3)
+
+		state = check(state, moira.ERROR, 900)
+		So(state.IsFlapping, ShouldBeFalse)
+		So(state.StateChanges, ShouldBeEmpty)
+		So(events, ShouldHaveLength, 4)
+		So(events[3].State, ShouldEqual, moira.ERROR)
+		So(events[3].OldState, ShouldEqual, moira.FLAPPING)
+		So(*events[3].Message, ShouldEqual, flappingStoppedMessage)
+	})
+
+	Convey("Flap detection is disabled", t, func() {
+		events = nil
+		triggerChecker.trigger.Flapping = nil
+		state := &moira.MetricState{State: moira.OK, Timestamp: 0}
+		for i, st := range []string{moira.ERROR, moira.OK, moira.ERROR, moira.OK} {
+			state = check(state, st, int64(i+1)*60)
+		}
+		So(state.IsFlapping, ShouldBeFalse)
+		So(state.StateChanges, ShouldBeNil)
+		So(events, ShouldHaveLength, 4)
+	})
+}
@@ -0,0 +1,197 @@
+package checker
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/target"
+)
+
+const forecastTimeFormat = "2006-01-02 15:04"
+
+// forecastPoint is a valid point of the series which the trend is fitted on
+type forecastPoint struct {
+	Timestamp int64
+	Value     float64
+}
+
+// getForecastStates projects the series and returns its state at the last valid point (if it hasn't been handled yet)
+func (triggerChecker *TriggerChecker) getForecastStates(timeSeries *target.TimeSeries, lastState *moira.MetricState) []*moira.MetricState {
+	var (
+		settings = triggerChecker.trigger.Forecast
+		points   = getForecastPoints(timeSeries, triggerChecker.Until-settings.Lookback, triggerChecker.Until)
+	)
+
+	if len(points) < 2 || points[len(points)-1].Timestamp <= lastState.Timestamp {
+		return nil
+	}
+
+	var level, slope float64
+	if settings.GetModel() == moira.ForecastModelHolt {
+		alpha, beta := settings.GetSmoothing()
+		level, slope = fitHoltTrend(points, alpha, beta)
+	} else {
+		level, slope = fitLinearTrend(points)
+	}
+
+	lastPoint := points[len(points)-1]
+	forecast := &moira.MetricForecast{
+		Threshold: *triggerChecker.trigger.ErrorValue,
+		Slope:     slope,
+		ETA:       getForecastETA(level, slope, *triggerChecker.trigger.ErrorValue, triggerChecker.isForecastAscending()),
+	}
+
+	return []*moira.MetricState{{
+		State:      triggerChecker.getForecastState(forecast),
+		Timestamp:  lastPoint.Timestamp,
+		Value:      &lastPoint.Value,
+		Suppressed: lastState.Suppressed,
+		Message:    forecastMessage(forecast, lastPoint.Timestamp),
+		Forecast:   forecast,
+	}}
+}
+
+// isForecastAscending tells if the bad values are the big ones, the same way as simple trigger expression does
+func (triggerChecker *TriggerChecker) isForecastAscending() bool {
+	warnValue, errorValue := triggerChecker.trigger.WarnValue, triggerChecker.trigger.ErrorValue
+	return warnValue == nil || *warnValue <= *errorValue
+}
+
+// getForecastState compares projected time to threshold with the horizons
+func (triggerChecker *TriggerChecker) getForecastState(forecast *moira.MetricForecast) string {
+	settings := triggerChecker.trigger.Forecast
+	switch {
+	case forecast.ETA == nil:
+		return moira.OK
+	case *forecast.ETA <= settings.ErrorHorizon:
+		return moira.ERROR
+	case settings.WarnHorizon > 0 && *forecast.ETA <= settings.WarnHorizon:
+		return moira.WARN
+	default:
+		return moira.OK
+	}
+}
+
+// getForecastPoints returns valid points of the series which belong to [from, until]
+func getForecastPoints(timeSeries *target.TimeSeries, from, until int64) []forecastPoint {
+	points := make([]forecastPoint, 0, len(timeSeries.Values))
+	if timeSeries.StepTime <= 0 {
+		return points
+	}
+
+	for i := range timeSeries.Values {
+		timestamp := int64(timeSeries.StartTime) + int64(i)*int64(timeSeries.StepTime)
+		if timestamp < from || timestamp > until {
+			continue
+		}
+		value := timeSeries.GetTimestampValue(timestamp)
+		if IsInvalidValue(value) {
+			continue
+		}
+		points = append(points, forecastPoint{Timestamp: timestamp, Value: value})
+	}
+	return points
+}
+
+// fitLinearTrend fits the least squares line and returns its value at the last point and its slope (per second)
+func fitLinearTrend(points []forecastPoint) (level, slope float64) {
+	var (
+		n      = float64(len(points))
+		origin = points[0].Timestamp // timestamps are shifted so that the sums don't lose precision
+		sumX   float64
+		sumY   float64
+		sumXY  float64
+		sumXX  float64
+	)
+
+	for _, point := range points {
+		x := float64(point.Timestamp - origin)
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return points[len(points)-1].Value, 0
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	level = intercept + slope*float64(points[len(points)-1].Timestamp-origin)
+	return level, slope
+}
+
+// fitHoltTrend applies Holt's double exponential smoothing and returns the final level and trend (per second)
+func fitHoltTrend(points []forecastPoint, alpha, beta float64) (level, slope float64) {
+	level = points[0].Value
+	slope = (points[1].Value - points[0].Value) / float64(points[1].Timestamp-points[0].Timestamp)
+
+	for i := 1; i < len(points); i++ {
+		dt := float64(points[i].Timestamp - points[i-1].Timestamp)
+		previousLevel := level
+		level = alpha*points[i].Value + (1-alpha)*(level+slope*dt)
+		slope = beta*(level-previousLevel)/dt + (1-beta)*slope
+	}
+	return level, slope
+}
+
+// getForecastETA returns seconds left until the threshold is reached or nil if the trend never reaches it
+func getForecastETA(level, slope, threshold float64, ascending bool) *int64 {
+	if !ascending {
+		level, slope, threshold = -level, -slope, -threshold
+	}
+
+	var eta int64
+	switch {
+	case level >= threshold:
+		eta = 0
+	case slope <= 0:
+		return nil
+	default:
+		seconds := (threshold - level) / slope
+		if seconds > math.MaxInt32 {
+			return nil
+		}
+		eta = int64(math.Ceil(seconds))
+	}
+	return &eta
+}
+
+// forecastMessage explains the projection, it goes to the event
+func forecastMessage(forecast *moira.MetricForecast, timestamp int64) string {
+	if forecast.ETA == nil {
+		return fmt.Sprintf("%v is not projected to be reached", forecast.Threshold)
+	}
+	if *forecast.ETA == 0 {
+		return fmt.Sprintf("%v has already been reached", forecast.Threshold)
+	}
+	return fmt.Sprintf(
+		"%v is projected to be reached in %s (at %s)",
+		forecast.Threshold, formatETA(*forecast.ETA),
+		time.Unix(timestamp+*forecast.ETA, 0).Format(forecastTimeFormat),
+	)
+}
+
+// formatETA formats seconds as days, hours and minutes, e.g. 93780 -> 1d 2h 3m
+func formatETA(seconds int64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+
+	minutes := seconds / 60
+	result := ""
+	if days := minutes / 1440; days > 0 {
+		result += fmt.Sprintf("%dd ", days)
+	}
+	if hours := minutes % 1440 / 60; hours > 0 {
+		result += fmt.Sprintf("%dh ", hours)
+	}
+	if minutes%60 > 0 {
+		result += fmt.Sprintf("%dm ", minutes%60)
+	}
+	return strings.TrimSpace(result)
+}
@@ -0,0 +1,95 @@
+package checker
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func linearValues(start, delta float64, qty int) []float64 {
+	result := make([]float64, qty)
+	for i := range result {
+		result[i] = start + delta*float64(i)
+	}
+	return result
+}
+
+func TestForecastTrend(t *testing.T) {
+	points := getForecastPoints(newSLOTimeSeries("disk", 60, 60, linearValues(10, 6, 10))[0], 0, 600)
+
+	Convey("Linear trend", t, func() {
+		level, slope := fitLinearTrend(points)
+		So(level, ShouldAlmostEqual, 64, 0.0001)
+		So(slope, ShouldAlmostEqual, 0.1, 0.0001)
+	})
+
+	Convey("Holt trend follows the straight line", t, func() {
+		level, slope := fitHoltTrend(points, 0.5, 0.3)
+		So(level, ShouldAlmostEqual, 64, 0.0001)
+		So(slope, ShouldAlmostEqual, 0.1, 0.0001)
+	})
+
+	Convey("Time to threshold", t, func() {
+		So(*getForecastETA(64, 0.1, 100, true), ShouldEqual, 360)
+		So(*getForecastETA(100, 0.1, 100, true), ShouldEqual, 0)
+		So(getForecastETA(64, -0.1, 100, true), ShouldBeNil)
+		So(*getForecastETA(64, -0.1, 10, false), ShouldEqual, 540)
+		So(getForecastETA(64, 0, 10, false), ShouldBeNil)
+	})
+
+	Convey("ETA formatting", t, func() {
+		So(formatETA(30), ShouldEqual, "30s")
+		So(formatETA(93780), ShouldEqual, "1d 2h 3m")
+		So(formatETA(7200), ShouldEqual, "2h")
+	})
+}
+
+func TestGetForecastStates(t *testing.T) {
+	var (
+		warnValue  float64 = 80
+		errorValue float64 = 100
+	)
+
+	triggerChecker := TriggerChecker{
+		TriggerID: "ForecastId",
+		Until:     600,
+		logger:    test_helpers.GetTestLogger(),
+		trigger: &moira.Trigger{
+			WarnValue:  &warnValue,
+			ErrorValue: &errorValue,
+			Forecast:   &moira.ForecastSettings{Lookback: 600, WarnHorizon: 3600, ErrorHorizon: 600},
+		},
+	}
+	// grows by 0.1 per second and reaches 64 at 600
+	timeSeries := newSLOTimeSeries("disk", 60, 60, linearValues(10, 6, 10))[0]
+
+	Convey("ERROR when threshold is close", t, func() {
+		states := triggerChecker.getForecastStates(timeSeries, &moira.MetricState{})
+		So(states, ShouldHaveLength, 1)
+		So(states[0].State, ShouldEqual, moira.ERROR)
+		So(states[0].Timestamp, ShouldEqual, 600)
+		So(*states[0].Forecast.ETA, ShouldEqual, 360)
+		So(states[0].Message, ShouldStartWith, "100 is projected to be reached in 6m")
+	})
+
+	Convey("WARN when threshold is further than error horizon", t, func() {
+		triggerChecker.trigger.Forecast.ErrorHorizon = 300
+		defer func() { triggerChecker.trigger.Forecast.ErrorHorizon = 600 }()
+
+		states := triggerChecker.getForecastStates(timeSeries, &moira.MetricState{})
+		So(states[0].State, ShouldEqual, moira.WARN)
+	})
+
+	Convey("OK when values fall", t, func() {
+		states := triggerChecker.getForecastStates(newSLOTimeSeries("disk", 60, 60, linearValues(64, -6, 10))[0], &moira.MetricState{})
+		So(states[0].State, ShouldEqual, moira.OK)
+		So(states[0].Forecast.ETA, ShouldBeNil)
+	})
+
+	Convey("Already handled points give no state", t, func() {
+		So(triggerChecker.getForecastStates(timeSeries, &moira.MetricState{Timestamp: 600}), ShouldBeEmpty)
+	})
+}
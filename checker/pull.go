@@ -1,10 +1,14 @@
 package checker
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	pb "github.com/go-graphite/carbonapi/carbonzipperpb3"
 	et "github.com/go-graphite/carbonapi/expr/types"
@@ -12,49 +16,98 @@ import (
 	"go.avito.ru/DO/moira/target"
 )
 
-func (triggerChecker *TriggerChecker) prepareGraphiteRequest(url string, from, until int64, targets []string) (*http.Request, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// jsonRenderSeries is single series of graphite json render format
+type jsonRenderSeries struct {
+	Target     string        `json:"target"`
+	Datapoints [][2]*float64 `json:"datapoints"`
+}
+
+// errRemoteResponse is used for responses which are worth retrying
+type errRemoteResponse struct {
+	status int
+	body   string
+}
+
+func (err errRemoteResponse) Error() string {
+	return fmt.Sprintf("bad response status %d: %s", err.status, err.body)
+}
+
+func (err errRemoteResponse) isTemporary() bool {
+	return err.status >= 500 || err.status == http.StatusTooManyRequests
+}
+
+func (triggerChecker *TriggerChecker) prepareGraphiteRequest(source *RemoteSource, from, until int64, targets []string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", source.URL, nil)
 	if err != nil {
 		return nil, err
 	}
 	q := req.URL.Query()
-	q.Add("format", "protobuf")
+	q.Add("format", source.Format)
 	q.Add("from", strconv.FormatInt(from, 10))
 	q.Add("until", strconv.FormatInt(until, 10))
 	for _, t := range targets {
 		q.Add("target", t)
 	}
 	req.URL.RawQuery = q.Encode()
-	return req, nil
-}
 
-func (triggerChecker *TriggerChecker) makeGraphiteRequest(req *http.Request) (pb.MultiFetchResponse, error) {
-	var pbResp pb.MultiFetchResponse
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return pbResp, err
+	for name, value := range source.Headers {
+		req.Header.Set(name, value)
 	}
-	body, _ := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		err = fmt.Errorf("bad response status %d: %s", resp.StatusCode, string(body))
-		return pbResp, err
+	if source.User != "" {
+		req.SetBasicAuth(source.User, source.Password)
 	}
-	err = pbResp.Unmarshal(body)
+	return req, nil
+}
 
-	if len(pbResp.Errors) > 0 {
-		triggerChecker.logger.ErrorE(
-			"pull trigger: carbonapi returned errors",
+// makeGraphiteRequest performs the request retrying it on network errors and 5xx responses
+func (triggerChecker *TriggerChecker) makeGraphiteRequest(source *RemoteSource, req *http.Request) ([]byte, error) {
+	var (
+		body []byte
+		err  error
+	)
+
+	for attempt := 0; attempt <= source.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(source.RetryDelay)
+		}
+
+		body, err = triggerChecker.doGraphiteRequest(source, req)
+		if err == nil {
+			return body, nil
+		}
+		if responseErr, ok := err.(errRemoteResponse); ok && !responseErr.isTemporary() {
+			break
+		}
+
+		triggerChecker.logger.WarnE(
+			"pull trigger: remote request failed",
 			map[string]interface{}{
-				"TriggerID":      triggerChecker.TriggerID,
-				"URL":            req.URL.String(),
-				"Carbonapi-UUID": resp.Header.Get("X-Carbonapi-UUID"),
-				"Errors":         pbResp.Errors,
+				"TriggerID": triggerChecker.TriggerID,
+				"Source":    source.Name,
+				"URL":       req.URL.String(),
+				"Attempt":   attempt + 1,
+				"Error":     err.Error(),
 			},
 		)
 	}
-	return pbResp, err
+	return nil, err
+}
+
+func (triggerChecker *TriggerChecker) doGraphiteRequest(source *RemoteSource, req *http.Request) ([]byte, error) {
+	resp, err := source.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errRemoteResponse{status: resp.StatusCode, body: string(body)}
+	}
+	return body, nil
 }
 
 func (triggerChecker *TriggerChecker) convertGraphiteResponse(r pb.MultiFetchResponse) []*target.TimeSeries {
@@ -67,14 +120,103 @@ func (triggerChecker *TriggerChecker) convertGraphiteResponse(r pb.MultiFetchRes
 	return ts
 }
 
-func (triggerChecker *TriggerChecker) PullRemote(url string, from, until int64, targets []string) ([]*target.TimeSeries, error) {
-	req, err := triggerChecker.prepareGraphiteRequest(url, from, until, targets)
+// parseProtobufResponse parses protobuf render response, errors reported by carbonapi are returned as error
+func (triggerChecker *TriggerChecker) parseProtobufResponse(body []byte) ([]*target.TimeSeries, error) {
+	var pbResp pb.MultiFetchResponse
+	if err := pbResp.Unmarshal(body); err != nil {
+		return nil, fmt.Errorf("failed to parse protobuf response: %v", err)
+	}
+
+	if len(pbResp.Errors) > 0 {
+		messages := make([]string, 0, len(pbResp.Errors))
+		for _, pbErr := range pbResp.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", pbErr.Target, pbErr.ErrorMessage))
+		}
+		return nil, fmt.Errorf("render errors: %s", strings.Join(messages, "; "))
+	}
+	return triggerChecker.convertGraphiteResponse(pbResp), nil
+}
+
+// parseJSONResponse parses graphite json render response
+// the step of the series is taken from the distance between the first two points
+func (triggerChecker *TriggerChecker) parseJSONResponse(body []byte, from, until int64) ([]*target.TimeSeries, error) {
+	var series []jsonRenderSeries
+	if err := json.Unmarshal(body, &series); err != nil {
+		return nil, fmt.Errorf("failed to parse json response: %v", err)
+	}
+
+	result := make([]*target.TimeSeries, 0, len(series))
+	for _, item := range series {
+		fetchResponse := pb.FetchResponse{
+			Name:      item.Target,
+			StartTime: int32(from),
+			StopTime:  int32(until),
+			StepTime:  60,
+			Values:    make([]float64, len(item.Datapoints)),
+			IsAbsent:  make([]bool, len(item.Datapoints)),
+		}
+
+		if len(item.Datapoints) > 0 && item.Datapoints[0][1] != nil {
+			fetchResponse.StartTime = int32(*item.Datapoints[0][1])
+		}
+		if len(item.Datapoints) > 1 && item.Datapoints[0][1] != nil && item.Datapoints[1][1] != nil {
+			fetchResponse.StepTime = int32(*item.Datapoints[1][1] - *item.Datapoints[0][1])
+		}
+		if fetchResponse.StepTime <= 0 {
+			return nil, fmt.Errorf("series '%s' has invalid step", item.Target)
+		}
+		fetchResponse.StopTime = fetchResponse.StartTime + int32(len(item.Datapoints))*fetchResponse.StepTime
+
+		for i, point := range item.Datapoints {
+			if point[0] == nil {
+				fetchResponse.Values[i] = math.NaN()
+				fetchResponse.IsAbsent[i] = true
+			} else {
+				fetchResponse.Values[i] = *point[0]
+			}
+		}
+
+		result = append(result, &target.TimeSeries{
+			MetricData: et.MetricData{FetchResponse: fetchResponse},
+			Wildcard:   false,
+		})
+	}
+	return result, nil
+}
+
+// PullRemote fetches targets from the remote source given
+// all failures are wrapped in ErrRemoteSource so that the trigger could get EXCEPTION state
+func (triggerChecker *TriggerChecker) PullRemote(source *RemoteSource, from, until int64, targets []string) ([]*target.TimeSeries, error) {
+	wrap := func(err error) error {
+		return ErrRemoteSource{Source: source.Name, internalError: err}
+	}
+
+	req, err := triggerChecker.prepareGraphiteRequest(source, from, until, targets)
 	if err != nil {
-		return nil, err
+		return nil, wrap(err)
+	}
+	body, err := triggerChecker.makeGraphiteRequest(source, req)
+	if err != nil {
+		return nil, wrap(err)
+	}
+
+	var result []*target.TimeSeries
+	if source.Format == RemoteFormatJSON {
+		result, err = triggerChecker.parseJSONResponse(body, from, until)
+	} else {
+		result, err = triggerChecker.parseProtobufResponse(body)
 	}
-	resp, err := triggerChecker.makeGraphiteRequest(req)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return result, nil
+}
+
+// pullRemoteTargets fetches targets from the remote source which is set for the trigger
+func (triggerChecker *TriggerChecker) pullRemoteTargets(from, until int64, targets []string) ([]*target.TimeSeries, error) {
+	source, err := triggerChecker.Config.GetRemoteSource(triggerChecker.trigger.Source)
 	if err != nil {
 		return nil, err
 	}
-	return triggerChecker.convertGraphiteResponse(resp), nil
+	return triggerChecker.PullRemote(source, from, until, targets)
 }
@@ -0,0 +1,113 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.avito.ru/DO/moira"
+)
+
+// quorumMessageMetricsLimit is how many bad metrics are listed in the message of the quorum trigger event
+const quorumMessageMetricsLimit = 20
+
+// quorumStates are the states of the metrics which count towards the quorum, from the worst to the mildest
+var quorumStates = []string{moira.ERROR, moira.NODATA, moira.WARN}
+
+// handleQuorum sets the state of the quorum trigger by the states of its metrics
+// and sends the trigger event if the state has changed, the metrics themselves are never alerted
+func (triggerChecker *TriggerChecker) handleQuorum(checkData moira.CheckData) (moira.CheckData, error) {
+	// the muted trigger keeps its state, it is sent again once the trigger is handled if it is bad
+	if triggerChecker.isHandleMetricDisabled(triggerChecker.Until, moira.WildcardMetric, "handleQuorum") {
+		checkData.State, checkData.Message = triggerChecker.lastCheck.State, triggerChecker.lastCheck.Message
+		if checkData.State == "" {
+			checkData.State = moira.OK
+		}
+		checkData.EventTimestamp = triggerChecker.lastCheck.EventTimestamp
+		checkData.Suppressed = true
+		return checkData, nil
+	}
+
+	badMetrics := make(map[string][]string, len(quorumStates))
+	bad, total := 0, 0
+	for metric, metricState := range checkData.Metrics {
+		// the metrics under maintenance, silenced or out of schedule count neither as bad nor towards the total
+		if triggerChecker.isHandleMetricDisabled(triggerChecker.Until, metric, "handleQuorum") {
+			continue
+		}
+
+		total++
+		if getQuorumStateLevel(metricState.State) >= 0 {
+			badMetrics[metricState.State] = append(badMetrics[metricState.State], metric)
+			bad++
+		}
+	}
+
+	checkData.State = moira.OK
+	checkData.Message = ""
+	quorum := triggerChecker.trigger.Quorum
+	reached := quorum.IsReached(bad, total)
+	if reached {
+		for _, state := range quorumStates {
+			if len(badMetrics[state]) > 0 {
+				checkData.State = state
+				break
+			}
+		}
+		checkData.Message = getQuorumMessage(badMetrics, bad, total)
+	}
+
+	triggerChecker.logger.InfoE(
+		fmt.Sprintf("[TriggerID:%s] Quorum evaluated", triggerChecker.TriggerID),
+		map[string]interface{}{
+			"trigger_id": triggerChecker.TriggerID,
+			"bad":        bad,
+			"total":      total,
+			"reached":    reached,
+			"state":      checkData.State,
+		},
+	)
+	triggerChecker.explain(ExplainDecision, moira.WildcardMetric, triggerChecker.Until,
+		fmt.Sprintf("%d of %d metrics are bad, the quorum is reached: %t, the trigger state is %s", bad, total, reached, checkData.State),
+		map[string]interface{}{
+			"count":   quorum.Count,
+			"percent": quorum.Percent,
+		},
+	)
+
+	return triggerChecker.compareChecks(checkData, triggerChecker.forced[moira.WildcardMetric])
+}
+
+// getQuorumMessage lists the bad metrics starting with the worst ones
+func getQuorumMessage(badMetrics map[string][]string, bad, total int) string {
+	message := strings.Builder{}
+	message.WriteString(fmt.Sprintf("%d of %d metrics (%.0f%%) are bad: ", bad, total, float64(bad)*100/float64(total)))
+
+	listed := 0
+	for _, state := range quorumStates {
+		metrics := badMetrics[state]
+		sort.Strings(metrics)
+		for _, metric := range metrics {
+			if listed == quorumMessageMetricsLimit {
+				message.WriteString(fmt.Sprintf(" and %d more", bad-listed))
+				return message.String()
+			}
+			if listed > 0 {
+				message.WriteString(", ")
+			}
+			message.WriteString(fmt.Sprintf("%s (%s)", metric, state))
+			listed++
+		}
+	}
+	return message.String()
+}
+
+// getQuorumStateLevel returns the index of the state in quorumStates or -1 if the state doesn't count towards the quorum
+func getQuorumStateLevel(state string) int {
+	for level, quorumState := range quorumStates {
+		if state == quorumState {
+			return level
+		}
+	}
+	return -1
+}
@@ -0,0 +1,237 @@
+package checker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestGetQuorumMessage(t *testing.T) {
+	Convey("Bad metrics are listed from the worst", t, func() {
+		badMetrics := map[string][]string{
+			moira.WARN:   {"host.c", "host.a"},
+			moira.ERROR:  {"host.b"},
+			moira.NODATA: {"host.d"},
+		}
+		So(getQuorumMessage(badMetrics, 4, 10), ShouldEqual,
+			"4 of 10 metrics (40%) are bad: host.b (ERROR), host.d (NODATA), host.a (WARN), host.c (WARN)")
+	})
+
+	Convey("Too many bad metrics are cut", t, func() {
+		metrics := make([]string, 0, quorumMessageMetricsLimit+5)
+		for i := 0; i < quorumMessageMetricsLimit+5; i++ {
+			metrics = append(metrics, fmt.Sprintf("host.%02d", i))
+		}
+		message := getQuorumMessage(map[string][]string{moira.ERROR: metrics}, len(metrics), 100)
+		So(message, ShouldEndWith, "host.19 (ERROR) and 5 more")
+	})
+}
+
+func TestHandleQuorum(t *testing.T) {
+	test_helpers.InitTestLogging()
+
+	makeMetrics := func(states ...string) map[string]*moira.MetricState {
+		metrics := make(map[string]*moira.MetricState, len(states))
+		for i, state := range states {
+			metrics[fmt.Sprintf("host.%d", i)] = &moira.MetricState{State: state, Timestamp: 60}
+		}
+		return metrics
+	}
+	makeTriggerChecker := func(dataBase moira.Database, lastState string) *TriggerChecker {
+		return &TriggerChecker{
+			TriggerID: "SuperId",
+			Database:  dataBase,
+			Until:     120,
+			logger:    test_helpers.GetTestLogger(),
+			trigger: &moira.Trigger{
+				Name:   "Super Trigger",
+				Quorum: &moira.QuorumSettings{Count: 3, Percent: 20},
+			},
+			lastCheck: &moira.CheckData{State: lastState, Timestamp: 60},
+			forced:    map[string]bool{},
+		}
+	}
+
+	Convey("Single bad metric doesn't reach the quorum", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		triggerChecker := makeTriggerChecker(mock_moira_alert.NewMockDatabase(mockCtrl), moira.OK)
+		checkData, err := triggerChecker.handleQuorum(moira.CheckData{
+			Timestamp: 120,
+			Metrics:   makeMetrics(moira.ERROR, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK),
+		})
+		So(err, ShouldBeNil)
+		So(checkData.State, ShouldEqual, moira.OK)
+		So(checkData.Message, ShouldBeEmpty)
+	})
+
+	Convey("Percent of bad metrics reaches the quorum", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		triggerChecker := makeTriggerChecker(dataBase, moira.OK)
+		message := "2 of 10 metrics (20%) are bad: host.1 (NODATA), host.0 (WARN)"
+		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+			IsTriggerEvent: true,
+			TriggerID:      triggerChecker.TriggerID,
+			State:          moira.NODATA,
+			OldState:       moira.OK,
+			Timestamp:      120,
+			Metric:         triggerChecker.trigger.Name,
+			Message:        &message,
+		}).Return(nil)
+
+		checkData, err := triggerChecker.handleQuorum(moira.CheckData{
+			Timestamp: 120,
+			Metrics:   makeMetrics(moira.WARN, moira.NODATA, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK),
+		})
+		So(err, ShouldBeNil)
+		So(checkData.State, ShouldEqual, moira.NODATA)
+		So(checkData.Message, ShouldEqual, message)
+		So(checkData.EventTimestamp, ShouldEqual, 120)
+	})
+
+	Convey("Trigger returns to OK", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		triggerChecker := makeTriggerChecker(dataBase, moira.ERROR)
+		message := ""
+		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+			IsTriggerEvent: true,
+			TriggerID:      triggerChecker.TriggerID,
+			State:          moira.OK,
+			OldState:       moira.ERROR,
+			Timestamp:      120,
+			Metric:         triggerChecker.trigger.Name,
+			Message:        &message,
+		}).Return(nil)
+
+		checkData, err := triggerChecker.handleQuorum(moira.CheckData{
+			Timestamp: 120,
+			Metrics:   makeMetrics(moira.OK, moira.OK, moira.ERROR, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK),
+		})
+		So(err, ShouldBeNil)
+		So(checkData.State, ShouldEqual, moira.OK)
+	})
+
+	Convey("Muted metrics count neither as bad nor towards the total", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		triggerChecker := makeTriggerChecker(dataBase, moira.OK)
+		triggerChecker.maintenance = moira.NewMaintenance()
+		for _, metric := range []string{"host.1", "host.2", "host.3", "host.4", "host.5"} {
+			triggerChecker.maintenance.AddWindow(moira.MaintenanceWindow{ID: metric, Metric: metric, From: 0, Until: 600})
+		}
+		message := "1 of 5 metrics (20%) are bad: host.0 (ERROR)"
+		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+			IsTriggerEvent: true,
+			TriggerID:      triggerChecker.TriggerID,
+			State:          moira.ERROR,
+			OldState:       moira.OK,
+			Timestamp:      120,
+			Metric:         triggerChecker.trigger.Name,
+			Message:        &message,
+		}).Return(nil)
+
+		checkData, err := triggerChecker.handleQuorum(moira.CheckData{
+			Timestamp: 120,
+			Metrics:   makeMetrics(moira.ERROR, moira.ERROR, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK),
+		})
+		So(err, ShouldBeNil)
+		So(checkData.State, ShouldEqual, moira.ERROR)
+		So(checkData.Message, ShouldEqual, message)
+	})
+
+	Convey("Muted trigger keeps its state", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		triggerChecker := makeTriggerChecker(mock_moira_alert.NewMockDatabase(mockCtrl), moira.ERROR)
+		triggerChecker.lastCheck.Message = "3 of 10 metrics (30%) are bad: host.0 (ERROR), host.1 (ERROR), host.2 (ERROR)"
+		triggerChecker.lastCheck.EventTimestamp = 60
+		triggerChecker.maintenance = moira.NewMaintenance()
+		triggerChecker.maintenance.AddWindow(moira.MaintenanceWindow{ID: "sp", Metric: moira.WildcardMetric, From: 0, Until: 600})
+
+		checkData, err := triggerChecker.handleQuorum(moira.CheckData{
+			Timestamp: 120,
+			Metrics:   makeMetrics(moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK),
+		})
+		So(err, ShouldBeNil)
+		So(checkData.State, ShouldEqual, moira.ERROR)
+		So(checkData.Message, ShouldEqual, triggerChecker.lastCheck.Message)
+		So(checkData.EventTimestamp, ShouldEqual, 60)
+		So(checkData.Suppressed, ShouldBeTrue)
+	})
+
+	Convey("Reminder keeps the bad metrics", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		triggerChecker := makeTriggerChecker(dataBase, moira.ERROR)
+		triggerChecker.lastCheck.EventTimestamp = 60
+		message := "This metric has been in bad state for more than 24 hours - please, fix.\n" +
+			"3 of 10 metrics (30%) are bad: host.0 (ERROR), host.1 (ERROR), host.2 (ERROR)"
+		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+			IsTriggerEvent: true,
+			TriggerID:      triggerChecker.TriggerID,
+			State:          moira.ERROR,
+			OldState:       moira.ERROR,
+			Timestamp:      86460,
+			Metric:         triggerChecker.trigger.Name,
+			Message:        &message,
+		}).Return(nil)
+
+		checkData, err := triggerChecker.handleQuorum(moira.CheckData{
+			Timestamp: 86460,
+			Metrics:   makeMetrics(moira.ERROR, moira.ERROR, moira.ERROR, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK, moira.OK),
+		})
+		So(err, ShouldBeNil)
+		So(checkData.State, ShouldEqual, moira.ERROR)
+	})
+
+	Convey("Event of the muted metric is suppressed", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		triggerChecker := makeTriggerChecker(mock_moira_alert.NewMockDatabase(mockCtrl), moira.OK)
+		triggerChecker.maintenance = moira.NewMaintenance()
+		triggerChecker.maintenance.AddWindow(moira.MaintenanceWindow{ID: "sp", Metric: "host.0", From: 0, Until: 600})
+		currState, err := triggerChecker.compareStates(
+			"host.0",
+			&moira.MetricState{State: moira.ERROR, Timestamp: 120},
+			&moira.MetricState{State: moira.OK, Timestamp: 60},
+			false,
+		)
+		So(err, ShouldBeNil)
+		So(currState.Suppressed, ShouldBeTrue)
+	})
+
+	Convey("Metric event is not sent", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		triggerChecker := makeTriggerChecker(mock_moira_alert.NewMockDatabase(mockCtrl), moira.OK)
+		currState, err := triggerChecker.compareStates(
+			"host.0",
+			&moira.MetricState{State: moira.ERROR, Timestamp: 120},
+			&moira.MetricState{State: moira.OK, Timestamp: 60},
+			false,
+		)
+		So(err, ShouldBeNil)
+		So(currState.State, ShouldEqual, moira.ERROR)
+		So(currState.EventTimestamp, ShouldEqual, 120)
+	})
+}
@@ -0,0 +1,159 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Remote sources' render formats
+const (
+	RemoteFormatProtobuf = "protobuf"
+	RemoteFormatJSON     = "json"
+)
+
+// DefaultRemoteSource is the name of the source which is used if the trigger has no source set
+const DefaultRemoteSource = "default"
+
+const (
+	defaultRemoteTimeout    = 30 * time.Second
+	defaultRemoteRetryDelay = time.Second
+)
+
+// RemoteSource is graphite-compatible render API which pull triggers fetch their data from
+type RemoteSource struct {
+	Name       string
+	URL        string
+	Format     string
+	Headers    map[string]string
+	User       string
+	Password   string
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+	TLS        RemoteSourceTLS
+
+	client *http.Client
+}
+
+// RemoteSourceTLS represents TLS settings of the remote source
+type RemoteSourceTLS struct {
+	InsecureSkipVerify bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+}
+
+// Init validates settings and creates http client of the remote source
+func (source *RemoteSource) Init() error {
+	if source.URL == "" {
+		return fmt.Errorf("remote source '%s': url is required", source.Name)
+	}
+
+	switch source.Format {
+	case "":
+		source.Format = RemoteFormatProtobuf
+	case RemoteFormatProtobuf, RemoteFormatJSON:
+	default:
+		return fmt.Errorf("remote source '%s': unknown format '%s'", source.Name, source.Format)
+	}
+
+	if source.Timeout <= 0 {
+		source.Timeout = defaultRemoteTimeout
+	}
+	if source.RetryDelay <= 0 {
+		source.RetryDelay = defaultRemoteRetryDelay
+	}
+
+	tlsConfig, err := source.TLS.getConfig()
+	if err != nil {
+		return fmt.Errorf("remote source '%s': %v", source.Name, err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	source.client = &http.Client{
+		Timeout:   source.Timeout,
+		Transport: transport,
+	}
+	return nil
+}
+
+func (settings *RemoteSourceTLS) getConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+	}
+
+	if settings.CAFile != "" {
+		caCert, err := ioutil.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse CA file %s", settings.CAFile)
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	if settings.CertFile != "" || settings.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// ErrRemoteSource is returned when the data can't be fetched from the remote source
+type ErrRemoteSource struct {
+	Source        string
+	internalError error
+}
+
+// Error is implementation of golang error interface for ErrRemoteSource struct
+func (err ErrRemoteSource) Error() string {
+	return fmt.Sprintf("Remote source '%s' failed: %s", err.Source, err.internalError.Error())
+}
+
+// InitRemoteSources initializes all configured remote sources
+// PullURL is used as the default remote source unless it is configured explicitly
+func (config *Config) InitRemoteSources() error {
+	if config.RemoteSources == nil {
+		config.RemoteSources = make(map[string]*RemoteSource)
+	}
+	if _, ok := config.RemoteSources[DefaultRemoteSource]; !ok && config.PullURL != "" {
+		config.RemoteSources[DefaultRemoteSource] = &RemoteSource{
+			URL: config.PullURL,
+		}
+	}
+
+	for name, source := range config.RemoteSources {
+		source.Name = name
+		if err := source.Init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRemoteSource returns remote source by its name (the default one if name is empty)
+func (config *Config) GetRemoteSource(name string) (*RemoteSource, error) {
+	if name == "" {
+		name = DefaultRemoteSource
+	}
+
+	source, ok := config.RemoteSources[name]
+	if !ok || source.client == nil {
+		return nil, ErrRemoteSource{
+			Source:        name,
+			internalError: fmt.Errorf("source is not configured"),
+		}
+	}
+	return source, nil
+}
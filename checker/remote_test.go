@@ -0,0 +1,163 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/go-graphite/carbonapi/carbonzipperpb3"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/metrics"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func newRemoteTriggerChecker(source *RemoteSource) *TriggerChecker {
+	test_helpers.InitTestLogging()
+	return &TriggerChecker{
+		TriggerID: "remote-trigger",
+		Config: &Config{
+			RemoteSources: map[string]*RemoteSource{"graphite": source},
+		},
+		Statsd:  metrics.NewCheckerMetrics(),
+		logger:  test_helpers.GetTestLogger(),
+		trigger: &moira.Trigger{IsPullType: true, Source: "graphite"},
+	}
+}
+
+func TestPullRemote(t *testing.T) {
+	Convey("JSON render format with auth headers", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			user, password, _ := request.BasicAuth()
+			if request.Header.Get("X-Token") != "secret" || user != "moira" || password != "pass" {
+				writer.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if request.URL.Query().Get("format") != RemoteFormatJSON {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = writer.Write([]byte(`[{"target": "my.metric", "datapoints": [[1, 100], [null, 160], [3, 220]]}]`))
+		}))
+		defer server.Close()
+
+		source := &RemoteSource{
+			URL:      server.URL,
+			Format:   RemoteFormatJSON,
+			Headers:  map[string]string{"X-Token": "secret"},
+			User:     "moira",
+			Password: "pass",
+		}
+		triggerChecker := newRemoteTriggerChecker(source)
+		So(triggerChecker.Config.InitRemoteSources(), ShouldBeNil)
+
+		series, err := triggerChecker.pullRemoteTargets(100, 280, []string{"my.metric"})
+		So(err, ShouldBeNil)
+		So(series, ShouldHaveLength, 1)
+		So(series[0].Name, ShouldEqual, "my.metric")
+		So(series[0].StartTime, ShouldEqual, 100)
+		So(series[0].StepTime, ShouldEqual, 60)
+		So(series[0].GetTimestampValue(100), ShouldEqual, 1)
+		So(IsInvalidValue(series[0].GetTimestampValue(160)), ShouldBeTrue)
+		So(series[0].GetTimestampValue(220), ShouldEqual, 3)
+	})
+
+	Convey("Temporary errors are retried", t, func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				writer.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			response := pb.MultiFetchResponse{Metrics: []*pb.FetchResponse{{Name: "my.metric", StepTime: 60}}}
+			body, _ := response.Marshal()
+			_, _ = writer.Write(body)
+		}))
+		defer server.Close()
+
+		triggerChecker := newRemoteTriggerChecker(&RemoteSource{URL: server.URL, Retries: 2, RetryDelay: time.Millisecond})
+		So(triggerChecker.Config.InitRemoteSources(), ShouldBeNil)
+
+		series, err := triggerChecker.pullRemoteTargets(0, 60, []string{"my.metric"})
+		So(err, ShouldBeNil)
+		So(series, ShouldHaveLength, 1)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 3)
+	})
+
+	Convey("Client errors are not retried and lead to EXCEPTION", t, func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write([]byte("bad target"))
+		}))
+		defer server.Close()
+
+		triggerChecker := newRemoteTriggerChecker(&RemoteSource{URL: server.URL, Retries: 5, RetryDelay: time.Millisecond})
+		So(triggerChecker.Config.InitRemoteSources(), ShouldBeNil)
+
+		_, err := triggerChecker.pullRemoteTargets(0, 60, []string{"my.metric"})
+		So(err, ShouldHaveSameTypeAs, ErrRemoteSource{})
+		So(err.Error(), ShouldEqual, "Remote source 'graphite' failed: bad response status 400: bad target")
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+	})
+
+	Convey("Render errors are not ignored", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			response := pb.MultiFetchResponse{Errors: []*pb.Error{{Target: "my.metric", ErrorMessage: "timeout"}}}
+			body, _ := response.Marshal()
+			_, _ = writer.Write(body)
+		}))
+		defer server.Close()
+
+		triggerChecker := newRemoteTriggerChecker(&RemoteSource{URL: server.URL})
+		So(triggerChecker.Config.InitRemoteSources(), ShouldBeNil)
+
+		_, err := triggerChecker.pullRemoteTargets(0, 60, []string{"my.metric"})
+		So(err, ShouldResemble, ErrRemoteSource{Source: "graphite", internalError: fmt.Errorf("render errors: my.metric: timeout")})
+	})
+
+	Convey("Timeout is respected", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		triggerChecker := newRemoteTriggerChecker(&RemoteSource{URL: server.URL, Timeout: 10 * time.Millisecond})
+		So(triggerChecker.Config.InitRemoteSources(), ShouldBeNil)
+
+		_, err := triggerChecker.pullRemoteTargets(0, 60, []string{"my.metric"})
+		So(err, ShouldHaveSameTypeAs, ErrRemoteSource{})
+	})
+
+	Convey("Unknown source", t, func() {
+		triggerChecker := newRemoteTriggerChecker(&RemoteSource{URL: "http://localhost"})
+		triggerChecker.trigger.Source = "unknown"
+		So(triggerChecker.Config.InitRemoteSources(), ShouldBeNil)
+
+		_, err := triggerChecker.pullRemoteTargets(0, 60, []string{"my.metric"})
+		So(err.Error(), ShouldEqual, "Remote source 'unknown' failed: source is not configured")
+	})
+}
+
+func TestInitRemoteSources(t *testing.T) {
+	Convey("Pull URL becomes the default source", t, func() {
+		config := &Config{PullURL: "http://graphite/render/"}
+		So(config.InitRemoteSources(), ShouldBeNil)
+
+		source, err := config.GetRemoteSource("")
+		So(err, ShouldBeNil)
+		So(source.Name, ShouldEqual, DefaultRemoteSource)
+		So(source.Format, ShouldEqual, RemoteFormatProtobuf)
+		So(source.Timeout, ShouldEqual, defaultRemoteTimeout)
+	})
+
+	Convey("Invalid format", t, func() {
+		config := &Config{RemoteSources: map[string]*RemoteSource{"x": {URL: "http://x", Format: "csv"}}}
+		So(config.InitRemoteSources(), ShouldNotBeNil)
+	})
+}
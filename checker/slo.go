@@ -0,0 +1,246 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/target"
+)
+
+// sloBurnRate is the result of evaluation of the single burn rate alert
+type sloBurnRate struct {
+	Alert     moira.SLOBurnRateAlert
+	LongRate  float64
+	ShortRate float64
+	HasData   bool
+}
+
+// isFiring tells if both windows burn the error budget faster than allowed
+func (burnRate *sloBurnRate) isFiring() bool {
+	return burnRate.HasData && burnRate.LongRate >= burnRate.Alert.BurnRate && burnRate.ShortRate >= burnRate.Alert.BurnRate
+}
+
+// sloEvaluation is the result of SLO trigger evaluation at the given timestamp
+type sloEvaluation struct {
+	State           string
+	BudgetRemaining float64 // fraction of the error budget which is left over the budget period
+	MaxBurnRate     float64 // the biggest long window burn rate
+	BurnRates       []sloBurnRate
+	HasData         bool
+}
+
+// message builds human-readable explanation of the evaluation
+func (evaluation *sloEvaluation) message() string {
+	parts := make([]string, 0, len(evaluation.BurnRates)+1)
+	parts = append(parts, fmt.Sprintf("Error budget remaining: %.2f%%", evaluation.BudgetRemaining*100))
+	for _, burnRate := range evaluation.BurnRates {
+		if !burnRate.HasData {
+			continue
+		}
+		mark := ""
+		if burnRate.isFiring() {
+			mark = " [" + burnRate.Alert.State + "]"
+		}
+		parts = append(parts, fmt.Sprintf(
+			"burn rate %s/%s: %.2f/%.2f (threshold %v)%s",
+			formatWindow(burnRate.Alert.LongWindow), formatWindow(burnRate.Alert.ShortWindow),
+			burnRate.LongRate, burnRate.ShortRate, burnRate.Alert.BurnRate, mark,
+		))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// handleSLOTrigger evaluates all burn rate windows of the SLO trigger
+// the whole trigger is represented by the single metric named after the trigger
+// note that push triggers can't look further back than metrics TTL allows, the api rejects longer windows
+func (triggerChecker *TriggerChecker) handleSLOTrigger(checkData moira.CheckData) (moira.CheckData, error) {
+	var (
+		slo    = triggerChecker.trigger.SLO
+		metric = triggerChecker.trigger.Name
+		until  = triggerChecker.Until
+		from   = until - slo.GetPeriod()
+	)
+
+	for _, window := range slo.GetWindows() {
+		from = moira.MinI64(from, until-window.LongWindow)
+	}
+
+	good, err := triggerChecker.getSLOTimeSeries(slo.GoodTarget, from, until)
+	if err != nil {
+		return checkData, err
+	}
+	total, err := triggerChecker.getSLOTimeSeries(slo.TotalTarget, from, until)
+	if err != nil {
+		return checkData, err
+	}
+
+	evaluation := evaluateSLO(slo, good, total, until)
+	triggerChecker.logger.InfoE(
+		fmt.Sprintf("[TriggerID:%s] SLO evaluated", triggerChecker.TriggerID),
+		map[string]interface{}{
+			"trigger_id":       triggerChecker.TriggerID,
+			"from":             from,
+			"until":            until,
+			"state":            evaluation.State,
+			"has_data":         evaluation.HasData,
+			"budget_remaining": evaluation.BudgetRemaining,
+			"burn_rates":       evaluation.BurnRates,
+		},
+	)
+	if !evaluation.HasData {
+		return checkData, ErrTriggerHasNoTimeSeries{}
+	}
+
+	// previous metrics (e.g. named after the old trigger name) are not relevant anymore
+	lastState := triggerChecker.lastCheck.GetOrCreateMetricState(metric, from)
+	checkData.Metrics = make(map[string]*moira.MetricState, 1)
+
+	if triggerChecker.isHandleMetricDisabled(triggerChecker.CheckStarted, metric, "handleSLOTrigger") {
+		checkData.Metrics[metric] = lastState
+		return checkData, nil
+	}
+
+	currState := &moira.MetricState{
+		State:      evaluation.State,
+		Timestamp:  until,
+		Value:      &evaluation.MaxBurnRate,
+		Suppressed: lastState.Suppressed,
+		Message:    evaluation.message(),
+	}
+	newState, err := triggerChecker.compareStates(metric, currState, lastState, triggerChecker.forced[metric])
+	checkData.Metrics[metric] = newState
+
+	if triggerChecker.forced[metric] {
+		if err := triggerChecker.Database.DeleteTriggerForcedNotification(triggerChecker.TriggerID, metric); err != nil {
+			triggerChecker.logger.ErrorE("Could not delete forced notification for trigger", map[string]interface{}{
+				"TriggerID": triggerChecker.TriggerID,
+				"Error":     err.Error(),
+			})
+		}
+	}
+
+	return checkData, err
+}
+
+// getSLOTimeSeries fetches SLO target either from graphite or from DB, the expired values of the metrics are removed from DB
+func (triggerChecker *TriggerChecker) getSLOTimeSeries(tar string, from, until int64) ([]*target.TimeSeries, error) {
+	if triggerChecker.trigger.IsPullType {
+		return triggerChecker.pullRemoteTargets(from, until, []string{tar})
+	}
+
+	result, err := target.EvaluateTarget(triggerChecker.Database, tar, from, until, false)
+	if err != nil {
+		return nil, err
+	}
+	triggerChecker.cleanupMetrics(result.Metrics, triggerChecker.Until)
+	return result.TimeSeries, nil
+}
+
+// evaluateSLO calculates burn rates of all windows and the remaining error budget
+func evaluateSLO(slo *moira.SLOSettings, good, total []*target.TimeSeries, until int64) *sloEvaluation {
+	var (
+		budget  = slo.GetErrorBudget()
+		windows = slo.GetWindows()
+	)
+
+	evaluation := &sloEvaluation{
+		State:     moira.OK,
+		BurnRates: make([]sloBurnRate, 0, len(windows)),
+	}
+
+	burnRate := func(window int64) (float64, bool) {
+		totalSum, totalPoints := sumSeriesInWindow(total, until-window, until)
+		if totalPoints == 0 || totalSum <= 0 {
+			return 0, false
+		}
+		goodSum, _ := sumSeriesInWindow(good, until-window, until)
+		errorRatio := 1 - goodSum/totalSum
+		if errorRatio < 0 {
+			errorRatio = 0
+		}
+		if budget <= 0 {
+			return 0, true
+		}
+		return errorRatio / budget, true
+	}
+
+	for _, window := range windows {
+		longRate, hasLong := burnRate(window.LongWindow)
+		shortRate, hasShort := burnRate(window.ShortWindow)
+
+		result := sloBurnRate{
+			Alert:     window,
+			LongRate:  longRate,
+			ShortRate: shortRate,
+			HasData:   hasLong && hasShort,
+		}
+		evaluation.BurnRates = append(evaluation.BurnRates, result)
+
+		if hasLong {
+			evaluation.HasData = true
+			if longRate > evaluation.MaxBurnRate {
+				evaluation.MaxBurnRate = longRate
+			}
+		}
+		if result.isFiring() && stateScore(window.State) > stateScore(evaluation.State) {
+			evaluation.State = window.State
+		}
+	}
+
+	periodRate, hasPeriod := burnRate(slo.GetPeriod())
+	if hasPeriod {
+		evaluation.HasData = true
+		evaluation.BudgetRemaining = 1 - periodRate
+	}
+
+	return evaluation
+}
+
+// sumSeriesInWindow sums all valid values of all series which belong to (from, until]
+func sumSeriesInWindow(series []*target.TimeSeries, from, until int64) (sum float64, points int) {
+	for _, timeSeries := range series {
+		if timeSeries == nil || timeSeries.StepTime <= 0 {
+			continue
+		}
+		for i := range timeSeries.Values {
+			timestamp := int64(timeSeries.StartTime) + int64(i)*int64(timeSeries.StepTime)
+			if timestamp <= from || timestamp > until {
+				continue
+			}
+			value := timeSeries.GetTimestampValue(timestamp)
+			if IsInvalidValue(value) {
+				continue
+			}
+			sum += value
+			points++
+		}
+	}
+	return sum, points
+}
+
+// stateScore orders states by their severity
+func stateScore(state string) int {
+	switch state {
+	case moira.ERROR:
+		return 2
+	case moira.WARN:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// formatWindow formats window given in seconds in the shortest way, e.g. 3600 -> 1h
+func formatWindow(seconds int64) string {
+	switch {
+	case seconds > 0 && seconds%86400 == 0:
+		return fmt.Sprintf("%dd", seconds/86400)
+	case seconds > 0 && seconds%3600 == 0:
+		return fmt.Sprintf("%dh", seconds/3600)
+	case seconds > 0 && seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
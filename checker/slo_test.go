@@ -0,0 +1,145 @@
+package checker
+
+import (
+	"testing"
+
+	pb "github.com/go-graphite/carbonapi/carbonzipperpb3"
+	et "github.com/go-graphite/carbonapi/expr/types"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/target"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func newSLOTimeSeries(name string, start, step int32, values []float64) []*target.TimeSeries {
+	return []*target.TimeSeries{{
+		MetricData: et.MetricData{FetchResponse: pb.FetchResponse{
+			Name:      name,
+			StartTime: start,
+			StopTime:  start + step*int32(len(values)),
+			StepTime:  step,
+			Values:    values,
+			IsAbsent:  make([]bool, len(values)),
+		}},
+	}}
+}
+
+func repeatValue(value float64, qty int) []float64 {
+	result := make([]float64, qty)
+	for i := range result {
+		result[i] = value
+	}
+	return result
+}
+
+func TestEvaluateSLO(t *testing.T) {
+	const (
+		step  = 60
+		until = 3600
+	)
+
+	slo := &moira.SLOSettings{
+		GoodTarget:  "good",
+		TotalTarget: "total",
+		Objective:   99,
+		Windows: []moira.SLOBurnRateAlert{
+			{LongWindow: 3600, ShortWindow: 300, BurnRate: 10, State: moira.ERROR},
+			{LongWindow: 3600, ShortWindow: 600, BurnRate: 2, State: moira.WARN},
+		},
+	}
+	total := newSLOTimeSeries("total", step, step, repeatValue(100, 60))
+
+	Convey("All requests are good", t, func() {
+		good := newSLOTimeSeries("good", step, step, repeatValue(100, 60))
+		evaluation := evaluateSLO(slo, good, total, until)
+		So(evaluation.HasData, ShouldBeTrue)
+		So(evaluation.State, ShouldEqual, moira.OK)
+		So(evaluation.BudgetRemaining, ShouldEqual, 1)
+		So(evaluation.MaxBurnRate, ShouldEqual, 0)
+	})
+
+	Convey("Slow burn triggers WARN", t, func() {
+		// 3% of errors all the time: burn rate is 3
+		good := newSLOTimeSeries("good", step, step, repeatValue(97, 60))
+		evaluation := evaluateSLO(slo, good, total, until)
+		So(evaluation.State, ShouldEqual, moira.WARN)
+		So(evaluation.MaxBurnRate, ShouldAlmostEqual, 3, 0.0001)
+		So(evaluation.BudgetRemaining, ShouldAlmostEqual, -2, 0.0001)
+		So(evaluation.message(), ShouldContainSubstring, "Error budget remaining: -200.00%")
+	})
+
+	Convey("Fast burn in the short window only doesn't trigger ERROR", t, func() {
+		// errors only during the last 5 minutes
+		values := append(repeatValue(100, 55), repeatValue(50, 5)...)
+		good := newSLOTimeSeries("good", step, step, values)
+		evaluation := evaluateSLO(slo, good, total, until)
+		So(evaluation.BurnRates[0].ShortRate, ShouldAlmostEqual, 50, 0.0001)
+		So(evaluation.BurnRates[0].isFiring(), ShouldBeFalse)
+		So(evaluation.State, ShouldEqual, moira.WARN)
+	})
+
+	Convey("Fast burn in both windows triggers ERROR", t, func() {
+		good := newSLOTimeSeries("good", step, step, repeatValue(80, 60))
+		evaluation := evaluateSLO(slo, good, total, until)
+		So(evaluation.State, ShouldEqual, moira.ERROR)
+		So(evaluation.message(), ShouldContainSubstring, "burn rate 1h/5m: 20.00/20.00 (threshold 10) [ERROR]")
+	})
+
+	Convey("No total requests means no data", t, func() {
+		good := newSLOTimeSeries("good", step, step, repeatValue(0, 60))
+		evaluation := evaluateSLO(slo, good, newSLOTimeSeries("total", step, step, repeatValue(0, 60)), until)
+		So(evaluation.HasData, ShouldBeFalse)
+		So(evaluation.State, ShouldEqual, moira.OK)
+	})
+}
+
+func TestSLOSettings(t *testing.T) {
+	Convey("Default windows and period", t, func() {
+		slo := &moira.SLOSettings{Objective: 99.9}
+		So(slo.GetWindows(), ShouldResemble, moira.DefaultSLOBurnRateAlerts)
+		So(slo.GetPeriod(), ShouldEqual, 3*24*3600)
+		So(slo.GetErrorBudget(), ShouldAlmostEqual, 0.001, 0.0000001)
+	})
+
+	Convey("Window formatting", t, func() {
+		So(formatWindow(300), ShouldEqual, "5m")
+		So(formatWindow(7200), ShouldEqual, "2h")
+		So(formatWindow(3*86400), ShouldEqual, "3d")
+		So(formatWindow(45), ShouldEqual, "45s")
+	})
+}
+
+func TestGetSLOTimeSeries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	const (
+		pattern = "requests.good"
+		metric  = "requests.good"
+	)
+	triggerChecker := TriggerChecker{
+		TriggerID: "slo",
+		Config:    &Config{MetricsTTLSeconds: 3600},
+		Database:  dataBase,
+		Until:     7200,
+		logger:    test_helpers.GetTestLogger(),
+		trigger:   &moira.Trigger{SLO: &moira.SLOSettings{GoodTarget: pattern}},
+	}
+
+	Convey("Expired values of push metrics are removed", t, func() {
+		dataBase.EXPECT().GetPatternMetrics(pattern).Return([]string{metric}, nil)
+		dataBase.EXPECT().GetMetricRetention(metric).Return(int64(60), nil)
+		dataBase.EXPECT().GetMetricsValues([]string{metric}, int64(3600), int64(7200)).Return(map[string][]*moira.MetricValue{
+			metric: {{RetentionTimestamp: 7140, Timestamp: 7150, Value: 1}},
+		}, nil)
+		dataBase.EXPECT().RemoveMetricsValues([]string{metric}, int64(3600))
+
+		series, err := triggerChecker.getSLOTimeSeries(pattern, 3600, 7200)
+		So(err, ShouldBeNil)
+		So(series, ShouldHaveLength, 1)
+	})
+}
@@ -66,9 +66,8 @@ func (triggerChecker *TriggerChecker) getRemoteTimeSeries(from, until int64) (*t
 		Additional: make([]*target.TimeSeries, 0),
 	}
 
-	pullURL := triggerChecker.Config.PullURL
 	for i, tar := range triggerChecker.trigger.Targets {
-		timeseries, err := triggerChecker.PullRemote(pullURL, from, until, []string{tar}) // TODO pull all with one query
+		timeseries, err := triggerChecker.pullRemoteTargets(from, until, []string{tar}) // TODO pull all with one query
 		if err != nil {
 			return nil, err
 		}
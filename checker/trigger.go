@@ -25,14 +25,18 @@ type TriggerChecker struct {
 	CheckStarted int64
 	From, Until  int64
 
-	trigger     *moira.Trigger
-	ttl         int64
-	ttlState    string
-	lastCheck   *moira.CheckData
-	maintenance moira.Maintenance
+	trigger       *moira.Trigger
+	ttl           int64
+	ttlState      string
+	keepState     bool  // the metrics without data keep their last state
+	removeAfter   int64 // the metrics without data for longer than this (in seconds) are removed, never if zero
+	notifyRemoved bool  // the removal of metrics is notified, only the triggers with the nodata policy ask for it
+	lastCheck     *moira.CheckData
+	maintenance   moira.Maintenance
 
 	forced      map[string]bool    // set of metrics' forced notifications
 	eventsBatch *moira.EventsBatch // grouper for created moira.NotificationEvent`s
+	explanation *Explanation       // the steps of the check are added here if the check is explained
 }
 
 // ErrTriggerNotExists used if trigger to check does not exists
@@ -63,7 +67,11 @@ func (triggerChecker *TriggerChecker) InitTriggerChecker() error {
 	triggerChecker.trigger = trigger
 	triggerChecker.ttl = trigger.TTL
 
-	if trigger.TTLState != nil {
+	if trigger.NoData != nil {
+		triggerChecker.ttlState, triggerChecker.keepState = getNoDataState(trigger.NoData.Action)
+		triggerChecker.removeAfter = trigger.NoData.RemoveAfterMinutes * 60
+		triggerChecker.notifyRemoved = true
+	} else if trigger.TTLState != nil {
 		triggerChecker.ttlState = *trigger.TTLState
 	} else {
 		triggerChecker.ttlState = moira.NODATA
@@ -97,6 +105,11 @@ func (triggerChecker *TriggerChecker) InitTriggerChecker() error {
 	}
 	// all in all, triggerChecker.From <= lastCheck.Timestamp and triggerChecker.From <= now() - ttlGap
 
+	// forecast trigger needs the whole lookback window to fit the trend, the api keeps it within metrics TTL for push triggers
+	if trigger.IsForecast() {
+		triggerChecker.From = moira.MinI64(triggerChecker.From, triggerChecker.Until-trigger.Forecast.Lookback)
+	}
+
 	triggerChecker.logger = logging.GetLogger(triggerChecker.TriggerID)
 	triggerChecker.silencer = silencer.NewSilencer(triggerChecker.Database, nil)
 
@@ -129,3 +142,17 @@ func (triggerChecker *TriggerChecker) InitTriggerChecker() error {
 
 	return nil
 }
+
+// getNoDataState returns the state the metric without data goes to, or true if it keeps its last state
+func getNoDataState(action moira.NoDataAction) (string, bool) {
+	switch action {
+	case moira.NoDataActionOK:
+		return moira.OK, false
+	case moira.NoDataActionRemove:
+		return moira.DEL, false
+	case moira.NoDataActionKeep:
+		return moira.NODATA, true
+	default:
+		return moira.NODATA, false
+	}
+}
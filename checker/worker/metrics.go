@@ -1,38 +1,103 @@
 package worker
 
 import (
+	"sync/atomic"
 	"time"
 
 	"go.avito.ru/DO/moira"
 )
 
-func (worker *Checker) metricsChecker(metricEventsChannel <-chan *moira.MetricEvent) error {
+// metricEventsLagInterval is how often the lag of metric events is reported
+const metricEventsLagInterval = 10 * time.Second
+
+func (worker *Checker) metricsChecker(metricEventsChannel <-chan []*moira.MetricEvent) error {
 	for {
-		metricEvent, ok := <-metricEventsChannel
+		metricEvents, ok := <-metricEventsChannel
 		if !ok {
-			close(worker.triggersToCheck)
 			worker.Logger.Info("Checking for new events stopped")
 			return nil
 		}
-		if err := worker.handleMetricEvent(metricEvent); err != nil {
+		if err := worker.handleMetricEvents(metricEvents); err != nil {
+			// the events are not acknowledged, so they are delivered again
 			worker.Logger.ErrorF("Failed to handle metricEvent: %s", err.Error())
 		}
 	}
 }
 
-func (worker *Checker) handleMetricEvent(metricEvent *moira.MetricEvent) error {
-	worker.lastData = time.Now().UTC().Unix()
-	pattern := metricEvent.Pattern
-	triggerIds, err := worker.Database.GetPatternTriggerIDs(pattern)
-	if err != nil {
-		return err
+// ackMetricEventsWhenDone returns the callback which acknowledges the events when all the given checks are over
+// (or merged into the queued ones, or deferred), so the events of the checks which are lost with the checker
+// (e.g. on restart) are claimed by the other checkers
+func (worker *Checker) ackMetricEventsWhenDone(metricEvents []*moira.MetricEvent, checks int) func() {
+	ack := func() {
+		if err := worker.Database.AckMetricEvents(metricEvents); err != nil {
+			worker.Logger.ErrorF("Failed to acknowledge metric events: %s", err.Error())
+		}
 	}
-	// Cleanup pattern and its metrics if this pattern doesn't match to any trigger
-	if len(triggerIds) == 0 {
-		if err := worker.Database.RemovePatternWithMetrics(pattern); err != nil {
+	if checks == 0 {
+		ack()
+		return nil
+	}
+
+	pending := int32(checks)
+	return func() {
+		if atomic.AddInt32(&pending, -1) == 0 {
+			ack()
+		}
+	}
+}
+
+// handleMetricEvents coalesces the events: every trigger is checked once however many of its patterns got new data
+func (worker *Checker) handleMetricEvents(metricEvents []*moira.MetricEvent) error {
+	worker.lastData = time.Now().UTC().Unix()
+
+	patterns := make(map[string]bool, len(metricEvents))
+	triggerIDs := make([]string, 0, len(metricEvents))
+	seenTriggerIDs := make(map[string]bool, len(metricEvents))
+	for _, metricEvent := range metricEvents {
+		pattern := metricEvent.Pattern
+		if pattern == "" || patterns[pattern] {
+			continue
+		}
+		patterns[pattern] = true
+
+		patternTriggerIDs, err := worker.Database.GetPatternTriggerIDs(pattern)
+		if err != nil {
 			return err
 		}
+		// Cleanup pattern and its metrics if this pattern doesn't match to any trigger
+		if len(patternTriggerIDs) == 0 {
+			if err := worker.Database.RemovePatternWithMetrics(pattern); err != nil {
+				return err
+			}
+		}
+		for _, triggerID := range patternTriggerIDs {
+			if !seenTriggerIDs[triggerID] {
+				seenTriggerIDs[triggerID] = true
+				triggerIDs = append(triggerIDs, triggerID)
+			}
+		}
 	}
-	worker.perform(triggerIds, worker.Config.CheckInterval, false)
+	worker.perform(triggerIDs, worker.Config.CheckInterval, false, worker.ackMetricEventsWhenDone(metricEvents, len(triggerIDs)))
 	return nil
 }
+
+// metricEventsLagReporter sends the lag of the checkers behind the filter to metrics
+func (worker *Checker) metricEventsLagReporter() error {
+	reportTicker := time.NewTicker(metricEventsLagInterval)
+	for {
+		select {
+		case <-worker.tomb.Dying():
+			reportTicker.Stop()
+			worker.Logger.Info("Metric events lag reporter stopped")
+			return nil
+		case <-reportTicker.C:
+			lag, err := worker.Database.GetMetricEventsLag()
+			if err != nil {
+				worker.Logger.ErrorF("Failed to get metric events lag: %s", err.Error())
+				continue
+			}
+			worker.Metrics.MetricEventsPending.Histogram(lag.Pending)
+			worker.Metrics.MetricEventsLag.Timing(int64(lag.Lag))
+		}
+	}
+}
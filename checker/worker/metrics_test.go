@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestAckMetricEventsWhenDone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	worker := &Checker{Database: dataBase, Logger: test_helpers.GetTestLogger()}
+	metricEvents := []*moira.MetricEvent{{Pattern: "my.metric.*", StreamID: "1-0"}}
+
+	Convey("Events are acknowledged when all their checks are over", t, func() {
+		done := worker.ackMetricEventsWhenDone(metricEvents, 2)
+		done()
+
+		dataBase.EXPECT().AckMetricEvents(metricEvents).Return(nil)
+		done()
+	})
+
+	Convey("Events without checks are acknowledged at once", t, func() {
+		dataBase.EXPECT().AckMetricEvents(metricEvents).Return(nil)
+		So(worker.ackMetricEventsWhenDone(metricEvents, 0), ShouldBeNil)
+	})
+}
@@ -30,7 +30,7 @@ func (worker *Checker) checkNoData() error {
 		if err != nil {
 			return err
 		}
-		worker.perform(triggerIds, time.Minute, false)
+		worker.perform(triggerIds, time.Minute, false, nil)
 	}
 	return nil
 }
@@ -5,18 +5,50 @@ import (
 	"runtime/debug"
 	"time"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/checker"
 )
 
-func (worker *Checker) perform(triggerIDs []string, cacheTTL time.Duration, isPullType bool) {
+// perform schedules the checks of the triggers, the check interval of the trigger overrides the given default one.
+// The trigger checked recently is scheduled to the end of its interval, onDone doesn't wait for such a deferred check:
+// the interval is not limited, so the metric events would be claimed and delivered again while waiting for it
+func (worker *Checker) perform(triggerIDs []string, defaultInterval time.Duration, isPullType bool, onDone func()) {
+	scheduler := worker.scheduler
+	if isPullType {
+		scheduler = worker.pullScheduler
+	}
+
+	now := time.Now()
+	schedules := worker.getTriggerSchedules(triggerIDs)
 	for _, triggerID := range triggerIDs {
-		if worker.needHandleTrigger(triggerID, cacheTTL) {
-			if isPullType {
-				worker.pullTriggersToCheck <- triggerID
-			} else {
-				worker.triggersToCheck <- triggerID
+		check := &scheduledCheck{
+			TriggerID: triggerID,
+			Priority:  moira.TriggerPriorityNormal,
+			Interval:  defaultInterval,
+			Due:       now,
+		}
+		if schedule, ok := schedules[triggerID]; ok {
+			check.Priority = schedule.Priority
+			if schedule.Interval > 0 {
+				check.Interval = schedule.Interval
 			}
 		}
+		deferred := false
+		if _, expiration, found := worker.Cache.GetWithExpiration(triggerID); found && expiration.After(now) {
+			check.Due = expiration
+			deferred = true
+		}
+		if onDone != nil && !deferred {
+			check.OnDone = []func(){onDone}
+		}
+
+		if shed := scheduler.schedule(check); shed != nil {
+			worker.Metrics.CheckQueueOverflow[shed.Priority].Increment()
+			shed.finish()
+		}
+		if onDone != nil && deferred {
+			onDone()
+		}
 	}
 }
 
@@ -39,13 +71,19 @@ func (worker *Checker) needHandleTrigger(triggerID string, cacheTTL time.Duratio
 	return locked
 }
 
-func (worker *Checker) triggerHandler(ch <-chan string) error {
+func (worker *Checker) triggerHandler(scheduler *checkScheduler) error {
 	for {
-		triggerID, ok := <-ch
+		check, ok := scheduler.next(worker.tomb.Dying())
 		if !ok {
 			return nil
 		}
-		worker.handle(triggerID)
+		if !worker.needHandleTrigger(check.TriggerID, check.Interval) {
+			check.finish()
+			continue
+		}
+		worker.Metrics.CheckLateness[check.Priority].Timing(int64(time.Since(check.Due)))
+		worker.handle(check.TriggerID)
+		check.finish()
 	}
 }
 
@@ -0,0 +1,280 @@
+package worker
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"go.avito.ru/DO/moira"
+)
+
+const (
+	defaultCheckQueueSize = 10000
+
+	// queueReportInterval is how often the depth of the queues of the checks is reported
+	queueReportInterval = 10 * time.Second
+	// triggerScheduleCacheTTL is how long the priority and the interval of the trigger are cached
+	triggerScheduleCacheTTL = time.Minute
+)
+
+// scheduledCheck is the check of the trigger waiting in the queue
+type scheduledCheck struct {
+	TriggerID string
+	Priority  moira.TriggerPriority
+	Interval  time.Duration // the trigger is checked not more often than this
+	Due       time.Time     // the check doesn't start before this time
+	OnDone    []func()      // called when the check is over or shed, e.g. to acknowledge the metric events
+
+	index int // position in the heap of its priority
+}
+
+// checkHeap orders the checks of the same priority by due time
+type checkHeap []*scheduledCheck
+
+func (h checkHeap) Len() int           { return len(h) }
+func (h checkHeap) Less(i, j int) bool { return h[i].Due.Before(h[j].Due) }
+func (h checkHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *checkHeap) Push(x interface{}) {
+	check := x.(*scheduledCheck)
+	check.index = len(*h)
+	*h = append(*h, check)
+}
+
+func (h *checkHeap) Pop() interface{} {
+	old := *h
+	check := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return check
+}
+
+// checkScheduler is the queue of the checks of the triggers: the due checks of higher priority go first,
+// the checks of the same priority go in order of their due time. Low priority checks are deferred while
+// there are due checks of higher priority and shed when the queue is full
+type checkScheduler struct {
+	mutex    sync.Mutex
+	levels   []checkHeap // by the levels of the priorities
+	checks   map[string]*scheduledCheck
+	capacity int
+	wakeup   chan struct{}
+}
+
+func newCheckScheduler(capacity int) *checkScheduler {
+	if capacity <= 0 {
+		capacity = defaultCheckQueueSize
+	}
+	return &checkScheduler{
+		levels:   make([]checkHeap, len(moira.TriggerPriorities)),
+		checks:   make(map[string]*scheduledCheck),
+		capacity: capacity,
+		wakeup:   make(chan struct{}, 1),
+	}
+}
+
+// schedule adds the check to the queue, the check of the trigger which is queued already is moved to the earlier time.
+// The ones waiting for the merged check (e.g. the metric events) wait for the queued one as it reads the same data,
+// unless the queued check is deferred: then the merged check is over at once, as perform does for the deferred checks.
+// If the queue is full the queued check of lower priority is shed, otherwise the check itself is shed.
+// The shed check is returned
+func (scheduler *checkScheduler) schedule(check *scheduledCheck) *scheduledCheck {
+	shed, deferred := scheduler.enqueue(check, time.Now())
+	if deferred {
+		check.finish()
+	}
+	return shed
+}
+
+// enqueue does the job of schedule under the lock, it tells true if the check is merged into the deferred one
+func (scheduler *checkScheduler) enqueue(check *scheduledCheck, now time.Time) (*scheduledCheck, bool) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	defer scheduler.notify()
+
+	if queued, ok := scheduler.checks[check.TriggerID]; ok {
+		queued.Interval = check.Interval
+		if check.Due.Before(queued.Due) {
+			queued.Due = check.Due
+			heap.Fix(&scheduler.levels[getPriorityLevel(queued.Priority)], queued.index)
+		}
+		if queued.Due.After(now) {
+			return nil, true
+		}
+		queued.OnDone = append(queued.OnDone, check.OnDone...)
+		return nil, false
+	}
+
+	var shed *scheduledCheck
+	level := getPriorityLevel(check.Priority)
+	if len(scheduler.checks) >= scheduler.capacity {
+		lowest := 0
+		for lowest < len(scheduler.levels) && scheduler.levels[lowest].Len() == 0 {
+			lowest++
+		}
+		if lowest >= level {
+			return check, false
+		}
+		// the last check of the heap is due not earlier than its parent
+		lowestHeap := &scheduler.levels[lowest]
+		shed = heap.Remove(lowestHeap, lowestHeap.Len()-1).(*scheduledCheck)
+		delete(scheduler.checks, shed.TriggerID)
+	}
+
+	heap.Push(&scheduler.levels[level], check)
+	scheduler.checks[check.TriggerID] = check
+	return shed, false
+}
+
+// finish tells the ones waiting for the check that it is over
+func (check *scheduledCheck) finish() {
+	for _, done := range check.OnDone {
+		done()
+	}
+}
+
+// next waits for the due check and takes it out of the queue, false is returned if dying is closed
+func (scheduler *checkScheduler) next(dying <-chan struct{}) (*scheduledCheck, bool) {
+	for {
+		scheduler.mutex.Lock()
+		check, wait := scheduler.pop(time.Now())
+		if check != nil && len(scheduler.checks) > 0 {
+			// let the other handlers take the rest
+			scheduler.notify()
+		}
+		scheduler.mutex.Unlock()
+		if check != nil {
+			return check, true
+		}
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+		select {
+		case <-dying:
+			return nil, false
+		case <-scheduler.wakeup:
+		case <-timer:
+		}
+	}
+}
+
+// pop takes out the due check of the highest priority,
+// if there is no one the time until the earliest check is returned (zero if the queue is empty)
+func (scheduler *checkScheduler) pop(now time.Time) (*scheduledCheck, time.Duration) {
+	var earliest time.Time
+	for level := len(scheduler.levels) - 1; level >= 0; level-- {
+		levelHeap := &scheduler.levels[level]
+		if levelHeap.Len() == 0 {
+			continue
+		}
+		due := (*levelHeap)[0].Due
+		if !due.After(now) {
+			check := heap.Pop(levelHeap).(*scheduledCheck)
+			delete(scheduler.checks, check.TriggerID)
+			return check, 0
+		}
+		if earliest.IsZero() || due.Before(earliest) {
+			earliest = due
+		}
+	}
+	if earliest.IsZero() {
+		return nil, 0
+	}
+	return nil, earliest.Sub(now)
+}
+
+// getDepth returns the count of the queued checks by priorities
+func (scheduler *checkScheduler) getDepth() map[moira.TriggerPriority]int {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	depth := make(map[moira.TriggerPriority]int, len(scheduler.levels))
+	for level, levelHeap := range scheduler.levels {
+		depth[moira.TriggerPriorities[level]] = levelHeap.Len()
+	}
+	return depth
+}
+
+func (scheduler *checkScheduler) notify() {
+	select {
+	case scheduler.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func getPriorityLevel(priority moira.TriggerPriority) int {
+	level := priority.Level()
+	if level < 0 {
+		return moira.TriggerPriorityNormal.Level()
+	}
+	return level
+}
+
+// triggerSchedule is the part of the trigger which is needed to schedule its checks
+type triggerSchedule struct {
+	Priority moira.TriggerPriority
+	Interval time.Duration
+}
+
+// getTriggerSchedules returns the priorities and the intervals of the triggers, they are cached for a while
+func (worker *Checker) getTriggerSchedules(triggerIDs []string) map[string]*triggerSchedule {
+	schedules := make(map[string]*triggerSchedule, len(triggerIDs))
+	missing := make([]string, 0)
+	for _, triggerID := range triggerIDs {
+		if cached, ok := worker.Cache.Get(triggerScheduleCacheKey(triggerID)); ok {
+			schedules[triggerID] = cached.(*triggerSchedule)
+		} else {
+			missing = append(missing, triggerID)
+		}
+	}
+	if len(missing) == 0 {
+		return schedules
+	}
+
+	triggers, err := worker.Database.GetTriggers(missing)
+	if err != nil {
+		// the triggers are scheduled with the defaults this time
+		worker.Logger.ErrorF("Failed to get triggers to schedule checks: %s", err.Error())
+		return schedules
+	}
+	for i, trigger := range triggers {
+		schedule := &triggerSchedule{Priority: moira.TriggerPriorityNormal}
+		if trigger != nil {
+			schedule.Priority = trigger.GetPriority()
+			schedule.Interval = time.Duration(trigger.CheckInterval) * time.Second
+		}
+		schedules[missing[i]] = schedule
+		worker.Cache.Set(triggerScheduleCacheKey(missing[i]), schedule, triggerScheduleCacheTTL)
+	}
+	return schedules
+}
+
+// queueReporter sends the depth of the queues of the checks to metrics
+func (worker *Checker) queueReporter() error {
+	reportTicker := time.NewTicker(queueReportInterval)
+	for {
+		select {
+		case <-worker.tomb.Dying():
+			reportTicker.Stop()
+			worker.Logger.Info("Check queue reporter stopped")
+			return nil
+		case <-reportTicker.C:
+			depth := worker.scheduler.getDepth()
+			for priority, pullDepth := range worker.pullScheduler.getDepth() {
+				depth[priority] += pullDepth
+			}
+			for priority, count := range depth {
+				worker.Metrics.CheckQueueDepth[priority].Histogram(int64(count))
+			}
+		}
+	}
+}
+
+func triggerScheduleCacheKey(triggerID string) string {
+	return "trigger-schedule:" + triggerID
+}
@@ -0,0 +1,187 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/patrickmn/go-cache"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/checker"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestCheckScheduler(t *testing.T) {
+	now := time.Now()
+	newCheck := func(triggerID string, priority moira.TriggerPriority, due time.Time) *scheduledCheck {
+		return &scheduledCheck{TriggerID: triggerID, Priority: priority, Interval: time.Minute, Due: due}
+	}
+	popAll := func(scheduler *checkScheduler, at time.Time) []string {
+		result := make([]string, 0)
+		for {
+			check, _ := scheduler.pop(at)
+			if check == nil {
+				return result
+			}
+			result = append(result, check.TriggerID)
+		}
+	}
+
+	Convey("Due checks of higher priority go first", t, func() {
+		scheduler := newCheckScheduler(10)
+		scheduler.schedule(newCheck("low", moira.TriggerPriorityLow, now.Add(-time.Minute)))
+		scheduler.schedule(newCheck("normal-late", moira.TriggerPriorityNormal, now))
+		scheduler.schedule(newCheck("normal-early", moira.TriggerPriorityNormal, now.Add(-time.Second)))
+		scheduler.schedule(newCheck("high", moira.TriggerPriorityHigh, now))
+		scheduler.schedule(newCheck("high-future", moira.TriggerPriorityHigh, now.Add(time.Minute)))
+
+		So(scheduler.getDepth(), ShouldResemble, map[moira.TriggerPriority]int{
+			moira.TriggerPriorityLow:    1,
+			moira.TriggerPriorityNormal: 2,
+			moira.TriggerPriorityHigh:   2,
+		})
+		So(popAll(scheduler, now), ShouldResemble, []string{"high", "normal-early", "normal-late", "low"})
+
+		check, wait := scheduler.pop(now)
+		So(check, ShouldBeNil)
+		So(wait, ShouldEqual, time.Minute)
+		So(popAll(scheduler, now.Add(time.Minute)), ShouldResemble, []string{"high-future"})
+
+		check, wait = scheduler.pop(now)
+		So(check, ShouldBeNil)
+		So(wait, ShouldEqual, 0)
+	})
+
+	Convey("Queued check is moved to the earlier time", t, func() {
+		scheduler := newCheckScheduler(10)
+		So(scheduler.schedule(newCheck("first", moira.TriggerPriorityNormal, now)), ShouldBeNil)
+		So(scheduler.schedule(newCheck("second", moira.TriggerPriorityNormal, now.Add(time.Minute))), ShouldBeNil)
+		So(scheduler.schedule(newCheck("second", moira.TriggerPriorityNormal, now.Add(-time.Minute))), ShouldBeNil)
+		So(scheduler.schedule(newCheck("first", moira.TriggerPriorityNormal, now.Add(time.Hour))), ShouldBeNil)
+
+		So(popAll(scheduler, now), ShouldResemble, []string{"second", "first"})
+	})
+
+	Convey("Merged check is over along with the queued one", t, func() {
+		scheduler := newCheckScheduler(10)
+		firstDone, secondDone := 0, 0
+		first, second := newCheck("trigger", moira.TriggerPriorityNormal, now.Add(time.Hour)), newCheck("trigger", moira.TriggerPriorityNormal, now)
+		first.OnDone = []func(){func() { firstDone++ }}
+		second.OnDone = []func(){func() { secondDone++ }}
+		So(scheduler.schedule(first), ShouldBeNil)
+		So(scheduler.schedule(second), ShouldBeNil)
+		So(firstDone, ShouldEqual, 0)
+		So(secondDone, ShouldEqual, 0)
+
+		check, _ := scheduler.pop(now)
+		So(check.OnDone, ShouldHaveLength, 2)
+		check.finish()
+		So(firstDone, ShouldEqual, 1)
+		So(secondDone, ShouldEqual, 1)
+	})
+
+	Convey("Check merged into the deferred one is over at once", t, func() {
+		scheduler := newCheckScheduler(10)
+		firstDone, secondDone := 0, 0
+		first, second := newCheck("trigger", moira.TriggerPriorityNormal, now.Add(time.Hour)), newCheck("trigger", moira.TriggerPriorityNormal, now.Add(2*time.Hour))
+		first.OnDone = []func(){func() { firstDone++ }}
+		second.OnDone = []func(){func() { secondDone++ }}
+		So(scheduler.schedule(first), ShouldBeNil)
+		So(scheduler.schedule(second), ShouldBeNil)
+		So(firstDone, ShouldEqual, 0)
+		So(secondDone, ShouldEqual, 1)
+
+		check, _ := scheduler.pop(now.Add(time.Hour))
+		So(check.OnDone, ShouldHaveLength, 1)
+		check.finish()
+		So(firstDone, ShouldEqual, 1)
+		So(secondDone, ShouldEqual, 1)
+	})
+
+	Convey("Checks of the lowest priority are shed when the queue is full", t, func() {
+		scheduler := newCheckScheduler(2)
+		So(scheduler.schedule(newCheck("low", moira.TriggerPriorityLow, now)), ShouldBeNil)
+		So(scheduler.schedule(newCheck("normal", moira.TriggerPriorityNormal, now)), ShouldBeNil)
+
+		shed := scheduler.schedule(newCheck("another-low", moira.TriggerPriorityLow, now))
+		So(shed.TriggerID, ShouldEqual, "another-low")
+		shed = scheduler.schedule(newCheck("high", moira.TriggerPriorityHigh, now))
+		So(shed.TriggerID, ShouldEqual, "low")
+		shed = scheduler.schedule(newCheck("another-normal", moira.TriggerPriorityNormal, now))
+		So(shed.TriggerID, ShouldEqual, "another-normal")
+
+		So(popAll(scheduler, now), ShouldResemble, []string{"high", "normal"})
+	})
+
+	Convey("Waiting handler is woken up", t, func() {
+		scheduler := newCheckScheduler(10)
+		dying := make(chan struct{})
+		result := make(chan string)
+		go func() {
+			for {
+				check, ok := scheduler.next(dying)
+				if !ok {
+					close(result)
+					return
+				}
+				result <- check.TriggerID
+			}
+		}()
+
+		scheduler.schedule(newCheck("soon", moira.TriggerPriorityNormal, time.Now().Add(50*time.Millisecond)))
+		scheduler.schedule(newCheck("now", moira.TriggerPriorityLow, time.Now()))
+		So(<-result, ShouldEqual, "now")
+		So(<-result, ShouldEqual, "soon")
+
+		close(dying)
+		_, ok := <-result
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestPerform(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	worker := &Checker{
+		Database:      dataBase,
+		Logger:        test_helpers.GetTestLogger(),
+		Config:        &checker.Config{CheckInterval: 10 * time.Second, PullInterval: time.Minute},
+		Cache:         cache.New(time.Minute, time.Minute),
+		scheduler:     newCheckScheduler(10),
+		pullScheduler: newCheckScheduler(10),
+	}
+
+	Convey("Pull triggers are checked by their own intervals", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{"default", "frequent"}).Return([]*moira.Trigger{
+			{ID: "default", IsPullType: true},
+			{ID: "frequent", IsPullType: true, CheckInterval: 20},
+		}, nil)
+		worker.perform([]string{"default", "frequent"}, worker.Config.PullInterval, true, nil)
+
+		intervals := make(map[string]time.Duration)
+		for {
+			check, _ := worker.pullScheduler.pop(time.Now())
+			if check == nil {
+				break
+			}
+			intervals[check.TriggerID] = check.Interval
+		}
+		So(intervals, ShouldResemble, map[string]time.Duration{"default": time.Minute, "frequent": 20 * time.Second})
+	})
+
+	Convey("Deferred check doesn't hold the metric events", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{"rare"}).Return([]*moira.Trigger{{ID: "rare", CheckInterval: 3600}}, nil)
+		worker.Cache.Set("rare", true, time.Hour)
+		done := 0
+		worker.perform([]string{"rare"}, worker.Config.CheckInterval, false, func() { done++ })
+		So(done, ShouldEqual, 1)
+
+		check, wait := worker.scheduler.pop(time.Now())
+		So(check, ShouldBeNil)
+		So(wait, ShouldBeGreaterThan, 59*time.Minute)
+	})
+}
@@ -20,10 +20,10 @@ type Checker struct {
 	Metrics  *metrics.CheckerMetrics
 	Cache    *cache.Cache
 
-	lastData            int64
-	triggersToCheck     chan string
-	pullTriggersToCheck chan string
-	tagsToCheck         chan string
+	lastData      int64
+	scheduler     *checkScheduler
+	pullScheduler *checkScheduler
+	tagsToCheck   chan string
 
 	tomb tomb.Tomb
 }
@@ -35,8 +35,8 @@ func (worker *Checker) Start() error {
 	}
 
 	worker.lastData = time.Now().UTC().Unix()
-	worker.triggersToCheck = make(chan string, 100)
-	worker.pullTriggersToCheck = make(chan string, 100)
+	worker.scheduler = newCheckScheduler(worker.Config.CheckQueueSize)
+	worker.pullScheduler = newCheckScheduler(worker.Config.CheckQueueSize)
 	worker.tagsToCheck = make(chan string, 100)
 
 	metricEventsChannel, err := worker.Database.SubscribeMetricEvents(&worker.tomb)
@@ -50,6 +50,8 @@ func (worker *Checker) Start() error {
 	worker.tomb.Go(func() error {
 		return worker.metricsChecker(metricEventsChannel)
 	})
+	worker.tomb.Go(worker.metricEventsLagReporter)
+	worker.tomb.Go(worker.queueReporter)
 	worker.tomb.Go(worker.checkPullTriggers)
 	worker.Logger.Info("Checking remote triggers started")
 
@@ -58,12 +60,12 @@ func (worker *Checker) Start() error {
 
 	for i := 0; i < worker.Config.MaxParallelChecks; i++ {
 		worker.tomb.Go(func() error {
-			return worker.triggerHandler(worker.triggersToCheck)
+			return worker.triggerHandler(worker.scheduler)
 		})
 	}
 	for i := 0; i < worker.Config.MaxParallelPullChecks; i++ {
 		worker.tomb.Go(func() error {
-			return worker.triggerHandler(worker.pullTriggersToCheck)
+			return worker.triggerHandler(worker.pullScheduler)
 		})
 	}
 	for i := 0; i < worker.Config.MaxParallelTagsChecks; i++ {
@@ -80,12 +82,20 @@ func (worker *Checker) Start() error {
 	return nil
 }
 
+// pullScheduleInterval is how often the pull triggers are scheduled, every trigger is checked as often as its own
+// interval allows (pull interval by default), so the intervals shorter than the pull interval are honored as well
+const pullScheduleInterval = 10 * time.Second
+
 func (worker *Checker) checkPullTriggers() error {
-	checkTicker := time.NewTicker(worker.Config.PullInterval)
+	scheduleInterval := pullScheduleInterval
+	if worker.Config.PullInterval < scheduleInterval {
+		scheduleInterval = worker.Config.PullInterval
+	}
+	checkTicker := time.NewTicker(scheduleInterval)
 	for {
 		select {
 		case <-worker.tomb.Dying():
-			close(worker.pullTriggersToCheck)
+			checkTicker.Stop()
 			worker.Logger.Info("pull checker stopped")
 			return nil
 		case <-checkTicker.C:
@@ -95,8 +105,8 @@ func (worker *Checker) checkPullTriggers() error {
 				continue
 			}
 
-			worker.Logger.InfoF("pulled %d trigger(s)", len(triggerIDs))
-			worker.perform(triggerIDs, worker.Config.CheckInterval, true)
+			worker.Logger.DebugF("pulled %d trigger(s)", len(triggerIDs))
+			worker.perform(triggerIDs, worker.Config.PullInterval, true, nil)
 		}
 	}
 }
@@ -1,68 +1,80 @@
 package main
 
 import (
-	"strings"
+	"fmt"
 
 	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/cmd"
 )
 
 type config struct {
-	API      apiConfig          `yaml:"api"`
-	Redis    cmd.RedisConfig    `yaml:"redis"`
-	Neo4j    cmd.Neo4jConfig    `yaml:"neo4j"`
-	Liveness cmd.LivenessConfig `yaml:"liveness"`
-	Logger   cmd.LoggerConfig   `yaml:"log"`
-	Netbox   cmd.NetboxConfig   `yaml:"netbox"`
-	Pprof    cmd.ProfilerConfig `yaml:"pprof"`
-	Rsyslog  cmd.RsyslogConfig  `yaml:"rsyslog"`
-	Statsd   cmd.StatsdConfig   `yaml:"statsd"`
+	API       apiConfig           `yaml:"api"`
+	Redis     cmd.RedisConfig     `yaml:"redis"`
+	Neo4j     cmd.Neo4jConfig     `yaml:"neo4j"`
+	Liveness  cmd.LivenessConfig  `yaml:"liveness"`
+	Logger    cmd.LoggerConfig    `yaml:"log"`
+	Netbox    cmd.NetboxConfig    `yaml:"netbox"`
+	Inventory cmd.InventoryConfig `yaml:"inventory"`
+	Pprof     cmd.ProfilerConfig  `yaml:"pprof"`
+	Rsyslog   cmd.RsyslogConfig   `yaml:"rsyslog"`
+	Statsd    cmd.StatsdConfig    `yaml:"statsd"`
 }
 
 type apiConfig struct {
-	Listen             string           `yaml:"listen"`
-	EnableCORS         bool             `yaml:"enable_cors"`
-	GrafanaPrefixes    []string         `yaml:"grafana_prefixes"`
-	LimitLogger        cmd.RateLimit    `yaml:"limit_logger"`
-	LimitMetrics       cmd.RateLimit    `yaml:"limit_metrics"`
-	Sentry             cmd.SentryConfig `yaml:"sentry"`
-	SuperUsers         []string         `yaml:"super_users"`
-	TargetRewriteRules []rewriteRule    `yaml:"target_rewrite"`
-	WebConfigPath      string           `yaml:"web_config_path"`
+	Access            cmd.AccessConfig            `yaml:",inline"`
+	Listen            string                      `yaml:"listen"`
+	EnableCORS        bool                        `yaml:"enable_cors"`
+	LimitLogger       cmd.RateLimit               `yaml:"limit_logger"`
+	LimitMetrics      cmd.RateLimit               `yaml:"limit_metrics"`
+	Sentry            cmd.SentryConfig            `yaml:"sentry"`
+	Slack             slackConfig                 `yaml:"slack"`
+	TriggerValidation cmd.TriggerValidationConfig `yaml:",inline"`
+	WebConfigPath     string                      `yaml:"web_config_path"`
 }
 
-type rewriteRule struct {
-	From string `yaml:"from"`
-	To   string `yaml:"to"`
+type slackConfig struct {
+	APIToken      string            `yaml:"api_token"` // path to the file with token
+	FrontURI      string            `yaml:"front_uri"`
+	SigningSecret string            `yaml:"signing_secret"` // path to the file with signing secret
+	Users         map[string]string `yaml:"users"`
 }
 
-func (config *apiConfig) getSettings() *api.Config {
-	rewriteRules := make([]api.RewriteRule, len(config.TargetRewriteRules))
-	for i, rule := range config.TargetRewriteRules {
-		rewriteRules[i] = api.RewriteRule{
-			From: rule.From,
-			To:   rule.To,
-		}
+// getSettings reads the token and the signing secret, the files must be readable if they are set
+func (config *slackConfig) getSettings() (api.SlackConfig, error) {
+	apiToken, err := cmd.ReadSecret(config.APIToken)
+	if err != nil {
+		return api.SlackConfig{}, fmt.Errorf("failed to read api token file: %v", err)
 	}
+	signingSecret, err := cmd.ReadSecret(config.SigningSecret)
+	if err != nil {
+		return api.SlackConfig{}, fmt.Errorf("failed to read signing secret file: %v", err)
+	}
+	return api.SlackConfig{
+		APIToken:      apiToken,
+		FrontURI:      config.FrontURI,
+		SigningSecret: signingSecret,
+		Users:         config.Users,
+	}, nil
+}
 
-	grafanaPrefixes := make([]string, 0, len(config.GrafanaPrefixes))
-	for i := 0; i < len(config.GrafanaPrefixes); i++ {
-		prefix := strings.TrimSpace(config.GrafanaPrefixes[i])
-		if prefix != "" {
-			grafanaPrefixes = append(grafanaPrefixes, prefix)
-		}
+func (config *apiConfig) getSettings() (*api.Config, error) {
+	authorization, err := config.Access.Authorization.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization: %v", err)
 	}
 
-	return &api.Config{
-		EnableCORS:         config.EnableCORS,
-		GrafanaPrefixes:    grafanaPrefixes,
-		Listen:             config.Listen,
-		LimitLogger:        config.LimitLogger.GetSettings(),
-		LimitMetrics:       config.LimitMetrics.GetSettings(),
-		Sentry:             config.Sentry.GetSettings(),
-		SuperUsers:         config.SuperUsers,
-		TargetRewriteRules: rewriteRules,
+	settings := config.TriggerValidation.GetSettings()
+	settings.Authorization = authorization
+	settings.EnableCORS = config.EnableCORS
+	settings.Listen = config.Listen
+	settings.LimitLogger = config.LimitLogger.GetSettings()
+	settings.LimitMetrics = config.LimitMetrics.GetSettings()
+	settings.Sentry = config.Sentry.GetSettings()
+	if settings.Slack, err = config.Slack.getSettings(); err != nil {
+		return nil, fmt.Errorf("invalid slack: %v", err)
 	}
+	settings.SuperUsers = config.Access.SuperUsers
+	return &settings, nil
 }
 
 func getDefault() config {
@@ -84,18 +96,22 @@ func getDefault() config {
 			LogLevel: "debug",
 		},
 		API: apiConfig{
-			Listen:          ":8081",
-			LimitLogger:     cmd.NewDefaultLoggerRateLimit(),
-			LimitMetrics:    cmd.NewDefaultMetricsRateLimit(),
-			WebConfigPath:   "/etc/moira/web.json",
-			EnableCORS:      false,
-			GrafanaPrefixes: []string{},
+			Listen:        ":8081",
+			LimitLogger:   cmd.NewDefaultLoggerRateLimit(),
+			LimitMetrics:  cmd.NewDefaultMetricsRateLimit(),
+			WebConfigPath: "/etc/moira/web.json",
+			EnableCORS:    false,
 			Sentry: cmd.SentryConfig{
 				Dsn:     "",
 				Enabled: false,
 			},
-			SuperUsers:         []string{},
-			TargetRewriteRules: []rewriteRule{},
+			Slack: slackConfig{
+				Users: map[string]string{},
+			},
+			Access: cmd.AccessConfig{
+				SuperUsers: []string{},
+			},
+			TriggerValidation: cmd.NewDefaultTriggerValidationConfig(),
 		},
 		Pprof: cmd.ProfilerConfig{
 			Listen: "",
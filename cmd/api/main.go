@@ -21,6 +21,7 @@ import (
 	"go.avito.ru/DO/moira/metrics"
 	"go.avito.ru/DO/moira/panicwrap"
 	"go.avito.ru/DO/moira/sentry"
+	"go.avito.ru/DO/moira/silencer"
 )
 
 const (
@@ -65,8 +66,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	apiConfig := config.API.getSettings()
-	apiConfig.Netbox = config.Netbox.GetSettings()
+	apiConfig, err := config.API.getSettings()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure api: %v\n", err)
+		os.Exit(1)
+	}
+	apiConfig.Inventory, err = config.Inventory.NewProvider(config.Netbox)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure inventory provider: %v\n", err)
+		os.Exit(1)
+	}
+	silencer.SetBlacklist(config.Inventory.Blacklist)
 
 	if err = metrics.Init(config.Statsd.GetSettings(), apiConfig.LimitMetrics); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Can not configure metrics: %v\n", err)
@@ -102,8 +112,14 @@ func main() {
 		cmd.StartLiveness(logger, config.Liveness)
 	}
 
-	databaseSettings := config.Redis.GetSettings()
-	database := redis.NewDatabase(logger, databaseSettings)
+	databaseSettings, err := config.Redis.GetSettings()
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
+	database, err := redis.NewDatabase(logger, databaseSettings)
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
 
 	neo4jDb, err := neo4j.NewDatabase(logger, config.Neo4j)
 	if err != nil {
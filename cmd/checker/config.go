@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/gosexy/to"
@@ -10,34 +11,80 @@ import (
 )
 
 type config struct {
-	Checker  checkerConfig      `yaml:"checker"`
-	Redis    cmd.RedisConfig    `yaml:"redis"`
-	Neo4j    cmd.Neo4jConfig    `yaml:"neo4j"`
-	Logger   cmd.LoggerConfig   `yaml:"log"`
-	Netbox   cmd.NetboxConfig   `yaml:"netbox"`
-	Rsyslog  cmd.RsyslogConfig  `yaml:"rsyslog"`
-	Statsd   cmd.StatsdConfig   `yaml:"statsd"`
-	Pprof    cmd.ProfilerConfig `yaml:"pprof"`
-	Liveness cmd.LivenessConfig `yaml:"liveness"`
+	Checker   checkerConfig       `yaml:"checker"`
+	Redis     cmd.RedisConfig     `yaml:"redis"`
+	Neo4j     cmd.Neo4jConfig     `yaml:"neo4j"`
+	Logger    cmd.LoggerConfig    `yaml:"log"`
+	Netbox    cmd.NetboxConfig    `yaml:"netbox"`
+	Inventory cmd.InventoryConfig `yaml:"inventory"`
+	Rsyslog   cmd.RsyslogConfig   `yaml:"rsyslog"`
+	Statsd    cmd.StatsdConfig    `yaml:"statsd"`
+	Pprof     cmd.ProfilerConfig  `yaml:"pprof"`
+	Liveness  cmd.LivenessConfig  `yaml:"liveness"`
 }
 
 type checkerConfig struct {
-	CheckInterval         string           `yaml:"check_interval"`
-	NoDataCheckInterval   string           `yaml:"nodata_check_interval"`
-	TagsCheckInterval     string           `yaml:"tags_check_interval"`
-	PullInterval          string           `yaml:"pull_interval"`
-	PullURL               string           `yaml:"pull_url"`
-	MetricsTTL            string           `yaml:"metrics_ttl"`
-	StopCheckingInterval  string           `yaml:"stop_checking_interval"`
-	MaxParallelChecks     int              `yaml:"max_parallel_checks"`
-	MaxParallelPullChecks int              `yaml:"max_parallel_pull_checks"`
-	MaxParallelTagsChecks int              `yaml:"max_parallel_tags_checks"`
-	Sentry                cmd.SentryConfig `yaml:"sentry"`
-	LimitLogger           cmd.RateLimit    `yaml:"limit_logger"`
-	LimitMetrics          cmd.RateLimit    `yaml:"limit_metrics"`
+	CheckInterval         string                        `yaml:"check_interval"`
+	NoDataCheckInterval   string                        `yaml:"nodata_check_interval"`
+	TagsCheckInterval     string                        `yaml:"tags_check_interval"`
+	PullInterval          string                        `yaml:"pull_interval"`
+	PullURL               string                        `yaml:"pull_url"`
+	RemoteSources         map[string]remoteSourceConfig `yaml:"remote_sources"`
+	MetricsTTL            string                        `yaml:"metrics_ttl"`
+	StopCheckingInterval  string                        `yaml:"stop_checking_interval"`
+	MaxParallelChecks     int                           `yaml:"max_parallel_checks"`
+	MaxParallelPullChecks int                           `yaml:"max_parallel_pull_checks"`
+	MaxParallelTagsChecks int                           `yaml:"max_parallel_tags_checks"`
+	CheckQueueSize        int                           `yaml:"check_queue_size"`
+	Sentry                cmd.SentryConfig              `yaml:"sentry"`
+	LimitLogger           cmd.RateLimit                 `yaml:"limit_logger"`
+	LimitMetrics          cmd.RateLimit                 `yaml:"limit_metrics"`
 }
 
-func (config *checkerConfig) getSettings() *checker.Config {
+type remoteSourceConfig struct {
+	URL          string            `yaml:"url"`
+	Format       string            `yaml:"format"`
+	Headers      map[string]string `yaml:"headers"`
+	User         string            `yaml:"user"`
+	PasswordPath string            `yaml:"password_path"`
+	Timeout      string            `yaml:"timeout"`
+	Retries      int               `yaml:"retries"`
+	RetryDelay   string            `yaml:"retry_delay"`
+	TLS          remoteTLSConfig   `yaml:"tls"`
+}
+
+type remoteTLSConfig struct {
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+}
+
+func (config *remoteSourceConfig) getSettings() (*checker.RemoteSource, error) {
+	password, err := cmd.ReadSecret(config.PasswordPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file: %v", err)
+	}
+
+	return &checker.RemoteSource{
+		URL:        config.URL,
+		Format:     config.Format,
+		Headers:    config.Headers,
+		User:       config.User,
+		Password:   password,
+		Timeout:    to.Duration(config.Timeout),
+		Retries:    config.Retries,
+		RetryDelay: to.Duration(config.RetryDelay),
+		TLS: checker.RemoteSourceTLS{
+			InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+			CAFile:             config.TLS.CAFile,
+			CertFile:           config.TLS.CertFile,
+			KeyFile:            config.TLS.KeyFile,
+		},
+	}, nil
+}
+
+func (config *checkerConfig) getSettings() (*checker.Config, error) {
 	if config.MaxParallelChecks == 0 {
 		config.MaxParallelChecks = runtime.NumCPU()
 	}
@@ -48,6 +95,15 @@ func (config *checkerConfig) getSettings() *checker.Config {
 		config.MaxParallelTagsChecks = runtime.NumCPU()
 	}
 
+	remoteSources := make(map[string]*checker.RemoteSource, len(config.RemoteSources))
+	for name, sourceConfig := range config.RemoteSources {
+		remoteSource, err := sourceConfig.getSettings()
+		if err != nil {
+			return nil, fmt.Errorf("remote source %s: %v", name, err)
+		}
+		remoteSources[name] = remoteSource
+	}
+
 	return &checker.Config{
 		MetricsTTLSeconds:           int64(to.Duration(config.MetricsTTL).Seconds()),
 		CheckInterval:               to.Duration(config.CheckInterval),
@@ -55,14 +111,16 @@ func (config *checkerConfig) getSettings() *checker.Config {
 		TagsCheckInterval:           to.Duration(config.TagsCheckInterval),
 		PullInterval:                to.Duration(config.PullInterval),
 		PullURL:                     config.PullURL,
+		RemoteSources:               remoteSources,
 		StopCheckingIntervalSeconds: int64(to.Duration(config.StopCheckingInterval).Seconds()),
 		MaxParallelChecks:           config.MaxParallelChecks,
 		MaxParallelPullChecks:       config.MaxParallelPullChecks,
 		MaxParallelTagsChecks:       config.MaxParallelTagsChecks,
+		CheckQueueSize:              config.CheckQueueSize,
 		LimitLogger:                 config.LimitLogger.GetSettings(),
 		LimitMetrics:                config.LimitMetrics.GetSettings(),
 		Sentry:                      config.Sentry.GetSettings(),
-	}
+	}, nil
 }
 
 func getDefault() config {
@@ -78,6 +136,7 @@ func getDefault() config {
 			MaxParallelChecks:     0,
 			MaxParallelPullChecks: 0,
 			MaxParallelTagsChecks: 0,
+			CheckQueueSize:        10000,
 			Sentry: cmd.SentryConfig{
 				Dsn:     "",
 				Enabled: false,
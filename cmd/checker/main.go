@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -33,6 +34,7 @@ var (
 	printVersion           = flag.Bool("version", false, "Print version and exit")
 	printDefaultConfigFlag = flag.Bool("default-config", false, "Print default config and exit")
 	triggerID              = flag.String("t", "", "Check single trigger by id and exit")
+	explain                = flag.Bool("explain", false, "Explain the check of single trigger (-t) step by step, nothing is saved")
 )
 
 // Moira checker bin version
@@ -66,8 +68,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	checkerSettings := config.Checker.getSettings()
-	checkerSettings.Netbox = config.Netbox.GetSettings()
+	checkerSettings, err := config.Checker.getSettings()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure checker: %v\n", err)
+		os.Exit(1)
+	}
+	checkerSettings.Inventory, err = config.Inventory.NewProvider(config.Netbox)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure inventory provider: %v\n", err)
+		os.Exit(1)
+	}
+	silencer.SetBlacklist(config.Inventory.Blacklist)
+	if err = checkerSettings.InitRemoteSources(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure remote sources: %v\n", err)
+		os.Exit(1)
+	}
 
 	if err = metrics.Init(config.Statsd.GetSettings(), checkerSettings.LimitMetrics); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Can not configure metrics: %v\n", err)
@@ -98,8 +113,14 @@ func main() {
 		cmd.StartLiveness(logger, config.Liveness)
 	}
 
-	databaseSettings := config.Redis.GetSettings()
-	database := redis.NewDatabase(logger, databaseSettings)
+	databaseSettings, err := config.Redis.GetSettings()
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
+	database, err := redis.NewDatabase(logger, databaseSettings)
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
 
 	checkerMetrics := metrics.NewCheckerMetrics()
 	if triggerID != nil && *triggerID != "" {
@@ -107,8 +128,13 @@ func main() {
 		return
 	}
 
+	// api limits the windows of the triggers by the TTL of the metrics, it reads the value set by the checkers
+	if err := database.SetMetricsTTL(checkerSettings.MetricsTTLSeconds); err != nil {
+		logger.ErrorF("Failed to share metrics TTL with api: %v", err)
+	}
+
 	logger.Debug("Starting silencer worker")
-	s := silencer.NewSilencer(database, checkerSettings.Netbox)
+	s := silencer.NewSilencer(database, checkerSettings.Inventory)
 	s.Start()
 	defer s.Stop()
 
@@ -148,6 +174,13 @@ func checkSingleTrigger(
 	if err := triggerChecker.InitTriggerChecker(); err != nil {
 		logger.FatalF("Failed initialize trigger checker: %s", err.Error())
 	}
+	// the silencer is not started for the single check
+	silencer.NewSilencer(database, nil).Update()
+
+	if *explain {
+		printExplanation(triggerChecker.Explain())
+		os.Exit(0)
+	}
 
 	if err := triggerChecker.Check(); err != nil {
 		logger.FatalF("Failed check trigger: %s", err)
@@ -156,6 +189,41 @@ func checkSingleTrigger(
 	os.Exit(0)
 }
 
+// printExplanation prints the steps of the check one per line, the results of the check are printed as json
+func printExplanation(explanation *checker.Explanation) {
+	fmt.Printf("Trigger %s is checked from %d until %d\n", explanation.TriggerID, explanation.From, explanation.Until)
+	for _, step := range explanation.Steps {
+		line := fmt.Sprintf("%-10s", step.Kind)
+		if step.Timestamp != 0 {
+			line += " " + time.Unix(step.Timestamp, 0).UTC().Format(time.RFC3339)
+		}
+		if step.Metric != "" {
+			line += " " + step.Metric
+		}
+		line += ": " + step.Message
+		if len(step.Data) > 0 {
+			if data, err := json.Marshal(step.Data); err == nil {
+				line += " " + string(data)
+			} else {
+				line += fmt.Sprintf(" %v", step.Data)
+			}
+		}
+		fmt.Println(line)
+	}
+
+	if explanation.Error != "" {
+		fmt.Println("Check failed:", explanation.Error)
+	}
+	for _, event := range explanation.Events {
+		data, _ := json.Marshal(event)
+		fmt.Println("Event would be pushed:", string(data))
+	}
+	if explanation.CheckData != nil {
+		data, _ := json.MarshalIndent(explanation.CheckData, "", "  ")
+		fmt.Println("Last check would be saved:", string(data))
+	}
+}
+
 func stopChecker(service *worker.Checker) {
 	if err := service.Stop(); err != nil {
 		logger.ErrorF("Failed to Stop Moira Checker: %v", err)
@@ -5,16 +5,35 @@ import (
 )
 
 type config struct {
-	LogFile  string            `yaml:"log_file"`
-	LogLevel string            `yaml:"log_level"`
-	Redis    cmd.RedisConfig   `yaml:"redis"`
-	Rsyslog  cmd.RsyslogConfig `yaml:"rsyslog"`
+	APIConfigPath string            `yaml:"api_config"` // the synced entities are validated with the settings of api
+	LogFile       string            `yaml:"log_file"`
+	LogLevel      string            `yaml:"log_level"`
+	Neo4j         cmd.Neo4jConfig   `yaml:"neo4j"`
+	Redis         cmd.RedisConfig   `yaml:"redis"`
+	Rsyslog       cmd.RsyslogConfig `yaml:"rsyslog"`
+}
+
+// apiFileConfig is the part of the config file of api which is needed to validate the synced entities the same way as api does
+type apiFileConfig struct {
+	API struct {
+		TriggerValidation cmd.TriggerValidationConfig `yaml:",inline"`
+	} `yaml:"api"`
+	Netbox    cmd.NetboxConfig    `yaml:"netbox"`
+	Inventory cmd.InventoryConfig `yaml:"inventory"`
 }
 
 func getDefault() config {
 	return config{
-		LogFile:  "stdout",
-		LogLevel: "debug",
+		APIConfigPath: "/etc/moira/api.yml",
+		LogFile:       "stdout",
+		LogLevel:      "debug",
+		Neo4j: cmd.Neo4jConfig{
+			Host:     "neo4j",
+			Port:     7474,
+			DBName:   "neo4j",
+			User:     "neo4j",
+			Password: "neo4j",
+		},
 		Redis: cmd.RedisConfig{
 			Host: "localhost",
 			Port: "6379",
@@ -7,7 +7,9 @@ import (
 	"os"
 
 	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/cmd"
+	"go.avito.ru/DO/moira/database/neo4j"
 	"go.avito.ru/DO/moira/database/redis"
 	"go.avito.ru/DO/moira/logging"
 )
@@ -20,6 +22,9 @@ var (
 	convertPythonExpression         = flag.String("convert-expression", "", "Convert python expression used in moira 1.x to govaluate expressions in moira 2.x for concrete trigger")
 	getTriggerWithPythonExpressions = flag.Bool("python-expressions-triggers", false, "Get count of triggers with python expression and count of triggers, that has python expression and has not govaluate expression")
 	removeBotInstanceLock           = flag.String("delete-bot-host-lock", "", "Delete bot host lock for launching bots with new distributed lock strategy. Must use for upgrade from Moira 1.x to 2.x")
+	syncDirectory                   = flag.String("sync", "", "Sync triggers, subscriptions, contacts and silences with YAML files of the directory, only the plan is printed without -sync-apply")
+	syncSource                      = flag.String("sync-source", "", "Name of the synced source, required with -sync, the entities of other sources are never touched")
+	syncApply                       = flag.Bool("sync-apply", false, "Apply the sync plan")
 )
 
 // Moira version
@@ -58,8 +63,16 @@ func main() {
 	}
 
 	logger := logging.GetLogger("")
-	databaseSettings := config.Redis.GetSettings()
-	dataBase := redis.NewDatabase(logger, databaseSettings)
+	databaseSettings, err := config.Redis.GetSettings()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure Redis: %v\n", err)
+		os.Exit(1)
+	}
+	dataBase, err := redis.NewDatabase(logger, databaseSettings)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Can not configure Redis: %v\n", err)
+		os.Exit(1)
+	}
 
 	if *convertPythonExpressions {
 		if err := ConvertPythonExpressions(dataBase); err != nil {
@@ -88,6 +101,48 @@ func main() {
 			os.Exit(1)
 		}
 	}
+
+	if *syncDirectory != "" {
+		// the source tells which entities the sync may delete, so it is never guessed
+		if *syncSource == "" {
+			fmt.Fprintf(os.Stderr, "Sync source is required, set it with -sync-source")
+			os.Exit(1)
+		}
+		apiSettings, err := getAPISettings(config.APIConfigPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Can not read settings of api: %v", err)
+			os.Exit(1)
+		}
+		// the inheritance of the triggers is saved only when the plan is applied
+		var neo4jDb moira.TriggerInheritanceDatabase
+		if *syncApply {
+			if neo4jDb, err = neo4j.NewDatabase(logger, config.Neo4j); err != nil {
+				fmt.Fprintf(os.Stderr, "Can not configure Neo4j: %v", err)
+				os.Exit(1)
+			}
+		}
+		if err := Sync(newSyncer(dataBase, neo4jDb, apiSettings, *syncSource), *syncDirectory, *syncApply); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to sync: %v", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// getAPISettings reads the config file of api, so that the synced entities are validated with the same settings
+func getAPISettings(path string) (api.Config, error) {
+	apiFile := apiFileConfig{}
+	apiFile.API.TriggerValidation = cmd.NewDefaultTriggerValidationConfig()
+	if err := cmd.ReadConfig(path, &apiFile); err != nil {
+		return api.Config{}, err
+	}
+
+	settings := apiFile.API.TriggerValidation.GetSettings()
+	inventoryProvider, err := apiFile.Inventory.NewProvider(apiFile.Netbox)
+	if err != nil {
+		return api.Config{}, fmt.Errorf("can not configure inventory provider: %v", err)
+	}
+	settings.Inventory = inventoryProvider
+	return settings, nil
 }
 
 // RemoveBotInstanceLock - in Moira 2.0 we switch from host-based single instance telegram-bot run lock
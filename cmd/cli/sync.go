@@ -0,0 +1,581 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"gopkg.in/yaml.v2"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/api/controller"
+	"go.avito.ru/DO/moira/api/dto"
+)
+
+// Actions of the changes of the sync plan
+const (
+	syncCreate = "create"
+	syncUpdate = "update"
+	syncDelete = "delete"
+)
+
+// Kinds of the synced entities
+const (
+	syncContact      = "contact"
+	syncSubscription = "subscription"
+	syncTrigger      = "trigger"
+	syncSilence      = "silence"
+)
+
+// syncFile is the YAML file of the synced directory: the entities have the same fields as api takes
+// plus the key which is stable across the syncs, ids are assigned by the sync itself
+type syncFile struct {
+	Contacts      []map[interface{}]interface{} `yaml:"contacts"`
+	Subscriptions []map[interface{}]interface{} `yaml:"subscriptions"`
+	Triggers      []map[interface{}]interface{} `yaml:"triggers"`
+	Silences      []map[interface{}]interface{} `yaml:"silences"`
+}
+
+// syncEntity is the entity described in the synced directory
+type syncEntity struct {
+	Key    string
+	File   string
+	Fields map[string]interface{}
+}
+
+// syncSpec is everything described in the synced directory
+type syncSpec struct {
+	Contacts      []*syncEntity
+	Subscriptions []*syncEntity
+	Triggers      []*syncEntity
+	Silences      []*syncEntity
+}
+
+// syncChange is the single change of the sync plan
+type syncChange struct {
+	Action string
+	Kind   string
+	Key    string
+	ID     string
+	apply  func() error
+}
+
+func (change *syncChange) String() string {
+	return fmt.Sprintf("%s %s %s (id %s)", change.Action, change.Kind, change.Key, change.ID)
+}
+
+// syncer makes the entities of its source in the database match the synced directory,
+// the entities which are not managed by the source are never touched
+type syncer struct {
+	database            moira.Database
+	inheritanceDatabase moira.TriggerInheritanceDatabase
+	config              api.Config
+	source              string
+	now                 int64
+}
+
+// Sync reads the directory and prints the plan of the changes, the plan is applied if apply is set
+func Sync(s *syncer, directory string, apply bool) error {
+	spec, err := readSyncDirectory(directory)
+	if err != nil {
+		return err
+	}
+	changes, err := s.plan(spec)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Nothing to change")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Println(change.String())
+	}
+	if !apply {
+		fmt.Println(fmt.Sprintf("%d changes planned, run with -sync-apply to apply them", len(changes)))
+		return nil
+	}
+
+	for _, change := range changes {
+		if err := change.apply(); err != nil {
+			return fmt.Errorf("failed to %s: %v", change.String(), err)
+		}
+	}
+	fmt.Println(fmt.Sprintf("%d changes applied", len(changes)))
+	return nil
+}
+
+// readSyncDirectory reads all the YAML files of the directory and its subdirectories
+func readSyncDirectory(directory string) (*syncSpec, error) {
+	spec := &syncSpec{}
+	keys := make(map[string]string) // kind:key -> file
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filepath.Ext(path) != ".yml" && filepath.Ext(path) != ".yaml") {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file := syncFile{}
+		if err := yaml.UnmarshalStrict(content, &file); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		for _, kind := range []struct {
+			name     string
+			items    []map[interface{}]interface{}
+			entities *[]*syncEntity
+		}{
+			{syncContact, file.Contacts, &spec.Contacts},
+			{syncSubscription, file.Subscriptions, &spec.Subscriptions},
+			{syncTrigger, file.Triggers, &spec.Triggers},
+			{syncSilence, file.Silences, &spec.Silences},
+		} {
+			for i, item := range kind.items {
+				entity, err := newSyncEntity(path, item)
+				if err != nil {
+					return fmt.Errorf("%s: %s #%d: %v", path, kind.name, i+1, err)
+				}
+				if other, ok := keys[kind.name+":"+entity.Key]; ok {
+					return fmt.Errorf("%s: %s %s is described in %s already", path, kind.name, entity.Key, other)
+				}
+				keys[kind.name+":"+entity.Key] = path
+				*kind.entities = append(*kind.entities, entity)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func newSyncEntity(file string, item map[interface{}]interface{}) (*syncEntity, error) {
+	fields, ok := toJSONValue(item).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("fields must be a map")
+	}
+	key, ok := fields["key"].(string)
+	if !ok || key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	delete(fields, "key")
+	for _, field := range []string{"id", "managed"} {
+		if _, ok := fields[field]; ok {
+			return nil, fmt.Errorf("%s is assigned by the sync, it can't be set", field)
+		}
+	}
+	return &syncEntity{Key: key, File: file, Fields: fields}, nil
+}
+
+// toJSONValue converts the value decoded by YAML so that it could be encoded to JSON
+func toJSONValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			result[fmt.Sprintf("%v", k)] = toJSONValue(v)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, v := range typed {
+			result[i] = toJSONValue(v)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// decode fills the api representation of the entity with its fields, unknown fields are not allowed
+func (entity *syncEntity) decode(target interface{}) error {
+	raw, err := json.Marshal(entity.Fields)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+func (entity *syncEntity) errorf(kind string, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s %s: %s", entity.File, kind, entity.Key, fmt.Sprintf(format, args...))
+}
+
+// plan computes the changes which make the database match the spec:
+// contacts go first so that the subscriptions could refer to them, everything is deleted in reverse order
+func (s *syncer) plan(spec *syncSpec) ([]*syncChange, error) {
+	contactChanges, contactDeletes, contactIDs, err := s.planContacts(spec.Contacts)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionChanges, subscriptionDeletes, err := s.planSubscriptions(spec.Subscriptions, contactIDs)
+	if err != nil {
+		return nil, err
+	}
+	triggerChanges, triggerDeletes, err := s.planTriggers(spec.Triggers)
+	if err != nil {
+		return nil, err
+	}
+	silenceChanges, silenceDeletes, err := s.planSilences(spec.Silences)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*syncChange, 0)
+	for _, changes := range [][]*syncChange{
+		contactChanges, subscriptionChanges, triggerChanges, silenceChanges,
+		silenceDeletes, triggerDeletes, subscriptionDeletes, contactDeletes,
+	} {
+		result = append(result, changes...)
+	}
+	return result, nil
+}
+
+func (s *syncer) planContacts(entities []*syncEntity) (changes, deletes []*syncChange, ids map[string]string, err error) {
+	all, err := s.database.GetAllContacts()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	existing := make(map[string]*moira.ContactData)
+	for _, contact := range all {
+		if contact != nil && s.isManaged(contact.Managed) {
+			existing[contact.Managed.Key] = contact
+		}
+	}
+
+	// the contacts are referred either by their keys or by their ids, the ids of the contacts which are not deleted are valid
+	ids = make(map[string]string, len(all)+len(entities))
+	for _, contact := range all {
+		if contact != nil {
+			ids[contact.ID] = contact.ID
+		}
+	}
+	for _, entity := range entities {
+		contactDTO := dto.Contact{}
+		if err := entity.decode(&contactDTO); err != nil {
+			return nil, nil, nil, entity.errorf(syncContact, "%v", err)
+		}
+		if err := contactDTO.Bind(nil); err != nil {
+			return nil, nil, nil, entity.errorf(syncContact, "%v", err)
+		}
+		if contactDTO.User == "" {
+			return nil, nil, nil, entity.errorf(syncContact, "user is required")
+		}
+		contact := &moira.ContactData{
+			Type:          contactDTO.Type,
+			Value:         contactDTO.Value,
+			FallbackValue: contactDTO.FallbackValue,
+			User:          contactDTO.User,
+			Managed:       s.managedBy(entity.Key),
+		}
+		if contact.NeedsFallbackValue() && contact.FallbackValue == "" {
+			return nil, nil, nil, entity.errorf(syncContact, "contact needs fallback value but it is not set")
+		}
+
+		action := syncCreate
+		if prev, ok := existing[entity.Key]; ok {
+			delete(existing, entity.Key)
+			contact.ID, contact.Expiration = prev.ID, prev.Expiration
+			if isSyncEqual(prev, contact) {
+				ids[entity.Key] = contact.ID
+				continue
+			}
+			action = syncUpdate
+		} else {
+			contact.ID = uuid.NewV4().String()
+		}
+		ids[entity.Key] = contact.ID
+		changes = append(changes, s.newChange(action, syncContact, entity.Key, contact.ID, func() error {
+			return s.database.SaveContact(contact)
+		}))
+	}
+
+	for key, contact := range existing {
+		contact := contact
+		delete(ids, contact.ID)
+		deletes = append(deletes, s.newChange(syncDelete, syncContact, key, contact.ID, func() error {
+			return fromErrorResponse(controller.RemoveManagedContact(s.database, contact.ID, contact.User))
+		}))
+	}
+	return changes, sortSyncChanges(deletes), ids, nil
+}
+
+func (s *syncer) planSubscriptions(entities []*syncEntity, contactIDs map[string]string) (changes, deletes []*syncChange, err error) {
+	all, err := s.database.GetAllSubscriptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]*moira.SubscriptionData)
+	for _, subscription := range all {
+		if subscription != nil && s.isManaged(subscription.Managed) {
+			existing[subscription.Managed.Key] = subscription
+		}
+	}
+
+	// the contacts are referred either by their keys or by the ids of existing contacts,
+	// anything else is most likely a typo which would make the notifications silently dropped
+	resolveContacts := func(contacts []string) ([]string, error) {
+		result := make([]string, len(contacts))
+		for i, contact := range contacts {
+			id, ok := contactIDs[contact]
+			if !ok {
+				return nil, fmt.Errorf("contact %s is neither a key of the synced contacts nor an id of existing contact", contact)
+			}
+			result[i] = id
+		}
+		return result, nil
+	}
+
+	for _, entity := range entities {
+		subscriptionDTO := dto.Subscription{}
+		if err := entity.decode(&subscriptionDTO); err != nil {
+			return nil, nil, entity.errorf(syncSubscription, "%v", err)
+		}
+		if err := subscriptionDTO.Bind(nil); err != nil {
+			return nil, nil, entity.errorf(syncSubscription, "%v", err)
+		}
+		if subscriptionDTO.User == "" {
+			return nil, nil, entity.errorf(syncSubscription, "user is required")
+		}
+		subscription := moira.SubscriptionData(subscriptionDTO)
+		subscription.Managed = s.managedBy(entity.Key)
+		if subscription.Contacts, err = resolveContacts(subscription.Contacts); err != nil {
+			return nil, nil, entity.errorf(syncSubscription, "%v", err)
+		}
+		for i := range subscription.Escalations {
+			if subscription.Escalations[i].Contacts, err = resolveContacts(subscription.Escalations[i].Contacts); err != nil {
+				return nil, nil, entity.errorf(syncSubscription, "escalation #%d: %v", i+1, err)
+			}
+		}
+		if err := fromErrorResponse(controller.CheckSubscriptionRotations(s.database, &subscription)); err != nil {
+			return nil, nil, entity.errorf(syncSubscription, "%v", err)
+		}
+
+		action := syncCreate
+		if prev, ok := existing[entity.Key]; ok {
+			delete(existing, entity.Key)
+			subscription.ID = prev.ID
+			// the ids of the escalations are assigned when the subscription is saved
+			for i := range subscription.Escalations {
+				if i < len(prev.Escalations) {
+					subscription.Escalations[i].ID = prev.Escalations[i].ID
+				}
+			}
+			if isSyncEqual(prev, &subscription) {
+				continue
+			}
+			action = syncUpdate
+		} else {
+			subscription.ID = uuid.NewV4().String()
+		}
+		changes = append(changes, s.newChange(action, syncSubscription, entity.Key, subscription.ID, func() error {
+			return s.database.SaveSubscription(&subscription)
+		}))
+	}
+
+	for key, subscription := range existing {
+		subscription := subscription
+		deletes = append(deletes, s.newChange(syncDelete, syncSubscription, key, subscription.ID, func() error {
+			return fromErrorResponse(controller.RemoveManagedSubscription(s.database, subscription.ID))
+		}))
+	}
+	return changes, sortSyncChanges(deletes), nil
+}
+
+func (s *syncer) planTriggers(entities []*syncEntity) (changes, deletes []*syncChange, err error) {
+	triggerIDs, err := s.database.GetTriggerIDs(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	all, err := s.database.GetTriggers(triggerIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]*moira.Trigger)
+	for _, trigger := range all {
+		if trigger != nil && s.isManaged(trigger.Managed) {
+			existing[trigger.Managed.Key] = trigger
+		}
+	}
+
+	for _, entity := range entities {
+		triggerDTO := &dto.Trigger{}
+		if err := entity.decode(&triggerDTO.TriggerModel); err != nil {
+			return nil, nil, entity.errorf(syncTrigger, "%v", err)
+		}
+		// the same validation as api does
+		timeSeriesNames, err := triggerDTO.Check(s.database, s.config)
+		if err != nil {
+			return nil, nil, entity.errorf(syncTrigger, "%v", err)
+		}
+		trigger := triggerDTO.ToMoiraTrigger()
+		trigger.Template = nil
+		trigger.Managed = s.managedBy(entity.Key)
+
+		action := syncCreate
+		if prev, ok := existing[entity.Key]; ok {
+			delete(existing, entity.Key)
+			trigger.ID = prev.ID
+			if isSyncEqual(dto.CreateTriggerModel(prev), dto.CreateTriggerModel(trigger)) {
+				continue
+			}
+			action = syncUpdate
+		} else {
+			trigger.ID = uuid.NewV4().String()
+		}
+		changes = append(changes, s.newChange(action, syncTrigger, entity.Key, trigger.ID, func() error {
+			return fromErrorResponse(controller.SaveManagedTrigger(s.database, s.inheritanceDatabase, trigger, timeSeriesNames))
+		}))
+	}
+
+	for key, trigger := range existing {
+		trigger := trigger
+		deletes = append(deletes, s.newChange(syncDelete, syncTrigger, key, trigger.ID, func() error {
+			return fromErrorResponse(controller.RemoveManagedTrigger(s.database, trigger.ID))
+		}))
+	}
+	return changes, sortSyncChanges(deletes), nil
+}
+
+func (s *syncer) planSilences(entities []*syncEntity) (changes, deletes []*syncChange, err error) {
+	all, err := s.database.GetSilentPatternsAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]*moira.SilentPatternData)
+	for _, silence := range all {
+		if silence != nil && s.isManaged(silence.Managed) {
+			existing[silence.Managed.Key] = silence
+		}
+	}
+
+	for _, entity := range entities {
+		raw := &moira.SilentPatternData{}
+		if err := entity.decode(raw); err != nil {
+			return nil, nil, entity.errorf(syncSilence, "%v", err)
+		}
+		login := raw.Login
+		if login == "" {
+			login = s.source
+		}
+		// the same validation and expansion of ranges and racks as api does
+		silences, err := controller.BuildSilentPatterns(raw, login, s.now, s.config.Inventory)
+		if err != nil {
+			return nil, nil, entity.errorf(syncSilence, "%v", err)
+		}
+
+		for _, silence := range silences {
+			// every pattern the silence is expanded to is managed by its own key
+			key := entity.Key
+			if len(silences) > 1 {
+				key = entity.Key + ":" + silence.Pattern
+			}
+			silence.Managed = s.managedBy(key)
+
+			action := syncCreate
+			if prev, ok := existing[key]; ok {
+				delete(existing, key)
+				silence.ID, silence.Created = prev.ID, prev.Created
+				if prev.Type == silence.Type && isSyncEqual(prev, silence) {
+					continue
+				}
+				if prev.Type != silence.Type {
+					// the patterns of different types are kept apart, so the old one is deleted
+					deletes = append(deletes, s.newSilenceDelete(key, prev))
+				}
+				action = syncUpdate
+			} else {
+				silence.ID = moira.NewStrID()
+			}
+			silence := silence
+			changes = append(changes, s.newChange(action, syncSilence, key, silence.ID, func() error {
+				return s.withSilentPatternsLock(silence.Type, func() error {
+					return s.database.SaveSilentPatterns(silence.Type, silence)
+				})
+			}))
+		}
+	}
+
+	for key, silence := range existing {
+		deletes = append(deletes, s.newSilenceDelete(key, silence))
+	}
+	return changes, sortSyncChanges(deletes), nil
+}
+
+func (s *syncer) newSilenceDelete(key string, silence *moira.SilentPatternData) *syncChange {
+	return s.newChange(syncDelete, syncSilence, key, silence.ID, func() error {
+		return s.withSilentPatternsLock(silence.Type, func() error {
+			return s.database.RemoveSilentPatterns(silence.Type, silence)
+		})
+	})
+}
+
+func (s *syncer) withSilentPatternsLock(patternType moira.SilentPatternType, action func() error) error {
+	if err := s.database.LockSilentPatterns(patternType); err != nil {
+		return err
+	}
+	defer s.database.UnlockSilentPatterns(patternType)
+	return action()
+}
+
+func (s *syncer) newChange(action, kind, key, id string, apply func() error) *syncChange {
+	return &syncChange{Action: action, Kind: kind, Key: key, ID: id, apply: apply}
+}
+
+func (s *syncer) managedBy(key string) *moira.ManagedBy {
+	return &moira.ManagedBy{Source: s.source, Key: key}
+}
+
+// isManaged tells if the entity is managed by the source of the syncer, the entities managed via UI are not
+func (s *syncer) isManaged(managed *moira.ManagedBy) bool {
+	return managed != nil && managed.Source == s.source
+}
+
+// isSyncEqual compares the entities as they are stored
+func isSyncEqual(prev, next interface{}) bool {
+	prevBytes, prevErr := json.Marshal(prev)
+	nextBytes, nextErr := json.Marshal(next)
+	return prevErr == nil && nextErr == nil && bytes.Equal(prevBytes, nextBytes)
+}
+
+// sortSyncChanges makes the order of the deletions stable
+func sortSyncChanges(changes []*syncChange) []*syncChange {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return strings.Compare(changes[i].Key, changes[j].Key) < 0
+	})
+	return changes
+}
+
+func fromErrorResponse(err *api.ErrorResponse) error {
+	if err != nil {
+		return err.Err
+	}
+	return nil
+}
+
+func newSyncer(dataBase moira.Database, inheritanceDatabase moira.TriggerInheritanceDatabase, config api.Config, source string) *syncer {
+	return &syncer{
+		database:            dataBase,
+		inheritanceDatabase: inheritanceDatabase,
+		config:              config,
+		source:              source,
+		now:                 time.Now().Unix(),
+	}
+}
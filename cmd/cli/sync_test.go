@@ -0,0 +1,252 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+)
+
+func TestReadSyncDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir("", "moira-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Convey("Entities are read from all the YAML files", t, func() {
+		write("contacts.yml", "contacts:\n  - key: team-mail\n    type: mail\n    value: team@example.com\n    user: team\n")
+		write("subscriptions.yaml", "subscriptions:\n  - key: team\n    tags: [team]\n    contacts: [team-mail]\n    user: team\n")
+		write("README.md", "not synced")
+
+		spec, err := readSyncDirectory(directory)
+		So(err, ShouldBeNil)
+		So(len(spec.Contacts), ShouldEqual, 1)
+		So(spec.Contacts[0].Key, ShouldEqual, "team-mail")
+		So(spec.Contacts[0].Fields, ShouldResemble, map[string]interface{}{
+			"type": "mail", "value": "team@example.com", "user": "team",
+		})
+		So(len(spec.Subscriptions), ShouldEqual, 1)
+		So(spec.Subscriptions[0].Fields["tags"], ShouldResemble, []interface{}{"team"})
+	})
+
+	Convey("Duplicate keys are refused", t, func() {
+		write("more.yml", "contacts:\n  - key: team-mail\n    type: mail\n    value: other@example.com\n")
+		defer os.Remove(filepath.Join(directory, "more.yml"))
+
+		_, err := readSyncDirectory(directory)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Id can't be set", t, func() {
+		write("more.yml", "contacts:\n  - key: other\n    id: some-id\n    type: mail\n    value: other@example.com\n")
+		defer os.Remove(filepath.Join(directory, "more.yml"))
+
+		_, err := readSyncDirectory(directory)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestSyncPlan(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	s := newSyncer(dataBase, nil, api.Config{}, "repo")
+
+	contact := func(key string) *syncEntity {
+		return &syncEntity{Key: key, Fields: map[string]interface{}{
+			"type": "mail", "value": key + "@example.com", "user": "team",
+		}}
+	}
+	managedContact := func(id, key string) *moira.ContactData {
+		return &moira.ContactData{
+			ID: id, Type: "mail", Value: key + "@example.com", User: "team",
+			Managed: &moira.ManagedBy{Source: "repo", Key: key},
+		}
+	}
+
+	Convey("Contacts are created, updated, kept and deleted by their keys", t, func() {
+		changed := managedContact("changed-id", "changed")
+		changed.Value = "old@example.com"
+		dataBase.EXPECT().GetAllContacts().Return([]*moira.ContactData{
+			managedContact("same-id", "same"),
+			changed,
+			managedContact("gone-id", "gone"),
+			{ID: "ui-id", Type: "mail", Value: "ui@example.com", User: "team"},
+			{ID: "other-id", Type: "mail", Value: "other@example.com", User: "team", Managed: &moira.ManagedBy{Source: "other", Key: "gone"}},
+		}, nil)
+
+		changes, deletes, ids, err := s.planContacts([]*syncEntity{contact("same"), contact("changed"), contact("new")})
+		So(err, ShouldBeNil)
+		So(len(changes), ShouldEqual, 2)
+		So(changes[0].Action, ShouldEqual, syncUpdate)
+		So(changes[0].ID, ShouldEqual, "changed-id")
+		So(changes[1].Action, ShouldEqual, syncCreate)
+		So(len(deletes), ShouldEqual, 1)
+		So(deletes[0].ID, ShouldEqual, "gone-id")
+		So(ids["same"], ShouldEqual, "same-id")
+		So(ids["new"], ShouldEqual, changes[1].ID)
+		// the existing contacts can be referred by their ids unless they are deleted
+		So(ids["ui-id"], ShouldEqual, "ui-id")
+		So(ids["other-id"], ShouldEqual, "other-id")
+		So(ids, ShouldNotContainKey, "gone-id")
+	})
+
+	Convey("Subscriptions refer to the contacts by their keys", t, func() {
+		dataBase.EXPECT().GetAllSubscriptions().Return([]*moira.SubscriptionData{}, nil)
+
+		changes, deletes, err := s.planSubscriptions([]*syncEntity{{Key: "team", Fields: map[string]interface{}{
+			"tags": []interface{}{"team"}, "contacts": []interface{}{"team-mail", "raw-id"}, "user": "team",
+		}}}, map[string]string{"team-mail": "contact-id", "raw-id": "raw-id"})
+		So(err, ShouldBeNil)
+		So(len(deletes), ShouldEqual, 0)
+		So(len(changes), ShouldEqual, 1)
+
+		dataBase.EXPECT().SaveSubscription(gomock.Any()).DoAndReturn(func(subscription *moira.SubscriptionData) error {
+			So(subscription.Contacts, ShouldResemble, []string{"contact-id", "raw-id"})
+			So(subscription.Managed, ShouldResemble, &moira.ManagedBy{Source: "repo", Key: "team"})
+			return nil
+		})
+		So(changes[0].apply(), ShouldBeNil)
+	})
+
+	Convey("Unknown contacts fail the plan", t, func() {
+		dataBase.EXPECT().GetAllSubscriptions().Return([]*moira.SubscriptionData{}, nil).Times(2)
+		contactIDs := map[string]string{"team-mail": "contact-id"}
+
+		_, _, err := s.planSubscriptions([]*syncEntity{{Key: "team", Fields: map[string]interface{}{
+			"tags": []interface{}{"team"}, "contacts": []interface{}{"team-mial"}, "user": "team",
+		}}}, contactIDs)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "contact team-mial is neither")
+
+		_, _, err = s.planSubscriptions([]*syncEntity{{Key: "team", Fields: map[string]interface{}{
+			"tags": []interface{}{"team"}, "contacts": []interface{}{"team-mail"}, "user": "team",
+			"escalations": []interface{}{map[string]interface{}{"contacts": []interface{}{"missing-id"}, "offset_in_minutes": 10}},
+		}}}, contactIDs)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "escalation #1: contact missing-id is neither")
+	})
+
+	Convey("Invalid entities fail the plan", t, func() {
+		dataBase.EXPECT().GetAllSubscriptions().Return([]*moira.SubscriptionData{}, nil)
+
+		_, _, err := s.planSubscriptions([]*syncEntity{{Key: "team", Fields: map[string]interface{}{
+			"tags": []interface{}{"team"}, "user": "team",
+		}}}, map[string]string{})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Triggers get the same validation as api does", t, func() {
+		trigger := func(key string, fields map[string]interface{}) *syncEntity {
+			entity := &syncEntity{Key: key, Fields: map[string]interface{}{
+				"name": key, "targets": []interface{}{"my.metric"}, "tags": []interface{}{"team"},
+				"warn_value": 10, "error_value": 20,
+			}}
+			for field, value := range fields {
+				entity.Fields[field] = value
+			}
+			return entity
+		}
+		managed := &moira.Trigger{ID: "gone-id", Name: "gone", Managed: &moira.ManagedBy{Source: "repo", Key: "gone"}}
+		manual := &moira.Trigger{ID: "ui-id", Name: "ui"}
+
+		Convey("Valid triggers are created, the ones removed from the spec are deleted", func() {
+			dataBase.EXPECT().GetTriggerIDs(false).Return([]string{"gone-id", "ui-id"}, nil)
+			dataBase.EXPECT().GetTriggers([]string{"gone-id", "ui-id"}).Return([]*moira.Trigger{managed, manual}, nil)
+			dataBase.EXPECT().GetPatternMetrics("my.metric").Return([]string{}, nil)
+
+			changes, deletes, err := s.planTriggers([]*syncEntity{trigger("new", nil)})
+			So(err, ShouldBeNil)
+			So(len(changes), ShouldEqual, 1)
+			So(changes[0].Action, ShouldEqual, syncCreate)
+			So(len(deletes), ShouldEqual, 1)
+			So(deletes[0].ID, ShouldEqual, "gone-id")
+		})
+
+		for name, fields := range map[string]map[string]interface{}{
+			"reserved tag":        {"tags": []interface{}{moira.EventProgressTag}},
+			"no thresholds":       {"warn_value": nil, "error_value": nil},
+			"negative interval":   {"check_interval": -1},
+			"unknown field":       {"owner": "team"},
+			"source of push type": {"source": "graphite"},
+		} {
+			fields := fields
+			Convey("Trigger with "+name+" fails the plan", func() {
+				dataBase.EXPECT().GetTriggerIDs(false).Return([]string{}, nil)
+				dataBase.EXPECT().GetTriggers([]string{}).Return([]*moira.Trigger{}, nil)
+
+				_, _, err := s.planTriggers([]*syncEntity{trigger("invalid", fields)})
+				So(err, ShouldNotBeNil)
+			})
+		}
+	})
+
+	Convey("Silences get the same validation as api does", t, func() {
+		silence := func(fields map[string]interface{}) *syncEntity {
+			entity := &syncEntity{Key: "db", Fields: map[string]interface{}{
+				"type": 0, "pattern": "db01", "until": s.now + 3600, "comment": "maintenance",
+			}}
+			for field, value := range fields {
+				entity.Fields[field] = value
+			}
+			return entity
+		}
+
+		for name, fields := range map[string]map[string]interface{}{
+			"unknown type":           {"type": 7},
+			"no comment":             {"comment": ""},
+			"invalid ticket":         {"ticket": "not a url"},
+			"start after its end":    {"start_at": s.now + 7200},
+			"invalid recurrence":     {"recurrence": map[string]interface{}{"cron": "0 3 * * *", "duration": 0}},
+			"invalid range":          {"pattern": "db0[a-b]"},
+			"rack without inventory": {"pattern": "rack_R-101"},
+		} {
+			fields := fields
+			Convey("Silence with "+name+" fails the plan", func() {
+				dataBase.EXPECT().GetSilentPatternsAll().Return([]*moira.SilentPatternData{}, nil)
+
+				_, _, err := s.planSilences([]*syncEntity{silence(fields)})
+				So(err, ShouldNotBeNil)
+			})
+		}
+
+		Convey("Range is expanded to the patterns managed by their own keys", func() {
+			kept := &moira.SilentPatternData{
+				ID: "kept-id", Login: "repo", Pattern: "db01", Created: 100, Until: s.now + 3600, Comment: "maintenance",
+				Managed: &moira.ManagedBy{Source: "repo", Key: "db:db01"},
+			}
+			dataBase.EXPECT().GetSilentPatternsAll().Return([]*moira.SilentPatternData{kept}, nil)
+
+			changes, deletes, err := s.planSilences([]*syncEntity{silence(map[string]interface{}{"pattern": "db0[1-2]"})})
+			So(err, ShouldBeNil)
+			So(len(deletes), ShouldEqual, 0)
+			So(len(changes), ShouldEqual, 1)
+			So(changes[0].Action, ShouldEqual, syncCreate)
+			So(changes[0].Key, ShouldEqual, "db:db02")
+
+			dataBase.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+			dataBase.EXPECT().SaveSilentPatterns(moira.SPTMetric, gomock.Any()).Do(func(_ moira.SilentPatternType, patterns ...*moira.SilentPatternData) {
+				So(patterns[0].Pattern, ShouldEqual, "db02")
+				So(patterns[0].Login, ShouldEqual, "repo")
+				So(patterns[0].Managed, ShouldResemble, &moira.ManagedBy{Source: "repo", Key: "db:db02"})
+			}).Return(nil)
+			dataBase.EXPECT().UnlockSilentPatterns(moira.SPTMetric).Return(nil)
+			So(changes[0].apply(), ShouldBeNil)
+		})
+	})
+}
@@ -5,30 +5,133 @@ import (
 	"io/ioutil"
 	"strings"
 
+	"github.com/gosexy/to"
 	"gopkg.in/yaml.v2"
 
 	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/database/redis"
+	"go.avito.ru/DO/moira/inventory"
 	"go.avito.ru/DO/moira/logging"
 	"go.avito.ru/DO/moira/metrics"
 	"go.avito.ru/DO/moira/netbox"
 	"go.avito.ru/DO/moira/sentry"
 )
 
-// RedisConfig is redis config structure, which are taken on the start of moira
+// RedisConfig is redis config structure, which are taken on the start of moira,
+// either a standalone instance, the master discovered via Sentinel or Redis Cluster
 type RedisConfig struct {
-	Host string `yaml:"host"`
-	Port string `yaml:"port"`
-	DBID int    `yaml:"dbid"`
+	Host         string `yaml:"host"`
+	Port         string `yaml:"port"`
+	DBID         int    `yaml:"dbid"`
+	PasswordPath string `yaml:"password_path"`
+
+	MaxIdle        int    `yaml:"max_idle"`
+	MaxActive      int    `yaml:"max_active"`
+	IdleTimeout    string `yaml:"idle_timeout"`
+	ConnectTimeout string `yaml:"connect_timeout"`
+	ReadTimeout    string `yaml:"read_timeout"`
+	WriteTimeout   string `yaml:"write_timeout"`
+
+	TLS          RedisTLSConfig          `yaml:"tls"`
+	Sentinel     RedisSentinelConfig     `yaml:"sentinel"`
+	Cluster      RedisClusterConfig      `yaml:"cluster"`
+	MetricEvents RedisMetricEventsConfig `yaml:"metric_events"`
+}
+
+// RedisTLSConfig is TLS settings of redis connections
+type RedisTLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	CAFile             string `yaml:"ca_file"`
+}
+
+// RedisSentinelConfig is settings of redis master discovery via sentinels
+type RedisSentinelConfig struct {
+	MasterName   string   `yaml:"master_name"`
+	Addrs        []string `yaml:"addrs"`
+	PasswordPath string   `yaml:"password_path"`
+}
+
+// RedisClusterConfig is settings of Redis Cluster, the slots are discovered via any of the given nodes
+type RedisClusterConfig struct {
+	Addrs []string `yaml:"addrs"`
 }
 
-// GetSettings return redis config parsed from moira config files
-func (config *RedisConfig) GetSettings() redis.Config {
+// RedisMetricEventsConfig is settings of delivery of metric events from filter to checkers,
+// the filter and the checkers must use the same delivery
+type RedisMetricEventsConfig struct {
+	// Delivery is "pubsub" (default) or "stream", the filter and the checkers must switch to the stream together
+	Delivery  string `yaml:"delivery"`
+	Consumer  string `yaml:"consumer"`
+	MaxLen    int64  `yaml:"max_len"`
+	BatchSize int    `yaml:"batch_size"`
+	ClaimIdle string `yaml:"claim_idle"`
+}
+
+// GetSettings return redis config parsed from moira config files, the password files must be readable if they are set
+func (config *RedisConfig) GetSettings() (redis.Config, error) {
+	password, err := getRedisPassword(config.PasswordPath)
+	if err != nil {
+		return redis.Config{}, err
+	}
+	sentinelPassword, err := getRedisPassword(config.Sentinel.PasswordPath)
+	if err != nil {
+		return redis.Config{}, err
+	}
+
 	return redis.Config{
-		Host: config.Host,
-		Port: config.Port,
-		DBID: config.DBID,
+		Host:           config.Host,
+		Port:           config.Port,
+		DBID:           config.DBID,
+		Password:       password,
+		MaxIdle:        config.MaxIdle,
+		MaxActive:      config.MaxActive,
+		IdleTimeout:    to.Duration(config.IdleTimeout),
+		ConnectTimeout: to.Duration(config.ConnectTimeout),
+		ReadTimeout:    to.Duration(config.ReadTimeout),
+		WriteTimeout:   to.Duration(config.WriteTimeout),
+		TLS: redis.TLSConfig{
+			Enabled:            config.TLS.Enabled,
+			InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+			CAFile:             config.TLS.CAFile,
+		},
+		Sentinel: redis.SentinelConfig{
+			MasterName: config.Sentinel.MasterName,
+			Addrs:      config.Sentinel.Addrs,
+			Password:   sentinelPassword,
+		},
+		Cluster: redis.ClusterConfig{
+			Addrs: config.Cluster.Addrs,
+		},
+		MetricEvents: redis.MetricEventsConfig{
+			Delivery:  config.MetricEvents.Delivery,
+			Consumer:  config.MetricEvents.Consumer,
+			MaxLen:    config.MetricEvents.MaxLen,
+			BatchSize: config.MetricEvents.BatchSize,
+			ClaimIdle: to.Duration(config.MetricEvents.ClaimIdle),
+		},
+	}, nil
+}
+
+func getRedisPassword(path string) (string, error) {
+	password, err := ReadSecret(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read redis password file: %v", err)
+	}
+	return password, nil
+}
+
+// ReadSecret reads the secret such as password or token from the file, nothing is read if the path is not set
+func ReadSecret(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	secret, err := moira.GetFileContent(path)
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(secret), nil
 }
 
 type Neo4jConfig struct {
@@ -66,6 +169,121 @@ func (netboxConfig *NetboxConfig) GetSettings() *netbox.Config {
 	}
 }
 
+// InventoryConfig is settings of CMDB which is used to silence inactive hosts and to expand groups of hosts,
+// netbox is used by default if it is enabled
+type InventoryConfig struct {
+	Provider  string   `yaml:"provider"` // netbox, file or http
+	File      string   `yaml:"file"`
+	URL       string   `yaml:"url"`
+	TokenPath string   `yaml:"token_path"` // path to the file with token of http provider
+	Timeout   string   `yaml:"timeout"`
+	Blacklist []string `yaml:"blacklist"` // metric path parts which precede host names
+}
+
+// GetSettings returns the settings of inventory provider, the token file must be readable if it is set
+func (inventoryConfig *InventoryConfig) GetSettings(netboxConfig NetboxConfig) (*inventory.Config, error) {
+	token, err := ReadSecret(inventoryConfig.TokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory token file: %v", err)
+	}
+	result := &inventory.Config{
+		Provider:    inventoryConfig.Provider,
+		Netbox:      netboxConfig.GetSettings(),
+		FilePath:    inventoryConfig.File,
+		HTTPURL:     inventoryConfig.URL,
+		HTTPToken:   token,
+		HTTPTimeout: to.Duration(inventoryConfig.Timeout),
+	}
+	if result.Provider == "" && result.Netbox != nil {
+		result.Provider = inventory.ProviderNetbox
+	}
+	return result, nil
+}
+
+// NewProvider creates the inventory provider, it fails if the settings are invalid
+func (inventoryConfig *InventoryConfig) NewProvider(netboxConfig NetboxConfig) (moira.InventoryProvider, error) {
+	settings, err := inventoryConfig.GetSettings(netboxConfig)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.NewProvider(settings)
+}
+
+// AuthorizationConfig defines roles of the users and the teams which own the triggers
+type AuthorizationConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	DefaultRole string            `yaml:"default_role"` // viewer, editor or admin
+	Users       map[string]string `yaml:"users"`        // login -> role
+	Teams       []moira.Team      `yaml:"teams"`
+}
+
+// GetSettings returns the settings of authorization, the unknown roles and the invalid teams are refused
+func (authorizationConfig *AuthorizationConfig) GetSettings() (api.AuthorizationConfig, error) {
+	defaultRole := moira.Role(authorizationConfig.DefaultRole)
+	if defaultRole != "" && !defaultRole.IsValid() {
+		return api.AuthorizationConfig{}, fmt.Errorf("unknown default role '%s'", defaultRole)
+	}
+	users := make(map[string]moira.Role, len(authorizationConfig.Users))
+	for login, role := range authorizationConfig.Users {
+		users[login] = moira.Role(role)
+		if !users[login].IsValid() {
+			return api.AuthorizationConfig{}, fmt.Errorf("unknown role '%s' of %s", role, login)
+		}
+	}
+	teamIDs := make(map[string]bool, len(authorizationConfig.Teams))
+	for _, team := range authorizationConfig.Teams {
+		if err := team.Validate(); err != nil {
+			return api.AuthorizationConfig{}, fmt.Errorf("invalid team '%s': %v", team.ID, err)
+		}
+		if teamIDs[team.ID] {
+			return api.AuthorizationConfig{}, fmt.Errorf("team '%s' is defined twice", team.ID)
+		}
+		teamIDs[team.ID] = true
+	}
+	return api.AuthorizationConfig{
+		Enabled:     authorizationConfig.Enabled,
+		DefaultRole: defaultRole,
+		Users:       users,
+		Teams:       authorizationConfig.Teams,
+	}, nil
+}
+
+// AccessConfig tells who can do what, it is shared by api and the chat commands of notifier,
+// which reads the config file of api
+type AccessConfig struct {
+	Authorization AuthorizationConfig `yaml:"authorization"`
+	SuperUsers    []string            `yaml:"super_users"` // admins regardless of authorization
+}
+
+// TriggerValidationConfig is the part of api settings the triggers are validated with,
+// it is shared by api and the declarative sync of moira-cli, which reads the config file of api
+type TriggerValidationConfig struct {
+	GrafanaPrefixes    []string          `yaml:"grafana_prefixes"`
+	TargetRewriteRules []api.RewriteRule `yaml:"target_rewrite"`
+}
+
+// NewDefaultTriggerValidationConfig returns the defaults of api
+func NewDefaultTriggerValidationConfig() TriggerValidationConfig {
+	return TriggerValidationConfig{
+		GrafanaPrefixes:    []string{},
+		TargetRewriteRules: []api.RewriteRule{},
+	}
+}
+
+// GetSettings returns api settings with the validation part filled
+func (triggerValidationConfig *TriggerValidationConfig) GetSettings() api.Config {
+	grafanaPrefixes := make([]string, 0, len(triggerValidationConfig.GrafanaPrefixes))
+	for _, prefix := range triggerValidationConfig.GrafanaPrefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			grafanaPrefixes = append(grafanaPrefixes, prefix)
+		}
+	}
+	return api.Config{
+		GrafanaPrefixes:    grafanaPrefixes,
+		TargetRewriteRules: triggerValidationConfig.TargetRewriteRules,
+	}
+}
+
 type RateLimit struct {
 	AcceptRate float64 `yaml:"rate"`
 	ThreadsQty int     `yaml:"threads"`
@@ -15,12 +15,13 @@ type config struct {
 }
 
 type filterConfig struct {
-	Listen            string           `yaml:"listen"`
-	LimitLogger       cmd.RateLimit    `yaml:"limit_logger"`
-	LimitMetrics      cmd.RateLimit    `yaml:"limit_metrics"`
-	MaxParallelChecks int              `yaml:"max_parallel_checks"`
-	RetentionConfig   string           `yaml:"retention-config"`
-	Sentry            cmd.SentryConfig `yaml:"sentry"`
+	Listen                    string           `yaml:"listen"`
+	LimitLogger               cmd.RateLimit    `yaml:"limit_logger"`
+	LimitMetrics              cmd.RateLimit    `yaml:"limit_metrics"`
+	MaxParallelChecks         int              `yaml:"max_parallel_checks"`
+	RetentionConfig           string           `yaml:"retention-config"`
+	Sentry                    cmd.SentryConfig `yaml:"sentry"`
+	PatternsReconcileInterval string           `yaml:"patterns_reconcile_interval"`
 }
 
 func getDefault() config {
@@ -35,10 +36,11 @@ func getDefault() config {
 			LogLevel: "debug",
 		},
 		Filter: filterConfig{
-			Listen:          ":2003",
-			LimitLogger:     cmd.NewDefaultLoggerRateLimit(),
-			LimitMetrics:    cmd.NewDefaultMetricsRateLimit(),
-			RetentionConfig: "/etc/moira/storage-schemas.conf",
+			Listen:                    ":2003",
+			LimitLogger:               cmd.NewDefaultLoggerRateLimit(),
+			LimitMetrics:              cmd.NewDefaultMetricsRateLimit(),
+			RetentionConfig:           "/etc/moira/storage-schemas.conf",
+			PatternsReconcileInterval: "1m",
 			Sentry: cmd.SentryConfig{
 				Dsn:     "",
 				Enabled: false,
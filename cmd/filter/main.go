@@ -10,6 +10,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/gosexy/to"
+
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/cmd"
 	"go.avito.ru/DO/moira/database/redis"
@@ -100,7 +102,14 @@ func main() {
 	}
 
 	cacheMetrics := metrics.NewFilterMetrics()
-	database := redis.NewDatabase(logger, config.Redis.GetSettings())
+	databaseSettings, err := config.Redis.GetSettings()
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
+	database, err := redis.NewDatabase(logger, databaseSettings)
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
 
 	retentionConfigFile, err := os.Open(config.Filter.RetentionConfig)
 	if err != nil {
@@ -118,7 +127,9 @@ func main() {
 	}
 
 	// Refresh Patterns on first init
-	refreshPatternWorker := patterns.NewRefreshPatternWorker(database, logger, patternStorage)
+	refreshPatternWorker := patterns.NewRefreshPatternWorker(
+		database, logger, patternStorage, to.Duration(config.Filter.PatternsReconcileInterval),
+	)
 
 	// Start patterns refresher
 	err = refreshPatternWorker.Start()
@@ -1,25 +1,30 @@
 package main
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gosexy/to"
 
 	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/cmd"
 	"go.avito.ru/DO/moira/notifier"
+	"go.avito.ru/DO/moira/notifier/contacts"
 	"go.avito.ru/DO/moira/notifier/selfstate"
 )
 
 type config struct {
-	Redis    cmd.RedisConfig    `yaml:"redis"`
-	Neo4j    cmd.Neo4jConfig    `yaml:"neo4j"`
-	Logger   cmd.LoggerConfig   `yaml:"log"`
-	Rsyslog  cmd.RsyslogConfig  `yaml:"rsyslog"`
-	Statsd   cmd.StatsdConfig   `yaml:"statsd"`
-	Notifier notifierConfig     `yaml:"notifier"`
-	Pprof    cmd.ProfilerConfig `yaml:"pprof"`
-	Liveness cmd.LivenessConfig `yaml:"liveness"`
+	Redis     cmd.RedisConfig     `yaml:"redis"`
+	Neo4j     cmd.Neo4jConfig     `yaml:"neo4j"`
+	Logger    cmd.LoggerConfig    `yaml:"log"`
+	Rsyslog   cmd.RsyslogConfig   `yaml:"rsyslog"`
+	Statsd    cmd.StatsdConfig    `yaml:"statsd"`
+	Notifier  notifierConfig      `yaml:"notifier"`
+	Netbox    cmd.NetboxConfig    `yaml:"netbox"`
+	Inventory cmd.InventoryConfig `yaml:"inventory"`
+	Pprof     cmd.ProfilerConfig  `yaml:"pprof"`
+	Liveness  cmd.LivenessConfig  `yaml:"liveness"`
 }
 
 type notifierConfig struct {
@@ -34,7 +39,21 @@ type notifierConfig struct {
 	LimitMetrics     cmd.RateLimit       `yaml:"limit_metrics"`
 	DutyApiToken     string              `yaml:"duty_api_token"`
 	DutyUrl          string              `yaml:"duty_url"`
+	OnCall           onCallConfig        `yaml:"on_call"`
 	FanURL           string              `yaml:"fan_url"`
+	APIConfigPath    string              `yaml:"api_config"` // chat commands check the permissions with the settings of api
+}
+
+// onCallConfig sets up the providers of the people on duty, duty.cc and the rotations maintained in Moira are always available
+type onCallConfig struct {
+	Default string               `yaml:"default"` // provider of duty_<service> contacts, duty.cc by default
+	HTTP    []httpOnCallProvider `yaml:"http"`
+}
+
+type httpOnCallProvider struct {
+	Name    string            `yaml:"name"` // duty_<name>:<service> contacts are resolved by the provider
+	URL     string            `yaml:"url"`  // {service} is replaced with the name of the service
+	Headers map[string]string `yaml:"headers"`
 }
 
 type selfStateConfig struct {
@@ -92,13 +111,14 @@ func getDefault() config {
 				Dsn:     "",
 				Enabled: false,
 			},
-			FrontURI:     "http://localhost",
-			Timezone:     "UTC",
-			LimitLogger:  cmd.NewDefaultLoggerRateLimit(),
-			LimitMetrics: cmd.NewDefaultMetricsRateLimit(),
-			DutyApiToken: "",
-			DutyUrl:      "",
-			FanURL:       "http://localhost:3260/api",
+			FrontURI:      "http://localhost",
+			Timezone:      "UTC",
+			LimitLogger:   cmd.NewDefaultLoggerRateLimit(),
+			LimitMetrics:  cmd.NewDefaultMetricsRateLimit(),
+			DutyApiToken:  "",
+			DutyUrl:       "",
+			FanURL:        "http://localhost:3260/api",
+			APIConfigPath: "/etc/moira/api.yml",
 		},
 		Pprof: cmd.ProfilerConfig{
 			Listen: "",
@@ -124,8 +144,45 @@ func (config *notifierConfig) getSettings(logger moira.Logger) notifier.Config {
 		Senders:          config.Senders,
 		FrontURL:         config.FrontURI,
 		Location:         location,
-		DutyApiToken:     config.DutyApiToken,
-		DutyUrl:          config.DutyUrl,
+		OnCall:           config.getOnCallSettings(),
+	}
+}
+
+// apiFileConfig is the part of the config file of api which is needed to check the permissions of chat commands
+type apiFileConfig struct {
+	API struct {
+		Access cmd.AccessConfig `yaml:",inline"`
+	} `yaml:"api"`
+}
+
+// getAccessSettings reads the roles of the users and the super users from the config file of api,
+// so that chat commands can't do what api doesn't allow
+func (config *notifierConfig) getAccessSettings() (api.AuthorizationConfig, []string, error) {
+	apiFile := apiFileConfig{}
+	if err := cmd.ReadConfig(config.APIConfigPath, &apiFile); err != nil {
+		return api.AuthorizationConfig{}, nil, err
+	}
+	authorization, err := apiFile.API.Access.Authorization.GetSettings()
+	if err != nil {
+		return api.AuthorizationConfig{}, nil, fmt.Errorf("invalid authorization: %v", err)
+	}
+	return authorization, apiFile.API.Access.SuperUsers, nil
+}
+
+func (config *notifierConfig) getOnCallSettings() contacts.OnCallConfig {
+	httpProviders := make([]contacts.HTTPOnCallConfig, len(config.OnCall.HTTP))
+	for i, provider := range config.OnCall.HTTP {
+		httpProviders[i] = contacts.HTTPOnCallConfig{
+			Name:    provider.Name,
+			URL:     provider.URL,
+			Headers: provider.Headers,
+		}
+	}
+	return contacts.OnCallConfig{
+		Default:      config.OnCall.Default,
+		DutyAPIToken: config.DutyApiToken,
+		DutyURL:      config.DutyUrl,
+		HTTP:         httpProviders,
 	}
 }
 
@@ -23,6 +23,7 @@ import (
 	"go.avito.ru/DO/moira/notifier/selfstate"
 	"go.avito.ru/DO/moira/panicwrap"
 	"go.avito.ru/DO/moira/sentry"
+	"go.avito.ru/DO/moira/silencer"
 )
 
 const (
@@ -96,8 +97,14 @@ func main() {
 		cmd.StartLiveness(logger, config.Liveness)
 	}
 
-	databaseSettings := config.Redis.GetSettings()
-	database := redis.NewDatabase(logger, databaseSettings)
+	databaseSettings, err := config.Redis.GetSettings()
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
+	database, err := redis.NewDatabase(logger, databaseSettings)
+	if err != nil {
+		logger.FatalF("Can not configure Redis: %v", err)
+	}
 	notifierMetrics := metrics.NewNotifierMetrics()
 
 	triggerInheritanceDatabase, err := neo4j.NewDatabase(logger, config.Neo4j)
@@ -106,6 +113,15 @@ func main() {
 	}
 
 	notifierConfig := config.Notifier.getSettings(logger)
+	notifierConfig.Authorization, notifierConfig.SuperUsers, err = config.Notifier.getAccessSettings()
+	if err != nil {
+		logger.FatalF("Can not read settings of api: %v", err)
+	}
+	notifierConfig.Inventory, err = config.Inventory.NewProvider(config.Netbox)
+	if err != nil {
+		logger.FatalF("Can not configure inventory provider: %v", err)
+	}
+	silencer.SetBlacklist(config.Inventory.Blacklist)
 	sender := notifier.NewNotifier(database, notifierConfig, notifierMetrics)
 
 	// Register moira senders
@@ -11,6 +11,17 @@ func (sb *SlackDashboard) Update(events NotificationEvents, triggerName string)
 	hasChanged = false
 	changes = make(NotificationEvents, 0, len(events))
 	for _, event := range events {
+		if event.MetricRemoved {
+			// the removed metric is not shown any more, the removal of the one which was OK is not a change to tell about
+			if _, ok := (*sb)[event.Metric]; ok {
+				hasChanged = true
+				delete(*sb, event.Metric)
+				if !event.DashboardOnly {
+					changes = append(changes, event)
+				}
+			}
+			continue
+		}
 		if event.State == OK {
 			if oldState, ok := (*sb)[event.Metric]; ok && !oldState {
 				hasChanged = true
@@ -41,7 +52,9 @@ func (sb SlackDashboard) IsEverythingOK() bool {
 func MakeDashboardFromEvents(events NotificationEvents) SlackDashboard {
 	result := make(SlackDashboard)
 	for _, event := range events {
-		result[event.Metric] = event.State == OK
+		if !event.DashboardOnly {
+			result[event.Metric] = event.State == OK
+		}
 	}
 	return result
 }
@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/database"
+)
+
+// apiTokensKey maps token ID to token
+func apiTokensKey() string {
+	return withHashTag("moira-api-tokens")
+}
+
+// apiTokenHashesKey maps hash of the token to token ID
+func apiTokenHashesKey() string {
+	return withHashTag("moira-api-token-hashes")
+}
+
+// GetAPITokens returns all API tokens
+func (connector *DbConnector) GetAPITokens() ([]*moira.APIToken, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	values, err := redis.StringMap(c.Do("HGETALL", apiTokensKey()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGETALL %s: %v", apiTokensKey(), err)
+	}
+
+	tokens := make([]*moira.APIToken, 0, len(values))
+	for id, value := range values {
+		token := &moira.APIToken{}
+		if err := json.Unmarshal([]byte(value), token); err != nil {
+			return nil, fmt.Errorf("Failed to parse API token %s: %v", id, err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// GetAPIToken returns API token by its ID, database.ErrNil is returned if there is no such token
+func (connector *DbConnector) GetAPIToken(tokenID string) (*moira.APIToken, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	value, err := redis.Bytes(c.Do("HGET", apiTokensKey(), tokenID))
+	if err == redis.ErrNil {
+		return nil, database.ErrNil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGET %s: %v", apiTokensKey(), err)
+	}
+
+	token := &moira.APIToken{}
+	if err := json.Unmarshal(value, token); err != nil {
+		return nil, fmt.Errorf("Failed to parse API token %s: %v", tokenID, err)
+	}
+	return token, nil
+}
+
+// GetAPITokenByHash returns API token by hash of its value, database.ErrNil is returned if there is no such token
+func (connector *DbConnector) GetAPITokenByHash(hash string) (*moira.APIToken, error) {
+	c := connector.pool.Get()
+	tokenID, err := redis.String(c.Do("HGET", apiTokenHashesKey(), hash))
+	c.Close()
+
+	if err == redis.ErrNil {
+		return nil, database.ErrNil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGET %s: %v", apiTokenHashesKey(), err)
+	}
+	return connector.GetAPIToken(tokenID)
+}
+
+// SaveAPIToken creates or replaces API token
+func (connector *DbConnector) SaveAPIToken(token *moira.APIToken) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	bytes, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	c.Send("MULTI")
+	c.Send("HSET", apiTokensKey(), token.ID, bytes)
+	c.Send("HSET", apiTokenHashesKey(), token.Hash, token.ID)
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %v", err)
+	}
+	return nil
+}
+
+// RemoveAPIToken revokes API token
+func (connector *DbConnector) RemoveAPIToken(tokenID string) error {
+	token, err := connector.GetAPIToken(tokenID)
+	if err == database.ErrNil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	c.Send("MULTI")
+	c.Send("HDEL", apiTokensKey(), tokenID)
+	c.Send("HDEL", apiTokenHashesKey(), token.Hash)
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %v", err)
+	}
+	return nil
+}
@@ -89,5 +89,5 @@ func (connector *DbConnector) DeregisterBot(messenger string) bool {
 }
 
 func usernameKey(messenger, username string) string {
-	return fmt.Sprintf("moira-%s-users:%s", messenger, username)
+	return withHashTag(fmt.Sprintf("moira-%s-users:%s", messenger, username))
 }
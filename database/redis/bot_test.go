@@ -65,7 +65,7 @@ import (
 
 func TestBotDataStoring(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -212,7 +212,7 @@ func TestBotDataStoring(t *testing.T) {
 
 func TestBotDataStoringErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 	Convey("Should throw error when no connection", t, func() {
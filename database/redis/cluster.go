@@ -0,0 +1,644 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const (
+	clusterSlotsCount = 16384
+	// clusterMaxRedirects limits the redirections a command follows while the slots are moved between the nodes
+	clusterMaxRedirects = 5
+	// moiraHashTag puts all the keys of Moira into the same slot so that the transactions and the multi-key commands
+	// keep working on Redis Cluster, only the values and the retentions of the metrics are spread over the nodes
+	moiraHashTag = "{moira}"
+)
+
+var errClusterConnClosed = errors.New("redigo: connection closed")
+
+// hashTagsEnabled is set for Redis Cluster only, the keys of the standalone instances are left as they are
+var hashTagsEnabled bool
+
+// withHashTag puts the key into the slot of Moira on Redis Cluster
+func withHashTag(key string) string {
+	if !hashTagsEnabled {
+		return key
+	}
+	return moiraHashTag + key
+}
+
+// metricHashTag makes the keys of the metric share the slot which is chosen by the name of the metric on Redis Cluster
+func metricHashTag(metric string) string {
+	if !hashTagsEnabled {
+		return metric
+	}
+	return "{" + metric + "}"
+}
+
+// getSlot returns the slot of the key, only the hash tag is hashed if the key has one
+func getSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % clusterSlotsCount)
+}
+
+// crc16 is CRC-16/XMODEM Redis Cluster hashes the keys with
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// getCommandKey returns the key the command is routed by, the commands without keys go to any node
+func getCommandKey(name string, args []interface{}) (string, bool) {
+	switch name {
+	case "", "MULTI", "EXEC", "DISCARD", "UNWATCH", "PING", "ECHO", "INFO", "TIME", "ASKING", "CLUSTER", "SCRIPT",
+		"PUBLISH", "SUBSCRIBE", "UNSUBSCRIBE", "PSUBSCRIBE", "PUNSUBSCRIBE", "FLUSHDB", "FLUSHALL":
+		return "", false
+	case "EVAL", "EVALSHA":
+		if len(args) < 3 {
+			return "", false
+		}
+		if numKeys, err := strconv.Atoi(formatKey(args[1])); err != nil || numKeys == 0 {
+			return "", false
+		}
+		return formatKey(args[2]), true
+	case "XREAD", "XREADGROUP":
+		for i := 0; i < len(args)-1; i++ {
+			if strings.EqualFold(formatKey(args[i]), "STREAMS") {
+				return formatKey(args[i+1]), true
+			}
+		}
+		return "", false
+	case "XGROUP", "XINFO":
+		if len(args) < 2 {
+			return "", false
+		}
+		return formatKey(args[1]), true
+	}
+	if len(args) == 0 {
+		return "", false
+	}
+	return formatKey(args[0]), true
+}
+
+func formatKey(arg interface{}) string {
+	switch arg := arg.(type) {
+	case string:
+		return arg
+	case []byte:
+		return string(arg)
+	default:
+		return fmt.Sprint(arg)
+	}
+}
+
+// redirection is the reply of the node which doesn't serve the slot of the key
+type redirection struct {
+	ask  bool
+	slot int
+	addr string
+}
+
+func parseRedirection(err error) (redirection, bool) {
+	redisErr, ok := err.(redis.Error)
+	if !ok {
+		return redirection{}, false
+	}
+	fields := strings.Fields(string(redisErr))
+	if len(fields) != 3 || (fields[0] != "MOVED" && fields[0] != "ASK") {
+		return redirection{}, false
+	}
+	slot, err := strconv.Atoi(fields[1])
+	if err != nil || slot < 0 || slot >= clusterSlotsCount {
+		return redirection{}, false
+	}
+	return redirection{ask: fields[0] == "ASK", slot: slot, addr: fields[2]}, true
+}
+
+// cluster routes the commands to the masters of Redis Cluster by the slots of their keys,
+// the slots are discovered via the configured nodes and rediscovered once a node redirects a command
+type cluster struct {
+	addrs   []string
+	newPool func(addr string) *redis.Pool
+
+	refreshMutex sync.Mutex
+	refreshing   int32
+
+	mutex sync.RWMutex
+	slots []string // the address of the master of every slot, nil until the slots are discovered
+	pools map[string]*redis.Pool
+}
+
+func newCluster(config ClusterConfig, newPool func(addr string) *redis.Pool) *cluster {
+	return &cluster{
+		addrs:   append([]string(nil), config.Addrs...),
+		newPool: newPool,
+		pools:   make(map[string]*redis.Pool),
+	}
+}
+
+// Get returns the connection which routes the commands to the nodes, it has to be closed as the pooled ones
+func (cl *cluster) Get() redis.Conn {
+	return &clusterConn{cluster: cl, conns: make(map[string]redis.Conn)}
+}
+
+// getAnyConn returns the pooled connection to a random master for the commands without keys which outlive
+// a single request as Pub/Sub subscriptions do
+func (cl *cluster) getAnyConn() redis.Conn {
+	addr, err := cl.getAnyNode()
+	if err != nil {
+		return errorConn{err: err}
+	}
+	c := cl.getPool(addr).Get()
+	if c.Err() != nil {
+		cl.refreshLater()
+	}
+	return c
+}
+
+func (cl *cluster) getNode(slot int) (string, error) {
+	slots, err := cl.getSlots()
+	if err != nil {
+		return "", err
+	}
+	if slots[slot] == "" {
+		cl.refreshLater()
+		return "", fmt.Errorf("Slot %d isn't served by any node of Redis Cluster", slot)
+	}
+	return slots[slot], nil
+}
+
+func (cl *cluster) getAnyNode() (string, error) {
+	return cl.getNode(rand.Intn(clusterSlotsCount))
+}
+
+func (cl *cluster) getSlots() ([]string, error) {
+	cl.mutex.RLock()
+	slots := cl.slots
+	cl.mutex.RUnlock()
+	if slots != nil {
+		return slots, nil
+	}
+
+	if err := cl.refresh(); err != nil {
+		return nil, err
+	}
+	cl.mutex.RLock()
+	defer cl.mutex.RUnlock()
+	return cl.slots, nil
+}
+
+// setNode moves the slot to the node which the slot has been redirected to until the slots are rediscovered
+func (cl *cluster) setNode(slot int, addr string) {
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+	if cl.slots == nil || cl.slots[slot] == addr {
+		return
+	}
+	slots := append([]string(nil), cl.slots...)
+	slots[slot] = addr
+	cl.slots = slots
+}
+
+func (cl *cluster) getPool(addr string) *redis.Pool {
+	cl.mutex.RLock()
+	pool, ok := cl.pools[addr]
+	cl.mutex.RUnlock()
+	if ok {
+		return pool
+	}
+
+	cl.mutex.Lock()
+	defer cl.mutex.Unlock()
+	if pool, ok = cl.pools[addr]; !ok {
+		pool = cl.newPool(addr)
+		cl.pools[addr] = pool
+	}
+	return pool
+}
+
+// refresh asks the known nodes one by one for the masters of the slots
+func (cl *cluster) refresh() error {
+	cl.refreshMutex.Lock()
+	defer cl.refreshMutex.Unlock()
+
+	var lastErr error
+	for _, addr := range cl.getKnownNodes() {
+		slots, err := cl.discoverSlots(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		cl.mutex.Lock()
+		cl.slots = slots
+		cl.mutex.Unlock()
+		return nil
+	}
+	return fmt.Errorf("Failed to discover slots of Redis Cluster: %v", lastErr)
+}
+
+// refreshLater rediscovers the slots in background, the commands aren't held while the slots are being moved
+func (cl *cluster) refreshLater() {
+	if !atomic.CompareAndSwapInt32(&cl.refreshing, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&cl.refreshing, 0)
+		cl.refresh()
+	}()
+}
+
+// getKnownNodes returns the configured nodes followed by the masters discovered before
+func (cl *cluster) getKnownNodes() []string {
+	nodes := append([]string(nil), cl.addrs...)
+	known := make(map[string]bool, len(nodes))
+	for _, addr := range nodes {
+		known[addr] = true
+	}
+
+	cl.mutex.RLock()
+	defer cl.mutex.RUnlock()
+	for _, addr := range cl.slots {
+		if addr != "" && !known[addr] {
+			known[addr] = true
+			nodes = append(nodes, addr)
+		}
+	}
+	return nodes
+}
+
+func (cl *cluster) discoverSlots(addr string) ([]string, error) {
+	c := cl.getPool(addr).Get()
+	defer c.Close()
+
+	ranges, err := redis.Values(c.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return nil, err
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("node %s serves no slots", addr)
+	}
+
+	slots := make([]string, clusterSlotsCount)
+	for _, slotsRange := range ranges {
+		fields, err := redis.Values(slotsRange, nil)
+		if err != nil || len(fields) < 3 {
+			return nil, fmt.Errorf("unexpected slots range %v", slotsRange)
+		}
+		start, startErr := redis.Int(fields[0], nil)
+		end, endErr := redis.Int(fields[1], nil)
+		master, masterErr := redis.Values(fields[2], nil)
+		if startErr != nil || endErr != nil || masterErr != nil || len(master) < 2 ||
+			start < 0 || start > end || end >= clusterSlotsCount {
+			return nil, fmt.Errorf("unexpected slots range %v", slotsRange)
+		}
+		host, _ := redis.String(master[0], nil)
+		port, err := redis.Int(master[1], nil)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected master of slots %d-%d: %v", start, end, master)
+		}
+		if host == "" {
+			// the node which is asked may not know its own address
+			host, _, _ = net.SplitHostPort(addr)
+		}
+
+		node := net.JoinHostPort(host, strconv.Itoa(port))
+		for slot := start; slot <= end; slot++ {
+			slots[slot] = node
+		}
+	}
+	return slots, nil
+}
+
+// follow runs the redirected command on the node the slot is served by now
+func (cl *cluster) follow(r redirection, name string, args []interface{}) (interface{}, error) {
+	for i := 0; ; i++ {
+		if !r.ask {
+			cl.setNode(r.slot, r.addr)
+			cl.refreshLater()
+		}
+
+		reply, err := cl.doOnNode(r.addr, r.ask, name, args)
+		next, ok := parseRedirection(err)
+		if !ok || i == clusterMaxRedirects {
+			return reply, err
+		}
+		r = next
+	}
+}
+
+func (cl *cluster) doOnNode(addr string, ask bool, name string, args []interface{}) (interface{}, error) {
+	c := cl.getPool(addr).Get()
+	defer c.Close()
+
+	if ask {
+		// the slot is being imported by the node, it serves the keys which have been moved already after ASKING only
+		if err := c.Send("ASKING"); err != nil {
+			return nil, err
+		}
+	}
+	return c.Do(name, args...)
+}
+
+// clusterCommand is the command which reply hasn't been received yet
+type clusterCommand struct {
+	addr string
+	name string
+	args []interface{}
+	// the commands of the transactions aren't redirected, the nodes discard the transactions with redirected keys
+	transaction bool
+}
+
+// clusterConn routes the commands to the connections to the nodes by the slots of their keys, the replies are received
+// in the order the commands are sent, so the pipelines of the commands for the keys of different slots keep working.
+// A transaction is sent to the node of its first key, its keys have to share the slot as Redis Cluster demands
+type clusterConn struct {
+	cluster *cluster
+	conns   map[string]redis.Conn
+	pending []clusterCommand
+	// last is the node of the last command, the commands without keys are sent to it
+	last string
+
+	multi     bool
+	multiAddr string
+	// queued are the commands of the transaction which are sent before the first key
+	queued []clusterCommand
+
+	err error
+}
+
+// Send routes the command, a transaction is held until its first key is sent
+func (c *clusterConn) Send(cmd string, args ...interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	command := clusterCommand{name: strings.ToUpper(cmd), args: args}
+	if command.name == "MULTI" {
+		c.multi = true
+	}
+	if !c.multi {
+		addr, err := c.route(command)
+		if err != nil {
+			return err
+		}
+		return c.send(addr, command)
+	}
+
+	command.transaction = true
+	if c.multiAddr != "" {
+		if err := c.send(c.multiAddr, command); err != nil {
+			return err
+		}
+	} else {
+		c.queued = append(c.queued, command)
+		if _, ok := getCommandKey(command.name, args); !ok && command.name != "EXEC" && command.name != "DISCARD" {
+			return nil
+		}
+		if err := c.sendTransaction(command); err != nil {
+			return err
+		}
+	}
+	if command.name == "EXEC" || command.name == "DISCARD" {
+		c.multi, c.multiAddr = false, ""
+	}
+	return nil
+}
+
+// sendTransaction sends the queued commands of the transaction to the node of the given command
+func (c *clusterConn) sendTransaction(command clusterCommand) error {
+	queued := c.queued
+	c.queued = nil
+
+	addr, err := c.route(command)
+	if err != nil {
+		return err
+	}
+	c.multiAddr = addr
+	for _, command := range queued {
+		if err := c.send(addr, command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clusterConn) route(command clusterCommand) (string, error) {
+	if key, ok := getCommandKey(command.name, command.args); ok {
+		return c.cluster.getNode(getSlot(key))
+	}
+	if c.last != "" {
+		return c.last, nil
+	}
+	return c.cluster.getAnyNode()
+}
+
+func (c *clusterConn) send(addr string, command clusterCommand) error {
+	conn, err := c.getConn(addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.Send(command.name, command.args...); err != nil {
+		return err
+	}
+	command.addr = addr
+	c.pending = append(c.pending, command)
+	c.last = addr
+	return nil
+}
+
+func (c *clusterConn) getConn(addr string) (redis.Conn, error) {
+	if conn, ok := c.conns[addr]; ok {
+		return conn, nil
+	}
+	conn := c.cluster.getPool(addr).Get()
+	if err := conn.Err(); err != nil {
+		conn.Close()
+		// the node may have left the cluster
+		c.cluster.refreshLater()
+		return nil, err
+	}
+	c.conns[addr] = conn
+	return conn, nil
+}
+
+// Flush sends the buffered commands to all the nodes, the transaction without keys goes to any node
+func (c *clusterConn) Flush() error {
+	if c.err != nil {
+		return c.err
+	}
+	if len(c.queued) > 0 {
+		if err := c.sendTransaction(c.queued[0]); err != nil {
+			return err
+		}
+	}
+	for _, conn := range c.conns {
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Receive receives the reply of the earliest command, the redirected commands are run again on the proper nodes
+func (c *clusterConn) Receive() (interface{}, error) {
+	return c.receive(func(conn redis.Conn) (interface{}, error) {
+		return conn.Receive()
+	})
+}
+
+// ReceiveWithTimeout is Receive which waits for the reply as long as the timeout is
+func (c *clusterConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.receive(func(conn redis.Conn) (interface{}, error) {
+		return redis.ReceiveWithTimeout(conn, timeout)
+	})
+}
+
+func (c *clusterConn) receive(receive func(redis.Conn) (interface{}, error)) (interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(c.pending) == 0 {
+		// the pushed messages come without the commands
+		conn, ok := c.conns[c.last]
+		if !ok {
+			return nil, errors.New("redigo: no pending replies")
+		}
+		return c.checkReply(receive(conn))
+	}
+
+	command := c.pending[0]
+	c.pending = c.pending[1:]
+	reply, err := c.checkReply(receive(c.conns[command.addr]))
+	if r, ok := parseRedirection(err); ok && !command.transaction {
+		return c.cluster.follow(r, command.name, command.args)
+	}
+	return reply, err
+}
+
+// checkReply breaks the connection once a node connection is broken, the replies of the nodes can't be matched anymore
+func (c *clusterConn) checkReply(reply interface{}, err error) (interface{}, error) {
+	if err != nil {
+		if _, ok := err.(redis.Error); !ok {
+			c.err = err
+		}
+	}
+	return reply, err
+}
+
+// Do sends the command and receives all the pending replies as redis.Conn does
+func (c *clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.do(c.Receive, cmd, args)
+}
+
+// DoWithTimeout is Do which waits for the replies as long as the timeout is
+func (c *clusterConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return c.do(func() (interface{}, error) {
+		return c.ReceiveWithTimeout(timeout)
+	}, cmd, args)
+}
+
+func (c *clusterConn) do(receive func() (interface{}, error), cmd string, args []interface{}) (interface{}, error) {
+	if cmd != "" {
+		if err := c.Send(cmd, args...); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+
+	if cmd == "" {
+		replies := make([]interface{}, len(c.pending))
+		for i := range replies {
+			reply, err := receive()
+			if redisErr, ok := err.(redis.Error); ok {
+				reply = redisErr
+			} else if err != nil {
+				return nil, err
+			}
+			replies[i] = reply
+		}
+		return replies, nil
+	}
+
+	var reply interface{}
+	var firstErr error
+	for len(c.pending) > 0 {
+		var err error
+		reply, err = receive()
+		if redisErr, ok := err.(redis.Error); ok {
+			reply = redisErr
+			if firstErr == nil {
+				firstErr = redisErr
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return reply, firstErr
+}
+
+// Err returns the error of the broken connection to any node
+func (c *clusterConn) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	for _, conn := range c.conns {
+		if err := conn.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close returns the connections to the nodes to their pools
+func (c *clusterConn) Close() error {
+	if c.err == errClusterConnClosed {
+		return nil
+	}
+	var firstErr error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.conns = nil
+	c.pending = nil
+	c.err = errClusterConnClosed
+	return firstErr
+}
+
+// errorConn is returned when there's no node to connect to as redis.Pool does when it fails to dial
+type errorConn struct {
+	err error
+}
+
+func (ec errorConn) Do(string, ...interface{}) (interface{}, error) { return nil, ec.err }
+func (ec errorConn) Send(string, ...interface{}) error              { return ec.err }
+func (ec errorConn) Err() error                                     { return ec.err }
+func (ec errorConn) Close() error                                   { return nil }
+func (ec errorConn) Flush() error                                   { return ec.err }
+func (ec errorConn) Receive() (interface{}, error)                  { return nil, ec.err }
@@ -0,0 +1,281 @@
+package redis
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+// fakeClusterNode serves the given slots and redirects the commands for the rest of them to the other node
+type fakeClusterNode struct {
+	addr     string
+	stop     func()
+	commands []string
+	values   map[string]string
+}
+
+// startFakeCluster starts two nodes, the first one serves slots 0-8191 and the second one serves slots 8192-16383
+func startFakeCluster(t *testing.T) (*fakeClusterNode, *fakeClusterNode) {
+	first := &fakeClusterNode{values: make(map[string]string)}
+	second := &fakeClusterNode{values: make(map[string]string)}
+	slots := func() string {
+		return "*2\r\n" + formatSlotsRange(0, 8191, first.addr) + formatSlotsRange(8192, 16383, second.addr)
+	}
+	serve := func(node, other *fakeClusterNode, owns func(slot int) bool) func(args []string) string {
+		multi := 0
+		return func(args []string) string {
+			node.commands = append(node.commands, strings.Join(args, " "))
+			switch strings.ToUpper(args[0]) {
+			case "CLUSTER":
+				return slots()
+			case "PING":
+				return "+PONG\r\n"
+			case "MULTI":
+				multi = 1
+				return "+OK\r\n"
+			case "EXEC":
+				replies := fmt.Sprintf("*%d\r\n%s", multi-1, strings.Repeat("+OK\r\n", multi-1))
+				multi = 0
+				return replies
+			}
+
+			slot := getSlot(args[1])
+			if !owns(slot) {
+				return fmt.Sprintf("-MOVED %d %s\r\n", slot, other.addr)
+			}
+			if multi > 0 {
+				multi++
+				return "+QUEUED\r\n"
+			}
+			if strings.ToUpper(args[0]) == "SET" {
+				node.values[args[1]] = args[2]
+				return "+OK\r\n"
+			}
+			value, ok := node.values[args[1]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		}
+	}
+	first.addr, first.stop = startFakeNode(t, serve(first, second, func(slot int) bool { return slot < 8192 }))
+	second.addr, second.stop = startFakeNode(t, serve(second, first, func(slot int) bool { return slot >= 8192 }))
+	return first, second
+}
+
+func formatSlotsRange(start, end int, addr string) string {
+	host, port, _ := net.SplitHostPort(addr)
+	return fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n*2\r\n$%d\r\n%s\r\n:%s\r\n", start, end, len(host), host, port)
+}
+
+func newTestCluster(addrs ...string) *cluster {
+	return newCluster(ClusterConfig{Addrs: addrs}, func(addr string) *redis.Pool {
+		return newPool(Config{}, func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr)
+		}, nil)
+	})
+}
+
+func TestGetSlot(t *testing.T) {
+	Convey("Keys are hashed as Redis Cluster does", t, func() {
+		So(crc16("123456789"), ShouldEqual, 0x31C3)
+		So(getSlot("foo"), ShouldEqual, 12182)
+		So(getSlot("bar"), ShouldEqual, 5061)
+	})
+
+	Convey("Only the hash tag is hashed", t, func() {
+		So(getSlot("{user1000}.following"), ShouldEqual, getSlot("user1000"))
+		So(getSlot("{moira}moira-trigger:{id}"), ShouldEqual, getSlot("moira"))
+		So(getSlot("foo{}{bar}"), ShouldEqual, int(crc16("foo{}{bar}")%clusterSlotsCount))
+		So(getSlot("foo{bar"), ShouldEqual, int(crc16("foo{bar")%clusterSlotsCount))
+	})
+}
+
+func TestGetCommandKey(t *testing.T) {
+	Convey("Commands are routed by their keys", t, func() {
+		key, ok := getCommandKey("GET", []interface{}{"foo"})
+		So(ok, ShouldBeTrue)
+		So(key, ShouldEqual, "foo")
+
+		key, ok = getCommandKey("EVALSHA", []interface{}{"sha", 1, []byte("foo"), "value"})
+		So(ok, ShouldBeTrue)
+		So(key, ShouldEqual, "foo")
+
+		key, ok = getCommandKey("XREADGROUP", []interface{}{"GROUP", "group", "consumer", "COUNT", 10, "STREAMS", "foo", ">"})
+		So(ok, ShouldBeTrue)
+		So(key, ShouldEqual, "foo")
+
+		key, ok = getCommandKey("XINFO", []interface{}{"GROUPS", "foo"})
+		So(ok, ShouldBeTrue)
+		So(key, ShouldEqual, "foo")
+	})
+
+	Convey("Commands without keys go to any node", t, func() {
+		for _, name := range []string{"MULTI", "EXEC", "PUBLISH", "PING"} {
+			_, ok := getCommandKey(name, []interface{}{"channel", "message"})
+			So(ok, ShouldBeFalse)
+		}
+		_, ok := getCommandKey("EVAL", []interface{}{"return 1", 0})
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestHashTags(t *testing.T) {
+	defer func() { hashTagsEnabled = false }()
+
+	Convey("Keys of standalone instance aren't tagged", t, func() {
+		hashTagsEnabled = false
+		So(triggerKey("id"), ShouldEqual, "moira-trigger:id")
+		So(metricDataKey("my.metric"), ShouldEqual, "moira-metric-data:my.metric")
+	})
+
+	Convey("Keys of Moira share the slot on Redis Cluster, the keys of the metric share another one", t, func() {
+		hashTagsEnabled = true
+		So(triggerKey("id"), ShouldEqual, "{moira}moira-trigger:id")
+		So(getSlot(triggersListKey()), ShouldEqual, getSlot(patternMetricsKey("{my,your}.metric")))
+		So(getSlot(eventsWithSaturationsListKey()), ShouldEqual, getSlot(allEventsLogKey()))
+		So(metricDataKey("my.metric"), ShouldEqual, "moira-metric-data:{my.metric}")
+		So(getSlot(metricDataKey("my.metric")), ShouldEqual, getSlot(metricRetentionKey("my.metric")))
+	})
+}
+
+func TestCluster(t *testing.T) {
+	Convey("Pipeline is split over the nodes and the replies keep the order", t, func() {
+		first, second := startFakeCluster(t)
+		defer first.stop()
+		defer second.stop()
+		first.values["bar"] = "first"
+		second.values["foo"] = "second"
+
+		c := newTestCluster(first.addr).Get()
+		defer c.Close()
+		c.Send("GET", "foo")
+		c.Send("GET", "bar")
+		c.Send("GET", "foo")
+		replies, err := redis.Strings(c.Do(""))
+		So(err, ShouldBeNil)
+		So(replies, ShouldResemble, []string{"second", "first", "second"})
+	})
+
+	Convey("Transaction is sent to the node of its first key", t, func() {
+		first, second := startFakeCluster(t)
+		defer first.stop()
+		defer second.stop()
+
+		c := newTestCluster(first.addr).Get()
+		defer c.Close()
+		c.Send("MULTI")
+		c.Send("SET", "{foo}.first", "1")
+		c.Send("SET", "{foo}.second", "2")
+		replies, err := redis.Values(c.Do("EXEC"))
+		So(err, ShouldBeNil)
+		So(replies, ShouldHaveLength, 2)
+		So(second.commands, ShouldResemble, []string{"MULTI", "SET {foo}.first 1", "SET {foo}.second 2", "EXEC"})
+	})
+
+	Convey("Moved slot is followed and remembered", t, func() {
+		first, second := startFakeCluster(t)
+		defer first.stop()
+		defer second.stop()
+		second.values["foo"] = "second"
+
+		redisCluster := newTestCluster(first.addr)
+		slots, err := redisCluster.getSlots()
+		So(err, ShouldBeNil)
+		So(slots[getSlot("foo")], ShouldEqual, second.addr)
+		redisCluster.setNode(getSlot("foo"), first.addr)
+
+		c := redisCluster.Get()
+		defer c.Close()
+		value, err := redis.String(c.Do("GET", "foo"))
+		So(err, ShouldBeNil)
+		So(value, ShouldEqual, "second")
+		node, err := redisCluster.getNode(getSlot("foo"))
+		So(err, ShouldBeNil)
+		So(node, ShouldEqual, second.addr)
+	})
+
+	Convey("Slot being migrated is asked for in the pipeline", t, func() {
+		asking := false
+		targetAddr, stopTarget := startFakeNode(t, func(args []string) string {
+			switch args[0] {
+			case "ASKING":
+				asking = true
+				return "+OK\r\n"
+			case "GET":
+				if asking {
+					asking = false
+					return "$8\r\nmigrated\r\n"
+				}
+			}
+			return fmt.Sprintf("-MOVED %d 127.0.0.1:1\r\n", getSlot(args[1]))
+		})
+		defer stopTarget()
+		var sourceAddr string
+		sourceAddr, stopSource := startFakeNode(t, func(args []string) string {
+			switch args[0] {
+			case "CLUSTER":
+				return "*1\r\n" + formatSlotsRange(0, 16383, sourceAddr)
+			case "GET":
+				if args[1] == "foo" {
+					return fmt.Sprintf("-ASK %d %s\r\n", getSlot("foo"), targetAddr)
+				}
+			}
+			return "$6\r\nsource\r\n"
+		})
+		defer stopSource()
+
+		redisCluster := newTestCluster(sourceAddr)
+		c := redisCluster.Get()
+		defer c.Close()
+		c.Send("GET", "foo")
+		c.Send("GET", "bar")
+		replies, err := redis.Strings(c.Do(""))
+		So(err, ShouldBeNil)
+		So(replies, ShouldResemble, []string{"migrated", "source"})
+		node, err := redisCluster.getNode(getSlot("foo"))
+		So(err, ShouldBeNil)
+		So(node, ShouldEqual, sourceAddr)
+	})
+
+	Convey("Connection fails if no node is available", t, func() {
+		first, second := startFakeCluster(t)
+		first.stop()
+		second.stop()
+
+		c := newTestCluster(first.addr).Get()
+		defer c.Close()
+		_, err := c.Do("GET", "foo")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestClusterConfig(t *testing.T) {
+	logger := test_helpers.GetTestLogger()
+	defer func() { hashTagsEnabled = false }()
+
+	Convey("Cluster can't be used along with Sentinel", t, func() {
+		_, err := NewDatabase(logger, Config{
+			Cluster:  ClusterConfig{Addrs: []string{"localhost:7000"}},
+			Sentinel: SentinelConfig{MasterName: "moira", Addrs: []string{"localhost:26379"}},
+		})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Cluster has database 0 only", t, func() {
+		_, err := NewDatabase(logger, Config{Cluster: ClusterConfig{Addrs: []string{"localhost:7000"}}, DBID: 1})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Keys are hash-tagged on Cluster", t, func() {
+		_, err := NewDatabase(logger, Config{Cluster: ClusterConfig{Addrs: []string{"localhost:7000"}}})
+		So(err, ShouldBeNil)
+		So(hashTagsEnabled, ShouldBeTrue)
+	})
+}
@@ -1,8 +1,77 @@
 package redis
 
-// Config - Redis database connection config
+import "time"
+
+// Config - Redis database connection config, either a standalone instance, the master discovered via Sentinel
+// or Redis Cluster
 type Config struct {
-	Host string
-	Port string
-	DBID int
+	Host     string
+	Port     string
+	DBID     int
+	Password string
+
+	// connection pool settings, zero values mean defaults
+	MaxIdle        int
+	MaxActive      int
+	IdleTimeout    time.Duration
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+
+	TLS          TLSConfig
+	Sentinel     SentinelConfig
+	Cluster      ClusterConfig
+	MetricEvents MetricEventsConfig
+}
+
+// TLSConfig - Redis TLS settings
+type TLSConfig struct {
+	Enabled            bool
+	InsecureSkipVerify bool
+	CAFile             string
+}
+
+// SentinelConfig - Redis Sentinel settings, if they are set then Host and Port are ignored
+// and the master is discovered via sentinels
+type SentinelConfig struct {
+	MasterName string
+	Addrs      []string
+	Password   string
+}
+
+// IsEnabled tells if master has to be discovered via sentinels
+func (config *SentinelConfig) IsEnabled() bool {
+	return config.MasterName != "" && len(config.Addrs) > 0
+}
+
+// ClusterConfig - Redis Cluster settings, if they are set then Host and Port are ignored and the slots are discovered
+// via the given nodes. The keys of Moira are hash-tagged into the same slot on Redis Cluster so that the transactions
+// keep working, only the values and the retentions of the metrics are spread over the nodes
+type ClusterConfig struct {
+	Addrs []string
+}
+
+// IsEnabled tells if the commands have to be routed to the nodes of Redis Cluster
+func (config *ClusterConfig) IsEnabled() bool {
+	return len(config.Addrs) > 0
+}
+
+// Delivery modes of metric events, Pub/Sub is used by default
+const (
+	// MetricEventsDeliveryStream is durable delivery via redis stream and consumer group of checkers
+	MetricEventsDeliveryStream = "stream"
+	// MetricEventsDeliveryPubSub is fire-and-forget delivery via Pub/Sub, every checker gets every event
+	MetricEventsDeliveryPubSub = "pubsub"
+)
+
+// MetricEventsConfig - settings of delivery of metric events from filter to checkers, zero values mean defaults
+type MetricEventsConfig struct {
+	Delivery string
+	// Consumer is the name of the checker in the consumer group, hostname by default
+	Consumer string
+	// MaxLen is the approximate length the stream is trimmed to
+	MaxLen    int64
+	BatchSize int
+	// ClaimIdle is the time after which the events not acknowledged by a checker are delivered again
+	ClaimIdle time.Duration
 }
@@ -3,6 +3,7 @@ package redis
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/garyburd/redigo/redis"
 
@@ -60,8 +61,7 @@ func (connector *DbConnector) GetAllContacts() ([]*moira.ContactData, error) {
 
 	contactIDs := make([]string, 0, len(keys))
 	for _, key := range keys {
-		key = key[14:]
-		contactIDs = append(contactIDs, key)
+		contactIDs = append(contactIDs, strings.TrimPrefix(key, contactKey("")))
 	}
 	return connector.GetContacts(contactIDs)
 }
@@ -125,9 +125,9 @@ func (connector *DbConnector) GetUserContactIDs(login string) ([]string, error)
 }
 
 func contactKey(id string) string {
-	return fmt.Sprintf("moira-contact:%s", id)
+	return withHashTag(fmt.Sprintf("moira-contact:%s", id))
 }
 
 func userContactsKey(userName string) string {
-	return fmt.Sprintf("moira-user-contacts:%s", userName)
+	return withHashTag(fmt.Sprintf("moira-user-contacts:%s", userName))
 }
@@ -19,7 +19,7 @@ var emptyConfig = Config{}
 
 func TestContacts(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -268,7 +268,7 @@ func TestContacts(t *testing.T) {
 
 func TestErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -1,8 +1,12 @@
 package redis
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/garyburd/redigo/redis"
@@ -13,19 +17,36 @@ import (
 	"go.avito.ru/DO/moira"
 )
 
+const (
+	defaultMaxIdle        = 3
+	defaultIdleTimeout    = 240 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+)
+
+// connPool gives the connections to the redis instance or the ones which route the commands over Redis Cluster
+type connPool interface {
+	Get() redis.Conn
+}
+
 // DbConnector contains redis pool
 type DbConnector struct {
-	pool            *redis.Pool
+	pool            connPool
 	logger          moira.Logger
 	retentionCache  *cache.Cache
 	metricsCache    *cache.Cache
 	messengersCache *cache.Cache
 	sync            *redsync.Redsync
+	metricEvents    MetricEventsConfig
 }
 
 // NewDatabase creates Redis pool based on config
-func NewDatabase(logger moira.Logger, config Config) *DbConnector {
-	pool := newRedisPool(fmt.Sprintf("%s:%s", config.Host, config.Port), config.DBID)
+func NewDatabase(logger moira.Logger, config Config) (*DbConnector, error) {
+	pool, err := newConnPool(config)
+	if err != nil {
+		return nil, err
+	}
+	hashTagsEnabled = config.Cluster.IsEnabled()
+
 	db := DbConnector{
 		pool:            pool,
 		logger:          logger,
@@ -33,33 +54,169 @@ func NewDatabase(logger moira.Logger, config Config) *DbConnector {
 		metricsCache:    cache.New(time.Minute, time.Minute*60),
 		messengersCache: cache.New(cache.NoExpiration, cache.DefaultExpiration),
 		sync:            redsync.New([]redsync.Pool{pool}),
+		metricEvents:    getMetricEventsConfig(logger, config.MetricEvents),
 	}
-	return &db
+	return &db, nil
 }
 
-func newRedisPool(redisURI string, dbID ...int) *redis.Pool {
-	return &redis.Pool{
-		MaxIdle:     3,
-		IdleTimeout: 240 * time.Second,
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", redisURI)
+func newConnPool(config Config) (connPool, error) {
+	if !config.Cluster.IsEnabled() {
+		pool, err := newRedisPool(config)
+		if err != nil {
+			return nil, err
+		}
+		return pool, nil
+	}
+
+	if config.Sentinel.IsEnabled() {
+		return nil, fmt.Errorf("invalid redis config: Sentinel and Cluster can't be used together")
+	}
+	if config.DBID != 0 {
+		return nil, fmt.Errorf("invalid redis config: Redis Cluster has database 0 only")
+	}
+	dialOptions, err := getDialOptions(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis config: %v", err)
+	}
+
+	// every master has its own pool with the configured limits
+	return newCluster(config.Cluster, func(addr string) *redis.Pool {
+		return newPool(config, func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, dialOptions...)
+		}, func(c redis.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		})
+	}), nil
+}
+
+func newRedisPool(config Config) (*redis.Pool, error) {
+	dialOptions, err := getDialOptions(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis config: %v", err)
+	}
+
+	var redisSentinel *sentinel
+	if config.Sentinel.IsEnabled() {
+		redisSentinel = newSentinel(config.Sentinel, getTimeoutDialOptions(config))
+	}
+
+	return newPool(config, func() (redis.Conn, error) {
+		redisURI := net.JoinHostPort(config.Host, config.Port)
+		if redisSentinel != nil {
+			master, err := redisSentinel.discoverMaster()
 			if err != nil {
 				return nil, err
 			}
-			if len(dbID) > 0 {
-				c.Do("SELECT", dbID[0])
+			redisURI = master
+		}
+
+		c, err := redis.Dial("tcp", redisURI, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+		if redisSentinel != nil {
+			if err := checkRole(c); err != nil {
+				c.Close()
+				return nil, err
 			}
-			return c, err
-		},
-		TestOnBorrow: func(c redis.Conn, t time.Time) error {
-			_, err := c.Do("PING")
-			return err
-		},
+		}
+		if err := checkNotCluster(c); err != nil {
+			c.Close()
+			return nil, err
+		}
+		return c, nil
+	}, func(c redis.Conn, t time.Time) error {
+		// the role is checked so that connections to the former master are dropped after failover
+		if redisSentinel != nil {
+			return checkRole(c)
+		}
+		_, err := c.Do("PING")
+		return err
+	}), nil
+}
+
+// newPool makes the pool of the connections to the redis instance with the configured limits
+func newPool(config Config, dial func() (redis.Conn, error), testOnBorrow func(redis.Conn, time.Time) error) *redis.Pool {
+	maxIdle := config.MaxIdle
+	if maxIdle == 0 {
+		maxIdle = defaultMaxIdle
+	}
+	idleTimeout := config.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+
+	return &redis.Pool{
+		MaxIdle:      maxIdle,
+		MaxActive:    config.MaxActive,
+		IdleTimeout:  idleTimeout,
+		Dial:         dial,
+		TestOnBorrow: testOnBorrow,
+	}
+}
+
+// checkNotCluster refuses the nodes of Redis Cluster which are configured as a standalone instance: the commands
+// for the slots of the other nodes would fail with MOVED errors. The instances which don't answer INFO are let through
+func checkNotCluster(c redis.Conn) error {
+	info, err := redis.String(c.Do("INFO", "cluster"))
+	if err != nil {
+		return nil
+	}
+	if strings.Contains(info, "cluster_enabled:1") {
+		return fmt.Errorf("redis instance is a node of Redis Cluster, set the addresses of the nodes in the cluster settings")
+	}
+	return nil
+}
+
+// getDialOptions returns options of connections to the redis instance itself
+func getDialOptions(config Config) ([]redis.DialOption, error) {
+	dialOptions := append(
+		getTimeoutDialOptions(config),
+		redis.DialDatabase(config.DBID),
+		redis.DialPassword(config.Password),
+	)
+
+	if config.TLS.Enabled {
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+		}
+		if config.TLS.CAFile != "" {
+			caCert, err := ioutil.ReadFile(config.TLS.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %v", err)
+			}
+			caPool := x509.NewCertPool()
+			if !caPool.AppendCertsFromPEM(caCert) {
+				return nil, fmt.Errorf("failed to parse CA file %s", config.TLS.CAFile)
+			}
+			tlsConfig.RootCAs = caPool
+		}
+		dialOptions = append(dialOptions, redis.DialUseTLS(true), redis.DialTLSConfig(tlsConfig))
+	}
+	return dialOptions, nil
+}
+
+func getTimeoutDialOptions(config Config) []redis.DialOption {
+	connectTimeout := config.ConnectTimeout
+	if connectTimeout == 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+	return []redis.DialOption{
+		redis.DialConnectTimeout(connectTimeout),
+		redis.DialReadTimeout(config.ReadTimeout),
+		redis.DialWriteTimeout(config.WriteTimeout),
 	}
 }
 
 func (connector *DbConnector) makePubSubConnection(channel string) (*redis.PubSubConn, error) {
-	c := connector.pool.Get()
+	var c redis.Conn
+	if redisCluster, ok := connector.pool.(*cluster); ok {
+		// the messages are broadcast over Redis Cluster, the subscription is held by a single node
+		c = redisCluster.getAnyConn()
+	} else {
+		c = connector.pool.Get()
+	}
 	psc := redis.PubSubConn{Conn: c}
 	if err := psc.Subscribe(channel); err != nil {
 		return nil, fmt.Errorf("Failed to subscribe to '%s', error: %v", channel, err)
@@ -83,7 +240,8 @@ func (connector *DbConnector) manageSubscriptions(tomb *tomb.Tomb, channel strin
 	go func() {
 		defer psc.Close()
 		for {
-			switch n := psc.Receive().(type) {
+			// subscriptions wait for messages as long as it takes regardless of configured read timeout
+			switch n := psc.ReceiveWithTimeout(0).(type) {
 			case redis.Message:
 				dataChan <- n.Data
 			case redis.Subscription:
@@ -100,7 +258,7 @@ func (connector *DbConnector) manageSubscriptions(tomb *tomb.Tomb, channel strin
 				}
 			case *net.OpError:
 				connector.logger.InfoF("psc.Receive() returned *net.OpError: %s. Reconnecting...", n.Err.Error())
-				newPsc, err := connector.makePubSubConnection(metricEventKey)
+				newPsc, err := connector.makePubSubConnection(channel)
 				if err != nil {
 					connector.logger.ErrorF("Failed to reconnect to subscription: %v", err)
 					<-time.After(5 * time.Second)
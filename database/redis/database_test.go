@@ -11,9 +11,16 @@ import (
 func TestInitialization(t *testing.T) {
 	Convey("Initialization methods", t, func() {
 		logger := test_helpers.GetTestLogger()
-		database := NewDatabase(logger, emptyConfig)
+		database, err := NewDatabase(logger, emptyConfig)
+		So(err, ShouldBeNil)
 		So(database, ShouldNotBeEmpty)
-		_, err := database.pool.Dial()
+		So(database.pool.Get().Err(), ShouldNotBeNil)
+	})
+
+	Convey("Invalid TLS settings", t, func() {
+		logger := test_helpers.GetTestLogger()
+		database, err := NewDatabase(logger, Config{TLS: TLSConfig{Enabled: true, CAFile: "/nonexistent/ca.pem"}})
 		So(err, ShouldNotBeNil)
+		So(database, ShouldBeNil)
 	})
 }
@@ -12,12 +12,15 @@ import (
 )
 
 const (
-	keyScheduledEscalations           = "moira-notifier-scheduled-escalations"
 	prefixPendingEscalation           = "moira-trigger-pending-escalations"
 	prefixPendingEscalationResolution = "moira-trigger-pending-escalations-res"
 	prefixProcessedEscalation         = "moira-trigger-processed-escalations"
 )
 
+func keyScheduledEscalations() string {
+	return withHashTag("moira-notifier-scheduled-escalations")
+}
+
 func (connector *DbConnector) AddEscalations(
 	ts int64, event moira.NotificationEvent, trigger moira.TriggerData, escalations []moira.EscalationData,
 ) error {
@@ -65,7 +68,7 @@ func (connector *DbConnector) AddEscalations(
 			// otherwise (if problem is resolved) escalation can be sent right now
 			offset = 0
 		}
-		c.Send("ZADD", keyScheduledEscalations, ts+offset, bytes)
+		c.Send("ZADD", keyScheduledEscalations(), ts+offset, bytes)
 	}
 
 	if _, err := c.Do("EXEC"); err != nil {
@@ -139,8 +142,8 @@ func (connector *DbConnector) FetchScheduledEscalationEvents(to int64) ([]*moira
 	defer c.Close()
 
 	c.Send("MULTI")
-	c.Send("ZRANGEBYSCORE", keyScheduledEscalations, "-inf", to)
-	c.Send("ZREMRANGEBYSCORE", keyScheduledEscalations, "-inf", to)
+	c.Send("ZRANGEBYSCORE", keyScheduledEscalations(), "-inf", to)
+	c.Send("ZREMRANGEBYSCORE", keyScheduledEscalations(), "-inf", to)
 	response, err := redis.Values(c.Do("EXEC"))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to EXEC: %s", err)
@@ -256,11 +259,11 @@ func triggerPendingEscalationsKey(triggerID, metric string, isResolution bool) s
 		prefix = prefixPendingEscalationResolution
 	}
 
-	return fmt.Sprintf("%s:%s:%s", prefix, triggerID, metric)
+	return withHashTag(fmt.Sprintf("%s:%s:%s", prefix, triggerID, metric))
 }
 
 func triggerProcessedEscalationsKey(triggerID, metric string) string {
-	return fmt.Sprintf("%s:%s:%s", prefixProcessedEscalation, triggerID, metric)
+	return withHashTag(fmt.Sprintf("%s:%s:%s", prefixProcessedEscalation, triggerID, metric))
 }
 
 func (connector *DbConnector) AddUnacknowledgedMessage(
@@ -327,5 +330,5 @@ func parseMessageLink(s string) (moira.MessageLink, error) {
 }
 
 func unacknowledgedMessagesKey(triggerID, metric string) string {
-	return fmt.Sprintf("moira-unacknowledged-messages:%s:%s", triggerID, metric)
+	return withHashTag(fmt.Sprintf("moira-unacknowledged-messages:%s:%s", triggerID, metric))
 }
@@ -121,7 +121,7 @@ func (connector *DbConnector) UpdateInheritanceDataVersion() error {
 	randomPart := rand.Uint32()
 	newVersion := fmt.Sprintf("%s:%x", datePart, randomPart)
 
-	_, err := c.Do("SET", inheritanceDataVersionKey, newVersion)
+	_, err := c.Do("SET", inheritanceDataVersionKey(), newVersion)
 	if err != nil {
 		return fmt.Errorf("Failed to update inheritance data version: %s", err.Error())
 	}
@@ -144,12 +144,14 @@ func decodeTriggerMetric(s string) (triggerID, metric string, err error) {
 
 func childEventsKey(triggerID, metric string) string {
 	// childEventsKey(ID, m) stores all children of event in trigger ID, metric m
-	return fmt.Sprintf("moira-inheritance-child-events:%s:%s", triggerID, metric)
+	return withHashTag(fmt.Sprintf("moira-inheritance-child-events:%s:%s", triggerID, metric))
 }
 
 func parentEventsKey(triggerID, metric string) string {
 	// parentEventsKey(ID, m) stores all parents of event in trigger ID, metric m
-	return fmt.Sprintf("moira-inheritance-parent-events:%s:%s", triggerID, metric)
+	return withHashTag(fmt.Sprintf("moira-inheritance-parent-events:%s:%s", triggerID, metric))
 }
 
-const inheritanceDataVersionKey = "moira-inheritance-data-version"
+func inheritanceDataVersionKey() string {
+	return withHashTag("moira-inheritance-data-version")
+}
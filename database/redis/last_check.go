@@ -109,13 +109,13 @@ func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *m
 
 	// store trigger check data
 	c.Send("SET", metricLastCheckKey(triggerID), bytes)
-	c.Send("ZADD", triggersChecksKey, checkData.Score, triggerID)
-	c.Send("INCR", selfStateChecksCounterKey)
+	c.Send("ZADD", triggersChecksKey(), checkData.Score, triggerID)
+	c.Send("INCR", selfStateChecksCounterKey())
 
 	if checkData.Score > 0 {
-		c.Send("SADD", badStateTriggersKey, triggerID)
+		c.Send("SADD", badStateTriggersKey(), triggerID)
 	} else {
-		c.Send("SREM", badStateTriggersKey, triggerID)
+		c.Send("SREM", badStateTriggersKey(), triggerID)
 	}
 
 	if _, err = c.Do("EXEC"); err != nil {
@@ -132,8 +132,8 @@ func (connector *DbConnector) RemoveTriggerLastCheck(triggerID string) error {
 	_ = c.Send("MULTI")
 
 	c.Send("DEL", metricLastCheckKey(triggerID))
-	c.Send("ZREM", triggersChecksKey, triggerID)
-	c.Send("SREM", badStateTriggersKey, triggerID)
+	c.Send("ZREM", triggersChecksKey(), triggerID)
+	c.Send("SREM", badStateTriggersKey(), triggerID)
 	_ = connector.delMaintenanceTrigger(c, triggerID, false)
 
 	_, err := c.Do("EXEC")
@@ -150,12 +150,12 @@ func (connector *DbConnector) GetTriggerCheckIDs(tagNames []string, onlyErrors b
 	c := connector.pool.Get()
 	defer c.Close()
 	c.Send("MULTI")
-	c.Send("ZREVRANGE", triggersChecksKey, 0, -1)
+	c.Send("ZREVRANGE", triggersChecksKey(), 0, -1)
 	for _, tagName := range tagNames {
 		c.Send("SMEMBERS", tagTriggersKey(tagName))
 	}
 	if onlyErrors {
-		c.Send("SMEMBERS", badStateTriggersKey)
+		c.Send("SMEMBERS", badStateTriggersKey())
 	}
 	rawResponse, err := redis.Values(c.Do("EXEC"))
 	if err != nil {
@@ -199,9 +199,14 @@ func (connector *DbConnector) GetTriggerCheckIDs(tagNames []string, onlyErrors b
 	return total, nil
 }
 
-var badStateTriggersKey = "moira-bad-state-triggers"
-var triggersChecksKey = "moira-triggers-checks"
+func badStateTriggersKey() string {
+	return withHashTag("moira-bad-state-triggers")
+}
+
+func triggersChecksKey() string {
+	return withHashTag("moira-triggers-checks")
+}
 
 func metricLastCheckKey(triggerID string) string {
-	return fmt.Sprintf("moira-metric-last-check:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-metric-last-check:%s", triggerID))
 }
@@ -13,7 +13,7 @@ import (
 
 func TestLastCheck(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -115,7 +115,7 @@ func TestLastCheck(t *testing.T) {
 
 func TestLastCheckErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 
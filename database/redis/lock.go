@@ -108,13 +108,13 @@ func (connector *DbConnector) setLock(conn redis.Conn, lockKey string, ttlSec in
 }
 
 func triggerCoolDownKey(triggerID string) string {
-	return fmt.Sprintf("moira-trigger-cool-down:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-trigger-cool-down:%s", triggerID))
 }
 
 func triggerLockKey(triggerID string) string {
-	return fmt.Sprintf("moira-metric-check-lock:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-metric-check-lock:%s", triggerID))
 }
 
 func triggerMaintenanceLockKey(triggerID string) string {
-	return fmt.Sprintf("moira-trigger-maintenance-lock:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-trigger-maintenance-lock:%s", triggerID))
 }
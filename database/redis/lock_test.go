@@ -10,7 +10,7 @@ import (
 
 func TestLock(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -45,7 +45,7 @@ func TestLock(t *testing.T) {
 
 func TestLockErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 	Convey("Should throw error when no connection", t, func() {
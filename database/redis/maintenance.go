@@ -139,9 +139,9 @@ func (connector *DbConnector) delMaintenanceTrigger(conn redis.Conn, id string,
 }
 
 func maintenanceKeyTrigger(id string) string {
-	return fmt.Sprintf("moira-maintenance-trigger:%s", id)
+	return withHashTag(fmt.Sprintf("moira-maintenance-trigger:%s", id))
 }
 
 func maintenanceKeySilent(spt moira.SilentPatternType) string {
-	return fmt.Sprintf("moira-maintenance-silent:%d", spt)
+	return withHashTag(fmt.Sprintf("moira-maintenance-silent:%d", spt))
 }
@@ -16,14 +16,15 @@ import (
 func (connector *DbConnector) GetPatterns() ([]string, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	patterns, err := redis.Strings(c.Do("SMEMBERS", patternsListKey))
+	patterns, err := redis.Strings(c.Do("SMEMBERS", patternsListKey()))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get moira patterns, error: %v", err)
 	}
 	return patterns, nil
 }
 
-// GetMetricsValues gets metrics values for given interval
+// GetMetricsValues gets metrics values for given interval, on Redis Cluster the metrics are requested
+// from the masters of their slots in a single pipeline per master
 func (connector *DbConnector) GetMetricsValues(metrics []string, from int64, until int64) (map[string][]*moira.MetricValue, error) {
 	c := connector.pool.Get()
 	defer c.Close()
@@ -89,17 +90,58 @@ func (connector *DbConnector) getMetricRetention(metric string) (int64, error) {
 	return retention, nil
 }
 
+// GetMetricsTTL gets the seconds the values of the metrics are kept for, as the checkers set it,
+// database.ErrNil is returned if no checker has set it yet
+func (connector *DbConnector) GetMetricsTTL() (int64, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	ttl, err := redis.Int64(c.Do("GET", metricsTTLKey()))
+	if err != nil {
+		if err == redis.ErrNil {
+			return 0, database.ErrNil
+		}
+		return 0, fmt.Errorf("Failed GET metrics TTL, error: %v", err)
+	}
+	return ttl, nil
+}
+
+// SetMetricsTTL sets the seconds the values of the metrics are kept for, so that api validates the triggers with it
+func (connector *DbConnector) SetMetricsTTL(ttl int64) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	if _, err := c.Do("SET", metricsTTLKey(), ttl); err != nil {
+		return fmt.Errorf("Failed SET metrics TTL, error: %v", err)
+	}
+	return nil
+}
+
 // SaveMetrics saves new metrics
 func (connector *DbConnector) SaveMetrics(metrics map[string]*moira.MatchedMetric) error {
 	c := connector.pool.Get()
 	defer c.Close()
+
+	// stream entries are coalesced per pattern, the checkers need the pattern only
+	streamPatterns := make(map[string]bool)
 	for _, metric := range metrics {
 		metricValue := fmt.Sprintf("%v %v", metric.Timestamp, metric.Value)
 		c.Send("ZADD", metricDataKey(metric.Metric), metric.RetentionTimestamp, metricValue)
 		c.Send("SET", metricRetentionKey(metric.Metric), metric.Retention)
 
+		indexed := false
 		for _, pattern := range metric.Patterns {
+			if !indexed && moira.IsTagQuery(pattern) {
+				// the series selected by tags are looked up in the index of tags by seriesByTag targets
+				addTaggedSeries(c, metric.Metric)
+				indexed = true
+			}
 			c.Send("SADD", patternMetricsKey(pattern), metric.Metric)
+
+			if connector.metricEvents.Delivery != MetricEventsDeliveryPubSub {
+				streamPatterns[pattern] = true
+				continue
+			}
 			event, err := json.Marshal(&moira.MetricEvent{
 				Metric:  metric.Metric,
 				Pattern: pattern,
@@ -110,37 +152,66 @@ func (connector *DbConnector) SaveMetrics(metrics map[string]*moira.MatchedMetri
 			c.Send("PUBLISH", metricEventKey, event)
 		}
 	}
+	for pattern := range streamPatterns {
+		connector.addMetricEvent(c, pattern)
+	}
 	return c.Flush()
 }
 
-// SubscribeMetricEvents creates subscription for new metrics and return channel for this events
-func (connector *DbConnector) SubscribeMetricEvents(tomb *tomb.Tomb) (<-chan *moira.MetricEvent, error) {
-	metricsChannel := make(chan *moira.MetricEvent, 100)
+// SubscribeMetricEvents creates subscription for new metrics and return channel for this events,
+// the events are grouped in batches which have to be acknowledged with AckMetricEvents
+func (connector *DbConnector) SubscribeMetricEvents(tomb *tomb.Tomb) (<-chan []*moira.MetricEvent, error) {
+	if connector.metricEvents.Delivery != MetricEventsDeliveryPubSub {
+		return connector.consumeMetricEvents(tomb)
+	}
+
+	metricsChannel := make(chan []*moira.MetricEvent, 100)
 	dataChannel, err := connector.manageSubscriptions(tomb, metricEventKey)
 	if err != nil {
 		return nil, err
 	}
 
 	go func() {
+		defer close(metricsChannel)
 		for {
 			data, ok := <-dataChannel
 			if !ok {
 				connector.logger.Info("No more subscriptions, channel is closed. Stop process data...")
-				close(metricsChannel)
 				return
 			}
-			metricEvent := &moira.MetricEvent{}
-			if err := json.Unmarshal(data, metricEvent); err != nil {
-				connector.logger.ErrorF("Failed to parse MetricEvent: %s, error : %v", string(data), err)
-				continue
+			batch := connector.appendMetricEvent(make([]*moira.MetricEvent, 0, 1), data)
+
+			// the events which have already arrived are handled together
+		collect:
+			for len(batch) < connector.metricEvents.BatchSize {
+				select {
+				case data, ok = <-dataChannel:
+					if !ok {
+						break collect
+					}
+					batch = connector.appendMetricEvent(batch, data)
+				default:
+					break collect
+				}
+			}
+			if len(batch) > 0 {
+				metricsChannel <- batch
 			}
-			metricsChannel <- metricEvent
 		}
 	}()
 
 	return metricsChannel, nil
 }
 
+func (connector *DbConnector) appendMetricEvent(batch []*moira.MetricEvent, data []byte) []*moira.MetricEvent {
+	metricEvent := &moira.MetricEvent{}
+	if err := json.Unmarshal(data, metricEvent); err != nil {
+		connector.logger.ErrorF("Failed to parse MetricEvent: %s, error : %v", string(data), err)
+		return batch
+	}
+	return append(batch, metricEvent)
+}
+
 // AddPatternMetric adds new metrics by given pattern
 func (connector *DbConnector) AddPatternMetric(pattern, metric string) error {
 	c := connector.pool.Get()
@@ -170,7 +241,10 @@ func (connector *DbConnector) GetPatternMetrics(pattern string) ([]string, error
 func (connector *DbConnector) RemovePattern(pattern string) error {
 	c := connector.pool.Get()
 	defer c.Close()
-	if _, err := c.Do("SREM", patternsListKey, pattern); err != nil {
+	c.Send("MULTI")
+	c.Send("SREM", patternsListKey(), pattern)
+	connector.publishPatternChange(c, pattern, true)
+	if _, err := c.Do("EXEC"); err != nil {
 		return fmt.Errorf("Failed to remove pattern: %s, error: %v", pattern, err)
 	}
 	return nil
@@ -190,7 +264,8 @@ func (connector *DbConnector) RemovePatternsMetrics(patterns []string) error {
 	return nil
 }
 
-// RemovePatternWithMetrics removes pattern metrics with data and given pattern
+// RemovePatternWithMetrics removes pattern metrics with data and given pattern,
+// the data of the metrics is removed after the pattern as it's spread over the slots on Redis Cluster
 func (connector *DbConnector) RemovePatternWithMetrics(pattern string) error {
 	metrics, err := connector.GetPatternMetrics(pattern)
 	if err != nil {
@@ -199,14 +274,22 @@ func (connector *DbConnector) RemovePatternWithMetrics(pattern string) error {
 	c := connector.pool.Get()
 	defer c.Close()
 	c.Send("MULTI")
-	c.Send("SREM", patternsListKey, pattern)
-	for _, metric := range metrics {
-		c.Send("DEL", metricDataKey(metric))
-	}
+	c.Send("SREM", patternsListKey(), pattern)
 	c.Send("DEL", patternMetricsKey(pattern))
+	connector.publishPatternChange(c, pattern, true)
 	if _, err = c.Do("EXEC"); err != nil {
 		return fmt.Errorf("Failed to EXEC: %v", err)
 	}
+
+	for _, metric := range metrics {
+		c.Send("DEL", metricDataKey(metric))
+	}
+	if _, err = c.Do(""); err != nil {
+		return fmt.Errorf("Failed to remove data of metrics: %v", err)
+	}
+	if moira.IsTagQuery(pattern) {
+		return removeTaggedSeries(c, metrics)
+	}
 	return nil
 }
 
@@ -223,21 +306,36 @@ func (connector *DbConnector) RemoveMetricValues(metric string, toTime int64) er
 	return nil
 }
 
-// RemoveMetricsValues remove metrics timestamps values from 0 to given time
+// RemoveMetricsValues remove metrics timestamps values from 0 to given time,
+// the series which have no values left are removed from the index of tags.
+// The metrics are trimmed in a pipeline instead of a transaction as they are spread over the slots on Redis Cluster
 func (connector *DbConnector) RemoveMetricsValues(metrics []string, toTime int64) error {
 	c := connector.pool.Get()
 	defer c.Close()
 
-	c.Send("MULTI")
+	trimmed := make([]string, 0, len(metrics))
 	for _, metric := range metrics {
 		if connector.needRemoveMetrics(metric) {
 			c.Send("ZREMRANGEBYSCORE", metricDataKey(metric), "-inf", toTime)
+			c.Send("ZCARD", metricDataKey(metric))
+			trimmed = append(trimmed, metric)
 		}
 	}
-	if _, err := c.Do("EXEC"); err != nil {
-		return fmt.Errorf("Failed to EXEC remove metrics: %v", err)
+	replies, err := redis.Values(c.Do(""))
+	if err != nil {
+		return fmt.Errorf("Failed to remove metrics: %v", err)
 	}
-	return nil
+
+	expired := make([]string, 0)
+	for i, metric := range trimmed {
+		if count, _ := redis.Int64(replies[2*i+1], nil); count == 0 {
+			expired = append(expired, metric)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return removeTaggedSeries(c, expired)
 }
 
 func (connector *DbConnector) needRemoveMetrics(metric string) bool {
@@ -245,17 +343,24 @@ func (connector *DbConnector) needRemoveMetrics(metric string) bool {
 	return err == nil
 }
 
-var patternsListKey = "moira-pattern-list"
+func patternsListKey() string {
+	return withHashTag("moira-pattern-list")
+}
+
 var metricEventKey = "metric-event"
 
+func metricsTTLKey() string {
+	return withHashTag("moira-metrics-ttl")
+}
+
 func patternMetricsKey(pattern string) string {
-	return fmt.Sprintf("moira-pattern-metrics:%s", pattern)
+	return withHashTag(fmt.Sprintf("moira-pattern-metrics:%s", pattern))
 }
 
 func metricDataKey(metric string) string {
-	return fmt.Sprintf("moira-metric-data:%s", metric)
+	return fmt.Sprintf("moira-metric-data:%s", metricHashTag(metric))
 }
 
 func metricRetentionKey(metric string) string {
-	return fmt.Sprintf("moira-metric-retention:%s", metric)
+	return fmt.Sprintf("moira-metric-retention:%s", metricHashTag(metric))
 }
@@ -0,0 +1,257 @@
+package redis
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"gopkg.in/tomb.v2"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/database/redis/reply"
+)
+
+const (
+	metricEventsGroup = "moira-checkers"
+
+	defaultMetricEventsMaxLen    = 100000
+	defaultMetricEventsBatchSize = 1000
+	defaultMetricEventsClaimIdle = time.Minute
+
+	// metricEventsBlock is how long the checker waits for new events in one request
+	metricEventsBlock       = time.Second
+	metricEventsReadTimeout = 10 * time.Second
+	metricEventsRetryDelay  = 5 * time.Second
+	// metricEventsMaxDeliveries is how many times the event is delivered before it is dropped
+	metricEventsMaxDeliveries = 10
+)
+
+func metricEventsStreamKey() string {
+	return withHashTag("moira-metric-events-stream")
+}
+
+// getMetricEventsConfig fills the defaults of the settings of metric events delivery
+func getMetricEventsConfig(logger moira.Logger, config MetricEventsConfig) MetricEventsConfig {
+	switch config.Delivery {
+	case MetricEventsDeliveryStream, MetricEventsDeliveryPubSub:
+	case "":
+		config.Delivery = MetricEventsDeliveryPubSub
+	default:
+		logger.ErrorF("Unknown delivery of metric events '%s', %s is used", config.Delivery, MetricEventsDeliveryPubSub)
+		config.Delivery = MetricEventsDeliveryPubSub
+	}
+	if config.Consumer == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "checker"
+		}
+		config.Consumer = hostname
+	}
+	if config.MaxLen == 0 {
+		config.MaxLen = defaultMetricEventsMaxLen
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = defaultMetricEventsBatchSize
+	}
+	if config.ClaimIdle == 0 {
+		config.ClaimIdle = defaultMetricEventsClaimIdle
+	}
+	return config
+}
+
+// addMetricEvent appends the event to the stream, the stream is trimmed so that it doesn't grow while checkers are down
+func (connector *DbConnector) addMetricEvent(c redis.Conn, pattern string) {
+	c.Send("XADD", metricEventsStreamKey(), "MAXLEN", "~", connector.metricEvents.MaxLen, "*", "pattern", pattern)
+}
+
+// AckMetricEvents acknowledges the handled events so that they are not delivered again
+func (connector *DbConnector) AckMetricEvents(events []*moira.MetricEvent) error {
+	args := []interface{}{metricEventsStreamKey(), metricEventsGroup}
+	for _, event := range events {
+		if event.StreamID != "" {
+			args = append(args, event.StreamID)
+		}
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+	if _, err := c.Do("XACK", args...); err != nil {
+		return fmt.Errorf("Failed to acknowledge metric events: %v", err)
+	}
+	return nil
+}
+
+// GetMetricEventsLag returns how far the consumer group of the checkers is behind the filter
+func (connector *DbConnector) GetMetricEventsLag() (*moira.MetricEventsLag, error) {
+	lag := &moira.MetricEventsLag{}
+	if connector.metricEvents.Delivery == MetricEventsDeliveryPubSub {
+		return lag, nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	stream, err := reply.StreamInfo(c.Do("XINFO", "STREAM", metricEventsStreamKey()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get info of metric events stream: %v", err)
+	}
+	groups, err := redis.Values(c.Do("XINFO", "GROUPS", metricEventsStreamKey()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get consumer groups of metric events stream: %v", err)
+	}
+	for _, value := range groups {
+		group, err := reply.StreamInfo(value, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get consumer groups of metric events stream: %v", err)
+		}
+		if name, _ := redis.String(group["name"], nil); name != metricEventsGroup {
+			continue
+		}
+
+		lag.Pending, _ = redis.Int64(group["pending"], nil)
+		lastGenerated, _ := redis.String(stream["last-generated-id"], nil)
+		lastDelivered, _ := redis.String(group["last-delivered-id"], nil)
+		if delta := getStreamIDTime(lastGenerated) - getStreamIDTime(lastDelivered); delta > 0 {
+			lag.Lag = time.Duration(delta) * time.Millisecond
+		}
+	}
+	return lag, nil
+}
+
+// consumeMetricEvents reads the stream as the member of the consumer group of the checkers.
+// The events of this consumer which were not acknowledged before restart are delivered first,
+// the events not acknowledged by any checker for ClaimIdle are claimed and delivered again
+func (connector *DbConnector) consumeMetricEvents(tomb *tomb.Tomb) (<-chan []*moira.MetricEvent, error) {
+	if err := connector.createMetricEventsGroup(); err != nil {
+		return nil, err
+	}
+
+	metricsChannel := make(chan []*moira.MetricEvent)
+	go func() {
+		defer close(metricsChannel)
+
+		// "0" reads the history of pending events of this consumer, ">" reads new events
+		lastID := "0"
+		lastClaim := time.Now()
+		for {
+			select {
+			case <-tomb.Dying():
+				connector.logger.Info("Calling shutdown, stop reading metric events stream...")
+				return
+			default:
+			}
+
+			var batch []*moira.MetricEvent
+			var err error
+			if time.Since(lastClaim) >= connector.metricEvents.ClaimIdle {
+				lastClaim = time.Now()
+				batch, err = connector.claimMetricEvents()
+			}
+			if err == nil && len(batch) == 0 {
+				batch, err = connector.readMetricEvents(lastID)
+				if err == nil && lastID != ">" {
+					if len(batch) == 0 {
+						lastID = ">"
+					} else {
+						lastID = batch[len(batch)-1].StreamID
+					}
+				}
+			}
+			if err != nil {
+				connector.logger.ErrorF("Failed to read metric events: %v", err)
+				<-time.After(metricEventsRetryDelay)
+				continue
+			}
+			if len(batch) == 0 {
+				continue
+			}
+
+			select {
+			case metricsChannel <- batch:
+			case <-tomb.Dying():
+				connector.logger.Info("Calling shutdown, stop reading metric events stream...")
+				return
+			}
+		}
+	}()
+	return metricsChannel, nil
+}
+
+func (connector *DbConnector) createMetricEventsGroup() error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	_, err := c.Do("XGROUP", "CREATE", metricEventsStreamKey(), metricEventsGroup, "$", "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("Failed to create consumer group of metric events: %v", err)
+	}
+	return nil
+}
+
+func (connector *DbConnector) readMetricEvents(lastID string) ([]*moira.MetricEvent, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	rep, err := redis.DoWithTimeout(c, metricEventsReadTimeout,
+		"XREADGROUP", "GROUP", metricEventsGroup, connector.metricEvents.Consumer,
+		"COUNT", connector.metricEvents.BatchSize, "BLOCK", int64(metricEventsBlock/time.Millisecond),
+		"STREAMS", metricEventsStreamKey(), lastID,
+	)
+	return reply.StreamMetricEvents(rep, err)
+}
+
+// claimMetricEvents takes over the events which are not acknowledged for too long, e.g. the checker has crashed
+func (connector *DbConnector) claimMetricEvents() ([]*moira.MetricEvent, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	pending, err := redis.Values(c.Do("XPENDING", metricEventsStreamKey(), metricEventsGroup, "-", "+", connector.metricEvents.BatchSize))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get pending metric events: %v", err)
+	}
+
+	minIdle := int64(connector.metricEvents.ClaimIdle / time.Millisecond)
+	claim := []interface{}{metricEventsStreamKey(), metricEventsGroup, connector.metricEvents.Consumer, minIdle}
+	drop := []interface{}{metricEventsStreamKey(), metricEventsGroup}
+	for _, value := range pending {
+		// every pending entry is [id, consumer, idle, deliveries]
+		entry, err := redis.Values(value, nil)
+		if err != nil || len(entry) < 4 {
+			return nil, fmt.Errorf("Failed to parse pending metric event: %v", value)
+		}
+		id, _ := redis.String(entry[0], nil)
+		idle, _ := redis.Int64(entry[2], nil)
+		deliveries, _ := redis.Int64(entry[3], nil)
+		if idle < minIdle {
+			continue
+		}
+		if deliveries >= metricEventsMaxDeliveries {
+			connector.logger.ErrorF("Metric event %s is delivered %d times, drop it", id, deliveries)
+			drop = append(drop, id)
+			continue
+		}
+		claim = append(claim, id)
+	}
+
+	if len(drop) > 2 {
+		if _, err := c.Do("XACK", drop...); err != nil {
+			return nil, fmt.Errorf("Failed to drop metric events: %v", err)
+		}
+	}
+	if len(claim) == 4 {
+		return nil, nil
+	}
+	return reply.StreamEntries(c.Do("XCLAIM", claim...))
+}
+
+// getStreamIDTime returns the milliseconds part of the id of the stream entry
+func getStreamIDTime(id string) int64 {
+	milliseconds, _ := strconv.ParseInt(strings.SplitN(id, "-", 2)[0], 10, 64)
+	return milliseconds
+}
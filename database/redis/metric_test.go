@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"sort"
 	"testing"
 	"time"
 
@@ -14,7 +15,7 @@ import (
 
 func TestMetricsStoring(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	metric1 := "my.test.super.metric"
 	metric2 := "my.test.super.metric2"
@@ -185,7 +186,7 @@ func TestMetricsStoring(t *testing.T) {
 
 func TestRemoveMetricValues(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.metricsCache = cache.New(time.Second*2, time.Minute*60)
 	dataBase.flush()
 	defer dataBase.flush()
@@ -337,7 +338,7 @@ func TestRemoveMetricValues(t *testing.T) {
 
 func TestMetricSubscription(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 	metric1 := "my.test.super.metric"
@@ -370,23 +371,25 @@ func TestMetricSubscription(t *testing.T) {
 
 		tomb1.Go(func() error {
 			for {
-				metricEvent, ok := <-ch
+				metricEvents, ok := <-ch
 				if !ok {
 					numberOfChecks++
 					logger.Info("Channel closed, end test")
 					return nil
 				}
-				if metricEvent.Metric == metric1 {
-					Convey("Test", t, func() {
-						numberOfChecks++
-						So(metricEvent, ShouldResemble, &moira.MetricEvent{Pattern: pattern, Metric: metric1})
-					})
-				}
-				if metricEvent.Metric == metric2 {
-					Convey("Test", t, func() {
-						numberOfChecks++
-						So(metricEvent, ShouldResemble, &moira.MetricEvent{Pattern: pattern, Metric: metric2})
-					})
+				for _, metricEvent := range metricEvents {
+					if metricEvent.Metric == metric1 {
+						Convey("Test", t, func() {
+							numberOfChecks++
+							So(metricEvent, ShouldResemble, &moira.MetricEvent{Pattern: pattern, Metric: metric1})
+						})
+					}
+					if metricEvent.Metric == metric2 {
+						Convey("Test", t, func() {
+							numberOfChecks++
+							So(metricEvent, ShouldResemble, &moira.MetricEvent{Pattern: pattern, Metric: metric2})
+						})
+					}
 				}
 			}
 		})
@@ -400,9 +403,113 @@ func TestMetricSubscription(t *testing.T) {
 	})
 }
 
+func TestMetricEventsStream(t *testing.T) {
+	logger := test_helpers.GetTestLogger()
+	streamConfig := config
+	streamConfig.MetricEvents.Delivery = MetricEventsDeliveryStream
+	dataBase, _ := NewDatabase(logger, streamConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	pattern1 := "my.test.*.metric"
+	pattern2 := "my.other.*.metric"
+	saveMetrics := func() {
+		err := dataBase.SaveMetrics(map[string]*moira.MatchedMetric{
+			"my.test.1.metric":  {Metric: "my.test.1.metric", Patterns: []string{pattern1}},
+			"my.test.2.metric":  {Metric: "my.test.2.metric", Patterns: []string{pattern1}},
+			"my.other.1.metric": {Metric: "my.other.1.metric", Patterns: []string{pattern2}},
+		})
+		So(err, ShouldBeNil)
+	}
+	readPatterns := func(ch <-chan []*moira.MetricEvent) ([]*moira.MetricEvent, []string) {
+		events := <-ch
+		patterns := make([]string, 0, len(events))
+		for _, event := range events {
+			So(event.StreamID, ShouldNotBeEmpty)
+			patterns = append(patterns, event.Pattern)
+		}
+		sort.Strings(patterns)
+		return events, patterns
+	}
+
+	Convey("Events are coalesced per pattern and acknowledged", t, func() {
+		var tomb1 tomb.Tomb
+		ch, err := dataBase.SubscribeMetricEvents(&tomb1)
+		So(err, ShouldBeNil)
+
+		saveMetrics()
+		events, patterns := readPatterns(ch)
+		So(patterns, ShouldResemble, []string{pattern2, pattern1})
+
+		lag, err := dataBase.GetMetricEventsLag()
+		So(err, ShouldBeNil)
+		So(lag.Pending, ShouldEqual, 2)
+		So(lag.Lag, ShouldEqual, 0)
+
+		So(dataBase.AckMetricEvents(events), ShouldBeNil)
+		lag, err = dataBase.GetMetricEventsLag()
+		So(err, ShouldBeNil)
+		So(lag.Pending, ShouldEqual, 0)
+
+		tomb1.Kill(nil)
+		tomb1.Wait()
+	})
+
+	Convey("Events which are not acknowledged are delivered again after restart", t, func() {
+		var tomb1 tomb.Tomb
+		ch, err := dataBase.SubscribeMetricEvents(&tomb1)
+		So(err, ShouldBeNil)
+		saveMetrics()
+		_, patterns := readPatterns(ch)
+		So(patterns, ShouldHaveLength, 2)
+		tomb1.Kill(nil)
+		for range ch {
+		}
+
+		var tomb2 tomb.Tomb
+		ch, err = dataBase.SubscribeMetricEvents(&tomb2)
+		So(err, ShouldBeNil)
+		events, patterns := readPatterns(ch)
+		So(patterns, ShouldResemble, []string{pattern2, pattern1})
+		So(dataBase.AckMetricEvents(events), ShouldBeNil)
+		tomb2.Kill(nil)
+		for range ch {
+		}
+	})
+
+	Convey("Events of crashed checker are claimed", t, func() {
+		crashedConfig := config
+		crashedConfig.MetricEvents.Consumer = "crashed"
+		crashed, _ := NewDatabase(logger, crashedConfig)
+		var tomb1 tomb.Tomb
+		ch, err := crashed.SubscribeMetricEvents(&tomb1)
+		So(err, ShouldBeNil)
+		saveMetrics()
+		readPatterns(ch)
+		tomb1.Kill(nil)
+		for range ch {
+		}
+
+		aliveConfig := config
+		aliveConfig.MetricEvents.Consumer = "alive"
+		aliveConfig.MetricEvents.ClaimIdle = 100 * time.Millisecond
+		alive, _ := NewDatabase(logger, aliveConfig)
+		time.Sleep(200 * time.Millisecond)
+		var tomb2 tomb.Tomb
+		ch, err = alive.SubscribeMetricEvents(&tomb2)
+		So(err, ShouldBeNil)
+		events, patterns := readPatterns(ch)
+		So(patterns, ShouldResemble, []string{pattern2, pattern1})
+		So(alive.AckMetricEvents(events), ShouldBeNil)
+		tomb2.Kill(nil)
+		for range ch {
+		}
+	})
+}
+
 func TestMetricsStoringErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 	Convey("Should throw error when no connection", t, func() {
@@ -17,8 +17,8 @@ func (connector *DbConnector) GetNotifications(start, end int64) ([]*moira.Sched
 	c := connector.pool.Get()
 	defer c.Close()
 	c.Send("MULTI")
-	c.Send("ZRANGE", notifierNotificationsKey, start, end)
-	c.Send("ZCARD", notifierNotificationsKey)
+	c.Send("ZRANGE", notifierNotificationsKey(), start, end)
+	c.Send("ZCARD", notifierNotificationsKey())
 	rawResponse, err := redis.Values(c.Do("EXEC"))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -59,7 +59,7 @@ func (connector *DbConnector) RemoveNotification(notificationKey string) (int64,
 			if err2 != nil {
 				return 0, err2
 			}
-			c.Send("ZREM", notifierNotificationsKey, notificationString)
+			c.Send("ZREM", notifierNotificationsKey(), notificationString)
 		}
 	}
 	response, err := redis.Ints(c.Do("EXEC"))
@@ -79,8 +79,8 @@ func (connector *DbConnector) FetchNotifications(to int64) ([]*moira.ScheduledNo
 	defer c.Close()
 
 	c.Send("MULTI")
-	c.Send("ZRANGEBYSCORE", notifierNotificationsKey, "-inf", to)
-	c.Send("ZREMRANGEBYSCORE", notifierNotificationsKey, "-inf", to)
+	c.Send("ZRANGEBYSCORE", notifierNotificationsKey(), "-inf", to)
+	c.Send("ZREMRANGEBYSCORE", notifierNotificationsKey(), "-inf", to)
 	response, err := redis.Values(c.Do("EXEC"))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to EXEC: %s", err)
@@ -99,7 +99,7 @@ func (connector *DbConnector) AddNotification(notification *moira.ScheduledNotif
 	}
 	c := connector.pool.Get()
 	defer c.Close()
-	_, err = c.Do("ZADD", notifierNotificationsKey, notification.Timestamp, bytes)
+	_, err = c.Do("ZADD", notifierNotificationsKey(), notification.Timestamp, bytes)
 	if err != nil {
 		return fmt.Errorf("Failed to add scheduled notification: %s, error: %s", string(bytes), err.Error())
 	}
@@ -116,7 +116,7 @@ func (connector *DbConnector) AddNotifications(notifications []*moira.ScheduledN
 		if err != nil {
 			return err
 		}
-		c.Send("ZADD", notifierNotificationsKey, timestamp, bytes)
+		c.Send("ZADD", notifierNotificationsKey(), timestamp, bytes)
 	}
 	_, err := c.Do("EXEC")
 	if err != nil {
@@ -125,4 +125,6 @@ func (connector *DbConnector) AddNotifications(notifications []*moira.ScheduledN
 	return nil
 }
 
-var notifierNotificationsKey = "moira-notifier-notifications"
+func notifierNotificationsKey() string {
+	return withHashTag("moira-notifier-notifications")
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/garyburd/redigo/redis"
+	"gopkg.in/tomb.v2"
 
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/database"
@@ -35,7 +36,7 @@ func (connector *DbConnector) GetAllNotificationEvents(start, end int64) ([]*moi
 	c := connector.pool.Get()
 	defer c.Close()
 
-	eventsData, err := reply.Events(c.Do("ZRANGEBYSCORE", allEventsLogKey, start, end))
+	eventsData, err := reply.Events(c.Do("ZRANGEBYSCORE", allEventsLogKey(), start, end))
 	if err != nil {
 		if err == redis.ErrNil {
 			return make([]*moira.NotificationEvent, 0), nil
@@ -63,9 +64,9 @@ func (connector *DbConnector) PushNotificationEvent(event *moira.NotificationEve
 
 	var eventKey string
 	if event.HasSaturations {
-		eventKey = eventsWithSaturationsListKey
+		eventKey = eventsWithSaturationsListKey()
 	} else {
-		eventKey = eventsListKey
+		eventKey = eventsListKey()
 	}
 
 	c.Send("MULTI")
@@ -73,12 +74,13 @@ func (connector *DbConnector) PushNotificationEvent(event *moira.NotificationEve
 
 	if event.TriggerID != "" {
 		removeEventsUntil := time.Now().Unix() - eventsTTL
-		triggerEventKeys := []string{triggerEventsKeyCommon(event.TriggerID), allEventsLogKey}
+		triggerEventKeys := []string{triggerEventsKeyCommon(event.TriggerID), allEventsLogKey()}
 
 		for _, key := range triggerEventKeys {
 			c.Send("ZADD", key, event.Timestamp, eventBytes)
 			c.Send("ZREMRANGEBYSCORE", key, "-inf", removeEventsUntil) // housekeeping
 		}
+		c.Send("PUBLISH", eventsChannelKey, eventBytes)
 	}
 
 	_, err = c.Do("EXEC")
@@ -107,9 +109,9 @@ func (connector *DbConnector) FetchNotificationEvent(withSaturations bool) (moir
 
 	var eventKey string
 	if withSaturations {
-		eventKey = eventsWithSaturationsListKey
+		eventKey = eventsWithSaturationsListKey()
 	} else {
-		eventKey = eventsListKey
+		eventKey = eventsListKey()
 	}
 
 	rawRes, err := c.Do("BRPOP", eventKey, 1)
@@ -134,15 +136,43 @@ func (connector *DbConnector) FetchNotificationEvent(withSaturations bool) (moir
 	return event, nil
 }
 
+// SubscribeNotificationEvents creates subscription for new events of the triggers and returns channel for these events
+func (connector *DbConnector) SubscribeNotificationEvents(tomb *tomb.Tomb) (<-chan *moira.NotificationEvent, error) {
+	eventsChannel := make(chan *moira.NotificationEvent, 100)
+	dataChannel, err := connector.manageSubscriptions(tomb, eventsChannelKey)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			data, ok := <-dataChannel
+			if !ok {
+				connector.logger.Info("No more subscriptions, channel is closed. Stop process events...")
+				close(eventsChannel)
+				return
+			}
+			event := &moira.NotificationEvent{}
+			if err := json.Unmarshal(data, event); err != nil {
+				connector.logger.ErrorF("Failed to parse NotificationEvent: %s, error : %v", string(data), err)
+				continue
+			}
+			eventsChannel <- event
+		}
+	}()
+
+	return eventsChannel, nil
+}
+
 func (connector *DbConnector) FetchDelayedNotificationEvents(to int64, withSaturations bool) ([]moira.NotificationEvent, error) {
 	c := connector.pool.Get()
 	defer c.Close()
 
 	var eventKey string
 	if withSaturations {
-		eventKey = delayedEventsWithSaturationsListKey
+		eventKey = delayedEventsWithSaturationsListKey()
 	} else {
-		eventKey = delayedEventsListKey
+		eventKey = delayedEventsListKey()
 	}
 
 	c.Send("MULTI")
@@ -173,9 +203,9 @@ func (connector *DbConnector) AddDelayedNotificationEvent(event moira.Notificati
 
 	var eventKey string
 	if event.HasSaturations {
-		eventKey = delayedEventsWithSaturationsListKey
+		eventKey = delayedEventsWithSaturationsListKey()
 	} else {
-		eventKey = delayedEventsListKey
+		eventKey = delayedEventsListKey()
 	}
 
 	bytes, err := json.Marshal(event)
@@ -189,16 +219,28 @@ func (connector *DbConnector) AddDelayedNotificationEvent(event moira.Notificati
 	return nil
 }
 
-const (
-	eventsListKey                = "moira-trigger-events"
-	eventsWithSaturationsListKey = eventsListKey + ":with-saturations"
+const eventsChannelKey = "moira-trigger-events-channel"
 
-	delayedEventsListKey                = "moira-trigger-delayed-events"
-	delayedEventsWithSaturationsListKey = delayedEventsListKey + ":with-saturations"
+func eventsListKey() string {
+	return withHashTag("moira-trigger-events")
+}
 
-	allEventsLogKey = "moira-all-events"
-)
+func eventsWithSaturationsListKey() string {
+	return eventsListKey() + ":with-saturations"
+}
+
+func delayedEventsListKey() string {
+	return withHashTag("moira-trigger-delayed-events")
+}
+
+func delayedEventsWithSaturationsListKey() string {
+	return delayedEventsListKey() + ":with-saturations"
+}
+
+func allEventsLogKey() string {
+	return withHashTag("moira-all-events")
+}
 
 func triggerEventsKeyCommon(triggerID string) string {
-	return fmt.Sprintf("moira-trigger-events:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-trigger-events:%s", triggerID))
 }
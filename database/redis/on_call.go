@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/database"
+)
+
+func onCallRotationsKey() string {
+	return withHashTag("moira-on-call-rotations")
+}
+
+// GetOnCallRotation returns the rotation by its id
+func (connector *DbConnector) GetOnCallRotation(rotationID string) (*moira.OnCallRotation, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	value, err := redis.Bytes(c.Do("HGET", onCallRotationsKey(), rotationID))
+	if err != nil {
+		if err == redis.ErrNil {
+			return nil, database.ErrNil
+		}
+		return nil, fmt.Errorf("Failed to HGET %s: %v", onCallRotationsKey(), err)
+	}
+
+	rotation := &moira.OnCallRotation{}
+	if err := json.Unmarshal(value, rotation); err != nil {
+		return nil, fmt.Errorf("Failed to parse on call rotation %s: %v", rotationID, err)
+	}
+	rotation.ID = rotationID
+	return rotation, nil
+}
+
+// SaveOnCallRotation creates or replaces the rotation
+func (connector *DbConnector) SaveOnCallRotation(rotation *moira.OnCallRotation) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	bytes, err := json.Marshal(rotation)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Do("HSET", onCallRotationsKey(), rotation.ID, bytes); err != nil {
+		return fmt.Errorf("Failed to HSET %s: %v", onCallRotationsKey(), err)
+	}
+	return nil
+}
+
+// GetOnCallRotations returns all the rotations
+func (connector *DbConnector) GetOnCallRotations() ([]*moira.OnCallRotation, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	values, err := redis.StringMap(c.Do("HGETALL", onCallRotationsKey()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGETALL %s: %v", onCallRotationsKey(), err)
+	}
+
+	rotations := make([]*moira.OnCallRotation, 0, len(values))
+	for id, value := range values {
+		rotation := &moira.OnCallRotation{}
+		if err := json.Unmarshal([]byte(value), rotation); err != nil {
+			return nil, fmt.Errorf("Failed to parse on call rotation %s: %v", id, err)
+		}
+		rotation.ID = id
+		rotations = append(rotations, rotation)
+	}
+	return rotations, nil
+}
+
+// RemoveOnCallRotation removes the rotation
+func (connector *DbConnector) RemoveOnCallRotation(rotationID string) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	if _, err := c.Do("HDEL", onCallRotationsKey(), rotationID); err != nil {
+		return fmt.Errorf("Failed to HDEL %s: %v", onCallRotationsKey(), err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"encoding/json"
+
+	"github.com/garyburd/redigo/redis"
+	"gopkg.in/tomb.v2"
+
+	"go.avito.ru/DO/moira"
+)
+
+const patternChangesChannelKey = "moira-pattern-changes"
+
+// publishPatternChange notifies the filters about the change of the patterns list,
+// the change is sent in the transaction which changes the list
+func (connector *DbConnector) publishPatternChange(c redis.Conn, pattern string, removed bool) {
+	changeBytes, err := json.Marshal(&moira.PatternChange{Pattern: pattern, Removed: removed})
+	if err != nil {
+		connector.logger.ErrorF("Failed to marshal pattern change of %s: %v", pattern, err)
+		return
+	}
+	c.Send("PUBLISH", patternChangesChannelKey, changeBytes)
+}
+
+// SubscribePatternChanges creates subscription for the changes of the patterns list and returns channel for these changes
+func (connector *DbConnector) SubscribePatternChanges(tomb *tomb.Tomb) (<-chan *moira.PatternChange, error) {
+	changesChannel := make(chan *moira.PatternChange, 100)
+	dataChannel, err := connector.manageSubscriptions(tomb, patternChangesChannelKey)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(changesChannel)
+		for {
+			data, ok := <-dataChannel
+			if !ok {
+				connector.logger.Info("No more subscriptions, channel is closed. Stop process pattern changes...")
+				return
+			}
+			change := &moira.PatternChange{}
+			if err := json.Unmarshal(data, change); err != nil {
+				connector.logger.ErrorF("Failed to parse PatternChange: %s, error : %v", string(data), err)
+				continue
+			}
+			changesChannel <- change
+		}
+	}()
+
+	return changesChannel, nil
+}
@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/tomb.v2"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestPatternChanges(t *testing.T) {
+	logger := test_helpers.GetTestLogger()
+	dataBase, _ := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Changes of the patterns are published", t, func() {
+		var tomb1 tomb.Tomb
+		ch, err := dataBase.SubscribePatternChanges(&tomb1)
+		So(err, ShouldBeNil)
+		// subscription is made asynchronously
+		time.Sleep(100 * time.Millisecond)
+
+		trigger := &moira.Trigger{ID: "id", Patterns: []string{"my.test.*"}}
+		So(dataBase.SaveTrigger(trigger.ID, trigger), ShouldBeNil)
+		So(<-ch, ShouldResemble, &moira.PatternChange{Pattern: "my.test.*"})
+
+		So(dataBase.RemoveTrigger(trigger.ID), ShouldBeNil)
+		So(<-ch, ShouldResemble, &moira.PatternChange{Pattern: "my.test.*", Removed: true})
+
+		tomb1.Kill(nil)
+		So(tomb1.Wait(), ShouldBeNil)
+	})
+}
@@ -0,0 +1,91 @@
+package reply
+
+import (
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+
+	"go.avito.ru/DO/moira"
+)
+
+// StreamMetricEvents converts XREADGROUP reply of the single stream to moira.MetricEvent objects
+func StreamMetricEvents(rep interface{}, err error) ([]*moira.MetricEvent, error) {
+	streams, err := redis.Values(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			// no new events before timeout
+			return make([]*moira.MetricEvent, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to read metric events: %v", err)
+	}
+	if len(streams) == 0 {
+		return make([]*moira.MetricEvent, 0), nil
+	}
+
+	// every stream is [key, entries]
+	stream, err := redis.Values(streams[0], nil)
+	if err != nil || len(stream) != 2 {
+		return nil, fmt.Errorf("Failed to read metric events stream: %v", streams[0])
+	}
+	return StreamEntries(stream[1], nil)
+}
+
+// StreamEntries converts the entries of the stream (XRANGE or XCLAIM reply) to moira.MetricEvent objects.
+// The entries removed from the stream by trimming have no fields, their events have empty pattern
+func StreamEntries(rep interface{}, err error) ([]*moira.MetricEvent, error) {
+	entries, err := redis.Values(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.MetricEvent, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to read metric events: %v", err)
+	}
+
+	events := make([]*moira.MetricEvent, 0, len(entries))
+	for _, value := range entries {
+		if value == nil {
+			// XCLAIM returns nil for the entries which are already deleted
+			continue
+		}
+		// every entry is [id, [field1, value1, ...]]
+		entry, err := redis.Values(value, nil)
+		if err != nil || len(entry) != 2 {
+			return nil, fmt.Errorf("Failed to read metric event: %v", value)
+		}
+		id, err := redis.String(entry[0], nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read id of metric event: %v", err)
+		}
+		event := &moira.MetricEvent{StreamID: id}
+		if entry[1] != nil {
+			fields, err := redis.StringMap(entry[1], nil)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read fields of metric event %s: %v", id, err)
+			}
+			event.Pattern = fields["pattern"]
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// StreamInfo converts XINFO reply which consists of field-value pairs to map
+func StreamInfo(rep interface{}, err error) (map[string]interface{}, error) {
+	values, err := redis.Values(rep, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("expects even number of values, got %d", len(values))
+	}
+
+	result := make(map[string]interface{}, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		field, err := redis.String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		result[field] = values[i+1]
+	}
+	return result, nil
+}
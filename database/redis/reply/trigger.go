@@ -13,24 +13,35 @@ import (
 
 // Duty hack for moira.Trigger TTL int64 and stored trigger TTL string compatibility
 type triggerStorageElement struct {
-	ID               string              `json:"id"`
-	Name             string              `json:"name"`
-	Desc             *string             `json:"desc,omitempty"`
-	Targets          []string            `json:"targets"`
-	Parents          []string            `json:"parents"`
-	WarnValue        *float64            `json:"warn_value"`
-	ErrorValue       *float64            `json:"error_value"`
-	Tags             []string            `json:"tags"`
-	TTLState         *string             `json:"ttl_state,omitempty"`
-	Schedule         *moira.ScheduleData `json:"sched,omitempty"`
-	Expression       *string             `json:"expr,omitempty"`
-	PythonExpression *string             `json:"expression,omitempty"`
-	Patterns         []string            `json:"patterns"`
-	TTL              string              `json:"ttl,omitempty"`
-	IsPullType       bool                `json:"is_pull_type"`
-	Dashboard        string              `json:"dashboard"`
-	PendingInterval  int64               `json:"pending_interval"`
-	Saturation       []moira.Saturation  `json:"saturation,omitempty"`
+	ID               string                     `json:"id"`
+	Name             string                     `json:"name"`
+	Desc             *string                    `json:"desc,omitempty"`
+	Targets          []string                   `json:"targets"`
+	Parents          []string                   `json:"parents"`
+	WarnValue        *float64                   `json:"warn_value"`
+	ErrorValue       *float64                   `json:"error_value"`
+	Tags             []string                   `json:"tags"`
+	TTLState         *string                    `json:"ttl_state,omitempty"`
+	Schedule         *moira.ScheduleData        `json:"sched,omitempty"`
+	Expression       *string                    `json:"expr,omitempty"`
+	PythonExpression *string                    `json:"expression,omitempty"`
+	Patterns         []string                   `json:"patterns"`
+	TTL              string                     `json:"ttl,omitempty"`
+	IsPullType       bool                       `json:"is_pull_type"`
+	Source           string                     `json:"source,omitempty"`
+	Dashboard        string                     `json:"dashboard"`
+	PendingInterval  int64                      `json:"pending_interval"`
+	Saturation       []moira.Saturation         `json:"saturation,omitempty"`
+	SLO              *moira.SLOSettings         `json:"slo,omitempty"`
+	Flapping         *moira.FlappingSettings    `json:"flapping,omitempty"`
+	Forecast         *moira.ForecastSettings    `json:"forecast,omitempty"`
+	Team             string                     `json:"team,omitempty"`
+	Template         *moira.TriggerTemplateLink `json:"template,omitempty"`
+	CheckInterval    int64                      `json:"check_interval,omitempty"`
+	Priority         moira.TriggerPriority      `json:"priority,omitempty"`
+	NoData           *moira.NoDataPolicy        `json:"nodata,omitempty"`
+	Quorum           *moira.QuorumSettings      `json:"quorum,omitempty"`
+	Managed          *moira.ManagedBy           `json:"managed,omitempty"`
 }
 
 func (storageElement *triggerStorageElement) toTrigger() *moira.Trigger {
@@ -50,9 +61,20 @@ func (storageElement *triggerStorageElement) toTrigger() *moira.Trigger {
 		Patterns:         storageElement.Patterns,
 		TTL:              getTriggerTTL(storageElement.TTL),
 		IsPullType:       storageElement.IsPullType,
+		Source:           storageElement.Source,
 		Dashboard:        storageElement.Dashboard,
 		PendingInterval:  storageElement.PendingInterval,
 		Saturation:       storageElement.Saturation,
+		SLO:              storageElement.SLO,
+		Flapping:         storageElement.Flapping,
+		Forecast:         storageElement.Forecast,
+		Team:             storageElement.Team,
+		Template:         storageElement.Template,
+		CheckInterval:    storageElement.CheckInterval,
+		Priority:         storageElement.Priority,
+		NoData:           storageElement.NoData,
+		Quorum:           storageElement.Quorum,
+		Managed:          storageElement.Managed,
 	}
 }
 
@@ -73,9 +95,20 @@ func toTriggerStorageElement(trigger *moira.Trigger, triggerID string) *triggerS
 		Patterns:         trigger.Patterns,
 		TTL:              getTriggerTTLString(trigger.TTL),
 		IsPullType:       trigger.IsPullType,
+		Source:           trigger.Source,
 		Dashboard:        trigger.Dashboard,
 		PendingInterval:  trigger.PendingInterval,
 		Saturation:       trigger.Saturation,
+		SLO:              trigger.SLO,
+		Flapping:         trigger.Flapping,
+		Forecast:         trigger.Forecast,
+		Team:             trigger.Team,
+		Template:         trigger.Template,
+		CheckInterval:    trigger.CheckInterval,
+		Priority:         trigger.Priority,
+		NoData:           trigger.NoData,
+		Quorum:           trigger.Quorum,
+		Managed:          trigger.Managed,
 	}
 }
 
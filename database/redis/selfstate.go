@@ -6,7 +6,7 @@ import "github.com/garyburd/redigo/redis"
 func (connector *DbConnector) UpdateMetricsHeartbeat() error {
 	c := connector.pool.Get()
 	defer c.Close()
-	err := c.Send("INCR", selfStateMetricsHeartbeatKey)
+	err := c.Send("INCR", selfStateMetricsHeartbeatKey())
 	return err
 }
 
@@ -14,7 +14,7 @@ func (connector *DbConnector) UpdateMetricsHeartbeat() error {
 func (connector *DbConnector) GetMetricsUpdatesCount() (int64, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	ts, err := redis.Int64(c.Do("GET", selfStateMetricsHeartbeatKey))
+	ts, err := redis.Int64(c.Do("GET", selfStateMetricsHeartbeatKey()))
 	if err == redis.ErrNil {
 		return 0, nil
 	}
@@ -25,12 +25,17 @@ func (connector *DbConnector) GetMetricsUpdatesCount() (int64, error) {
 func (connector *DbConnector) GetChecksUpdatesCount() (int64, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	ts, err := redis.Int64(c.Do("GET", selfStateChecksCounterKey))
+	ts, err := redis.Int64(c.Do("GET", selfStateChecksCounterKey()))
 	if err == redis.ErrNil {
 		return 0, nil
 	}
 	return ts, err
 }
 
-var selfStateMetricsHeartbeatKey = "moira-selfstate:metrics-heartbeat"
-var selfStateChecksCounterKey = "moira-selfstate:checks-counter"
+func selfStateMetricsHeartbeatKey() string {
+	return withHashTag("moira-selfstate:metrics-heartbeat")
+}
+
+func selfStateChecksCounterKey() string {
+	return withHashTag("moira-selfstate:checks-counter")
+}
@@ -11,7 +11,7 @@ import (
 func TestSelfCheck(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
 
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -55,7 +55,7 @@ func TestSelfCheck(t *testing.T) {
 func TestSelfCheckErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
 
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 
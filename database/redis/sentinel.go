@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// sentinel discovers the current master address via Redis Sentinel
+type sentinel struct {
+	masterName  string
+	dialOptions []redis.DialOption
+
+	mutex sync.Mutex
+	addrs []string
+}
+
+func newSentinel(config SentinelConfig, dialOptions []redis.DialOption) *sentinel {
+	if config.Password != "" {
+		dialOptions = append(dialOptions, redis.DialPassword(config.Password))
+	}
+	return &sentinel{
+		masterName:  config.MasterName,
+		dialOptions: dialOptions,
+		addrs:       append([]string(nil), config.Addrs...),
+	}
+}
+
+// discoverMaster asks sentinels one by one for the master address
+// the sentinel which has answered goes first next time
+func (s *sentinel) discoverMaster() (string, error) {
+	s.mutex.Lock()
+	addrs := append([]string(nil), s.addrs...)
+	s.mutex.Unlock()
+
+	var lastErr error
+	for i, addr := range addrs {
+		master, err := s.getMasterAddr(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if i > 0 {
+			s.mutex.Lock()
+			s.addrs = append(append([]string{addr}, addrs[:i]...), addrs[i+1:]...)
+			s.mutex.Unlock()
+		}
+		return master, nil
+	}
+	return "", fmt.Errorf("Failed to discover master '%s' via sentinels: %v", s.masterName, lastErr)
+}
+
+func (s *sentinel) getMasterAddr(sentinelAddr string) (string, error) {
+	c, err := redis.Dial("tcp", sentinelAddr, s.dialOptions...)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	res, err := redis.Strings(c.Do("SENTINEL", "get-master-addr-by-name", s.masterName))
+	if err == redis.ErrNil {
+		return "", fmt.Errorf("sentinel %s doesn't know master '%s'", sentinelAddr, s.masterName)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(res) != 2 {
+		return "", fmt.Errorf("sentinel %s returned unexpected master address %v", sentinelAddr, res)
+	}
+	return net.JoinHostPort(res[0], res[1]), nil
+}
+
+// checkRole makes sure that the connection is established with the master
+// after failover the old master becomes replica and such connections must be dropped
+func checkRole(c redis.Conn) error {
+	res, err := redis.Values(c.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		return fmt.Errorf("empty ROLE reply")
+	}
+	role, err := redis.String(res[0], nil)
+	if err != nil {
+		return err
+	}
+	if role != "master" {
+		return fmt.Errorf("redis instance has role '%s' instead of master", role)
+	}
+	return nil
+}
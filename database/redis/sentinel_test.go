@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// startFakeRedis answers every received command with the same raw RESP reply
+func startFakeRedis(t *testing.T, reply string) (string, func()) {
+	return startFakeNode(t, func([]string) string {
+		return reply
+	})
+}
+
+// startFakeNode answers every received command with the raw RESP reply the handler makes up,
+// the calls of the handler are serialized
+func startFakeNode(t *testing.T, handle func(args []string) string) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	go func() {
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer netConn.Close()
+				c := redis.NewConn(netConn, 0, 0)
+				for {
+					args, err := redis.Strings(c.Receive())
+					if err != nil {
+						return
+					}
+					mutex.Lock()
+					reply := handle(args)
+					mutex.Unlock()
+					if _, err := netConn.Write([]byte(reply)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), func() { listener.Close() }
+}
+
+func TestSentinel(t *testing.T) {
+	Convey("Master is discovered via the first available sentinel", t, func() {
+		deadAddr, stop := startFakeRedis(t, "")
+		stop()
+		aliveAddr, stop := startFakeRedis(t, "*2\r\n$8\r\n10.0.0.1\r\n$4\r\n6379\r\n")
+		defer stop()
+
+		s := newSentinel(SentinelConfig{MasterName: "moira", Addrs: []string{deadAddr, aliveAddr}}, nil)
+		master, err := s.discoverMaster()
+		So(err, ShouldBeNil)
+		So(master, ShouldEqual, "10.0.0.1:6379")
+		So(s.addrs, ShouldResemble, []string{aliveAddr, deadAddr})
+	})
+
+	Convey("Unknown master", t, func() {
+		addr, stop := startFakeRedis(t, "*-1\r\n")
+		defer stop()
+
+		s := newSentinel(SentinelConfig{MasterName: "moira", Addrs: []string{addr}}, nil)
+		_, err := s.discoverMaster()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "doesn't know master 'moira'")
+	})
+
+	Convey("Role check", t, func() {
+		masterAddr, stopMaster := startFakeRedis(t, "*3\r\n$6\r\nmaster\r\n:0\r\n*0\r\n")
+		defer stopMaster()
+		replicaAddr, stopReplica := startFakeRedis(t, "*5\r\n$5\r\nslave\r\n$8\r\n10.0.0.1\r\n:6379\r\n$9\r\nconnected\r\n:0\r\n")
+		defer stopReplica()
+
+		master, err := redis.Dial("tcp", masterAddr)
+		So(err, ShouldBeNil)
+		defer master.Close()
+		So(checkRole(master), ShouldBeNil)
+
+		replica, err := redis.Dial("tcp", replicaAddr)
+		So(err, ShouldBeNil)
+		defer replica.Close()
+		So(checkRole(replica), ShouldNotBeNil)
+	})
+}
+
+func TestCheckNotCluster(t *testing.T) {
+	Convey("Nodes of Redis Cluster are refused", t, func() {
+		standaloneAddr, stopStandalone := startFakeRedis(t, "$28\r\n# Cluster\r\ncluster_enabled:0\r\n")
+		defer stopStandalone()
+		clusterAddr, stopCluster := startFakeRedis(t, "$28\r\n# Cluster\r\ncluster_enabled:1\r\n")
+		defer stopCluster()
+
+		standalone, err := redis.Dial("tcp", standaloneAddr)
+		So(err, ShouldBeNil)
+		defer standalone.Close()
+		So(checkNotCluster(standalone), ShouldBeNil)
+
+		node, err := redis.Dial("tcp", clusterAddr)
+		So(err, ShouldBeNil)
+		defer node.Close()
+		So(checkNotCluster(node), ShouldNotBeNil)
+	})
+}
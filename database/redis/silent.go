@@ -12,17 +12,23 @@ import (
 )
 
 const (
-	silentPatternKey         = "moira-silent-patterns"
-	silentPatternLockKey     = "moira-silent-patterns-lock"
 	silentPatternLockTimeout = 6 * time.Second
 	silentPatternLockTtlSec  = 30
 )
 
+func silentPatternKey() string {
+	return withHashTag("moira-silent-patterns")
+}
+
+func silentPatternLockKey() string {
+	return withHashTag("moira-silent-patterns-lock")
+}
+
 func (connector *DbConnector) GetSilentPatternsAll() ([]*moira.SilentPatternData, error) {
 	c := connector.pool.Get()
 	defer c.Close()
 
-	rep, err := redis.Values(c.Do("HGETALL", silentPatternKey))
+	rep, err := redis.Values(c.Do("HGETALL", silentPatternKey()))
 	result := make([]*moira.SilentPatternData, 0, len(rep)/2)
 	if err != nil {
 		return result, fmt.Errorf("Failed to HGETALL: %s", err.Error())
@@ -82,10 +88,14 @@ func (connector *DbConnector) SaveSilentPatterns(pt moira.SilentPatternType, spl
 			return errors.Wrap(err, "Failed to marshal")
 		}
 
-		if err = c.Send("HSET", silentPatternKey, sp.ID, payload); err != nil {
+		if err = c.Send("HSET", silentPatternKey(), sp.ID, payload); err != nil {
 			return errors.Wrap(err, "Failed to HSET")
 		}
-		maintenance.Add(sp.Pattern, sp.Until)
+		if sp.IsScheduled() {
+			maintenance.AddWindow(sp.GetWindow())
+		} else {
+			maintenance.Add(sp.Pattern, sp.Until)
+		}
 	}
 
 	if err = connector.setMaintenanceSilent(c, pt, maintenance, false); err != nil {
@@ -115,10 +125,12 @@ func (connector *DbConnector) RemoveSilentPatterns(pt moira.SilentPatternType, s
 
 	_ = c.Send("MULTI")
 	for _, sp := range spl {
-		if err = c.Send("HDEL", silentPatternKey, sp.ID); err != nil {
+		if err = c.Send("HDEL", silentPatternKey(), sp.ID); err != nil {
 			return errors.Wrap(err, "Failed to HDEL")
 		}
-		maintenance.Del(sp.Pattern)
+		if !maintenance.DelWindow(sp.Pattern, sp.ID) {
+			maintenance.Del(sp.Pattern)
+		}
 	}
 
 	if err = connector.setMaintenanceSilent(c, pt, maintenance, false); err != nil {
@@ -146,5 +158,5 @@ func (connector *DbConnector) UnlockSilentPatterns(pt moira.SilentPatternType) e
 }
 
 func silentPatternLockKeyTyped(pt moira.SilentPatternType) string {
-	return fmt.Sprintf("%s:%d", silentPatternLockKey, pt)
+	return fmt.Sprintf("%s:%d", silentPatternLockKey(), pt)
 }
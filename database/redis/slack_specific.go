@@ -122,11 +122,11 @@ func (connector *DbConnector) RemoveSlackThreadLinks(contactID, triggerID string
 }
 
 func messageIDKey(contactID, triggerID string) string {
-	return fmt.Sprintf("moira-sender-slack-threads:%s:%s", contactID, triggerID)
+	return withHashTag(fmt.Sprintf("moira-sender-slack-threads:%s:%s", contactID, triggerID))
 }
 
 func allMessageIDsKey(triggerID string) string {
-	return fmt.Sprintf("moira-sender-slack-all-threads:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-sender-slack-all-threads:%s", triggerID))
 }
 
 func linkStorageKey(contact, threadTs, dashboardTs string) string {
@@ -205,7 +205,7 @@ func (connector *DbConnector) RemoveSlackDashboards(contactID string, dashboards
 }
 
 func slackDashboardKey(contactID, ts string) string {
-	return fmt.Sprintf("moira-sender-slack-dashboard:%s:%s", contactID, ts)
+	return withHashTag(fmt.Sprintf("moira-sender-slack-dashboard:%s:%s", contactID, ts))
 }
 
 func (connector *DbConnector) GetAllInheritedTriggerDashboards(triggerID, ancestorTriggerID, ancestorMetric string) ([]moira.SlackThreadLink, error) {
@@ -281,7 +281,7 @@ func (connector *DbConnector) DeleteInheritedTriggerDashboard(
 }
 
 func slackInheritedDashboardKey(triggerID, ancestorTriggerID, ancestorMetric string) string {
-	return fmt.Sprintf("moira-sender-slack-inherited-dashboards:%s:%s:%s", triggerID, ancestorTriggerID, ancestorMetric)
+	return withHashTag(fmt.Sprintf("moira-sender-slack-inherited-dashboards:%s:%s:%s", triggerID, ancestorTriggerID, ancestorMetric))
 }
 
 func (connector *DbConnector) GetServiceDuty(service string) (moira.DutyData, error) {
@@ -329,7 +329,7 @@ func (connector *DbConnector) UpdateServiceDuty(service string, dutyData moira.D
 }
 
 func dutyKey(service string) string {
-	return fmt.Sprintf("moira-service-duty:%s", service)
+	return withHashTag(fmt.Sprintf("moira-service-duty:%s", service))
 }
 
 func (connector *DbConnector) FetchSlackDelayedActions(until time.Time) ([]moira.SlackDelayedAction, error) {
@@ -337,8 +337,8 @@ func (connector *DbConnector) FetchSlackDelayedActions(until time.Time) ([]moira
 	defer c.Close()
 
 	c.Send("MULTI")
-	c.Send("ZRANGEBYSCORE", apiActionKey, "-inf", until.Unix())
-	c.Send("ZREMRANGEBYSCORE", apiActionKey, "-inf", until.Unix())
+	c.Send("ZRANGEBYSCORE", apiActionKey(), "-inf", until.Unix())
+	c.Send("ZREMRANGEBYSCORE", apiActionKey(), "-inf", until.Unix())
 	rawRes, err := c.Do("EXEC")
 	if rawRes == nil {
 		return []moira.SlackDelayedAction{}, database.ErrNil
@@ -374,7 +374,7 @@ func (connector *DbConnector) SaveSlackDelayedAction(action moira.SlackDelayedAc
 	if err != nil {
 		return err
 	}
-	_, err = c.Do("ZADD", apiActionKey, action.ScheduledAt.Unix(), raw)
+	_, err = c.Do("ZADD", apiActionKey(), action.ScheduledAt.Unix(), raw)
 	if err != nil {
 		return fmt.Errorf(
 			"Failed to save Slack API action [%+v] to Redis: %s",
@@ -390,7 +390,7 @@ func (connector *DbConnector) GetSlackUserGroups() (moira.SlackUserGroupsCache,
 	c := connector.pool.Get()
 	defer c.Close()
 
-	data, err := redis.Bytes(c.Do("GET", userGroupsKey))
+	data, err := redis.Bytes(c.Do("GET", userGroupsKey()))
 	if err != nil {
 		if err == redis.ErrNil {
 			err = database.ErrNil
@@ -412,14 +412,17 @@ func (connector *DbConnector) SaveSlackUserGroups(userGroups moira.SlackUserGrou
 		return err
 	}
 
-	_, err = c.Do("SET", userGroupsKey, data)
+	_, err = c.Do("SET", userGroupsKey(), data)
 	if err != nil {
 		err = fmt.Errorf("Failed to save slack user groups: %v", err)
 	}
 	return err
 }
 
-const (
-	apiActionKey  = "moira-slack-api-actions"
-	userGroupsKey = "moira-slack-user-groups"
-)
+func apiActionKey() string {
+	return withHashTag("moira-slack-api-actions")
+}
+
+func userGroupsKey() string {
+	return withHashTag("moira-slack-user-groups")
+}
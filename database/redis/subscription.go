@@ -3,6 +3,7 @@ package redis
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/garyburd/redigo/redis"
 	"github.com/satori/go.uuid"
@@ -65,8 +66,7 @@ func (connector *DbConnector) GetAllSubscriptions() ([]*moira.SubscriptionData,
 
 	subscriptionIDs := make([]string, 0, len(keys))
 	for _, key := range keys {
-		key = key[19:]
-		subscriptionIDs = append(subscriptionIDs, key)
+		subscriptionIDs = append(subscriptionIDs, strings.TrimPrefix(key, subscriptionKey("")))
 	}
 
 	return connector.GetSubscriptions(subscriptionIDs)
@@ -180,9 +180,7 @@ func (connector *DbConnector) GetTagsSubscriptions(tags []string) ([]*moira.Subs
 
 // getTagsSubscriptions does all the logic of GetTagsSubscriptions with reusing of redis connection
 func (connector *DbConnector) getTagsSubscriptions(conn redis.Conn, tags []string) ([]*moira.SubscriptionData, error) {
-	keys := convertArgs(tags, func(tag string) string {
-		return fmt.Sprintf("moira-tag-subscriptions:%s", tag)
-	})
+	keys := convertArgs(tags, tagSubscriptionKey)
 
 	values, err := redis.Values(conn.Do("SUNION", keys...))
 	if err != nil {
@@ -228,9 +226,9 @@ func addSendSubscriptionRequest(c redis.Conn, subscription *moira.SubscriptionDa
 }
 
 func subscriptionKey(id string) string {
-	return fmt.Sprintf("moira-subscription:%s", id)
+	return withHashTag(fmt.Sprintf("moira-subscription:%s", id))
 }
 
 func userSubscriptionsKey(userName string) string {
-	return fmt.Sprintf("moira-user-subscriptions:%s", userName)
+	return withHashTag(fmt.Sprintf("moira-user-subscriptions:%s", userName))
 }
@@ -8,16 +8,16 @@ import (
 	"go.avito.ru/DO/moira"
 )
 
-const (
-	tagsKey = "moira-tags"
-)
+func tagsKey() string {
+	return withHashTag("moira-tags")
+}
 
 // GetTagNames returns all tags from set with tag data
 func (connector *DbConnector) GetTagNames() ([]string, error) {
 	c := connector.pool.Get()
 	defer c.Close()
 
-	tagNames, err := redis.Strings(c.Do("SMEMBERS", tagsKey))
+	tagNames, err := redis.Strings(c.Do("SMEMBERS", tagsKey()))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to retrieve tags: %s", err.Error())
 	}
@@ -30,7 +30,7 @@ func (connector *DbConnector) RemoveTag(tagName string) error {
 	defer c.Close()
 
 	c.Send("MULTI")
-	c.Send("SREM", tagsKey, tagName)
+	c.Send("SREM", tagsKey(), tagName)
 	c.Send("DEL", tagSubscriptionKey(tagName))
 	c.Send("DEL", tagTriggersKey(tagName))
 	_, err := c.Do("EXEC")
@@ -144,9 +144,9 @@ func (connector *DbConnector) GetTagsStats(tags ...string) ([]moira.TagStats, er
 }
 
 func tagTriggersKey(tagName string) string {
-	return fmt.Sprintf("moira-tag-triggers:%s", tagName)
+	return withHashTag(fmt.Sprintf("moira-tag-triggers:%s", tagName))
 }
 
 func tagSubscriptionKey(tagName string) string {
-	return fmt.Sprintf("moira-tag-subscriptions:%s", tagName)
+	return withHashTag(fmt.Sprintf("moira-tag-subscriptions:%s", tagName))
 }
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+
+	"go.avito.ru/DO/moira"
+)
+
+// GetSeriesTagValues returns all known values of the tag of the tagged series
+func (connector *DbConnector) GetSeriesTagValues(tag string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	values, err := redis.Strings(c.Do("SMEMBERS", seriesTagValuesKey(tag)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get values of series tag %s, error: %v", tag, err)
+	}
+	return values, nil
+}
+
+// GetSeriesByTagValues returns the series which have one of the given values of the tag
+func (connector *DbConnector) GetSeriesByTagValues(tag string, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return make([]string, 0), nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	keys := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		keys = append(keys, seriesTagValueKey(tag, value))
+	}
+	series, err := redis.Strings(c.Do("SUNION", keys...))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get series by tag %s, error: %v", tag, err)
+	}
+	return series, nil
+}
+
+// addTaggedSeries adds the series to the index of tags, the name of the series is indexed as "name" tag
+func addTaggedSeries(c redis.Conn, metric string) {
+	series, err := moira.ParseTaggedSeries(metric)
+	if err != nil {
+		return
+	}
+	for tag, value := range series.GetAllTags() {
+		c.Send("SADD", seriesTagValuesKey(tag), value)
+		c.Send("SADD", seriesTagValueKey(tag, value), metric)
+	}
+}
+
+// removeTaggedSeries removes the series which have no values left from the index of tags along with the values
+// of the tags which have no series left, the series which come back are indexed again as soon as they are saved
+func removeTaggedSeries(c redis.Conn, metrics []string) error {
+	type tagValue struct {
+		tag, value string
+	}
+	touched := make([]tagValue, 0)
+	seen := make(map[tagValue]bool)
+
+	c.Send("MULTI")
+	for _, metric := range metrics {
+		series, err := moira.ParseTaggedSeries(metric)
+		if err != nil {
+			continue
+		}
+		for tag, value := range series.GetAllTags() {
+			c.Send("SREM", seriesTagValueKey(tag, value), metric)
+			if key := (tagValue{tag, value}); !seen[key] {
+				seen[key] = true
+				touched = append(touched, key)
+			}
+		}
+	}
+	for _, key := range touched {
+		c.Send("SCARD", seriesTagValueKey(key.tag, key.value))
+	}
+	replies, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return fmt.Errorf("Failed to remove series from the index of tags: %v", err)
+	}
+
+	counts := replies[len(replies)-len(touched):]
+	c.Send("MULTI")
+	for i, key := range touched {
+		if count, _ := redis.Int64(counts[i], nil); count == 0 {
+			c.Send("SREM", seriesTagValuesKey(key.tag), key.value)
+		}
+	}
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to remove tag values from the index of tags: %v", err)
+	}
+	return nil
+}
+
+func seriesTagValuesKey(tag string) string {
+	return withHashTag(fmt.Sprintf("moira-series-tag-values:%s", tag))
+}
+
+func seriesTagValueKey(tag, value string) string {
+	return withHashTag(fmt.Sprintf("moira-series-tag-series:%s:%s", tag, value))
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestTaggedSeriesIndex(t *testing.T) {
+	logger := test_helpers.GetTestLogger()
+	dataBase, _ := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Series matched by tag patterns are indexed", t, func() {
+		err := dataBase.SaveMetrics(map[string]*moira.MatchedMetric{
+			"cpu.load;env=prod;host=web1": {
+				Metric:   "cpu.load;env=prod;host=web1",
+				Patterns: []string{"seriesByTag('name=cpu.load')"},
+			},
+			"cpu.load;env=dev;host=web2": {
+				Metric:   "cpu.load;env=dev;host=web2",
+				Patterns: []string{"seriesByTag('name=cpu.load')"},
+			},
+			"cpu.idle": {
+				Metric:   "cpu.idle",
+				Patterns: []string{"cpu.*"},
+			},
+		})
+		So(err, ShouldBeNil)
+
+		values, err := dataBase.GetSeriesTagValues("host")
+		So(err, ShouldBeNil)
+		So(values, ShouldHaveLength, 2)
+		So(values, ShouldContain, "web1")
+		So(values, ShouldContain, "web2")
+
+		values, err = dataBase.GetSeriesTagValues("name")
+		So(err, ShouldBeNil)
+		So(values, ShouldResemble, []string{"cpu.load"})
+
+		series, err := dataBase.GetSeriesByTagValues("env", []string{"prod"})
+		So(err, ShouldBeNil)
+		So(series, ShouldResemble, []string{"cpu.load;env=prod;host=web1"})
+
+		series, err = dataBase.GetSeriesByTagValues("host", []string{"web1", "web2", "db1"})
+		So(err, ShouldBeNil)
+		So(series, ShouldHaveLength, 2)
+
+		series, err = dataBase.GetSeriesByTagValues("host", []string{})
+		So(err, ShouldBeNil)
+		So(series, ShouldBeEmpty)
+	})
+
+	Convey("Series without values are removed from the index", t, func() {
+		err := dataBase.SaveMetrics(map[string]*moira.MatchedMetric{
+			"cpu.load;env=prod;host=web1": {
+				Metric:             "cpu.load;env=prod;host=web1",
+				Patterns:           []string{"seriesByTag('name=cpu.load')"},
+				RetentionTimestamp: 200,
+			},
+		})
+		So(err, ShouldBeNil)
+
+		err = dataBase.RemoveMetricsValues([]string{"cpu.load;env=prod;host=web1", "cpu.load;env=dev;host=web2"}, 100)
+		So(err, ShouldBeNil)
+
+		values, err := dataBase.GetSeriesTagValues("host")
+		So(err, ShouldBeNil)
+		So(values, ShouldResemble, []string{"web1"})
+
+		values, err = dataBase.GetSeriesTagValues("env")
+		So(err, ShouldBeNil)
+		So(values, ShouldResemble, []string{"prod"})
+
+		series, err := dataBase.GetSeriesByTagValues("name", []string{"cpu.load"})
+		So(err, ShouldBeNil)
+		So(series, ShouldResemble, []string{"cpu.load;env=prod;host=web1"})
+	})
+
+	Convey("Series of removed tag pattern are removed from the index", t, func() {
+		err := dataBase.RemovePatternWithMetrics("seriesByTag('name=cpu.load')")
+		So(err, ShouldBeNil)
+
+		values, err := dataBase.GetSeriesTagValues("name")
+		So(err, ShouldBeNil)
+		So(values, ShouldBeEmpty)
+
+		series, err := dataBase.GetSeriesByTagValues("host", []string{"web1", "web2"})
+		So(err, ShouldBeNil)
+		So(series, ShouldBeEmpty)
+	})
+}
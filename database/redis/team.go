@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+
+	"go.avito.ru/DO/moira"
+)
+
+func teamsKey() string {
+	return withHashTag("moira-teams")
+}
+
+// GetTeams returns all teams stored in the database
+func (connector *DbConnector) GetTeams() ([]*moira.Team, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	values, err := redis.StringMap(c.Do("HGETALL", teamsKey()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGETALL %s: %v", teamsKey(), err)
+	}
+
+	teams := make([]*moira.Team, 0, len(values))
+	for id, value := range values {
+		team := &moira.Team{}
+		if err := json.Unmarshal([]byte(value), team); err != nil {
+			return nil, fmt.Errorf("Failed to parse team %s: %v", id, err)
+		}
+		team.ID = id
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// SaveTeam creates or replaces the team
+func (connector *DbConnector) SaveTeam(team *moira.Team) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	bytes, err := json.Marshal(team)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Do("HSET", teamsKey(), team.ID, bytes); err != nil {
+		return fmt.Errorf("Failed to HSET %s: %v", teamsKey(), err)
+	}
+	return nil
+}
+
+// RemoveTeam removes the team
+func (connector *DbConnector) RemoveTeam(teamID string) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	if _, err := c.Do("HDEL", teamsKey(), teamID); err != nil {
+		return fmt.Errorf("Failed to HDEL %s: %v", teamsKey(), err)
+	}
+	return nil
+}
@@ -23,9 +23,9 @@ func (connector *DbConnector) CheckTriggerExists(triggerID string) (bool, error)
 func (connector *DbConnector) GetTriggerIDs(onlyPull bool) ([]string, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	key := triggersListKey
+	key := triggersListKey()
 	if onlyPull {
-		key = pullTriggersListKey
+		key = pullTriggersListKey()
 	}
 	triggerIds, err := redis.Strings(c.Do("SMEMBERS", key))
 	if err != nil {
@@ -125,10 +125,10 @@ func (connector *DbConnector) SaveTrigger(triggerID string, trigger *moira.Trigg
 	if errGetTrigger != database.ErrNil {
 		patterns := existing.Patterns
 		if trigger.IsPullType {
-			c.Do("SREM", triggersListKey, triggerID)
+			c.Do("SREM", triggersListKey(), triggerID)
 		} else {
 			patterns = leftJoin(existing.Patterns, trigger.Patterns)
-			c.Do("SREM", pullTriggersListKey, triggerID)
+			c.Do("SREM", pullTriggersListKey(), triggerID)
 		}
 
 		for _, pattern := range patterns {
@@ -139,23 +139,30 @@ func (connector *DbConnector) SaveTrigger(triggerID string, trigger *moira.Trigg
 			c.Send("SREM", triggerTagsKey(triggerID), tag)
 			c.Send("SREM", tagTriggersKey(tag), triggerID)
 		}
+		if existing.Template != nil {
+			c.Send("SREM", templateTriggersKey(existing.Template.ID), triggerID)
+		}
 	}
 	c.Do("SET", triggerKey(triggerID), bytes)
 
-	c.Do("SADD", triggersListKey, triggerID)
+	c.Do("SADD", triggersListKey(), triggerID)
 	if trigger.IsPullType {
-		c.Do("SADD", pullTriggersListKey, triggerID)
+		c.Do("SADD", pullTriggersListKey(), triggerID)
 	} else {
 		for _, pattern := range trigger.Patterns {
-			c.Do("SADD", patternsListKey, pattern)
+			c.Do("SADD", patternsListKey(), pattern)
 			c.Do("SADD", patternTriggersKey(pattern), triggerID)
+			connector.publishPatternChange(c, pattern, false)
 		}
 	}
 
 	for _, tag := range trigger.Tags {
 		c.Send("SADD", triggerTagsKey(triggerID), tag)
 		c.Send("SADD", tagTriggersKey(tag), triggerID)
-		c.Send("SADD", tagsKey, tag)
+		c.Send("SADD", tagsKey(), tag)
+	}
+	if trigger.Template != nil {
+		c.Send("SADD", templateTriggersKey(trigger.Template.ID), triggerID)
 	}
 	_, err = c.Do("EXEC")
 	if err != nil {
@@ -193,14 +200,17 @@ func (connector *DbConnector) RemoveTrigger(triggerID string) error {
 	c.Send("MULTI")
 	c.Send("DEL", triggerKey(triggerID))
 	c.Send("DEL", triggerTagsKey(triggerID))
-	c.Send("SREM", triggersListKey, triggerID)
-	c.Send("SREM", pullTriggersListKey, triggerID)
+	c.Send("SREM", triggersListKey(), triggerID)
+	c.Send("SREM", pullTriggersListKey(), triggerID)
 	for _, tag := range trigger.Tags {
 		c.Send("SREM", tagTriggersKey(tag), triggerID)
 	}
 	for _, pattern := range trigger.Patterns {
 		c.Send("SREM", patternTriggersKey(pattern), triggerID)
 	}
+	if trigger.Template != nil {
+		c.Send("SREM", templateTriggersKey(trigger.Template.ID), triggerID)
+	}
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -370,25 +380,30 @@ func leftJoin(left, right []string) []string {
 	return arr
 }
 
-var triggersListKey = "moira-triggers-list"
-var pullTriggersListKey = "moira-pull-triggers-list"
+func triggersListKey() string {
+	return withHashTag("moira-triggers-list")
+}
+
+func pullTriggersListKey() string {
+	return withHashTag("moira-pull-triggers-list")
+}
 
 func triggerCheckLogStatsKey(triggerID string) string {
-	return fmt.Sprintf("moira-trigger-check-log-stats:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-trigger-check-log-stats:%s", triggerID))
 }
 
 func triggerForcedNotificationsKey(triggerID string) string {
-	return fmt.Sprintf("moira-triggers-forced-notifications:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-triggers-forced-notifications:%s", triggerID))
 }
 
 func triggerKey(triggerID string) string {
-	return fmt.Sprintf("moira-trigger:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-trigger:%s", triggerID))
 }
 
 func triggerTagsKey(triggerID string) string {
-	return fmt.Sprintf("moira-trigger-tags:%s", triggerID)
+	return withHashTag(fmt.Sprintf("moira-trigger-tags:%s", triggerID))
 }
 
 func patternTriggersKey(pattern string) string {
-	return fmt.Sprintf("moira-pattern-triggers:%s", pattern)
+	return withHashTag(fmt.Sprintf("moira-pattern-triggers:%s", pattern))
 }
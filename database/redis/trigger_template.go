@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/database"
+)
+
+// triggerTemplatesKey maps template ID to template
+func triggerTemplatesKey() string {
+	return withHashTag("moira-trigger-templates")
+}
+
+// GetTriggerTemplates returns all trigger templates
+func (connector *DbConnector) GetTriggerTemplates() ([]*moira.TriggerTemplate, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	values, err := redis.StringMap(c.Do("HGETALL", triggerTemplatesKey()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGETALL %s: %v", triggerTemplatesKey(), err)
+	}
+
+	templates := make([]*moira.TriggerTemplate, 0, len(values))
+	for id, value := range values {
+		template := &moira.TriggerTemplate{}
+		if err := json.Unmarshal([]byte(value), template); err != nil {
+			return nil, fmt.Errorf("Failed to parse trigger template %s: %v", id, err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// GetTriggerTemplate returns trigger template by its ID, database.ErrNil is returned if there is no such template
+func (connector *DbConnector) GetTriggerTemplate(templateID string) (*moira.TriggerTemplate, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	value, err := redis.Bytes(c.Do("HGET", triggerTemplatesKey(), templateID))
+	if err == redis.ErrNil {
+		return nil, database.ErrNil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to HGET %s: %v", triggerTemplatesKey(), err)
+	}
+
+	template := &moira.TriggerTemplate{}
+	if err := json.Unmarshal(value, template); err != nil {
+		return nil, fmt.Errorf("Failed to parse trigger template %s: %v", templateID, err)
+	}
+	return template, nil
+}
+
+// SaveTriggerTemplate creates or replaces trigger template, its instances are saved by SaveTrigger
+func (connector *DbConnector) SaveTriggerTemplate(template *moira.TriggerTemplate) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	bytes, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Do("HSET", triggerTemplatesKey(), template.ID, bytes); err != nil {
+		return fmt.Errorf("Failed to HSET %s: %v", triggerTemplatesKey(), err)
+	}
+	return nil
+}
+
+// RemoveTriggerTemplate removes trigger template, its instances are not changed
+func (connector *DbConnector) RemoveTriggerTemplate(templateID string) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	c.Send("MULTI")
+	c.Send("HDEL", triggerTemplatesKey(), templateID)
+	c.Send("DEL", templateTriggersKey(templateID))
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %v", err)
+	}
+	return nil
+}
+
+// GetTemplateTriggerIDs returns IDs of the triggers instantiated from the template
+func (connector *DbConnector) GetTemplateTriggerIDs(templateID string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	triggerIDs, err := redis.Strings(c.Do("SMEMBERS", templateTriggersKey(templateID)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to SMEMBERS %s: %v", templateTriggersKey(templateID), err)
+	}
+	return triggerIDs, nil
+}
+
+func templateTriggersKey(templateID string) string {
+	return withHashTag(fmt.Sprintf("moira-trigger-template-triggers:%s", templateID))
+}
@@ -22,7 +22,7 @@ func StringSetShouldResemble(actual interface{}, expected ...interface{}) string
 
 func TestTriggerStoring(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, config)
+	dataBase, _ := NewDatabase(logger, config)
 	dataBase.flush()
 	defer dataBase.flush()
 
@@ -240,7 +240,7 @@ func TestTriggerStoring(t *testing.T) {
 
 func TestTriggerErrorConnection(t *testing.T) {
 	logger := test_helpers.GetTestLogger()
-	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase, _ := NewDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 	Convey("Should throw error when no connection", t, func() {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ const (
 	EXCEPTION = "EXCEPTION"
 	DEL       = "DEL"
 	TEST      = "TEST"
+	FLAPPING  = "FLAPPING" // used only as the state of the event which tells that the metric started flapping
 )
 
 // Events' mute reasons
@@ -59,7 +61,7 @@ const (
 )
 
 var (
-	eventStates = [...]string{OK, WARN, ERROR, NODATA, TEST}
+	eventStates = [...]string{OK, WARN, FLAPPING, ERROR, NODATA, TEST}
 
 	scores = map[string]int64{
 		OK:        0,
@@ -89,6 +91,8 @@ type NotificationEvent struct {
 	Batch          *EventsBatch `json:"batch"`
 
 	HasSaturations bool `json:"has_saturations,omitempty"`
+	MetricRemoved  bool `json:"metric_removed,omitempty"` // the metric is removed from the trigger because it has no data
+	DashboardOnly  bool `json:"dashboard_only,omitempty"` // the event only updates the dashboards, nobody is notified of it
 
 	OverriddenByAncestor bool   `json:"overridden,omitempty"`
 	DelayedForAncestor   bool   `json:"delayed_for_ancestor,omitempty"`
@@ -225,6 +229,27 @@ func (events NotificationEvents) GetSubjectState() string {
 	return result
 }
 
+// WithoutDashboardOnly returns the events which are notified of, the ones which only update the dashboards are left out
+func (events NotificationEvents) WithoutDashboardOnly() NotificationEvents {
+	result := make(NotificationEvents, 0, len(events))
+	for _, event := range events {
+		if !event.DashboardOnly {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// IsEventState can tell whether the given state can be the state of the event
+func IsEventState(state string) bool {
+	for _, eventState := range eventStates {
+		if state == eventState {
+			return true
+		}
+	}
+	return false
+}
+
 // TriggerData represents trigger object
 type TriggerData struct {
 	ID         string       `json:"id"`
@@ -264,6 +289,7 @@ type ContactData struct {
 	ID            string `json:"id"`
 	User          string `json:"user"` // User is the user that _created_ the contact
 	Expiration    *time.Time
+	Managed       *ManagedBy `json:"managed,omitempty"`
 }
 
 func (cd *ContactData) NeedsFallbackValue() bool {
@@ -277,6 +303,35 @@ type SilentPatternData struct {
 	Created int64             `json:"created_at"`
 	Until   int64             `json:"until"`
 	Type    SilentPatternType `json:"type"`
+
+	// optional schedule: pattern starts to work at Start (instead of right now) and/or works only during recurring windows
+	Start      int64       `json:"start_at,omitempty"`
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+
+	// the reason of silence: comment is mandatory for patterns created via API, ticket is an optional link
+	Comment string `json:"comment,omitempty"`
+	Ticket  string `json:"ticket,omitempty"`
+
+	// Matcher is set for patterns of SPTMatcher type, their Pattern is a readable form of it
+	Matcher *SilentMatcher `json:"matcher,omitempty"`
+
+	Managed *ManagedBy `json:"managed,omitempty"`
+}
+
+// IsScheduled tells if the pattern doesn't simply work from now until Until
+func (spd *SilentPatternData) IsScheduled() bool {
+	return spd.Start > 0 || spd.Recurrence != nil
+}
+
+// GetWindow returns maintenance window of the scheduled pattern
+func (spd *SilentPatternData) GetWindow() MaintenanceWindow {
+	return MaintenanceWindow{
+		ID:         spd.ID,
+		Metric:     spd.Pattern,
+		From:       MaxI64(spd.Start, spd.Created),
+		Until:      spd.Until,
+		Recurrence: spd.Recurrence,
+	}
 }
 
 func (spd *SilentPatternData) IsMetric() bool {
@@ -287,23 +342,77 @@ func (spd *SilentPatternData) IsTag() bool {
 	return spd.Type == SPTTag
 }
 
+func (spd *SilentPatternData) IsMatcher() bool {
+	return spd.Type == SPTMatcher
+}
+
 type SilentPatternType int
 
 const (
-	SPTMetric SilentPatternType = 0
-	SPTTag    SilentPatternType = 1
+	SPTMetric  SilentPatternType = 0
+	SPTTag     SilentPatternType = 1
+	SPTMatcher SilentPatternType = 2
 )
 
+// SilentMatcher describes what is silenced by the pattern of SPTMatcher type
+// all the given conditions must hold, empty conditions are not checked
+type SilentMatcher struct {
+	MetricRegex string   `json:"metric_regex,omitempty"`
+	Tags        []string `json:"tags,omitempty"` // trigger must have all of them
+	TriggerID   string   `json:"trigger_id,omitempty"`
+	States      []string `json:"states,omitempty"` // event must have any of them
+}
+
+// Validate checks that the matcher is not empty and all its conditions are correct
+func (matcher *SilentMatcher) Validate() error {
+	if matcher.MetricRegex == "" && len(matcher.Tags) == 0 && matcher.TriggerID == "" && len(matcher.States) == 0 {
+		return fmt.Errorf("matcher must have at least one condition")
+	}
+	if _, err := regexp.Compile(matcher.MetricRegex); err != nil {
+		return fmt.Errorf("invalid metric regex: %v", err)
+	}
+	for _, state := range matcher.States {
+		if !IsEventState(state) {
+			return fmt.Errorf("unknown state '%s'", state)
+		}
+	}
+	return nil
+}
+
+// String returns readable form of the matcher, e.g. 'metric=~"^db\." tags=prod,mysql state=ERROR'
+func (matcher *SilentMatcher) String() string {
+	parts := make([]string, 0, 4)
+	if matcher.MetricRegex != "" {
+		parts = append(parts, fmt.Sprintf("metric=~%q", matcher.MetricRegex))
+	}
+	if len(matcher.Tags) > 0 {
+		tags := append([]string(nil), matcher.Tags...)
+		sort.Strings(tags)
+		parts = append(parts, "tags="+strings.Join(tags, ","))
+	}
+	if matcher.TriggerID != "" {
+		parts = append(parts, "trigger="+matcher.TriggerID)
+	}
+	if len(matcher.States) > 0 {
+		states := append([]string(nil), matcher.States...)
+		sort.Strings(states)
+		parts = append(parts, "state="+strings.Join(states, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
 // EscalationData represents escalation object
 type EscalationData struct {
 	ID              string   `json:"id"`
 	Contacts        []string `json:"contacts"`
+	Rotations       []string `json:"rotations,omitempty"` // the people on call by the rotations are notified as well
 	OffsetInMinutes int64    `json:"offset_in_minutes"`
 }
 
 // SubscriptionData represent user subscription
 type SubscriptionData struct {
 	Contacts          []string         `json:"contacts"`
+	Rotations         []string         `json:"rotations,omitempty"` // the people on call by the rotations are notified as well
 	Tags              []string         `json:"tags"`
 	Schedule          ScheduleData     `json:"sched"`
 	ID                string           `json:"id"`
@@ -311,6 +420,14 @@ type SubscriptionData struct {
 	ThrottlingEnabled bool             `json:"throttling"`
 	User              string           `json:"user"`
 	Escalations       []EscalationData `json:"escalations"`
+	Managed           *ManagedBy       `json:"managed,omitempty"`
+}
+
+// ManagedBy marks the entity which is managed by declarative sync (moira-cli -sync) rather than by UI:
+// the sync touches only the entities of its own source and never the ones without the mark
+type ManagedBy struct {
+	Source string `json:"source"` // the owner of the entities, e.g. the repository the files are kept in
+	Key    string `json:"key"`    // stable external key of the entity within its source
 }
 
 // ScheduleData represent subscription schedule
@@ -391,6 +508,7 @@ type ScheduledNotification struct {
 	Timestamp int64             `json:"timestamp"`
 	SendFail  int               `json:"send_fail"`
 	NeedAck   bool              `json:"need_ack"`
+	Escalated bool              `json:"escalated,omitempty"`
 	Throttled bool              `json:"throttled"`
 }
 
@@ -448,24 +566,247 @@ type MetricValue struct {
 
 // Trigger represents trigger data object
 type Trigger struct {
-	ID               string        `json:"id"`
-	Name             string        `json:"name"`
-	Desc             *string       `json:"desc,omitempty"`
-	Targets          []string      `json:"targets"`
-	Parents          []string      `json:"parents"`
-	WarnValue        *float64      `json:"warn_value"`
-	ErrorValue       *float64      `json:"error_value"`
-	Tags             []string      `json:"tags"`
-	TTLState         *string       `json:"ttl_state,omitempty"`
-	TTL              int64         `json:"ttl,omitempty"`
-	Schedule         *ScheduleData `json:"sched,omitempty"`
-	Expression       *string       `json:"expression,omitempty"`
-	PythonExpression *string       `json:"python_expression,omitempty"`
-	Patterns         []string      `json:"patterns"`
-	IsPullType       bool          `json:"is_pull_type"`
-	Dashboard        string        `json:"dashboard"`
-	PendingInterval  int64         `json:"pending_interval"`
-	Saturation       []Saturation  `json:"saturation"`
+	ID               string               `json:"id"`
+	Name             string               `json:"name"`
+	Desc             *string              `json:"desc,omitempty"`
+	Targets          []string             `json:"targets"`
+	Parents          []string             `json:"parents"`
+	WarnValue        *float64             `json:"warn_value"`
+	ErrorValue       *float64             `json:"error_value"`
+	Tags             []string             `json:"tags"`
+	TTLState         *string              `json:"ttl_state,omitempty"`
+	TTL              int64                `json:"ttl,omitempty"`
+	Schedule         *ScheduleData        `json:"sched,omitempty"`
+	Expression       *string              `json:"expression,omitempty"`
+	PythonExpression *string              `json:"python_expression,omitempty"`
+	Patterns         []string             `json:"patterns"`
+	IsPullType       bool                 `json:"is_pull_type"`
+	Source           string               `json:"source,omitempty"` // remote source of pull trigger
+	Dashboard        string               `json:"dashboard"`
+	PendingInterval  int64                `json:"pending_interval"`
+	Saturation       []Saturation         `json:"saturation"`
+	SLO              *SLOSettings         `json:"slo,omitempty"`
+	Flapping         *FlappingSettings    `json:"flapping,omitempty"`
+	Forecast         *ForecastSettings    `json:"forecast,omitempty"`
+	Team             string               `json:"team,omitempty"`           // explicit owner, see Team
+	Template         *TriggerTemplateLink `json:"template,omitempty"`       // the trigger is rendered from the template
+	CheckInterval    int64                `json:"check_interval,omitempty"` // seconds between checks, the checker default (pull interval for pull triggers) is used if zero
+	Priority         TriggerPriority      `json:"priority,omitempty"`
+	NoData           *NoDataPolicy        `json:"nodata,omitempty"` // overrides TTLState if set
+	Quorum           *QuorumSettings      `json:"quorum,omitempty"` // the trigger alerts on the share of its bad metrics
+	Managed          *ManagedBy           `json:"managed,omitempty"`
+}
+
+// TriggerPriority tells the checker which triggers to check first when it can't keep up
+type TriggerPriority string
+
+const (
+	TriggerPriorityLow    TriggerPriority = "low"
+	TriggerPriorityNormal TriggerPriority = "normal"
+	TriggerPriorityHigh   TriggerPriority = "high"
+)
+
+// TriggerPriorities lists the priorities from the lowest to the highest
+var TriggerPriorities = []TriggerPriority{TriggerPriorityLow, TriggerPriorityNormal, TriggerPriorityHigh}
+
+// IsValid can tell whether or not the priority is known, the empty priority is the normal one
+func (priority TriggerPriority) IsValid() bool {
+	return priority == "" || priority.Level() >= 0
+}
+
+// Level returns the index of the priority in TriggerPriorities or -1 if the priority is unknown
+func (priority TriggerPriority) Level() int {
+	if priority == "" {
+		priority = TriggerPriorityNormal
+	}
+	for level, known := range TriggerPriorities {
+		if priority == known {
+			return level
+		}
+	}
+	return -1
+}
+
+// GetPriority returns the priority of the trigger, the normal one is the default
+func (trigger *Trigger) GetPriority() TriggerPriority {
+	if trigger.Priority == "" || trigger.Priority.Level() < 0 {
+		return TriggerPriorityNormal
+	}
+	return trigger.Priority
+}
+
+// NoDataAction tells what to do with the metric which has no data for longer than TTL
+type NoDataAction string
+
+const (
+	NoDataActionAlert  NoDataAction = "nodata" // the metric goes to NODATA state
+	NoDataActionOK     NoDataAction = "ok"     // the metric goes to OK state
+	NoDataActionKeep   NoDataAction = "keep"   // the metric keeps its last state
+	NoDataActionRemove NoDataAction = "remove" // the metric is removed
+)
+
+// NoDataPolicy describes how the trigger treats the metrics which stopped receiving data
+type NoDataPolicy struct {
+	Action             NoDataAction `json:"action,omitempty"`               // NoDataActionAlert if empty
+	RemoveAfterMinutes int64        `json:"remove_after_minutes,omitempty"` // minutes without data after which the metric is removed whatever the action is, never if zero
+}
+
+// Validate checks the action and the removal delay of the policy
+func (policy *NoDataPolicy) Validate() error {
+	switch policy.Action {
+	case "", NoDataActionAlert, NoDataActionOK, NoDataActionKeep, NoDataActionRemove:
+	default:
+		return fmt.Errorf("unknown nodata action '%s'", policy.Action)
+	}
+	if policy.RemoveAfterMinutes < 0 {
+		return fmt.Errorf("nodata remove_after_minutes can't be negative")
+	}
+	return nil
+}
+
+// IsSLO tells if the trigger is a burn-rate SLO trigger
+func (trigger *Trigger) IsSLO() bool {
+	return trigger.SLO != nil
+}
+
+// SLOSettings describes multi-window burn-rate SLO trigger
+// the SLI is calculated as sum(GoodTarget) / sum(TotalTarget) over each window
+type SLOSettings struct {
+	GoodTarget  string             `json:"good_target"`
+	TotalTarget string             `json:"total_target"`
+	Objective   float64            `json:"objective"`        // percent, e.g. 99.9
+	Period      int64              `json:"period,omitempty"` // error budget period in seconds
+	Windows     []SLOBurnRateAlert `json:"windows,omitempty"`
+}
+
+// SLOBurnRateAlert is a pair of windows (in seconds) and the burn rate
+// both windows must exceed in order to switch the trigger to the State
+type SLOBurnRateAlert struct {
+	LongWindow  int64   `json:"long_window"`
+	ShortWindow int64   `json:"short_window"`
+	BurnRate    float64 `json:"burn_rate"`
+	State       string  `json:"state"`
+}
+
+// DefaultSLOBurnRateAlerts is the standard fast/slow burn policy for 30 days budget
+var DefaultSLOBurnRateAlerts = []SLOBurnRateAlert{
+	{LongWindow: 3600, ShortWindow: 300, BurnRate: 14.4, State: ERROR},
+	{LongWindow: 6 * 3600, ShortWindow: 1800, BurnRate: 6, State: ERROR},
+	{LongWindow: 24 * 3600, ShortWindow: 2 * 3600, BurnRate: 3, State: WARN},
+	{LongWindow: 3 * 24 * 3600, ShortWindow: 6 * 3600, BurnRate: 1, State: WARN},
+}
+
+// GetWindows returns burn rate alerts of the SLO or the default ones if none are set
+func (slo *SLOSettings) GetWindows() []SLOBurnRateAlert {
+	if len(slo.Windows) == 0 {
+		return DefaultSLOBurnRateAlerts
+	}
+	return slo.Windows
+}
+
+// GetPeriod returns error budget period which is the longest window unless it is set explicitly
+func (slo *SLOSettings) GetPeriod() int64 {
+	if slo.Period > 0 {
+		return slo.Period
+	}
+
+	period := int64(0)
+	for _, window := range slo.GetWindows() {
+		period = MaxI64(period, window.LongWindow)
+	}
+	return period
+}
+
+// GetErrorBudget returns allowed fraction of bad events
+func (slo *SLOSettings) GetErrorBudget() float64 {
+	return 1 - slo.Objective/100
+}
+
+// FlappingSettings describes flap detection of trigger's metrics
+// the metric is flapping if it has changed its state more than Threshold times during the last Window seconds,
+// it stops flapping as soon as the number of changes during the window falls to Threshold/2 or less
+type FlappingSettings struct {
+	Threshold int   `json:"threshold"`
+	Window    int64 `json:"window"`
+}
+
+// IsFlappingDetectionEnabled tells if the flap detection is set up for the trigger
+func (trigger *Trigger) IsFlappingDetectionEnabled() bool {
+	return trigger.Flapping != nil && trigger.Flapping.Threshold > 0 && trigger.Flapping.Window > 0
+}
+
+// Forecast models
+const (
+	ForecastModelLinear = "linear"
+	ForecastModelHolt   = "holt"
+)
+
+// ForecastSettings describes "time to threshold" trigger mode:
+// the trend of each series is fitted over the last Lookback seconds and the trigger goes to ERROR (WARN)
+// if ErrorValue is projected to be reached sooner than in ErrorHorizon (WarnHorizon) seconds
+type ForecastSettings struct {
+	Model        string  `json:"model,omitempty"` // linear (default) or holt
+	Lookback     int64   `json:"lookback"`
+	WarnHorizon  int64   `json:"warn_horizon,omitempty"`
+	ErrorHorizon int64   `json:"error_horizon"`
+	Alpha        float64 `json:"alpha,omitempty"` // level smoothing factor of holt model
+	Beta         float64 `json:"beta,omitempty"`  // trend smoothing factor of holt model
+}
+
+// IsForecast tells if the trigger works in "time to threshold" mode
+func (trigger *Trigger) IsForecast() bool {
+	return trigger.Forecast != nil
+}
+
+// GetModel returns forecast model, linear one is the default
+func (forecast *ForecastSettings) GetModel() string {
+	if forecast.Model == "" {
+		return ForecastModelLinear
+	}
+	return forecast.Model
+}
+
+// GetSmoothing returns smoothing factors of holt model falling back to the defaults
+func (forecast *ForecastSettings) GetSmoothing() (alpha, beta float64) {
+	alpha, beta = 0.5, 0.3
+	if forecast.Alpha > 0 {
+		alpha = forecast.Alpha
+	}
+	if forecast.Beta > 0 {
+		beta = forecast.Beta
+	}
+	return alpha, beta
+}
+
+// QuorumSettings describes aggregation mode: the metrics of the trigger are not alerted separately,
+// the whole trigger goes to the worst of their states if at least Count of them or Percent of them
+// are in WARN, ERROR or NODATA state
+type QuorumSettings struct {
+	Count   int     `json:"count,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// IsQuorum tells if the trigger works in aggregation mode
+func (trigger *Trigger) IsQuorum() bool {
+	return trigger.Quorum != nil
+}
+
+// IsReached tells if the bad metrics out of the total are enough for the trigger to alert
+func (quorum *QuorumSettings) IsReached(bad, total int) bool {
+	if bad == 0 {
+		return false
+	}
+	if quorum.Count > 0 && bad >= quorum.Count {
+		return true
+	}
+	return quorum.Percent > 0 && total > 0 && float64(bad)*100 >= quorum.Percent*float64(total)
+}
+
+// MetricForecast is the projection of the metric made by forecast trigger
+type MetricForecast struct {
+	Threshold float64 `json:"threshold"`
+	Slope     float64 `json:"slope"`         // change of the value per second
+	ETA       *int64  `json:"eta,omitempty"` // seconds left until the threshold is reached, absent if it is never reached
 }
 
 // IsSimple checks triggers patterns
@@ -547,6 +888,16 @@ type MetricState struct {
 
 	IsNoData bool `json:"is_no_data"`
 	IsForced bool `json:"is_forced,omitempty"`
+
+	// Message is an optional explanation of the state which goes to the event
+	Message string `json:"msg,omitempty"`
+
+	// flap detection: timestamps of the recent state changes and the flag itself
+	StateChanges []int64 `json:"state_changes,omitempty"`
+	IsFlapping   bool    `json:"is_flapping,omitempty"`
+
+	// the projection made by forecast trigger
+	Forecast *MetricForecast `json:"forecast,omitempty"`
 }
 
 // GetCheckPoint gets check point for given MetricState
@@ -567,12 +918,90 @@ func (metricState *MetricState) GetEventTimestamp() int64 {
 type MetricEvent struct {
 	Metric  string `json:"metric"`
 	Pattern string `json:"pattern"`
+
+	// StreamID is the id of the entry of the stream the event is delivered with, it is empty for Pub/Sub delivery
+	StreamID string `json:"-"`
+}
+
+// MetricEventsLag describes how far the checkers are behind the filter
+type MetricEventsLag struct {
+	// Pending is the count of the events delivered to the checkers but not acknowledged yet
+	Pending int64
+	// Lag is the time between the last event sent by the filter and the last event delivered to the checkers
+	Lag time.Duration
+}
+
+// PatternChange notifies the filter that the pattern is added to or removed from the patterns list
+type PatternChange struct {
+	Pattern string `json:"pattern"`
+	Removed bool   `json:"removed,omitempty"`
 }
 
 // maintenanceInterval is maintenance interval for some metric or for the whole trigger
 type maintenanceInterval struct {
 	From  int64 `json:"from"`
 	Until int64 `json:"until"`
+
+	// scheduled windows are identified, they respect From and might be recurring
+	ID         string      `json:"id,omitempty"`
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+}
+
+// isScheduled can tell scheduled window from the maintenance which has been set "from now until X"
+func (interval *maintenanceInterval) isScheduled() bool {
+	return interval.ID != ""
+}
+
+// activeAt can tell whether the interval covers ts and when it ends
+func (interval *maintenanceInterval) activeAt(ts int64) (bool, int64) {
+	if interval.Until < ts {
+		return false, 0
+	}
+	if !interval.isScheduled() {
+		return true, interval.Until
+	}
+	if interval.From > ts {
+		return false, 0
+	}
+	if interval.Recurrence == nil {
+		return true, interval.Until
+	}
+
+	// the occurrence must start within the window
+	active, until := interval.Recurrence.ActiveAt(ts)
+	if !active || until-interval.Recurrence.Duration < interval.From {
+		return false, 0
+	}
+	return true, MinI64(until, interval.Until)
+}
+
+// MaintenanceWindow is scheduled (and maybe recurring) maintenance of some metric or the whole trigger
+type MaintenanceWindow struct {
+	ID         string      `json:"id"`
+	Metric     string      `json:"metric"`
+	From       int64       `json:"from"`
+	Until      int64       `json:"until"`
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+}
+
+// Validate checks that the window can be scheduled
+func (window *MaintenanceWindow) Validate() error {
+	if window.Until <= window.From {
+		return fmt.Errorf("window must end after it starts")
+	}
+	if window.Recurrence != nil {
+		return window.Recurrence.Validate()
+	}
+	return nil
+}
+
+// MaintenanceOccurrence is a single occurrence of the scheduled maintenance window
+type MaintenanceOccurrence struct {
+	WindowID  string `json:"window_id"`
+	Metric    string `json:"metric"`
+	From      int64  `json:"from"`
+	Until     int64  `json:"until"`
+	Recurrent bool   `json:"recurrent"`
 }
 
 // Maintenance is history of maintenance intervals for each metric of the trigger
@@ -600,55 +1029,76 @@ func NewMaintenanceFromCheckData(data *CheckData) Maintenance {
 // Add adds maintenance for the given metric
 func (maintenance Maintenance) Add(metric string, until int64) {
 	now := time.Now().Unix()
-	history, ok := maintenance[metric]
+	history := maintenance[metric]
 
-	if ok {
-		last := &history[len(history)-1]
-		if last.Until > now {
-			// last maintenance isn't over yet, extend it
-			last.Until = until
-		} else {
-			// append new maintenance
-			history = append(history, maintenanceInterval{
-				From:  now,
-				Until: until,
-			})
-			maintenance[metric] = history
+	if last := maintenance.lastImmediate(metric); last != nil && last.Until > now {
+		// last maintenance isn't over yet, extend it
+		last.Until = until
+		return
+	}
+
+	// append new maintenance
+	maintenance[metric] = append(history, maintenanceInterval{
+		From:  now,
+		Until: until,
+	})
+}
+
+// AddWindow schedules maintenance window of the given metric, the window with the same ID is replaced
+func (maintenance Maintenance) AddWindow(window MaintenanceWindow) {
+	maintenance.DelWindow(window.Metric, window.ID)
+	maintenance[window.Metric] = append(maintenance[window.Metric], maintenanceInterval{
+		From:       window.From,
+		Until:      window.Until,
+		ID:         window.ID,
+		Recurrence: window.Recurrence,
+	})
+}
+
+// DelWindow removes scheduled window of the given metric, it tells if the window has been found
+func (maintenance Maintenance) DelWindow(metric, id string) bool {
+	history := maintenance[metric]
+	for i := range history {
+		if history[i].ID == id {
+			history = append(history[:i], history[i+1:]...)
+			if len(history) == 0 {
+				delete(maintenance, metric)
+			} else {
+				maintenance[metric] = history
+			}
+			return true
 		}
-	} else {
-		maintenance[metric] = []maintenanceInterval{{
-			From:  now,
-			Until: until,
-		}}
 	}
+	return false
 }
 
 // Get returns maintenance state for the given metric at the given timestamp
 func (maintenance Maintenance) Get(metric string, ts int64) (maintained bool, until int64) {
-	// check the metric is present in the first place
-	history, ok := maintenance[metric]
-	if !ok {
-		return false, 0
-	}
-	qty := len(history)
-
-	// look for the interval where Until exceeds ts
-	pos := sort.Search(qty, func(i int) bool {
-		return history[i].Until >= ts
-	})
-	if pos == qty { // sort.Search returns collection's length if nothing has been found
-		return false, 0
+	for _, interval := range maintenance[metric] {
+		if active, intervalUntil := interval.activeAt(ts); active && intervalUntil > until {
+			maintained, until = true, intervalUntil
+		}
 	}
-	return true, history[pos].Until
+	return maintained, until
 }
 
 // Del terminates maintenance for the given metric
-// it does nothing if the metric doesn't exist
+// it does nothing if the metric doesn't exist, scheduled windows are not affected
 func (maintenance Maintenance) Del(metric string) {
-	if history, ok := maintenance[metric]; ok {
-		history[len(history)-1].Until = time.Now().Unix()
-		maintenance[metric] = history
+	if last := maintenance.lastImmediate(metric); last != nil {
+		last.Until = time.Now().Unix()
+	}
+}
+
+// lastImmediate returns the latest maintenance which has been set "from now until X"
+func (maintenance Maintenance) lastImmediate(metric string) *maintenanceInterval {
+	history := maintenance[metric]
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].isScheduled() {
+			return &history[i]
+		}
 	}
+	return nil
 }
 
 // Clean removes all outdated maintenance intervals
@@ -657,18 +1107,18 @@ func (maintenance Maintenance) Clean() {
 	margin := time.Now().Unix() - housekeepingRange
 
 	for metric, history := range maintenance {
-		// find first non-outdated interval
-		qty := len(history)
-		pos := sort.Search(qty, func(i int) bool {
-			return history[i].From > margin
-		})
+		actual := history[:0]
+		for _, interval := range history {
+			if interval.From > margin || interval.Until > margin {
+				actual = append(actual, interval)
+			}
+		}
 
-		if pos == qty {
+		if len(actual) == 0 {
 			// all intervals are outdated -- just delete metric entry
 			delete(maintenance, metric)
-		} else if pos > 0 {
-			// truncate intervals
-			maintenance[metric] = history[pos:]
+		} else {
+			maintenance[metric] = actual
 		}
 	}
 }
@@ -676,14 +1126,9 @@ func (maintenance Maintenance) Clean() {
 // Snapshot returns map of metrics to their actual maintenance on the given timestamp
 func (maintenance Maintenance) Snapshot(ts int64) map[string]int64 {
 	result := make(map[string]int64, len(maintenance))
-	for metric, history := range maintenance {
-		qty := len(history)
-		pos := sort.Search(qty, func(i int) bool {
-			return history[i].Until >= ts
-		})
-
-		if pos < qty {
-			result[metric] = history[pos].Until
+	for metric := range maintenance {
+		if maintained, until := maintenance.Get(metric, ts); maintained {
+			result[metric] = until
 		}
 	}
 	return result
@@ -693,6 +1138,67 @@ func (maintenance Maintenance) SnapshotNow() map[string]int64 {
 	return maintenance.Snapshot(time.Now().Unix())
 }
 
+// Windows returns all scheduled windows
+func (maintenance Maintenance) Windows() []MaintenanceWindow {
+	result := make([]MaintenanceWindow, 0)
+	for metric, history := range maintenance {
+		for _, interval := range history {
+			if interval.isScheduled() {
+				result = append(result, MaintenanceWindow{
+					ID:         interval.ID,
+					Metric:     metric,
+					From:       interval.From,
+					Until:      interval.Until,
+					Recurrence: interval.Recurrence,
+				})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].From < result[j].From
+	})
+	return result
+}
+
+// Upcoming returns occurrences of the scheduled windows which overlap with [from, until) ordered by their start
+func (maintenance Maintenance) Upcoming(from, until int64) []MaintenanceOccurrence {
+	result := make([]MaintenanceOccurrence, 0)
+	for _, window := range maintenance.Windows() {
+		windowFrom, windowUntil := MaxI64(from, window.From), MinI64(until, window.Until)
+		if windowFrom >= windowUntil {
+			continue
+		}
+
+		if window.Recurrence == nil {
+			result = append(result, MaintenanceOccurrence{
+				WindowID: window.ID,
+				Metric:   window.Metric,
+				From:     window.From,
+				Until:    window.Until,
+			})
+			continue
+		}
+
+		for _, occurrence := range window.Recurrence.Occurrences(windowFrom, windowUntil) {
+			if occurrence[0] < window.From {
+				continue
+			}
+			result = append(result, MaintenanceOccurrence{
+				WindowID:  window.ID,
+				Metric:    window.Metric,
+				From:      occurrence[0],
+				Until:     MinI64(occurrence[1], window.Until),
+				Recurrent: true,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].From < result[j].From
+	})
+	return result
+}
+
 // ScheduledEscalationEvent represent escalated notification event
 type ScheduledEscalationEvent struct {
 	Escalation   EscalationData    `json:"escalation"`
@@ -714,13 +1220,142 @@ type GlobalSettings struct {
 type DutyItem struct {
 	Login   string
 	DutyEnd *time.Time `json:"duty_end"`
+
+	// Contacts are the values of the contacts of the person on duty by the contact type, see OnCallParticipant
+	Contacts map[string]string `json:"contacts,omitempty"`
 }
 
 type DutyData struct {
 	Duty      []DutyItem
+	Backup    []DutyItem `json:",omitempty"` // those who are notified if the main ones don't acknowledge
 	Timestamp time.Time
 }
 
+// InventoryGroupKind is the kind of group of hosts known to CMDB
+type InventoryGroupKind string
+
+const (
+	InventoryGroupRack      InventoryGroupKind = "rack"
+	InventoryGroupRackGroup InventoryGroupKind = "rack_group"
+)
+
+// InventoryHost represents a host (server, switch, etc.) known to CMDB
+type InventoryHost struct {
+	Name         string                        `json:"name" yaml:"name"`
+	PreviousName string                        `json:"previous_name,omitempty" yaml:"previous_name"`
+	Containers   []string                      `json:"containers,omitempty" yaml:"containers"`
+	Inactive     bool                          `json:"inactive" yaml:"inactive"`
+	Groups       map[InventoryGroupKind]string `json:"groups,omitempty" yaml:"groups"` // e.g. rack -> its name
+	Meta         map[string]string             `json:"meta,omitempty" yaml:"meta"`
+}
+
+// Role defines what the user is allowed to do, each role includes the previous ones
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // read-only access
+	RoleEditor Role = "editor" // triggers, maintenance and silent patterns can be changed
+	RoleAdmin  Role = "admin"  // everything can be changed
+)
+
+var roleLevels = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// IsValid can tell whether or not the role is known
+func (role Role) IsValid() bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// Includes can tell whether or not the role grants everything the other one does
+func (role Role) Includes(other Role) bool {
+	return roleLevels[role] >= roleLevels[other] && roleLevels[role] > 0
+}
+
+// Team is a group of users which owns the triggers: either explicitly (see Trigger.Team) or by tags
+type Team struct {
+	ID      string          `json:"id" yaml:"id"`
+	Name    string          `json:"name" yaml:"name"`
+	Tags    []string        `json:"tags" yaml:"tags"`       // triggers with any of the tags are owned by the team
+	Members map[string]Role `json:"members" yaml:"members"` // login -> role within the team
+}
+
+// Validate checks that the team can be saved
+func (team *Team) Validate() error {
+	if team.ID == "" {
+		return fmt.Errorf("team ID is required")
+	}
+	for login, role := range team.Members {
+		if !role.IsValid() {
+			return fmt.Errorf("unknown role '%s' of %s", role, login)
+		}
+	}
+	return nil
+}
+
+// IsOwnerOf can tell whether or not the team owns the trigger
+func (team *Team) IsOwnerOf(trigger *Trigger) bool {
+	if trigger.Team != "" {
+		return trigger.Team == team.ID
+	}
+	for _, tag := range team.Tags {
+		for _, triggerTag := range trigger.Tags {
+			if tag == triggerTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// APITokenScope limits what can be done with the API token
+type APITokenScope string
+
+const (
+	APITokenScopeRead          APITokenScope = "read"           // any GET request
+	APITokenScopeWriteTriggers APITokenScope = "write-triggers" // changes of the triggers, their maintenance and templates
+	APITokenScopeWriteSilences APITokenScope = "write-silences" // changes of the silent patterns
+)
+
+// IsValid can tell whether or not the scope is known
+func (scope APITokenScope) IsValid() bool {
+	switch scope {
+	case APITokenScopeRead, APITokenScopeWriteTriggers, APITokenScopeWriteSilences:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIToken lets machine clients call api on behalf of the user or service, only hash of the token is stored
+type APIToken struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Login     string          `json:"login"`
+	Hash      string          `json:"hash"`
+	Scopes    []APITokenScope `json:"scopes"`
+	CreatedAt int64           `json:"created_at"`
+	ExpiresAt int64           `json:"expires_at"` // 0 means that the token never expires
+}
+
+// HasScope can tell whether or not the token has the given scope
+func (token *APIToken) HasScope(scope APITokenScope) bool {
+	for _, tokenScope := range token.Scopes {
+		if tokenScope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired can tell whether or not the token is expired at the given moment
+func (token *APIToken) IsExpired(now int64) bool {
+	return token.ExpiresAt != 0 && token.ExpiresAt <= now
+}
+
 type RateLimit struct {
 	AcceptRate float64
 	ThreadsQty int
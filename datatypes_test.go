@@ -180,6 +180,12 @@ func TestEventsData_GetSubjectState(t *testing.T) {
 		So(states[0].String(), ShouldResemble, "TriggerId: , Metric: \nValue: 0, OldValue: 0\nState: OK, OldState: \nMessage: \nTimestamp: 0")
 		So(states[1].String(), ShouldResemble, "TriggerId: , Metric: \nValue: 1, OldValue: 0\nState: ERROR, OldState: \nMessage: mes1\nTimestamp: 0")
 	})
+	Convey("FLAPPING is more critical than WARN but less critical than ERROR", t, func() {
+		So(NotificationEvents{{State: OK}, {State: FLAPPING}}.GetSubjectState(), ShouldEqual, FLAPPING)
+		So(NotificationEvents{{State: FLAPPING}, {State: WARN}}.GetSubjectState(), ShouldEqual, FLAPPING)
+		So(NotificationEvents{{State: ERROR}, {State: FLAPPING}}.GetSubjectState(), ShouldEqual, ERROR)
+		So(NotificationEvents{{State: FLAPPING}, {State: NODATA}}.GetSubjectState(), ShouldEqual, NODATA)
+	})
 }
 
 func TestTriggerData_GetTags(t *testing.T) {
@@ -288,6 +294,70 @@ func TestTrigger_IsSimple(t *testing.T) {
 	})
 }
 
+func TestNoDataPolicy_Validate(t *testing.T) {
+	Convey("Valid policies", t, func() {
+		policies := []NoDataPolicy{
+			{},
+			{Action: NoDataActionAlert},
+			{Action: NoDataActionOK, RemoveAfterMinutes: 60},
+			{Action: NoDataActionKeep},
+			{Action: NoDataActionRemove},
+		}
+		for _, policy := range policies {
+			So(policy.Validate(), ShouldBeNil)
+		}
+	})
+
+	Convey("Invalid policies", t, func() {
+		policies := []NoDataPolicy{
+			{Action: "DEL"},
+			{Action: NoDataActionKeep, RemoveAfterMinutes: -1},
+		}
+		for _, policy := range policies {
+			So(policy.Validate(), ShouldNotBeNil)
+		}
+	})
+}
+
+func TestSlackDashboard_Update(t *testing.T) {
+	Convey("Removed metric is deleted from the dashboard", t, func() {
+		dashboard := SlackDashboard{"first": false, "second": false}
+		removed := NotificationEvent{Metric: "first", State: OK, MetricRemoved: true}
+		hasChanged, changes := dashboard.Update(NotificationEvents{removed}, "trigger")
+		So(hasChanged, ShouldBeTrue)
+		So(changes, ShouldResemble, NotificationEvents{removed})
+		So(dashboard, ShouldResemble, SlackDashboard{"second": false})
+	})
+
+	Convey("Removal of the metric which was OK isn't a change to tell about", t, func() {
+		dashboard := SlackDashboard{"first": true, "second": false}
+		removed := NotificationEvent{Metric: "first", State: OK, OldState: OK, MetricRemoved: true, DashboardOnly: true}
+		hasChanged, changes := dashboard.Update(NotificationEvents{removed}, "trigger")
+		So(hasChanged, ShouldBeTrue)
+		So(changes, ShouldBeEmpty)
+		So(dashboard, ShouldResemble, SlackDashboard{"second": false})
+	})
+
+	Convey("New dashboard doesn't show the metric which only updates the dashboards", t, func() {
+		dashboard := MakeDashboardFromEvents(NotificationEvents{
+			{Metric: "first", State: OK, OldState: OK, MetricRemoved: true, DashboardOnly: true},
+			{Metric: "second", State: ERROR},
+		})
+		So(dashboard, ShouldResemble, SlackDashboard{"second": false})
+	})
+}
+
+func TestQuorumSettings_IsReached(t *testing.T) {
+	Convey("Count or percent", t, func() {
+		quorum := QuorumSettings{Count: 3, Percent: 20}
+		So(quorum.IsReached(0, 0), ShouldBeFalse)
+		So(quorum.IsReached(1, 300), ShouldBeFalse)
+		So(quorum.IsReached(3, 300), ShouldBeTrue)
+		So(quorum.IsReached(2, 10), ShouldBeTrue)
+		So(quorum.IsReached(1, 10), ShouldBeFalse)
+	})
+}
+
 func TestCheckData_GetEventTimestamp(t *testing.T) {
 	Convey("Get event timestamp", t, func() {
 		checkData := CheckData{Timestamp: 800, EventTimestamp: 0}
@@ -0,0 +1,241 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/fasthash/fnv1a"
+
+	"go.avito.ru/DO/moira"
+)
+
+// patternIndex is the immutable version of the patterns, every change of the patterns makes the new version
+// so that the matchers never see a tree which is being changed
+type patternIndex struct {
+	tree        *patternNode
+	tagPatterns []*tagPattern
+}
+
+// patternNode contains pattern node
+type patternNode struct {
+	Children   []*patternNode
+	Part       string
+	Hash       uint64
+	Prefix     string
+	InnerParts []string
+
+	// Terminal is true if some pattern ends at this node
+	Terminal bool
+}
+
+// tagPattern is seriesByTag pattern, the tagged series are matched against it instead of the pattern tree
+type tagPattern struct {
+	Pattern string
+	Query   *moira.TagQuery
+}
+
+// newPatternIndex builds the index from scratch, the nodes are changed in place
+func newPatternIndex(logger moira.Logger, patterns []string) *patternIndex {
+	index := &patternIndex{
+		tree:        &patternNode{},
+		tagPatterns: make([]*tagPattern, 0),
+	}
+	for _, pattern := range patterns {
+		if moira.IsTagQuery(pattern) {
+			if added := newTagPattern(logger, pattern); added != nil {
+				index.tagPatterns = append(index.tagPatterns, added)
+			}
+			continue
+		}
+		if parts, ok := splitPattern(pattern); ok {
+			index.tree = index.tree.withPattern(parts, true)
+		}
+	}
+	return index
+}
+
+// withPattern returns the index with the pattern added, the index itself is not changed
+func (index *patternIndex) withPattern(logger moira.Logger, pattern string) *patternIndex {
+	if moira.IsTagQuery(pattern) {
+		for _, existing := range index.tagPatterns {
+			if existing.Pattern == pattern {
+				return index
+			}
+		}
+		added := newTagPattern(logger, pattern)
+		if added == nil {
+			return index
+		}
+		tagPatterns := make([]*tagPattern, len(index.tagPatterns), len(index.tagPatterns)+1)
+		copy(tagPatterns, index.tagPatterns)
+		return &patternIndex{tree: index.tree, tagPatterns: append(tagPatterns, added)}
+	}
+
+	parts, ok := splitPattern(pattern)
+	if !ok || index.tree.hasPattern(parts) {
+		return index
+	}
+	return &patternIndex{tree: index.tree.withPattern(parts, false), tagPatterns: index.tagPatterns}
+}
+
+// withoutPattern returns the index with the pattern removed, the index itself is not changed
+func (index *patternIndex) withoutPattern(pattern string) *patternIndex {
+	if moira.IsTagQuery(pattern) {
+		tagPatterns := make([]*tagPattern, 0, len(index.tagPatterns))
+		for _, existing := range index.tagPatterns {
+			if existing.Pattern != pattern {
+				tagPatterns = append(tagPatterns, existing)
+			}
+		}
+		if len(tagPatterns) == len(index.tagPatterns) {
+			return index
+		}
+		return &patternIndex{tree: index.tree, tagPatterns: tagPatterns}
+	}
+
+	parts, ok := splitPattern(pattern)
+	if !ok || !index.tree.hasPattern(parts) {
+		return index
+	}
+	tree := index.tree.withoutPattern(parts)
+	if tree == nil {
+		tree = &patternNode{}
+	}
+	return &patternIndex{tree: tree, tagPatterns: index.tagPatterns}
+}
+
+// getPatterns returns all the patterns of the index
+func (index *patternIndex) getPatterns() map[string]bool {
+	patterns := make(map[string]bool)
+	index.tree.collectPatterns(patterns)
+	for _, tagPattern := range index.tagPatterns {
+		patterns[tagPattern.Pattern] = true
+	}
+	return patterns
+}
+
+func newTagPattern(logger moira.Logger, pattern string) *tagPattern {
+	query, err := moira.ParseTagQuery(pattern)
+	if err != nil {
+		logger.WarnF("invalid tag pattern %s: %v", pattern, err)
+		return nil
+	}
+	return &tagPattern{Pattern: pattern, Query: query}
+}
+
+func splitPattern(pattern string) ([]string, bool) {
+	parts := strings.Split(pattern, ".")
+	return parts, !hasEmptyParts(parts)
+}
+
+func newPatternNode(part string, parentPrefix string) *patternNode {
+	node := &patternNode{Part: part}
+
+	if parentPrefix == "" {
+		node.Prefix = part
+	} else {
+		node.Prefix = fmt.Sprintf("%s.%s", parentPrefix, part)
+	}
+
+	if part == "*" || !strings.ContainsAny(part, "{*?") {
+		node.Hash = fnv1a.HashString64(part)
+	} else if strings.Contains(part, "{") && strings.Contains(part, "}") {
+		prefix, bigSuffix := split2(part, "{")
+		inner, suffix := split2(bigSuffix, "}")
+		innerParts := strings.Split(inner, ",")
+
+		node.InnerParts = make([]string, 0, len(innerParts))
+		for _, innerPart := range innerParts {
+			node.InnerParts = append(node.InnerParts, fmt.Sprintf("%s%s%s", prefix, innerPart, suffix))
+		}
+	} else {
+		node.InnerParts = []string{part}
+	}
+	return node
+}
+
+// copy makes the shallow copy of the node, the children are shared but the list of them is not
+func (node *patternNode) copy() *patternNode {
+	result := *node
+	result.Children = make([]*patternNode, len(node.Children), len(node.Children)+1)
+	copy(result.Children, node.Children)
+	return &result
+}
+
+func (node *patternNode) findChild(part string) int {
+	for i, child := range node.Children {
+		if child.Part == part {
+			return i
+		}
+	}
+	return -1
+}
+
+func (node *patternNode) hasPattern(parts []string) bool {
+	for _, part := range parts {
+		i := node.findChild(part)
+		if i < 0 {
+			return false
+		}
+		node = node.Children[i]
+	}
+	return node.Terminal
+}
+
+// withPattern adds the pattern given by its parts, the nodes on the path are copied unless inPlace is set
+func (node *patternNode) withPattern(parts []string, inPlace bool) *patternNode {
+	if !inPlace {
+		node = node.copy()
+	}
+	if len(parts) == 0 {
+		node.Terminal = true
+		return node
+	}
+
+	i := node.findChild(parts[0])
+	if i < 0 {
+		// the new node isn't seen by anybody yet
+		node.Children = append(node.Children, newPatternNode(parts[0], node.Prefix).withPattern(parts[1:], true))
+		return node
+	}
+	node.Children[i] = node.Children[i].withPattern(parts[1:], inPlace)
+	return node
+}
+
+// withoutPattern removes the pattern given by its parts copying the nodes on the path,
+// nil is returned if the node isn't needed anymore
+func (node *patternNode) withoutPattern(parts []string) *patternNode {
+	if len(parts) == 0 {
+		if len(node.Children) == 0 {
+			return nil
+		}
+		node = node.copy()
+		node.Terminal = false
+		return node
+	}
+
+	i := node.findChild(parts[0])
+	if i < 0 {
+		return node
+	}
+	child := node.Children[i].withoutPattern(parts[1:])
+	node = node.copy()
+	if child == nil {
+		node.Children = append(node.Children[:i], node.Children[i+1:]...)
+		if len(node.Children) == 0 && !node.Terminal {
+			return nil
+		}
+	} else {
+		node.Children[i] = child
+	}
+	return node
+}
+
+func (node *patternNode) collectPatterns(patterns map[string]bool) {
+	if node.Terminal {
+		patterns[node.Prefix] = true
+	}
+	for _, child := range node.Children {
+		child.collectPatterns(patterns)
+	}
+}
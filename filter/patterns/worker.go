@@ -16,35 +16,62 @@ type RefreshPatternWorker struct {
 	logger         moira.Logger
 	metrics        *metrics.FilterMetrics
 	patternStorage *filter.PatternStorage
-	tomb           tomb.Tomb
+	// reconcileInterval is how often the tree is compared with the patterns list in case some changes were missed
+	reconcileInterval time.Duration
+	tomb              tomb.Tomb
 }
 
 // NewRefreshPatternWorker creates new RefreshPatternWorker
-func NewRefreshPatternWorker(database moira.Database, logger moira.Logger, patternStorage *filter.PatternStorage) *RefreshPatternWorker {
+func NewRefreshPatternWorker(
+	database moira.Database,
+	logger moira.Logger,
+	patternStorage *filter.PatternStorage,
+	reconcileInterval time.Duration,
+) *RefreshPatternWorker {
+	if reconcileInterval <= 0 {
+		reconcileInterval = time.Minute
+	}
 	return &RefreshPatternWorker{
-		database:       database,
-		metrics:        metrics.NewFilterMetrics(),
-		logger:         logger,
-		patternStorage: patternStorage,
+		database:          database,
+		metrics:           metrics.NewFilterMetrics(),
+		logger:            logger,
+		patternStorage:    patternStorage,
+		reconcileInterval: reconcileInterval,
 	}
 }
 
-// Start process to refresh pattern tree every second
+// Start process to apply the changes of the patterns to the pattern tree as they are published
+// and to reconcile the tree with the patterns list periodically
 func (worker *RefreshPatternWorker) Start() error {
-	err := worker.patternStorage.RefreshTree()
+	// the subscription goes first so that no change is lost between the refresh and the subscription
+	changes, err := worker.database.SubscribePatternChanges(&worker.tomb)
+	if err != nil {
+		worker.logger.ErrorF("pattern changes subscription failed: %s", err.Error())
+		return err
+	}
+
+	err = worker.patternStorage.RefreshTree()
 	if err != nil {
 		worker.logger.ErrorF("pattern refresh failed: %s", err.Error())
+		worker.tomb.Kill(nil)
 		return err
 	}
 
 	worker.tomb.Go(func() error {
-		checkTicker := time.NewTicker(time.Second)
+		reconcileTicker := time.NewTicker(worker.reconcileInterval)
+		defer reconcileTicker.Stop()
 		for {
 			select {
 			case <-worker.tomb.Dying():
 				worker.logger.Info("Moira Filter Pattern Updater stopped")
 				return nil
-			case <-checkTicker.C:
+			case change, ok := <-changes:
+				if !ok {
+					worker.logger.Info("Moira Filter Pattern Updater stopped")
+					return nil
+				}
+				worker.patternStorage.ApplyPatternChange(change)
+			case <-reconcileTicker.C:
 				timer := time.Now()
 				err := worker.patternStorage.RefreshTree()
 				if err != nil {
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -36,7 +37,11 @@ type PatternStorage struct {
 
 	heartbeat   chan bool
 	matcherPool sync.Pool
-	PatternTree *patternNode
+
+	// index holds *patternIndex, it is replaced as a whole on every change so that matching needs no locks
+	index atomic.Value
+	// indexLock serializes the changes of the index
+	indexLock sync.Mutex
 }
 
 // matcherBuffer is operative buffer for PatternStorage.matchPattern method
@@ -46,15 +51,6 @@ type matcherBuffer struct {
 	next []*patternNode // next is tree's nodes (horizontal) level which is supposed to be processed after current
 }
 
-// patternNode contains pattern node
-type patternNode struct {
-	Children   []*patternNode
-	Part       string
-	Hash       uint64
-	Prefix     string
-	InnerParts []string
-}
-
 // NewPatternStorage creates new PatternStorage struct
 func NewPatternStorage(
 	database moira.Database,
@@ -67,6 +63,7 @@ func NewPatternStorage(
 		metrics:   metrics,
 		heartbeat: make(chan bool, heartBeatCap),
 	}
+	storage.index.Store(&patternIndex{tree: &patternNode{}})
 	storage.matcherPool = sync.Pool{
 		New: func() interface{} {
 			return &matcherBuffer{
@@ -96,10 +93,28 @@ func (storage *PatternStorage) ProcessIncomingMetric(line []byte) *moira.Matched
 		storage.logger.InfoF("cannot parse input: %v", err)
 		return nil
 	}
+	var series *moira.TaggedSeries
+	if moira.IsTaggedSeries(metric) {
+		if series, err = moira.ParseTaggedSeries(metric); err != nil {
+			storage.logger.InfoF("cannot parse input: %v", err)
+			return nil
+		}
+		metric = series.Path()
+	}
 	storage.metrics.ValidMetricsReceived.Increment()
 
 	matchingStart := monotime.Now()
-	matched := storage.matchPattern(metric)
+	index := storage.getIndex()
+	var matched []string
+	if series != nil {
+		matched = index.matchTagPatterns(series, []string{})
+	} else {
+		matched = storage.matchPattern(index, metric)
+		if len(index.tagPatterns) > 0 {
+			// plain metrics are selected by seriesByTag('name=...') too
+			matched = index.matchTagPatterns(&moira.TaggedSeries{Name: metric}, matched)
+		}
+	}
 	matchingDuration := monotime.Since(matchingStart)
 
 	storage.metrics.MatchingTimer.Timing(int64(matchingDuration))
@@ -122,19 +137,73 @@ func (storage *PatternStorage) ProcessIncomingMetric(line []byte) *moira.Matched
 	return nil
 }
 
-// RefreshTree builds pattern tree from redis data
+// GetPatternTree returns the current pattern tree, it must not be changed
+func (storage *PatternStorage) GetPatternTree() *patternNode {
+	return storage.getIndex().tree
+}
+
+// ApplyPatternChange adds the pattern to or removes it from the tree
+func (storage *PatternStorage) ApplyPatternChange(change *moira.PatternChange) {
+	storage.indexLock.Lock()
+	defer storage.indexLock.Unlock()
+
+	index := storage.getIndex()
+	if change.Removed {
+		storage.index.Store(index.withoutPattern(change.Pattern))
+	} else {
+		storage.index.Store(index.withPattern(storage.logger, change.Pattern))
+	}
+}
+
+// RefreshTree reconciles pattern tree with redis data: the changes which were missed are applied.
+// The tree is built from scratch if it is empty
 func (storage *PatternStorage) RefreshTree() error {
+	// the patterns are read under the lock: the changes are published along with the patterns,
+	// so the ones applied before can't be newer than the read patterns and be undone
+	storage.indexLock.Lock()
+	defer storage.indexLock.Unlock()
+
 	patterns, err := storage.database.GetPatterns()
 	if err != nil {
 		return err
 	}
 
-	return storage.buildTree(patterns)
+	index := storage.getIndex()
+	existing := index.getPatterns()
+	if len(existing) == 0 {
+		storage.index.Store(newPatternIndex(storage.logger, patterns))
+		return nil
+	}
+
+	added, removed := 0, 0
+	for _, pattern := range patterns {
+		if existing[pattern] {
+			delete(existing, pattern)
+			continue
+		}
+		if changed := index.withPattern(storage.logger, pattern); changed != index {
+			index = changed
+			added++
+		}
+	}
+	for pattern := range existing {
+		index = index.withoutPattern(pattern)
+		removed++
+	}
+	if added > 0 || removed > 0 {
+		storage.logger.WarnF("Pattern tree is out of sync: %d patterns are added, %d patterns are removed", added, removed)
+	}
+	storage.index.Store(index)
+	return nil
+}
+
+func (storage *PatternStorage) getIndex() *patternIndex {
+	return storage.index.Load().(*patternIndex)
 }
 
 // matchPattern returns array of matched patterns
-func (storage *PatternStorage) matchPattern(metric string) []string {
-	buff := storage.matchedBufferAcquire()
+func (storage *PatternStorage) matchPattern(patterns *patternIndex, metric string) []string {
+	buff := storage.matchedBufferAcquire(patterns.tree)
 	defer storage.matchedBufferRelease(buff)
 
 	index := 0
@@ -159,7 +228,7 @@ func (storage *PatternStorage) matchPattern(metric string) []string {
 
 	matched := make([]string, 0, len(buff.curr))
 	for _, node := range buff.curr {
-		if len(node.Children) == 0 {
+		if node.Terminal {
 			matched = append(matched, node.Prefix)
 		}
 	}
@@ -167,64 +236,20 @@ func (storage *PatternStorage) matchPattern(metric string) []string {
 	return matched
 }
 
-func (storage *PatternStorage) buildTree(patterns []string) error {
-	newTree := &patternNode{}
-
-	for _, pattern := range patterns {
-		currentNode := newTree
-		parts := strings.Split(pattern, ".")
-		if hasEmptyParts(parts) {
-			continue
-		}
-
-		for _, part := range parts {
-			found := false
-			for _, child := range currentNode.Children {
-				if part == child.Part {
-					currentNode = child
-					found = true
-					break
-				}
-			}
-
-			if !found {
-				newNode := &patternNode{Part: part}
-
-				if currentNode.Prefix == "" {
-					newNode.Prefix = part
-				} else {
-					newNode.Prefix = fmt.Sprintf("%s.%s", currentNode.Prefix, part)
-				}
-
-				if part == "*" || !strings.ContainsAny(part, "{*?") {
-					newNode.Hash = fnv1a.HashString64(part)
-				} else if strings.Contains(part, "{") && strings.Contains(part, "}") {
-					prefix, bigSuffix := split2(part, "{")
-					inner, suffix := split2(bigSuffix, "}")
-					innerParts := strings.Split(inner, ",")
-
-					newNode.InnerParts = make([]string, 0, len(innerParts))
-					for _, innerPart := range innerParts {
-						newNode.InnerParts = append(newNode.InnerParts, fmt.Sprintf("%s%s%s", prefix, innerPart, suffix))
-					}
-				} else {
-					newNode.InnerParts = []string{part}
-				}
-
-				currentNode.Children = append(currentNode.Children, newNode)
-				currentNode = newNode
-			}
+// matchTagPatterns appends seriesByTag patterns which select the series to matched
+func (index *patternIndex) matchTagPatterns(series *moira.TaggedSeries, matched []string) []string {
+	for _, tagPattern := range index.tagPatterns {
+		if tagPattern.Query.IsMatched(series) {
+			matched = append(matched, tagPattern.Pattern)
 		}
 	}
-
-	storage.PatternTree = newTree
-	return nil
+	return matched
 }
 
 // matchedBufferAcquire acquires buffer from pool and initializes it with tree's root
-func (storage *PatternStorage) matchedBufferAcquire() *matcherBuffer {
+func (storage *PatternStorage) matchedBufferAcquire(tree *patternNode) *matcherBuffer {
 	buff := storage.matcherPool.Get().(*matcherBuffer)
-	buff.curr = append(buff.curr, storage.PatternTree)
+	buff.curr = append(buff.curr, tree)
 	return buff
 }
 
@@ -282,7 +307,8 @@ func hasEmptyParts(parts []string) bool {
 }
 
 // parseMetricFromString parses metric from string
-// supported format: "<metricString> <valueFloat64> <timestampInt64>"
+// supported format: "<metricString> <valueFloat64> <timestampInt64>",
+// metricString may be Graphite tagged series: "<name>;<tag>=<value>;..."
 func parseMetricFromString(line []byte) (string, float64, int64, error) {
 	var parts [3][]byte
 	partIndex := 0
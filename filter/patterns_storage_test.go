@@ -5,10 +5,12 @@ import (
 	"math/rand"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/metrics"
 	"go.avito.ru/DO/moira/mock/moira-alert"
 	"go.avito.ru/DO/moira/test-helpers"
@@ -234,5 +236,136 @@ func TestProcessIncomingMetric(t *testing.T) {
 		})
 	})
 
+	Convey("When tagged series arrives", t, func() {
+		database.EXPECT().GetPatterns().Return([]string{
+			"Simple.matching.pattern",
+			"seriesByTag('name=cpu.load', 'env=prod')",
+			"seriesByTag('name=~cpu.*', 'host!=db')",
+			"seriesByTag('name=Simple.matching.pattern')",
+			"seriesByTag('env=')",
+		}, nil)
+		So(patternsStorage.RefreshTree(), ShouldBeNil)
+		So(patternsStorage.getIndex().tagPatterns, ShouldHaveLength, 3)
+
+		matched := patternsStorage.ProcessIncomingMetric([]byte("cpu.load;host=web;env=prod 12 1234567890"))
+		So(matched, ShouldNotBeNil)
+		So(matched.Metric, ShouldEqual, "cpu.load;env=prod;host=web")
+		So(matched.Patterns, ShouldResemble, []string{
+			"seriesByTag('name=cpu.load', 'env=prod')",
+			"seriesByTag('name=~cpu.*', 'host!=db')",
+		})
+
+		So(patternsStorage.ProcessIncomingMetric([]byte("cpu.load;host=db 12 1234567890")), ShouldBeNil)
+		So(patternsStorage.ProcessIncomingMetric([]byte("cpu.load;host= 12 1234567890")), ShouldBeNil)
+
+		matched = patternsStorage.ProcessIncomingMetric([]byte("Simple.matching.pattern 12 1234567890"))
+		So(matched, ShouldNotBeNil)
+		So(matched.Patterns, ShouldResemble, []string{
+			"Simple.matching.pattern",
+			"seriesByTag('name=Simple.matching.pattern')",
+		})
+	})
+
 	mockCtrl.Finish()
 }
+
+func TestPatternChanges(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	test_helpers.InitTestLogging()
+	logger := test_helpers.GetTestLogger()
+
+	database.EXPECT().GetPatterns().Return([]string{"Simple.pattern", "Star.*.pattern"}, nil)
+	patternsStorage, err := NewPatternStorage(database, metrics.NewFilterMetrics(), logger)
+
+	getMatched := func(metric string) []string {
+		matched := patternsStorage.ProcessIncomingMetric([]byte(metric + " 12 1234567890"))
+		if matched == nil {
+			return nil
+		}
+		return matched.Patterns
+	}
+
+	Convey("Pattern is added", t, func() {
+		So(err, ShouldBeNil)
+		tree := patternsStorage.GetPatternTree()
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Star.one.pattern"})
+
+		So(getMatched("Star.one.pattern"), ShouldResemble, []string{"Star.*.pattern", "Star.one.pattern"})
+		So(getMatched("Star.two.pattern"), ShouldResemble, []string{"Star.*.pattern"})
+		So(tree.Children[1].Children, ShouldHaveLength, 1)
+	})
+
+	Convey("Pattern is the prefix of another pattern", t, func() {
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Simple.pattern.long"})
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Simple"})
+
+		So(getMatched("Simple"), ShouldResemble, []string{"Simple"})
+		So(getMatched("Simple.pattern"), ShouldResemble, []string{"Simple.pattern"})
+		So(getMatched("Simple.pattern.long"), ShouldResemble, []string{"Simple.pattern.long"})
+	})
+
+	Convey("Pattern is removed", t, func() {
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Simple.pattern", Removed: true})
+		So(getMatched("Simple.pattern"), ShouldBeNil)
+		So(getMatched("Simple.pattern.long"), ShouldResemble, []string{"Simple.pattern.long"})
+
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Simple.pattern.long", Removed: true})
+		So(getMatched("Simple.pattern.long"), ShouldBeNil)
+		So(getMatched("Simple"), ShouldResemble, []string{"Simple"})
+		So(patternsStorage.GetPatternTree().Children[0].Children, ShouldBeEmpty)
+
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Unknown.pattern", Removed: true})
+		So(patternsStorage.getIndex().getPatterns(), ShouldResemble, map[string]bool{
+			"Simple":           true,
+			"Star.*.pattern":   true,
+			"Star.one.pattern": true,
+		})
+	})
+
+	Convey("Tag pattern is added and removed", t, func() {
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "seriesByTag('name=cpu')"})
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "seriesByTag('name=cpu')"})
+		So(getMatched("cpu;host=web"), ShouldResemble, []string{"seriesByTag('name=cpu')"})
+
+		patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "seriesByTag('name=cpu')", Removed: true})
+		So(getMatched("cpu;host=web"), ShouldBeNil)
+	})
+
+	Convey("Missed changes are reconciled", t, func() {
+		database.EXPECT().GetPatterns().Return([]string{"Simple", "Star.two.pattern", "seriesByTag('name=cpu')"}, nil)
+		So(patternsStorage.RefreshTree(), ShouldBeNil)
+
+		So(patternsStorage.getIndex().getPatterns(), ShouldResemble, map[string]bool{
+			"Simple":                  true,
+			"Star.two.pattern":        true,
+			"seriesByTag('name=cpu')": true,
+		})
+		So(getMatched("Star.one.pattern"), ShouldBeNil)
+		So(getMatched("Star.two.pattern"), ShouldResemble, []string{"Star.two.pattern"})
+		So(getMatched("cpu;host=web"), ShouldResemble, []string{"seriesByTag('name=cpu')"})
+	})
+
+	Convey("Change applied while the patterns are read isn't undone", t, func() {
+		applied := make(chan bool)
+		database.EXPECT().GetPatterns().DoAndReturn(func() ([]string, error) {
+			// the pattern is added right after it has been read
+			go func() {
+				patternsStorage.ApplyPatternChange(&moira.PatternChange{Pattern: "Star.three.pattern"})
+				close(applied)
+			}()
+			select {
+			case <-applied:
+			case <-time.After(100 * time.Millisecond):
+			}
+			return []string{"Simple", "Star.two.pattern", "seriesByTag('name=cpu')"}, nil
+		})
+		So(patternsStorage.RefreshTree(), ShouldBeNil)
+		<-applied
+
+		So(getMatched("Star.three.pattern"), ShouldResemble, []string{"Star.three.pattern"})
+		So(getMatched("Star.two.pattern"), ShouldResemble, []string{"Star.two.pattern"})
+	})
+}
@@ -84,7 +84,7 @@ func TestNotifier(t *testing.T) {
 	mockCtrl = gomock.NewController(t)
 	defer mockCtrl.Finish()
 
-	database := redis.NewDatabase(logger, redis.Config{Port: "6379", Host: "localhost"})
+	database, _ := redis.NewDatabase(logger, redis.Config{Port: "6379", Host: "localhost"})
 	database.SaveContact(&contact)
 	database.SaveSubscription(&subscription)
 	database.SaveTrigger(trigger.ID, &trigger)
@@ -63,6 +63,7 @@ type Database interface {
 	FetchNotificationEvent(withSaturations bool) (NotificationEvent, error)
 	FetchDelayedNotificationEvents(to int64, withSaturations bool) ([]NotificationEvent, error)
 	AddDelayedNotificationEvent(event NotificationEvent, timestamp int64) error
+	SubscribeNotificationEvents(tomb *tomb.Tomb) (<-chan *NotificationEvent, error)
 
 	// Event inheritance
 	AddChildEvents(parentTriggerID string, parentMetric string, childTriggerID string, childMetrics []string) error
@@ -111,10 +112,19 @@ type Database interface {
 	RemovePattern(pattern string) error
 	RemovePatternsMetrics(pattern []string) error
 	RemovePatternWithMetrics(pattern string) error
+	SubscribePatternChanges(tomb *tomb.Tomb) (<-chan *PatternChange, error)
 
-	SubscribeMetricEvents(tomb *tomb.Tomb) (<-chan *MetricEvent, error)
+	// Tagged series index
+	GetSeriesTagValues(tag string) ([]string, error)
+	GetSeriesByTagValues(tag string, values []string) ([]string, error)
+
+	SubscribeMetricEvents(tomb *tomb.Tomb) (<-chan []*MetricEvent, error)
+	AckMetricEvents(events []*MetricEvent) error
+	GetMetricEventsLag() (*MetricEventsLag, error)
 	SaveMetrics(buffer map[string]*MatchedMetric) error
 	GetMetricRetention(metric string) (int64, error)
+	GetMetricsTTL() (int64, error)
+	SetMetricsTTL(ttl int64) error
 	GetMetricsValues(metrics []string, from int64, until int64) (map[string][]*MetricValue, error)
 	RemoveMetricValues(metric string, toTime int64) error
 	RemoveMetricsValues(metrics []string, toTime int64) error
@@ -155,6 +165,25 @@ type Database interface {
 	GetGlobalSettings() (GlobalSettings, error)
 	SetGlobalSettings(GlobalSettings) error
 
+	// Teams
+	GetTeams() ([]*Team, error)
+	SaveTeam(team *Team) error
+	RemoveTeam(teamID string) error
+
+	// API tokens
+	GetAPITokens() ([]*APIToken, error)
+	GetAPIToken(tokenID string) (*APIToken, error)
+	GetAPITokenByHash(hash string) (*APIToken, error)
+	SaveAPIToken(token *APIToken) error
+	RemoveAPIToken(tokenID string) error
+
+	// Trigger templates
+	GetTriggerTemplates() ([]*TriggerTemplate, error)
+	GetTriggerTemplate(templateID string) (*TriggerTemplate, error)
+	SaveTriggerTemplate(template *TriggerTemplate) error
+	RemoveTriggerTemplate(templateID string) error
+	GetTemplateTriggerIDs(templateID string) ([]string, error)
+
 	// Slack-specific
 	GetSlackThreadLinks(contactID, triggerID string) (messages map[string]string, err error)
 	AddSlackThreadLinks(contactID, triggerID, threadTs, payload string, expiryTime *time.Time) error
@@ -177,6 +206,12 @@ type Database interface {
 
 	GetServiceDuty(service string) (DutyData, error)
 	UpdateServiceDuty(service string, dutyData DutyData) error
+
+	// On call rotations
+	GetOnCallRotation(rotationID string) (*OnCallRotation, error)
+	GetOnCallRotations() ([]*OnCallRotation, error)
+	SaveOnCallRotation(rotation *OnCallRotation) error
+	RemoveOnCallRotation(rotationID string) error
 }
 
 type TriggerInheritanceDatabase interface {
@@ -224,6 +259,13 @@ type SenderWithInheritance interface {
 	) error
 }
 
+// DashboardSender is a sender that keeps the dashboards of the metrics,
+// only such senders get the events which just update the dashboards
+type DashboardSender interface {
+	Sender
+	KeepsDashboards()
+}
+
 // MessageLink is a link to a message sent in a Sender.
 type MessageLink interface {
 	// StorageKey is used to serialize a Link to store it in a Database
@@ -231,3 +273,14 @@ type MessageLink interface {
 	FromString(string) error
 	SenderName() string
 }
+
+// InventoryProvider gives access to CMDB data which is used to silence inactive hosts automatically
+// and to expand groups of hosts in silent patterns
+type InventoryProvider interface {
+	// InactiveHosts returns all hosts which are out of service now
+	InactiveHosts() ([]InventoryHost, error)
+	// GroupHosts returns names of all hosts of the given group along with names of their containers
+	GroupHosts(kind InventoryGroupKind, name string) ([]string, error)
+	// HostInfo returns metadata of the given host, nil is returned if it is unknown
+	HostInfo(name string) (*InventoryHost, error)
+}
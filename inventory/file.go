@@ -0,0 +1,119 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"go.avito.ru/DO/moira"
+)
+
+// inventoryFile is the structure of the file which is read by fileProvider, e.g.
+//
+//	hosts:
+//	  - name: avi-web01
+//	    inactive: true
+//	    containers: [avi-web01-nginx]
+//	    groups: {rack: R-101, rack_group: DC1}
+type inventoryFile struct {
+	Hosts []moira.InventoryHost `json:"hosts" yaml:"hosts"`
+}
+
+// fileProvider takes inventory data from YAML or JSON file, the file is reread once it is modified
+type fileProvider struct {
+	path string
+
+	mutex    sync.Mutex
+	modified time.Time
+	hosts    []moira.InventoryHost
+}
+
+func newFileProvider(path string) (*fileProvider, error) {
+	provider := &fileProvider{path: path}
+	if _, err := provider.getHosts(); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// InactiveHosts implements moira.InventoryProvider
+func (provider *fileProvider) InactiveHosts() ([]moira.InventoryHost, error) {
+	hosts, err := provider.getHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]moira.InventoryHost, 0)
+	for _, host := range hosts {
+		if host.Inactive {
+			result = append(result, host)
+		}
+	}
+	return result, nil
+}
+
+// GroupHosts implements moira.InventoryProvider
+func (provider *fileProvider) GroupHosts(kind moira.InventoryGroupKind, name string) ([]string, error) {
+	hosts, err := provider.getHosts()
+	if err != nil {
+		return nil, err
+	}
+	return collectGroupHosts(hosts, kind, name), nil
+}
+
+// HostInfo implements moira.InventoryProvider
+func (provider *fileProvider) HostInfo(name string) (*moira.InventoryHost, error) {
+	hosts, err := provider.getHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hosts {
+		if hosts[i].Name == name {
+			host := hosts[i]
+			return &host, nil
+		}
+	}
+	return nil, nil
+}
+
+// getHosts returns the hosts from the file, it rereads the file if it has been modified since the last read
+func (provider *fileProvider) getHosts() ([]moira.InventoryHost, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat inventory file: %v", err)
+	}
+	if provider.hosts != nil && info.ModTime().Equal(provider.modified) {
+		return provider.hosts, nil
+	}
+
+	content, err := ioutil.ReadFile(provider.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory file: %v", err)
+	}
+
+	data := inventoryFile{}
+	if filepath.Ext(provider.path) == ".json" {
+		err = json.Unmarshal(content, &data)
+	} else {
+		err = yaml.Unmarshal(content, &data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse inventory file %s: %v", provider.path, err)
+	}
+
+	if data.Hosts == nil {
+		data.Hosts = make([]moira.InventoryHost, 0)
+	}
+	provider.hosts, provider.modified = data.Hosts, info.ModTime()
+	return provider.hosts, nil
+}
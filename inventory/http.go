@@ -0,0 +1,105 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.avito.ru/DO/moira"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// errNotFound is returned by get on 404, only the lookup of a single host treats it as an unknown host
+var errNotFound = errors.New("not found")
+
+// httpProvider takes inventory data from HTTP service which implements the following JSON API:
+//
+//	GET /inactive                         -> [moira.InventoryHost]
+//	GET /groups?kind=rack&name=R-101      -> ["host or container name"]
+//	GET /hosts?name=avi-web01             -> moira.InventoryHost, 404 if the host is unknown
+type httpProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newHTTPProvider(baseURL, token string, timeout time.Duration) (*httpProvider, error) {
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid inventory url '%s': %v", baseURL, err)
+	}
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &httpProvider{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// InactiveHosts implements moira.InventoryProvider
+func (provider *httpProvider) InactiveHosts() ([]moira.InventoryHost, error) {
+	result := make([]moira.InventoryHost, 0)
+	if err := provider.get("/inactive", nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GroupHosts implements moira.InventoryProvider
+func (provider *httpProvider) GroupHosts(kind moira.InventoryGroupKind, name string) ([]string, error) {
+	result := make([]string, 0)
+	query := url.Values{"kind": {string(kind)}, "name": {name}}
+	if err := provider.get("/groups", query, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// HostInfo implements moira.InventoryProvider
+func (provider *httpProvider) HostInfo(name string) (*moira.InventoryHost, error) {
+	result := &moira.InventoryHost{}
+	err := provider.get("/hosts", url.Values{"name": {name}}, result)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// get requests the given method and decodes the response, it returns errNotFound on 404
+func (provider *httpProvider) get(method string, query url.Values, target interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, provider.url+method, nil)
+	if err != nil {
+		return err
+	}
+	request.URL.RawQuery = query.Encode()
+	if provider.token != "" {
+		request.Header.Set("Authorization", "Bearer "+provider.token)
+	}
+
+	response, err := provider.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("inventory request %s failed: %v", method, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("inventory request %s failed: %w", method, errNotFound)
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("inventory request %s failed with status %d", method, response.StatusCode)
+	}
+
+	if err = json.NewDecoder(response.Body).Decode(target); err != nil {
+		return fmt.Errorf("could not decode inventory response of %s: %v", method, err)
+	}
+	return nil
+}
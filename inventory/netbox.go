@@ -0,0 +1,145 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/netbox"
+)
+
+const netboxTimeout = 5 * time.Second
+
+// netboxProvider takes inventory data from netbox
+type netboxProvider struct {
+	client *netbox.Client
+}
+
+func newNetboxProvider(config *netbox.Config) *netboxProvider {
+	client := netbox.CreateClient(config)
+	client.SetTimeout(netboxTimeout)
+	return &netboxProvider{client: client}
+}
+
+// InactiveHosts implements moira.InventoryProvider
+func (provider *netboxProvider) InactiveHosts() (result []moira.InventoryHost, err error) {
+	defer recoverClientPanic(&err)
+
+	list := provider.client.InactiveDeviceList()
+	result = make([]moira.InventoryHost, 0, len(list.List))
+	for _, device := range list.List {
+		host := moira.InventoryHost{
+			Name:         device.Name,
+			PreviousName: device.NamePrevious,
+			Containers:   make([]string, 0, len(device.Containers)),
+			Inactive:     true,
+		}
+		for _, container := range device.Containers {
+			host.Containers = append(host.Containers, container.Name)
+		}
+		result = append(result, host)
+	}
+	return result, nil
+}
+
+// GroupHosts implements moira.InventoryProvider
+func (provider *netboxProvider) GroupHosts(kind moira.InventoryGroupKind, name string) (result []string, err error) {
+	defer recoverClientPanic(&err)
+
+	var devices netbox.DeviceList
+	switch kind {
+	case moira.InventoryGroupRack:
+		racks := provider.client.RackList(&name)
+		// there should be exactly one rack with this name
+		if len(racks.List) != 1 {
+			return nil, fmt.Errorf("Could not find rack with name \"%s\" (%d found)", name, len(racks.List))
+		}
+		devices = provider.client.DeviceList(nil, &racks.List[0].Id)
+	case moira.InventoryGroupRackGroup:
+		rackGroups := provider.client.RackGroupList(&name)
+		// there should be exactly one rack group with this name
+		if len(rackGroups.List) != 1 {
+			return nil, fmt.Errorf("Could not find rack group with name \"%s\" (%d found)", name, len(rackGroups.List))
+		}
+		devices = provider.client.DeviceList(&rackGroups.List[0].Id, nil)
+	default:
+		return nil, fmt.Errorf("unknown group kind '%s'", kind)
+	}
+
+	return provider.saturateDeviceList(devices), nil
+}
+
+// HostInfo implements moira.InventoryProvider
+func (provider *netboxProvider) HostInfo(name string) (result *moira.InventoryHost, err error) {
+	defer recoverClientPanic(&err)
+
+	devices := provider.client.DeviceListByName(name)
+	if len(devices.List) == 0 {
+		return nil, nil
+	}
+
+	device := devices.List[0]
+	result = &moira.InventoryHost{
+		Name:   device.Name,
+		Groups: make(map[moira.InventoryGroupKind]string),
+		Meta:   make(map[string]string),
+	}
+	if device.Role != nil {
+		result.Meta["role"] = device.Role.Slug
+	}
+	if rackName := device.Rack.GetString("name"); rackName != "" {
+		result.Groups[moira.InventoryGroupRack] = rackName
+	}
+	if siteName := device.Site.GetString("name"); siteName != "" {
+		result.Meta["site"] = siteName
+	}
+	if status := device.Status.GetString("label"); status != "" {
+		result.Meta["status"] = status
+	}
+
+	containers := provider.client.ContainerList([]int{device.Id})
+	for _, container := range containers.List {
+		result.Containers = append(result.Containers, container.Name)
+	}
+	return result, nil
+}
+
+// saturateDeviceList requests nested containers of considerable devices and
+// returns flattened list of all names (both containers' and devices' names)
+func (provider *netboxProvider) saturateDeviceList(devices netbox.DeviceList) []string {
+	// will process only devices with this roles
+	considerableRoles := map[string]bool{
+		"server": true,
+		"switch": true,
+	}
+
+	ids := make([]int, 0, len(devices.List))
+	names := newNameList(2 * len(devices.List))
+	for _, device := range devices.List {
+		if device.Role == nil || !considerableRoles[device.Role.Slug] {
+			continue
+		}
+		ids = append(ids, device.Id)
+		names.add(device.Name)
+	}
+	if len(ids) == 0 {
+		return names.list
+	}
+
+	containers := provider.client.ContainerList(ids)
+	for _, container := range containers.List {
+		names.add(container.Name)
+	}
+	return names.list
+}
+
+// recoverClientPanic turns panic of netbox client into error
+func recoverClientPanic(err *error) {
+	if r := recover(); r != nil {
+		if clientErr, ok := r.(error); ok {
+			*err = clientErr
+		} else {
+			*err = fmt.Errorf("netbox client panic: %v", r)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/netbox"
+)
+
+// supported providers
+const (
+	ProviderNetbox = "netbox"
+	ProviderFile   = "file"
+	ProviderHTTP   = "http"
+)
+
+// Config - inventory provider config
+type Config struct {
+	Provider string
+
+	Netbox *netbox.Config
+
+	FilePath string
+
+	HTTPURL     string
+	HTTPToken   string
+	HTTPTimeout time.Duration
+}
+
+// NewProvider creates inventory provider of the configured type
+// nil is returned if no provider is configured
+func NewProvider(config *Config) (moira.InventoryProvider, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	switch config.Provider {
+	case "":
+		return nil, nil
+	case ProviderNetbox:
+		if config.Netbox == nil {
+			return nil, fmt.Errorf("netbox must be enabled to be used as inventory provider")
+		}
+		return newNetboxProvider(config.Netbox), nil
+	case ProviderFile:
+		return newFileProvider(config.FilePath)
+	case ProviderHTTP:
+		return newHTTPProvider(config.HTTPURL, config.HTTPToken, config.HTTPTimeout)
+	default:
+		return nil, fmt.Errorf("unknown inventory provider '%s'", config.Provider)
+	}
+}
+
+// collectGroupHosts returns names of the hosts of the given group along with names of their containers
+// it is used by providers which have all hosts at hand
+func collectGroupHosts(hosts []moira.InventoryHost, kind moira.InventoryGroupKind, name string) []string {
+	groupHosts := make([]moira.InventoryHost, 0)
+	for _, host := range hosts {
+		if host.Groups[kind] == name {
+			groupHosts = append(groupHosts, host)
+		}
+	}
+
+	// hosts' names go first, then containers' names
+	names := newNameList(2 * len(groupHosts))
+	for _, host := range groupHosts {
+		names.add(host.Name)
+	}
+	for _, host := range groupHosts {
+		for _, container := range host.Containers {
+			names.add(container)
+		}
+	}
+	return names.list
+}
+
+// nameList is the list of unique names
+type nameList struct {
+	list []string
+	used map[string]bool
+}
+
+func newNameList(capacity int) *nameList {
+	return &nameList{
+		list: make([]string, 0, capacity),
+		used: make(map[string]bool, capacity),
+	}
+}
+
+func (names *nameList) add(name string) {
+	if !names.used[name] {
+		names.list = append(names.list, name)
+		names.used[name] = true
+	}
+}
@@ -0,0 +1,177 @@
+package inventory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+)
+
+const testInventoryFile = `
+hosts:
+  - name: avi-web01
+    previous_name: avi-web-old01
+    inactive: true
+    containers: [avi-web01-nginx, avi-web01-php]
+    groups: {rack: R-101, rack_group: DC1}
+  - name: avi-web02
+    containers: [avi-web02-nginx]
+    groups: {rack: R-101, rack_group: DC1}
+    meta: {role: server}
+  - name: avi-db01
+    groups: {rack: R-102, rack_group: DC1}
+`
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "inventory.yml")
+	if err = ioutil.WriteFile(path, []byte(testInventoryFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewProvider(&Config{Provider: ProviderFile, FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Inactive hosts", t, func() {
+		hosts, err := provider.InactiveHosts()
+		So(err, ShouldBeNil)
+		So(hosts, ShouldHaveLength, 1)
+		So(hosts[0].Name, ShouldEqual, "avi-web01")
+		So(hosts[0].PreviousName, ShouldEqual, "avi-web-old01")
+		So(hosts[0].Containers, ShouldResemble, []string{"avi-web01-nginx", "avi-web01-php"})
+	})
+
+	Convey("Group hosts", t, func() {
+		names, err := provider.GroupHosts(moira.InventoryGroupRack, "R-101")
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"avi-web01", "avi-web02", "avi-web01-nginx", "avi-web01-php", "avi-web02-nginx"})
+
+		names, err = provider.GroupHosts(moira.InventoryGroupRackGroup, "DC1")
+		So(err, ShouldBeNil)
+		So(names, ShouldHaveLength, 6)
+
+		names, err = provider.GroupHosts(moira.InventoryGroupRack, "R-999")
+		So(err, ShouldBeNil)
+		So(names, ShouldBeEmpty)
+	})
+
+	Convey("Host info", t, func() {
+		host, err := provider.HostInfo("avi-web02")
+		So(err, ShouldBeNil)
+		So(host.Meta, ShouldResemble, map[string]string{"role": "server"})
+		So(host.Groups[moira.InventoryGroupRack], ShouldEqual, "R-101")
+
+		host, err = provider.HostInfo("unknown")
+		So(err, ShouldBeNil)
+		So(host, ShouldBeNil)
+	})
+
+	Convey("Broken file", t, func() {
+		brokenPath := filepath.Join(dir, "broken.json")
+		So(ioutil.WriteFile(brokenPath, []byte("{"), 0644), ShouldBeNil)
+		_, err := NewProvider(&Config{Provider: ProviderFile, FilePath: brokenPath})
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestHTTPProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer secret" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch request.URL.Path {
+		case "/inactive":
+			_ = json.NewEncoder(writer).Encode([]moira.InventoryHost{{Name: "avi-web01", Inactive: true, Containers: []string{"avi-web01-nginx"}}})
+		case "/groups":
+			_ = json.NewEncoder(writer).Encode([]string{request.URL.Query().Get("kind"), request.URL.Query().Get("name")})
+		case "/hosts":
+			if request.URL.Query().Get("name") != "avi-web01" {
+				writer.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(writer).Encode(moira.InventoryHost{Name: "avi-web01", Meta: map[string]string{"site": "msk"}})
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{Provider: ProviderHTTP, HTTPURL: server.URL + "/", HTTPToken: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Inactive hosts", t, func() {
+		hosts, err := provider.InactiveHosts()
+		So(err, ShouldBeNil)
+		So(hosts, ShouldResemble, []moira.InventoryHost{{Name: "avi-web01", Inactive: true, Containers: []string{"avi-web01-nginx"}}})
+	})
+
+	Convey("Group hosts", t, func() {
+		names, err := provider.GroupHosts(moira.InventoryGroupRackGroup, "DC1")
+		So(err, ShouldBeNil)
+		So(names, ShouldResemble, []string{"rack_group", "DC1"})
+	})
+
+	Convey("Host info", t, func() {
+		host, err := provider.HostInfo("avi-web01")
+		So(err, ShouldBeNil)
+		So(host.Meta["site"], ShouldEqual, "msk")
+
+		host, err = provider.HostInfo("unknown")
+		So(err, ShouldBeNil)
+		So(host, ShouldBeNil)
+	})
+
+	Convey("Request errors", t, func() {
+		unauthorized, err := NewProvider(&Config{Provider: ProviderHTTP, HTTPURL: server.URL})
+		So(err, ShouldBeNil)
+		_, err = unauthorized.InactiveHosts()
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Missing list endpoint is an error", t, func() {
+		misrouted, err := NewProvider(&Config{Provider: ProviderHTTP, HTTPURL: server.URL + "/missing", HTTPToken: "secret"})
+		So(err, ShouldBeNil)
+		hosts, err := misrouted.InactiveHosts()
+		So(err, ShouldNotBeNil)
+		So(hosts, ShouldBeNil)
+		names, err := misrouted.GroupHosts(moira.InventoryGroupRack, "R-101")
+		So(err, ShouldNotBeNil)
+		So(names, ShouldBeNil)
+	})
+}
+
+func TestNewProvider(t *testing.T) {
+	Convey("No provider", t, func() {
+		provider, err := NewProvider(&Config{})
+		So(err, ShouldBeNil)
+		So(provider, ShouldBeNil)
+	})
+
+	Convey("Netbox must be configured", t, func() {
+		_, err := NewProvider(&Config{Provider: ProviderNetbox})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Unknown provider", t, func() {
+		_, err := NewProvider(&Config{Provider: "ldap"})
+		So(err, ShouldNotBeNil)
+	})
+}
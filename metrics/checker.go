@@ -1,19 +1,51 @@
 package metrics
 
+import (
+	"fmt"
+
+	"go.avito.ru/DO/moira"
+)
+
 type CheckerMetrics struct {
 	CheckError  *Bucket
 	CheckTime   *Bucket
 	HandleError *Bucket
+
+	MetricEventsLag     *Bucket
+	MetricEventsPending *Bucket
+
+	// the state of the queue of the checks by the priorities of the triggers
+	CheckQueueDepth    map[moira.TriggerPriority]*Bucket
+	CheckLateness      map[moira.TriggerPriority]*Bucket
+	CheckQueueOverflow map[moira.TriggerPriority]*Bucket
 }
 
 func NewCheckerMetrics() *CheckerMetrics {
 	checkError, _ := NewBucket("checker.errors.check")
 	checkTime, _ := NewBucket("checker.triggers")
 	handleError, _ := NewBucket("checker.errors.handle")
+	metricEventsLag, _ := NewBucket("checker.metric_events.lag")
+	metricEventsPending, _ := NewBucket("checker.metric_events.pending")
+
+	checkQueueDepth := make(map[moira.TriggerPriority]*Bucket, len(moira.TriggerPriorities))
+	checkLateness := make(map[moira.TriggerPriority]*Bucket, len(moira.TriggerPriorities))
+	checkQueueOverflow := make(map[moira.TriggerPriority]*Bucket, len(moira.TriggerPriorities))
+	for _, priority := range moira.TriggerPriorities {
+		checkQueueDepth[priority], _ = NewBucket(fmt.Sprintf("checker.queue.%s.depth", priority))
+		checkLateness[priority], _ = NewBucket(fmt.Sprintf("checker.queue.%s.lateness", priority))
+		checkQueueOverflow[priority], _ = NewBucket(fmt.Sprintf("checker.queue.%s.shed", priority))
+	}
 
 	return &CheckerMetrics{
 		CheckError:  checkError,
 		CheckTime:   checkTime,
 		HandleError: handleError,
+
+		MetricEventsLag:     metricEventsLag,
+		MetricEventsPending: metricEventsPending,
+
+		CheckQueueDepth:    checkQueueDepth,
+		CheckLateness:      checkLateness,
+		CheckQueueOverflow: checkQueueOverflow,
 	}
 }
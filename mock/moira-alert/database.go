@@ -63,6 +63,20 @@ func (mr *MockDatabaseMockRecorder) AckEscalationsBatch(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AckEscalationsBatch", reflect.TypeOf((*MockDatabase)(nil).AckEscalationsBatch), arg0, arg1, arg2)
 }
 
+// AckMetricEvents mocks base method
+func (m *MockDatabase) AckMetricEvents(arg0 []*moira.MetricEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AckMetricEvents", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AckMetricEvents indicates an expected call of AckMetricEvents
+func (mr *MockDatabaseMockRecorder) AckMetricEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AckMetricEvents", reflect.TypeOf((*MockDatabase)(nil).AckMetricEvents), arg0)
+}
+
 // AckUnacknowledgedMessages mocks base method
 func (m *MockDatabase) AckUnacknowledgedMessages(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -260,6 +274,20 @@ func (mr *MockDatabaseMockRecorder) CheckTriggerExists(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckTriggerExists", reflect.TypeOf((*MockDatabase)(nil).CheckTriggerExists), arg0)
 }
 
+// DelMaintenanceTrigger mocks base method
+func (m *MockDatabase) DelMaintenanceTrigger(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelMaintenanceTrigger", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelMaintenanceTrigger indicates an expected call of DelMaintenanceTrigger
+func (mr *MockDatabaseMockRecorder) DelMaintenanceTrigger(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelMaintenanceTrigger", reflect.TypeOf((*MockDatabase)(nil).DelMaintenanceTrigger), arg0)
+}
+
 // DeleteChildEvents mocks base method
 func (m *MockDatabase) DeleteChildEvents(arg0, arg1, arg2 string, arg3 []string) error {
 	m.ctrl.T.Helper()
@@ -372,20 +400,6 @@ func (mr *MockDatabaseMockRecorder) DeleteTriggerThrottling(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTriggerThrottling", reflect.TypeOf((*MockDatabase)(nil).DeleteTriggerThrottling), arg0)
 }
 
-// DelMaintenanceTrigger mocks base method
-func (m *MockDatabase) DelMaintenanceTrigger(arg0 string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DelMaintenanceTrigger", arg0)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// DelMaintenanceTrigger indicates an expected call of DelMaintenanceTrigger
-func (mr *MockDatabaseMockRecorder) DelMaintenanceTrigger(arg0 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelMaintenanceTrigger", reflect.TypeOf((*MockDatabase)(nil).DelMaintenanceTrigger), arg0)
-}
-
 // DeregisterBot mocks base method
 func (m *MockDatabase) DeregisterBot(arg0 string) bool {
 	m.ctrl.T.Helper()
@@ -487,6 +501,51 @@ func (mr *MockDatabaseMockRecorder) FetchSlackDelayedActions(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSlackDelayedActions", reflect.TypeOf((*MockDatabase)(nil).FetchSlackDelayedActions), arg0)
 }
 
+// GetAPIToken mocks base method
+func (m *MockDatabase) GetAPIToken(arg0 string) (*moira.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", arg0)
+	ret0, _ := ret[0].(*moira.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken
+func (mr *MockDatabaseMockRecorder) GetAPIToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockDatabase)(nil).GetAPIToken), arg0)
+}
+
+// GetAPITokenByHash mocks base method
+func (m *MockDatabase) GetAPITokenByHash(arg0 string) (*moira.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPITokenByHash", arg0)
+	ret0, _ := ret[0].(*moira.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokenByHash indicates an expected call of GetAPITokenByHash
+func (mr *MockDatabaseMockRecorder) GetAPITokenByHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokenByHash", reflect.TypeOf((*MockDatabase)(nil).GetAPITokenByHash), arg0)
+}
+
+// GetAPITokens mocks base method
+func (m *MockDatabase) GetAPITokens() ([]*moira.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPITokens")
+	ret0, _ := ret[0].([]*moira.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokens indicates an expected call of GetAPITokens
+func (mr *MockDatabaseMockRecorder) GetAPITokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokens", reflect.TypeOf((*MockDatabase)(nil).GetAPITokens))
+}
+
 // GetAllContacts mocks base method
 func (m *MockDatabase) GetAllContacts() ([]*moira.ContactData, error) {
 	m.ctrl.T.Helper()
@@ -682,6 +741,21 @@ func (mr *MockDatabaseMockRecorder) GetMaintenanceTrigger(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceTrigger", reflect.TypeOf((*MockDatabase)(nil).GetMaintenanceTrigger), arg0)
 }
 
+// GetMetricEventsLag mocks base method
+func (m *MockDatabase) GetMetricEventsLag() (*moira.MetricEventsLag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetricEventsLag")
+	ret0, _ := ret[0].(*moira.MetricEventsLag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricEventsLag indicates an expected call of GetMetricEventsLag
+func (mr *MockDatabaseMockRecorder) GetMetricEventsLag() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricEventsLag", reflect.TypeOf((*MockDatabase)(nil).GetMetricEventsLag))
+}
+
 // GetMetricRetention mocks base method
 func (m *MockDatabase) GetMetricRetention(arg0 string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -697,6 +771,21 @@ func (mr *MockDatabaseMockRecorder) GetMetricRetention(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricRetention", reflect.TypeOf((*MockDatabase)(nil).GetMetricRetention), arg0)
 }
 
+// GetMetricsTTL mocks base method
+func (m *MockDatabase) GetMetricsTTL() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetricsTTL")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricsTTL indicates an expected call of GetMetricsTTL
+func (mr *MockDatabaseMockRecorder) GetMetricsTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricsTTL", reflect.TypeOf((*MockDatabase)(nil).GetMetricsTTL))
+}
+
 // GetMetricsUpdatesCount mocks base method
 func (m *MockDatabase) GetMetricsUpdatesCount() (int64, error) {
 	m.ctrl.T.Helper()
@@ -772,6 +861,36 @@ func (mr *MockDatabaseMockRecorder) GetNotifications(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockDatabase)(nil).GetNotifications), arg0, arg1)
 }
 
+// GetOnCallRotation mocks base method
+func (m *MockDatabase) GetOnCallRotation(arg0 string) (*moira.OnCallRotation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOnCallRotation", arg0)
+	ret0, _ := ret[0].(*moira.OnCallRotation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOnCallRotation indicates an expected call of GetOnCallRotation
+func (mr *MockDatabaseMockRecorder) GetOnCallRotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOnCallRotation", reflect.TypeOf((*MockDatabase)(nil).GetOnCallRotation), arg0)
+}
+
+// GetOnCallRotations mocks base method
+func (m *MockDatabase) GetOnCallRotations() ([]*moira.OnCallRotation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOnCallRotations")
+	ret0, _ := ret[0].([]*moira.OnCallRotation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOnCallRotations indicates an expected call of GetOnCallRotations
+func (mr *MockDatabaseMockRecorder) GetOnCallRotations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOnCallRotations", reflect.TypeOf((*MockDatabase)(nil).GetOnCallRotations))
+}
+
 // GetOrCreateMaintenanceSilent mocks base method
 func (m *MockDatabase) GetOrCreateMaintenanceSilent(arg0 moira.SilentPatternType) (moira.Maintenance, error) {
 	m.ctrl.T.Helper()
@@ -877,6 +996,36 @@ func (mr *MockDatabaseMockRecorder) GetPatterns() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPatterns", reflect.TypeOf((*MockDatabase)(nil).GetPatterns))
 }
 
+// GetSeriesByTagValues mocks base method
+func (m *MockDatabase) GetSeriesByTagValues(arg0 string, arg1 []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeriesByTagValues", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeriesByTagValues indicates an expected call of GetSeriesByTagValues
+func (mr *MockDatabaseMockRecorder) GetSeriesByTagValues(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesByTagValues", reflect.TypeOf((*MockDatabase)(nil).GetSeriesByTagValues), arg0, arg1)
+}
+
+// GetSeriesTagValues mocks base method
+func (m *MockDatabase) GetSeriesTagValues(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeriesTagValues", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeriesTagValues indicates an expected call of GetSeriesTagValues
+func (mr *MockDatabaseMockRecorder) GetSeriesTagValues(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesTagValues", reflect.TypeOf((*MockDatabase)(nil).GetSeriesTagValues), arg0)
+}
+
 // GetServiceDuty mocks base method
 func (m *MockDatabase) GetServiceDuty(arg0 string) (moira.DutyData, error) {
 	m.ctrl.T.Helper()
@@ -1061,6 +1210,36 @@ func (mr *MockDatabaseMockRecorder) GetTagsSubscriptions(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagsSubscriptions", reflect.TypeOf((*MockDatabase)(nil).GetTagsSubscriptions), arg0)
 }
 
+// GetTeams mocks base method
+func (m *MockDatabase) GetTeams() ([]*moira.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeams")
+	ret0, _ := ret[0].([]*moira.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeams indicates an expected call of GetTeams
+func (mr *MockDatabaseMockRecorder) GetTeams() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeams", reflect.TypeOf((*MockDatabase)(nil).GetTeams))
+}
+
+// GetTemplateTriggerIDs mocks base method
+func (m *MockDatabase) GetTemplateTriggerIDs(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateTriggerIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateTriggerIDs indicates an expected call of GetTemplateTriggerIDs
+func (mr *MockDatabaseMockRecorder) GetTemplateTriggerIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateTriggerIDs", reflect.TypeOf((*MockDatabase)(nil).GetTemplateTriggerIDs), arg0)
+}
+
 // GetTrigger mocks base method
 func (m *MockDatabase) GetTrigger(arg0 string) (*moira.Trigger, error) {
 	m.ctrl.T.Helper()
@@ -1166,6 +1345,36 @@ func (mr *MockDatabaseMockRecorder) GetTriggerLastChecks(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerLastChecks", reflect.TypeOf((*MockDatabase)(nil).GetTriggerLastChecks), arg0)
 }
 
+// GetTriggerTemplate mocks base method
+func (m *MockDatabase) GetTriggerTemplate(arg0 string) (*moira.TriggerTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggerTemplate", arg0)
+	ret0, _ := ret[0].(*moira.TriggerTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerTemplate indicates an expected call of GetTriggerTemplate
+func (mr *MockDatabaseMockRecorder) GetTriggerTemplate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerTemplate", reflect.TypeOf((*MockDatabase)(nil).GetTriggerTemplate), arg0)
+}
+
+// GetTriggerTemplates mocks base method
+func (m *MockDatabase) GetTriggerTemplates() ([]*moira.TriggerTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggerTemplates")
+	ret0, _ := ret[0].([]*moira.TriggerTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerTemplates indicates an expected call of GetTriggerTemplates
+func (mr *MockDatabaseMockRecorder) GetTriggerTemplates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerTemplates", reflect.TypeOf((*MockDatabase)(nil).GetTriggerTemplates))
+}
+
 // GetTriggerThrottling mocks base method
 func (m *MockDatabase) GetTriggerThrottling(arg0 string) (time.Time, time.Time) {
 	m.ctrl.T.Helper()
@@ -1326,6 +1535,20 @@ func (mr *MockDatabaseMockRecorder) RegisterProcessedEscalationID(arg0, arg1, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterProcessedEscalationID", reflect.TypeOf((*MockDatabase)(nil).RegisterProcessedEscalationID), arg0, arg1, arg2)
 }
 
+// RemoveAPIToken mocks base method
+func (m *MockDatabase) RemoveAPIToken(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAPIToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAPIToken indicates an expected call of RemoveAPIToken
+func (mr *MockDatabaseMockRecorder) RemoveAPIToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAPIToken", reflect.TypeOf((*MockDatabase)(nil).RemoveAPIToken), arg0)
+}
+
 // RemoveContact mocks base method
 func (m *MockDatabase) RemoveContact(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -1383,6 +1606,20 @@ func (mr *MockDatabaseMockRecorder) RemoveNotification(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveNotification", reflect.TypeOf((*MockDatabase)(nil).RemoveNotification), arg0)
 }
 
+// RemoveOnCallRotation mocks base method
+func (m *MockDatabase) RemoveOnCallRotation(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOnCallRotation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOnCallRotation indicates an expected call of RemoveOnCallRotation
+func (mr *MockDatabaseMockRecorder) RemoveOnCallRotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOnCallRotation", reflect.TypeOf((*MockDatabase)(nil).RemoveOnCallRotation), arg0)
+}
+
 // RemovePattern mocks base method
 func (m *MockDatabase) RemovePattern(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -1442,7 +1679,11 @@ func (mr *MockDatabaseMockRecorder) RemovePatternsMetrics(arg0 interface{}) *gom
 // RemoveSilentPatterns mocks base method
 func (m *MockDatabase) RemoveSilentPatterns(arg0 moira.SilentPatternType, arg1 ...*moira.SilentPatternData) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveSilentPatterns", arg0, arg1)
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveSilentPatterns", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
@@ -1450,7 +1691,8 @@ func (m *MockDatabase) RemoveSilentPatterns(arg0 moira.SilentPatternType, arg1 .
 // RemoveSilentPatterns indicates an expected call of RemoveSilentPatterns
 func (mr *MockDatabaseMockRecorder) RemoveSilentPatterns(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSilentPatterns", reflect.TypeOf((*MockDatabase)(nil).RemoveSilentPatterns), arg0, arg1)
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSilentPatterns", reflect.TypeOf((*MockDatabase)(nil).RemoveSilentPatterns), varargs...)
 }
 
 // RemoveSlackDashboards mocks base method
@@ -1509,6 +1751,20 @@ func (mr *MockDatabaseMockRecorder) RemoveTag(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockDatabase)(nil).RemoveTag), arg0)
 }
 
+// RemoveTeam mocks base method
+func (m *MockDatabase) RemoveTeam(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTeam", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTeam indicates an expected call of RemoveTeam
+func (mr *MockDatabaseMockRecorder) RemoveTeam(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeam", reflect.TypeOf((*MockDatabase)(nil).RemoveTeam), arg0)
+}
+
 // RemoveTrigger mocks base method
 func (m *MockDatabase) RemoveTrigger(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -1537,6 +1793,20 @@ func (mr *MockDatabaseMockRecorder) RemoveTriggerLastCheck(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTriggerLastCheck", reflect.TypeOf((*MockDatabase)(nil).RemoveTriggerLastCheck), arg0)
 }
 
+// RemoveTriggerTemplate mocks base method
+func (m *MockDatabase) RemoveTriggerTemplate(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTriggerTemplate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTriggerTemplate indicates an expected call of RemoveTriggerTemplate
+func (mr *MockDatabaseMockRecorder) RemoveTriggerTemplate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTriggerTemplate", reflect.TypeOf((*MockDatabase)(nil).RemoveTriggerTemplate), arg0)
+}
+
 // RemoveUser mocks base method
 func (m *MockDatabase) RemoveUser(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -1565,6 +1835,20 @@ func (mr *MockDatabaseMockRecorder) RenewBotRegistration(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewBotRegistration", reflect.TypeOf((*MockDatabase)(nil).RenewBotRegistration), arg0)
 }
 
+// SaveAPIToken mocks base method
+func (m *MockDatabase) SaveAPIToken(arg0 *moira.APIToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAPIToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAPIToken indicates an expected call of SaveAPIToken
+func (mr *MockDatabaseMockRecorder) SaveAPIToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAPIToken", reflect.TypeOf((*MockDatabase)(nil).SaveAPIToken), arg0)
+}
+
 // SaveContact mocks base method
 func (m *MockDatabase) SaveContact(arg0 *moira.ContactData) error {
 	m.ctrl.T.Helper()
@@ -1607,10 +1891,28 @@ func (mr *MockDatabaseMockRecorder) SaveMetrics(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMetrics", reflect.TypeOf((*MockDatabase)(nil).SaveMetrics), arg0)
 }
 
+// SaveOnCallRotation mocks base method
+func (m *MockDatabase) SaveOnCallRotation(arg0 *moira.OnCallRotation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOnCallRotation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOnCallRotation indicates an expected call of SaveOnCallRotation
+func (mr *MockDatabaseMockRecorder) SaveOnCallRotation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOnCallRotation", reflect.TypeOf((*MockDatabase)(nil).SaveOnCallRotation), arg0)
+}
+
 // SaveSilentPatterns mocks base method
 func (m *MockDatabase) SaveSilentPatterns(arg0 moira.SilentPatternType, arg1 ...*moira.SilentPatternData) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveSilentPatterns", arg0, arg1)
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveSilentPatterns", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
@@ -1618,7 +1920,8 @@ func (m *MockDatabase) SaveSilentPatterns(arg0 moira.SilentPatternType, arg1 ...
 // SaveSilentPatterns indicates an expected call of SaveSilentPatterns
 func (mr *MockDatabaseMockRecorder) SaveSilentPatterns(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSilentPatterns", reflect.TypeOf((*MockDatabase)(nil).SaveSilentPatterns), arg0, arg1)
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSilentPatterns", reflect.TypeOf((*MockDatabase)(nil).SaveSilentPatterns), varargs...)
 }
 
 // SaveSlackDelayedAction mocks base method
@@ -1677,6 +1980,20 @@ func (mr *MockDatabaseMockRecorder) SaveSubscriptions(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscriptions", reflect.TypeOf((*MockDatabase)(nil).SaveSubscriptions), arg0)
 }
 
+// SaveTeam mocks base method
+func (m *MockDatabase) SaveTeam(arg0 *moira.Team) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTeam", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTeam indicates an expected call of SaveTeam
+func (mr *MockDatabaseMockRecorder) SaveTeam(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTeam", reflect.TypeOf((*MockDatabase)(nil).SaveTeam), arg0)
+}
+
 // SaveTrigger mocks base method
 func (m *MockDatabase) SaveTrigger(arg0 string, arg1 *moira.Trigger) error {
 	m.ctrl.T.Helper()
@@ -1691,6 +2008,20 @@ func (mr *MockDatabaseMockRecorder) SaveTrigger(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTrigger", reflect.TypeOf((*MockDatabase)(nil).SaveTrigger), arg0, arg1)
 }
 
+// SaveTriggerTemplate mocks base method
+func (m *MockDatabase) SaveTriggerTemplate(arg0 *moira.TriggerTemplate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTriggerTemplate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTriggerTemplate indicates an expected call of SaveTriggerTemplate
+func (mr *MockDatabaseMockRecorder) SaveTriggerTemplate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTriggerTemplate", reflect.TypeOf((*MockDatabase)(nil).SaveTriggerTemplate), arg0)
+}
+
 // SetGlobalSettings mocks base method
 func (m *MockDatabase) SetGlobalSettings(arg0 moira.GlobalSettings) error {
 	m.ctrl.T.Helper()
@@ -1734,6 +2065,20 @@ func (mr *MockDatabaseMockRecorder) SetMaintenanceTrigger(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaintenanceTrigger", reflect.TypeOf((*MockDatabase)(nil).SetMaintenanceTrigger), arg0, arg1)
 }
 
+// SetMetricsTTL mocks base method
+func (m *MockDatabase) SetMetricsTTL(arg0 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMetricsTTL", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMetricsTTL indicates an expected call of SetMetricsTTL
+func (mr *MockDatabaseMockRecorder) SetMetricsTTL(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMetricsTTL", reflect.TypeOf((*MockDatabase)(nil).SetMetricsTTL), arg0)
+}
+
 // SetTriggerCheckLock mocks base method
 func (m *MockDatabase) SetTriggerCheckLock(arg0 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1807,10 +2152,10 @@ func (mr *MockDatabaseMockRecorder) SetUsernameID(arg0, arg1, arg2 interface{})
 }
 
 // SubscribeMetricEvents mocks base method
-func (m *MockDatabase) SubscribeMetricEvents(arg0 *tomb.Tomb) (<-chan *moira.MetricEvent, error) {
+func (m *MockDatabase) SubscribeMetricEvents(arg0 *tomb.Tomb) (<-chan []*moira.MetricEvent, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SubscribeMetricEvents", arg0)
-	ret0, _ := ret[0].(<-chan *moira.MetricEvent)
+	ret0, _ := ret[0].(<-chan []*moira.MetricEvent)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1821,6 +2166,36 @@ func (mr *MockDatabaseMockRecorder) SubscribeMetricEvents(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeMetricEvents", reflect.TypeOf((*MockDatabase)(nil).SubscribeMetricEvents), arg0)
 }
 
+// SubscribeNotificationEvents mocks base method
+func (m *MockDatabase) SubscribeNotificationEvents(arg0 *tomb.Tomb) (<-chan *moira.NotificationEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeNotificationEvents", arg0)
+	ret0, _ := ret[0].(<-chan *moira.NotificationEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeNotificationEvents indicates an expected call of SubscribeNotificationEvents
+func (mr *MockDatabaseMockRecorder) SubscribeNotificationEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeNotificationEvents", reflect.TypeOf((*MockDatabase)(nil).SubscribeNotificationEvents), arg0)
+}
+
+// SubscribePatternChanges mocks base method
+func (m *MockDatabase) SubscribePatternChanges(arg0 *tomb.Tomb) (<-chan *moira.PatternChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribePatternChanges", arg0)
+	ret0, _ := ret[0].(<-chan *moira.PatternChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribePatternChanges indicates an expected call of SubscribePatternChanges
+func (mr *MockDatabaseMockRecorder) SubscribePatternChanges(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePatternChanges", reflect.TypeOf((*MockDatabase)(nil).SubscribePatternChanges), arg0)
+}
+
 // TriggerHasPendingEscalations mocks base method
 func (m *MockDatabase) TriggerHasPendingEscalations(arg0 string, arg1 bool) (bool, error) {
 	m.ctrl.T.Helper()
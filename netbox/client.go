@@ -98,6 +98,12 @@ func (client *Client) DeviceList(rackGroupId *int, rackId *int) DeviceList {
 	return result
 }
 
+func (client *Client) DeviceListByName(name string) DeviceList {
+	result := DeviceList{}
+	client.decodeJson(client.doApiQuery(apiMethodDevices, QueryParams{"name": name}), &result)
+	return result
+}
+
 func (client *Client) InactiveDeviceList() DeviceBriefList {
 	queryParams := QueryParams{"limit": "0"} // limit = 0 means that maximum page size will be specified
 	singleAllocateQty := 1024
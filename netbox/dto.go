@@ -6,6 +6,15 @@ type list []interface{}
 // Object is plain JSON object having structure nobody cares about
 type object map[string]interface{}
 
+// GetString returns string value of the given key of the object, empty string if there is no such value
+func (o *object) GetString(key string) string {
+	if o == nil {
+		return ""
+	}
+	value, _ := (*o)[key].(string)
+	return value
+}
+
 // ContainerBrief is DTO definition of brief container's data, part of DeviceBrief
 type ContainerBrief struct {
 	Id   int    `json:"id"`
@@ -2,6 +2,10 @@ package notifier
 
 import (
 	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/notifier/contacts"
 )
 
 // Config is sending settings including log settings
@@ -14,6 +18,8 @@ type Config struct {
 	LogLevel         string
 	FrontURL         string
 	Location         *time.Location
-	DutyApiToken     string
-	DutyUrl          string
+	OnCall           contacts.OnCallConfig
+	Inventory        moira.InventoryProvider
+	Authorization    api.AuthorizationConfig // permissions of the users of chat commands
+	SuperUsers       []string
 }
@@ -1,6 +1,7 @@
 package contacts
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -14,29 +15,25 @@ type ContactReplacement struct {
 }
 
 type Decoder struct {
-	db           moira.Database
-	logger       moira.Logger
-	dutyAPIToken string
-	dutyUrl      string
+	db              moira.Database
+	logger          moira.Logger
+	onCallProviders map[string]OnCallProvider
+	defaultProvider string
 }
 
-func NewDecoder(db moira.Database, logger moira.Logger, dutyAPIToken, dutyUrl string) *Decoder {
-	var (
-		err    error = nil
-		result       = &Decoder{db: db, logger: logger}
-	)
-
-	result.dutyAPIToken, err = moira.GetFileContent(dutyAPIToken)
-	result.dutyAPIToken = strings.TrimSpace(result.dutyAPIToken)
-	if err != nil || result.dutyAPIToken == "" {
-		logger.WarnF("Failed to read duty.cc token from path \"%s\", or it is empty. Error is %v.", dutyAPIToken, err)
+func NewDecoder(db moira.Database, logger moira.Logger, onCallConfig OnCallConfig) *Decoder {
+	result := &Decoder{
+		db:              db,
+		logger:          logger,
+		onCallProviders: newOnCallProviders(db, logger, onCallConfig),
+		defaultProvider: onCallConfig.Default,
 	}
-
-	result.dutyUrl = dutyUrl
-	if result.dutyUrl == "" {
-		result.dutyUrl = "https://duty.cc" // default
+	if _, ok := result.onCallProviders[result.defaultProvider]; !ok {
+		if result.defaultProvider != "" {
+			logger.WarnF("Unknown default on call provider \"%s\", duty.cc is used", result.defaultProvider)
+		}
+		result.defaultProvider = DutyCCProvider
 	}
-
 	return result
 }
 
@@ -47,12 +44,22 @@ const (
 	groupPrefix         = "group__"
 )
 
-// UnwrapContact processes moira.ContactData using its value as template and moira.NotificationEvents as context
-func (decoder *Decoder) UnwrapContact(contact *moira.ContactData, events moira.NotificationEvents) ([]ContactReplacement, error) {
+// onCallContactTypes are the types of the contacts which can be sent to the person on duty
+var onCallContactTypes = map[string]bool{
+	"mail":         true,
+	"slack":        true,
+	"telegram":     true,
+	"twilio sms":   true,
+	"twilio voice": true,
+}
+
+// UnwrapContact processes moira.ContactData using its value as template and moira.NotificationEvents as context,
+// escalated packages are sent to the backup people on duty
+func (decoder *Decoder) UnwrapContact(contact *moira.ContactData, events moira.NotificationEvents, escalated bool) ([]ContactReplacement, error) {
+	if strings.HasPrefix(contact.Value, dutyPrefix) && onCallContactTypes[contact.Type] {
+		return decoder.unwrapDuty(contact, escalated)
+	}
 	if contact.Type == "slack" {
-		if strings.HasPrefix(contact.Value, dutyPrefix) {
-			return decoder.unwrapDuty(contact, events)
-		}
 		if contact.Value == deployer {
 			return decoder.unwrapDeployers(contact, events)
 		}
@@ -71,16 +78,38 @@ func (decoder *Decoder) UnwrapContact(contact *moira.ContactData, events moira.N
 	return defaultResult, nil
 }
 
-func (decoder *Decoder) unwrapDuty(contact *moira.ContactData, events moira.NotificationEvents) ([]ContactReplacement, error) {
-	items, err := decoder.resolveDuty(contact.Value[len(dutyPrefix):])
+// unwrapDuty resolves duty_<service> or duty_<provider>:<service> to the person on duty
+func (decoder *Decoder) unwrapDuty(contact *moira.ContactData, escalated bool) ([]ContactReplacement, error) {
+	providerName, service := decoder.defaultProvider, contact.Value[len(dutyPrefix):]
+	if i := strings.Index(service, ":"); i >= 0 {
+		providerName, service = service[:i], service[i+1:]
+	}
+	provider, ok := decoder.onCallProviders[providerName]
+	if !ok {
+		return nil, ErrUnknownOnCallProvider{provider: providerName}
+	}
+
+	duty, err := provider.GetOnCall(service)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("ResolveDuty: %s", err.Error())
 	}
 
 	// use only first duty user
+	item, ok := getOnCallItem(duty, escalated)
+	if !ok {
+		return nil, ErrNobodyOnDuty{service: contact.Value[len(dutyPrefix):]}
+	}
+
+	value, err := decoder.getOnCallContactValue(contact.Type, item)
+	if err != nil {
+		if _, ok := err.(ErrNoOnCallContact); !ok || contact.FallbackValue == "" {
+			return nil, err
+		}
+		value = contact.FallbackValue
+	}
 	result := []ContactReplacement{{
-		Expiration:    items[0].DutyEnd,
-		ValueReplaced: "@" + decoder.truncateSuffix(items[0].Login),
+		Expiration:    item.DutyEnd,
+		ValueReplaced: value,
 		ValueRollback: contact.Value,
 	}}
 	return result, nil
@@ -5,93 +5,89 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"go.avito.ru/DO/moira"
 )
 
-const (
-	dutyCacheTTL = 5 * time.Minute
-)
-
 type dutyAPIData struct {
 	Main   []moira.DutyItem
 	Backup []moira.DutyItem
 }
 
-// resolveDuty finds the person on duty for a service and returns their login
-func (decoder *Decoder) resolveDuty(service string) ([]moira.DutyItem, error) {
-	cachedResult, err := decoder.db.GetServiceDuty(service)
-	if err != nil {
-		decoder.logger.ErrorF("Failed to resolve duty, service [%s]: %v", service, err)
-	} else if time.Now().Sub(cachedResult.Timestamp) <= dutyCacheTTL {
-		return cachedResult.Duty, nil
-	}
+// httpOnCallProvider requests the people on call from the url, the response is the same as duty.cc returns
+type httpOnCallProvider struct {
+	url     string
+	headers map[string]string
+	logger  moira.Logger
+}
 
-	result, err := decoder.requestDutyApi(service)
-	if err != nil {
-		err = fmt.Errorf("ResolveDuty: %s", err.Error())
-		return result, err
+func newDutyCCProvider(logger moira.Logger, dutyAPIToken, dutyUrl string) *httpOnCallProvider {
+	token, err := moira.GetFileContent(dutyAPIToken)
+	token = strings.TrimSpace(token)
+	if err != nil || token == "" {
+		logger.WarnF("Failed to read duty.cc token from path \"%s\", or it is empty. Error is %v.", dutyAPIToken, err)
 	}
 
-	if len(result) == 0 {
-		return result, ErrNobodyOnDuty{service: service}
+	if dutyUrl == "" {
+		dutyUrl = "https://duty.cc" // default
 	}
 
-	go func(duty []moira.DutyItem) { // cache the data asynchronously
-		err := decoder.db.UpdateServiceDuty(service, moira.DutyData{
-			Duty:      duty,
-			Timestamp: time.Now(),
-		})
-		if err != nil {
-			decoder.logger.ErrorF("Error while saving duty, service %s: %s", service, err.Error())
-		}
-	}(result)
-
-	return result, nil
+	return &httpOnCallProvider{
+		url:     dutyUrl + "/api/duty/{service}",
+		headers: map[string]string{"Authorization": "Token " + token},
+		logger:  logger,
+	}
 }
 
-func (decoder *Decoder) requestDutyApi(service string) ([]moira.DutyItem, error) {
+// GetOnCall finds the people on duty for a service
+func (provider *httpOnCallProvider) GetOnCall(service string) (moira.DutyData, error) {
+	result := moira.DutyData{}
 	client := http.Client{}
 	client.Timeout = 3 * time.Second
-	url := decoder.dutyUrl + "/api/duty/" + service
+	requestURL := strings.Replace(provider.url, "{service}", url.PathEscape(service), -1)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", requestURL, nil)
 	if err != nil {
-		return nil, err
+		return result, err
+	}
+	for name, value := range provider.headers {
+		req.Header.Add(name, value)
 	}
-	req.Header.Add("Authorization", "Token "+decoder.dutyAPIToken)
 	req.Header.Add("User-Agent", "moira")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
-	decoder.logger.InfoE("Duty API returned some data", map[string]interface{}{
+	provider.logger.InfoE("Duty API returned some data", map[string]interface{}{
 		"status": resp.StatusCode,
 		"body":   string(respBody),
 	})
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return result, fmt.Errorf("on call request %s failed with status %d", requestURL, resp.StatusCode)
+	}
 
 	// duty.cc
 	if len(respBody) == 0 {
-		return nil, nil
+		return result, nil
 	}
 
 	dutyAPIData := dutyAPIData{}
 	if err = json.Unmarshal(respBody, &dutyAPIData); err != nil {
-		decoder.logger.ErrorF("Bad json data received from url %s, err: %v", url, err)
-		return nil, err
+		provider.logger.ErrorF("Bad json data received from url %s, err: %v", requestURL, err)
+		return result, err
 	}
 
-	if len(dutyAPIData.Main) > 0 {
-		return dutyAPIData.Main, nil
-	} else {
-		return dutyAPIData.Backup, nil
-	}
+	result.Duty = dutyAPIData.Main
+	result.Backup = dutyAPIData.Backup
+	return result, nil
 }
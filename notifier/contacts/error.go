@@ -20,6 +20,23 @@ func (err ErrNobodyOnDuty) Error() string {
 	return fmt.Sprintf("Nobody on duty, service %s", err.service)
 }
 
+type ErrNoOnCallContact struct {
+	login       string
+	contactType string
+}
+
+func (err ErrNoOnCallContact) Error() string {
+	return fmt.Sprintf("%s is on duty but has no %s contact and there is no fallback value", err.login, err.contactType)
+}
+
+type ErrUnknownOnCallProvider struct {
+	provider string
+}
+
+func (err ErrUnknownOnCallProvider) Error() string {
+	return fmt.Sprintf("Unknown on call provider %s", err.provider)
+}
+
 type ErrNoDeployers struct{}
 
 func (err ErrNoDeployers) Error() string {
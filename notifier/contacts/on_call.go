@@ -0,0 +1,254 @@
+package contacts
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/database"
+)
+
+// the names of the built-in providers
+const (
+	DutyCCProvider = "duty.cc"
+	StaticProvider = "moira" // rotations maintained in Moira
+)
+
+const onCallCacheTTL = 5 * time.Minute
+
+// OnCallProvider tells who is on call for the service: the main people and their backups
+type OnCallProvider interface {
+	GetOnCall(service string) (moira.DutyData, error)
+}
+
+// OnCallConfig sets up the providers which resolve duty_ contacts
+type OnCallConfig struct {
+	Default      string // provider of the contacts which don't name it, duty.cc if not set
+	DutyAPIToken string // path to the file with duty.cc token
+	DutyURL      string
+	HTTP         []HTTPOnCallConfig
+}
+
+// HTTPOnCallConfig is the generic provider which returns the people on call in the same JSON as duty.cc does
+type HTTPOnCallConfig struct {
+	Name    string
+	URL     string            // {service} is replaced with the name of the service
+	Headers map[string]string // e.g. authorization
+}
+
+// cachedOnCallProvider keeps the answers of the remote provider in the database for a while
+type cachedOnCallProvider struct {
+	name     string
+	provider OnCallProvider
+	db       moira.Database
+	logger   moira.Logger
+}
+
+func (cached *cachedOnCallProvider) GetOnCall(service string) (moira.DutyData, error) {
+	key := cached.name + ":" + service
+	cachedResult, err := cached.db.GetServiceDuty(key)
+	if err != nil {
+		if err != database.ErrNil {
+			cached.logger.ErrorF("Failed to get cached duty, service [%s]: %v", key, err)
+		}
+	} else if time.Now().Sub(cachedResult.Timestamp) <= onCallCacheTTL {
+		return cachedResult, nil
+	}
+
+	result, err := cached.provider.GetOnCall(service)
+	if err != nil {
+		return result, err
+	}
+	if len(result.Duty) == 0 && len(result.Backup) == 0 {
+		return result, nil
+	}
+
+	result.Timestamp = time.Now()
+	go func(duty moira.DutyData) { // cache the data asynchronously
+		if err := cached.db.UpdateServiceDuty(key, duty); err != nil {
+			cached.logger.ErrorF("Error while saving duty, service %s: %s", key, err.Error())
+		}
+	}(result)
+
+	return result, nil
+}
+
+// staticOnCallProvider resolves the service as the id of the rotation maintained in Moira
+type staticOnCallProvider struct {
+	db moira.Database
+}
+
+func (provider *staticOnCallProvider) GetOnCall(service string) (moira.DutyData, error) {
+	result := moira.DutyData{Timestamp: time.Now()}
+	rotation, err := provider.db.GetOnCallRotation(service)
+	if err == database.ErrNil {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	main, backup, end := rotation.GetOnCall(result.Timestamp.Unix())
+	if main != nil {
+		dutyEnd := time.Unix(end, 0)
+		result.Duty = []moira.DutyItem{{Login: main.Login, DutyEnd: &dutyEnd, Contacts: main.Contacts}}
+	}
+	if backup != nil {
+		result.Backup = []moira.DutyItem{{Login: backup.Login, Contacts: backup.Contacts}}
+	}
+	return result, nil
+}
+
+// newOnCallProviders sets up all the configured providers by their names
+func newOnCallProviders(db moira.Database, logger moira.Logger, config OnCallConfig) map[string]OnCallProvider {
+	providers := make(map[string]OnCallProvider, len(config.HTTP)+2)
+	cache := func(name string, provider OnCallProvider) OnCallProvider {
+		return &cachedOnCallProvider{name: name, provider: provider, db: db, logger: logger}
+	}
+
+	providers[DutyCCProvider] = cache(DutyCCProvider, newDutyCCProvider(logger, config.DutyAPIToken, config.DutyURL))
+	providers[StaticProvider] = &staticOnCallProvider{db: db}
+	for _, httpConfig := range config.HTTP {
+		if _, ok := providers[httpConfig.Name]; ok || httpConfig.Name == "" {
+			logger.WarnF("On call provider name \"%s\" is empty or taken, provider %s is skipped", httpConfig.Name, httpConfig.URL)
+			continue
+		}
+		providers[httpConfig.Name] = cache(httpConfig.Name, &httpOnCallProvider{
+			url:     httpConfig.URL,
+			headers: httpConfig.Headers,
+			logger:  logger,
+		})
+	}
+	return providers
+}
+
+// UnwrapRotation resolves the rotation contact to the contacts of the person on call (the backup one for the escalated packages):
+// the Moira contacts of their login and the values set in the rotation for the other types (phone is used by both twilio types).
+// Only the contacts of the given types are returned, they are ordered by type and value
+func (decoder *Decoder) UnwrapRotation(contact *moira.ContactData, escalated bool, contactTypes map[string]bool) ([]moira.ContactData, error) {
+	duty, err := decoder.onCallProviders[StaticProvider].GetOnCall(contact.Value)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := getOnCallItem(duty, escalated)
+	if !ok {
+		return nil, ErrNobodyOnDuty{service: contact.Value}
+	}
+
+	values, err := decoder.getUserContactValues(item.Login, contactTypes)
+	if err != nil {
+		return nil, err
+	}
+	for contactType := range contactTypes {
+		if _, ok := values[contactType]; ok {
+			continue
+		}
+		value := item.Contacts[contactType]
+		if value == "" && (contactType == "twilio sms" || contactType == "twilio voice") {
+			value = item.Contacts["phone"]
+		}
+		if value != "" {
+			values[contactType] = []string{value}
+		}
+	}
+
+	result := make([]moira.ContactData, 0, len(values))
+	for contactType, typeValues := range values {
+		for _, value := range typeValues {
+			result = append(result, moira.ContactData{
+				Type:       contactType,
+				Value:      value,
+				ID:         contact.ID,
+				User:       contact.User,
+				Expiration: item.DutyEnd,
+			})
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrNoOnCallContact{login: item.Login, contactType: "supported"}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result, nil
+}
+
+// getUserContactValues returns the values of the Moira contacts of the user by the contact type,
+// only the contacts of the given types are returned, the duty_ ones are skipped as they can't be resolved again
+func (decoder *Decoder) getUserContactValues(login string, contactTypes map[string]bool) (map[string][]string, error) {
+	values := make(map[string][]string)
+	if login == "" {
+		return values, nil
+	}
+	contactIDs, err := decoder.db.GetUserContactIDs(login)
+	if err != nil {
+		return nil, err
+	}
+	if len(contactIDs) == 0 {
+		return values, nil
+	}
+	userContacts, err := decoder.db.GetContacts(contactIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, userContact := range userContacts {
+		if userContact == nil || !contactTypes[userContact.Type] || strings.HasPrefix(userContact.Value, dutyPrefix) {
+			continue
+		}
+		values[userContact.Type] = append(values[userContact.Type], userContact.Value)
+	}
+	return values, nil
+}
+
+// getOnCallItem returns the first person on duty: the main one or the backup one if the main one
+// hasn't acknowledged the escalated problem or if there is no main one at all
+func getOnCallItem(duty moira.DutyData, escalated bool) (moira.DutyItem, bool) {
+	items := duty.Duty
+	if len(items) == 0 || (escalated && len(duty.Backup) > 0) {
+		items = duty.Backup
+	}
+	if len(items) == 0 {
+		return moira.DutyItem{}, false
+	}
+	return items[0], true
+}
+
+// getOnCallContactValue returns the value of the contact of the given type of the person on call: the one returned by the provider,
+// the Moira contact of their login or the one derived from the login, ErrNoOnCallContact is returned if there is no one
+func (decoder *Decoder) getOnCallContactValue(contactType string, item moira.DutyItem) (string, error) {
+	if value := item.Contacts[contactType]; value != "" {
+		return value, nil
+	}
+	if contactType == "twilio sms" || contactType == "twilio voice" {
+		if value := item.Contacts["phone"]; value != "" {
+			return value, nil
+		}
+	}
+
+	// the people on call of the remote providers are mapped to Moira users by their logins (with and without the domain)
+	for _, login := range []string{item.Login, decoder.truncateSuffix(item.Login)} {
+		values, err := decoder.getUserContactValues(login, map[string]bool{contactType: true})
+		if err != nil {
+			return "", err
+		}
+		if typeValues := values[contactType]; len(typeValues) > 0 {
+			sort.Strings(typeValues)
+			return typeValues[0], nil
+		}
+	}
+
+	// the logins of duty.cc are either slack logins or emails
+	switch contactType {
+	case "slack":
+		return "@" + decoder.truncateSuffix(item.Login), nil
+	case "mail":
+		if at := strings.LastIndex(item.Login, "@"); at > 0 && at < len(item.Login)-1 {
+			return item.Login, nil
+		}
+	}
+	return "", ErrNoOnCallContact{login: item.Login, contactType: contactType}
+}
@@ -0,0 +1,219 @@
+package contacts
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/database"
+	"go.avito.ru/DO/moira/mock/moira-alert"
+	"go.avito.ru/DO/moira/test-helpers"
+)
+
+func TestUnwrapDuty(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	decoder := NewDecoder(dataBase, test_helpers.GetTestLogger(), OnCallConfig{Default: StaticProvider})
+	// nobody on call has Moira contacts
+	dataBase.EXPECT().GetUserContactIDs(gomock.Any()).Return([]string{}, nil).AnyTimes()
+
+	now := time.Now().Unix()
+	rotation := &moira.OnCallRotation{
+		ID: "team",
+		Participants: []moira.OnCallParticipant{
+			{Login: "alice@example.ru", Contacts: map[string]string{"telegram": "@alice", "phone": "+10000000001"}},
+			{Login: "bob@example.ru", Contacts: map[string]string{"telegram": "@bob"}},
+		},
+		Start: now,
+	}
+
+	Convey("Person on duty is resolved by the contact type", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(rotation, nil).Times(4)
+
+		for contactType, expected := range map[string]string{
+			"slack":        "@alice",
+			"telegram":     "@alice",
+			"mail":         "alice@example.ru",
+			"twilio voice": "+10000000001",
+		} {
+			replacements, err := decoder.UnwrapContact(&moira.ContactData{Type: contactType, Value: "duty_team"}, nil, false)
+			So(err, ShouldBeNil)
+			So(len(replacements), ShouldEqual, 1)
+			So(replacements[0].ValueReplaced, ShouldEqual, expected)
+			So(replacements[0].ValueRollback, ShouldEqual, "duty_team")
+		}
+	})
+
+	Convey("Escalated package goes to the backup", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(rotation, nil)
+
+		replacements, err := decoder.UnwrapContact(&moira.ContactData{Type: "telegram", Value: "duty_moira:team"}, nil, true)
+		So(err, ShouldBeNil)
+		So(replacements[0].ValueReplaced, ShouldEqual, "@bob")
+	})
+
+	Convey("Missing contact is replaced with the fallback value", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(rotation, nil).Times(2)
+
+		_, err := decoder.UnwrapContact(&moira.ContactData{Type: "twilio sms", Value: "duty_team"}, nil, true)
+		So(err, ShouldHaveSameTypeAs, ErrNoOnCallContact{})
+
+		replacements, err := decoder.UnwrapContact(&moira.ContactData{Type: "twilio sms", Value: "duty_team", FallbackValue: "+10000000000"}, nil, true)
+		So(err, ShouldBeNil)
+		So(replacements[0].ValueReplaced, ShouldEqual, "+10000000000")
+	})
+
+	Convey("Nobody is on duty without the rotation", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("other").Return(nil, database.ErrNil)
+
+		_, err := decoder.UnwrapContact(&moira.ContactData{Type: "slack", Value: "duty_other"}, nil, false)
+		So(err, ShouldHaveSameTypeAs, ErrNobodyOnDuty{})
+	})
+
+	Convey("Unknown provider", t, func() {
+		_, err := decoder.UnwrapContact(&moira.ContactData{Type: "slack", Value: "duty_unknown:team"}, nil, false)
+		So(err, ShouldHaveSameTypeAs, ErrUnknownOnCallProvider{})
+	})
+
+	Convey("Other contacts are kept as is", t, func() {
+		replacements, err := decoder.UnwrapContact(&moira.ContactData{Type: "webhook", Value: "duty_team"}, nil, false)
+		So(err, ShouldBeNil)
+		So(replacements[0].ValueReplaced, ShouldEqual, "duty_team")
+	})
+}
+
+func TestUnwrapDutyToMoiraContacts(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	decoder := NewDecoder(dataBase, test_helpers.GetTestLogger(), OnCallConfig{})
+	duty := &mockOnCallProvider{duty: moira.DutyData{Duty: []moira.DutyItem{{Login: "alice@example.ru"}}}}
+	decoder.onCallProviders["remote"] = duty
+
+	Convey("Person on call is mapped to Moira user by the login without the domain", t, func() {
+		dataBase.EXPECT().GetUserContactIDs("alice@example.ru").Return([]string{}, nil)
+		dataBase.EXPECT().GetUserContactIDs("alice").Return([]string{"1", "2"}, nil)
+		dataBase.EXPECT().GetContacts([]string{"1", "2"}).Return([]*moira.ContactData{
+			{ID: "1", Type: "telegram", Value: "@alice"},
+			{ID: "2", Type: "mail", Value: "alice@example.ru"},
+		}, nil)
+
+		replacements, err := decoder.UnwrapContact(&moira.ContactData{Type: "telegram", Value: "duty_remote:team"}, nil, false)
+		So(err, ShouldBeNil)
+		So(replacements[0].ValueReplaced, ShouldEqual, "@alice")
+	})
+
+	Convey("Fallback value doesn't hide the errors of the database", t, func() {
+		dataBase.EXPECT().GetUserContactIDs("alice@example.ru").Return(nil, fmt.Errorf("no connection"))
+
+		_, err := decoder.UnwrapContact(&moira.ContactData{Type: "telegram", Value: "duty_remote:team", FallbackValue: "@team"}, nil, false)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+type mockOnCallProvider struct {
+	duty moira.DutyData
+}
+
+func (provider *mockOnCallProvider) GetOnCall(string) (moira.DutyData, error) {
+	return provider.duty, nil
+}
+
+func TestUnwrapRotation(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	decoder := NewDecoder(dataBase, test_helpers.GetTestLogger(), OnCallConfig{})
+	senders := map[string]bool{"mail": true, "slack": true, "telegram": true, "twilio sms": true}
+
+	rotation := &moira.OnCallRotation{
+		ID: "team",
+		Participants: []moira.OnCallParticipant{
+			{Login: "alice", Contacts: map[string]string{"telegram": "@alice-rotation", "phone": "+10000000001"}},
+			{Login: "bob"},
+		},
+		Start: time.Now().Unix(),
+	}
+	contact := &moira.ContactData{ID: "rotation-contact", User: "team", Value: "team"}
+
+	Convey("Moira contacts of the person on call go first, the rotation fills in the other types", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(rotation, nil)
+		dataBase.EXPECT().GetUserContactIDs("alice").Return([]string{"1", "2", "3", "4"}, nil)
+		dataBase.EXPECT().GetContacts([]string{"1", "2", "3", "4"}).Return([]*moira.ContactData{
+			{ID: "1", Type: "telegram", Value: "@alice"},
+			{ID: "2", Type: "mail", Value: "alice@example.ru"},
+			{ID: "3", Type: "webhook", Value: "http://example.ru"},
+			{ID: "4", Type: "slack", Value: "duty_team"},
+			nil,
+		}, nil)
+
+		contacts, err := decoder.UnwrapRotation(contact, false, senders)
+		So(err, ShouldBeNil)
+		So(len(contacts), ShouldEqual, 3)
+		for i, expected := range []moira.ContactData{
+			{Type: "mail", Value: "alice@example.ru"},
+			{Type: "telegram", Value: "@alice"},
+			{Type: "twilio sms", Value: "+10000000001"},
+		} {
+			So(contacts[i].Type, ShouldEqual, expected.Type)
+			So(contacts[i].Value, ShouldEqual, expected.Value)
+			So(contacts[i].ID, ShouldEqual, "rotation-contact")
+			So(contacts[i].User, ShouldEqual, "team")
+		}
+	})
+
+	Convey("Person on call without contacts of the registered senders", t, func() {
+		dataBase.EXPECT().GetOnCallRotation("team").Return(rotation, nil)
+		dataBase.EXPECT().GetUserContactIDs("bob").Return([]string{}, nil)
+
+		_, err := decoder.UnwrapRotation(contact, true, senders)
+		So(err, ShouldHaveSameTypeAs, ErrNoOnCallContact{})
+	})
+}
+
+func TestHTTPOnCallProvider(t *testing.T) {
+	test_helpers.InitTestLogging()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/on-call/team" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"main": [{"login": "alice", "contacts": {"telegram": "@alice"}}], "backup": [{"login": "bob"}]}`))
+	}))
+	defer server.Close()
+
+	Convey("Main and backup people are returned", t, func() {
+		provider := &httpOnCallProvider{
+			url:     server.URL + "/on-call/{service}",
+			headers: map[string]string{"Authorization": "Bearer secret"},
+			logger:  test_helpers.GetTestLogger(),
+		}
+		duty, err := provider.GetOnCall("team")
+		So(err, ShouldBeNil)
+		So(duty.Duty, ShouldResemble, []moira.DutyItem{{Login: "alice", Contacts: map[string]string{"telegram": "@alice"}}})
+		So(duty.Backup, ShouldResemble, []moira.DutyItem{{Login: "bob"}})
+	})
+
+	Convey("Failed request is an error", t, func() {
+		provider := &httpOnCallProvider{
+			url:    server.URL + "/on-call/{service}",
+			logger: test_helpers.GetTestLogger(),
+		}
+		_, err := provider.GetOnCall("team")
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -101,6 +101,9 @@ func (w *FetchEscalationsWorker) processScheduledEscalations() error {
 		if err != nil {
 			return err
 		}
+		for _, rotationID := range escalation.Escalation.Rotations {
+			contacts = append(contacts, moira.NewRotationContact(rotationID, ""))
+		}
 
 		for i, contact := range contacts {
 			if contact == nil {
@@ -124,6 +127,7 @@ func (w *FetchEscalationsWorker) processScheduledEscalations() error {
 					Throttled: false,
 					FailCount: 0,
 					NeedAck:   true,
+					Escalated: true,
 				}
 			}
 			p.Events = append(p.Events, escalation.Event)
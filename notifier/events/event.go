@@ -112,7 +112,8 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 			event.DelayedForAncestor = true
 		}
 
-		if !event.DelayedForAncestor {
+		// the events which only update the dashboards are neither delayed nor bound to the ancestors
+		if !event.DelayedForAncestor && !event.DashboardOnly {
 			var (
 				depth       = 0
 				err   error = nil
@@ -142,7 +143,7 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 			}
 		}
 
-		if event.DelayedForAncestor && event.AncestorTriggerID == "" {
+		if event.DelayedForAncestor && !event.DashboardOnly && event.AncestorTriggerID == "" {
 			events, ancestors, err := worker.processTriggerAncestors(&event)
 			switch {
 			case err != nil:
@@ -219,7 +220,7 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 		}
 
 		next, throttled := worker.Scheduler.GetDeliveryInfo(time.Now(), event, false, 0)
-		hasEscalations := len(subscription.Escalations) > 0
+		hasEscalations := len(subscription.Escalations) > 0 && !event.DashboardOnly
 		needAck := false
 		if hasEscalations {
 			if err := worker.Database.MaybeUpdateEscalationsOfSubscription(subscription); err != nil {
@@ -233,13 +234,22 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 			needAck = event.State == moira.ERROR || event.State == moira.WARN
 		}
 
-		worker.Logger.DebugF("Processing contact ids %v for subscription %s", subscription.Contacts, subscription.ID)
+		worker.Logger.DebugF("Processing contact ids %v and rotations %v for subscription %s", subscription.Contacts, subscription.Rotations, subscription.ID)
+		contacts := make([]moira.ContactData, 0, len(subscription.Contacts)+len(subscription.Rotations))
 		for _, contactID := range subscription.Contacts {
 			contact, err := worker.Database.GetContact(contactID)
 			if err != nil {
 				worker.Logger.WarnF("Failed to get contact: %s, skip handling it, error: %v", contactID, err)
 				continue
 			}
+			contacts = append(contacts, contact)
+		}
+		// the rotations are resolved to the people on call when the notifications are sent
+		for _, rotationID := range subscription.Rotations {
+			contacts = append(contacts, *moira.NewRotationContact(rotationID, subscription.User))
+		}
+
+		for _, contact := range contacts {
 			event.SubscriptionID = &subscription.ID
 
 			notification := worker.Scheduler.ScheduleNotification(next, throttled, event, triggerData, contact, 0, needAck)
@@ -284,7 +294,8 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 // and (probably) reduces the list of saturation methods applicable
 // for this moira.NotificationEvent
 func (worker *FetchEventsWorker) filterSaturationForEvent(event *moira.NotificationEvent, saturation []moira.Saturation) []moira.Saturation {
-	if saturation == nil {
+	// the events which only update the dashboards are not saturated
+	if saturation == nil || event.DashboardOnly {
 		return nil
 	}
 
@@ -62,9 +62,9 @@ func (worker *FetchNotificationsWorker) processScheduledNotifications() error {
 	notificationPackages := make(map[string]*notifier.NotificationPackage)
 	for _, notification := range notifications {
 		eventContextString := notification.Event.Context.MustMarshal()
-		packageKey := fmt.Sprintf("%s:%s:%s:%t:%s",
+		packageKey := fmt.Sprintf("%s:%s:%s:%t:%t:%s",
 			notification.Contact.Type, notification.Contact.Value,
-			notification.Event.TriggerID, notification.NeedAck,
+			notification.Event.TriggerID, notification.NeedAck, notification.Escalated,
 			eventContextString,
 		)
 		p, found := notificationPackages[packageKey]
@@ -76,6 +76,7 @@ func (worker *FetchNotificationsWorker) processScheduledNotifications() error {
 				Throttled: notification.Throttled,
 				FailCount: notification.SendFail,
 				NeedAck:   notification.NeedAck,
+				Escalated: notification.Escalated,
 			}
 		}
 		p.Events = append(p.Events, notification.Event)
@@ -23,6 +23,7 @@ type NotificationPackage struct {
 	Throttled  bool                     `json:"throttled"`
 	DontResend bool                     `json:"dont_resend"`
 	NeedAck    bool                     `json:"need_ack"`
+	Escalated  bool                     `json:"escalated"` // sent by the escalation because nobody has acknowledged the problem
 
 	OverridingTriggerID string `json:"overriding_trigger_id"`
 	OverridingMetric    string `json:"overriding_metric"`
@@ -65,13 +66,13 @@ type StandardNotifier struct {
 
 // NewNotifier is initializer for StandardNotifier
 func NewNotifier(database moira.Database, config Config, metrics *metrics.NotifierMetrics) *StandardNotifier {
-	silencerWorker := silencer.NewSilencer(database, nil)
+	silencerWorker := silencer.NewSilencer(database, config.Inventory)
 	silencerWorker.Start()
 
 	logger := logging.GetLogger("")
 	return &StandardNotifier{
 		config:          config,
-		contactsDecoder: contacts.NewDecoder(database, logger, config.DutyApiToken, config.DutyUrl),
+		contactsDecoder: contacts.NewDecoder(database, logger, config.OnCall),
 		database:        database,
 		logger:          logger,
 		senders:         make(map[string]chan NotificationPackage),
@@ -87,6 +88,11 @@ const maxDutyTries = 3
 
 // Send is realization of StandardNotifier Send functionality
 func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync.WaitGroup) {
+	if pkg.Contact.Type == moira.RotationContactType {
+		notifier.sendToRotation(pkg, waitGroup)
+		return
+	}
+
 	ch, found := notifier.senders[pkg.Contact.Type]
 	if !found {
 		notifier.resend(pkg, fmt.Sprintf("Unknown contact type '%s' [%s]", pkg.Contact.Type, pkg))
@@ -110,6 +116,16 @@ func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync
 				logger.InfoE("Event is filtered due to tags", event)
 				continue
 			}
+			subject := silencer.Subject{
+				TriggerID: pkg.Trigger.ID,
+				Tags:      pkg.Trigger.Tags,
+				Metric:    event.Metric,
+				State:     event.State,
+			}
+			if notifier.silencer.IsSubjectSilenced(subject, event.Timestamp) {
+				logger.InfoE("Event is filtered by silent matcher", event)
+				continue
+			}
 			eventsFiltered = append(eventsFiltered, event)
 		}
 
@@ -128,6 +144,37 @@ func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync
 	}(pkg)
 }
 
+// sendToRotation sends the package to the contacts of the person on call by the rotation
+func (notifier *StandardNotifier) sendToRotation(pkg *NotificationPackage, waitGroup *sync.WaitGroup) {
+	logger := logging.GetLogger(pkg.Trigger.ID)
+	rotationContacts, err := notifier.contactsDecoder.UnwrapRotation(&pkg.Contact, pkg.Escalated, notifier.GetSenders())
+	if err != nil {
+		logger.ErrorE("Failed to resolve rotation", map[string]interface{}{
+			"error_message": err.Error(),
+			"package":       pkg,
+		})
+		switch err.(type) {
+		case contacts.ErrNoOnCallContact:
+			logger.Error("Person on call has no contacts of the registered senders, drop the package")
+		case contacts.ErrNobodyOnDuty:
+			if pkg.FailCount < maxDutyTries {
+				notifier.resend(pkg, err.Error())
+			} else {
+				logger.Error(fmt.Sprintf("Nobody is on call (%d attempts exceeded), drop package", maxDutyTries))
+			}
+		default:
+			notifier.resend(pkg, err.Error())
+		}
+		return
+	}
+
+	for _, contact := range rotationContacts {
+		contactPkg := *pkg
+		contactPkg.Contact = contact
+		notifier.Send(&contactPkg, waitGroup)
+	}
+}
+
 // GetSenders get hash of registered notifier senders
 func (notifier *StandardNotifier) GetSenders() map[string]bool {
 	hash := make(map[string]bool)
@@ -160,6 +207,7 @@ func (notifier *StandardNotifier) resend(pkg *NotificationPackage, reason string
 	for _, event := range pkg.Events {
 		next, throttled := notifier.scheduler.GetDeliveryInfo(time.Now(), event, pkg.Throttled, failCount)
 		notification := notifier.scheduler.ScheduleNotification(next, throttled, event, pkg.Trigger, pkg.Contact, failCount, pkg.NeedAck)
+		notification.Escalated = pkg.Escalated
 		if err := notifier.database.AddNotification(notification); err != nil {
 			logger.Error(fmt.Sprintf("Failed to save scheduled notification: %v", err))
 		}
@@ -173,6 +221,7 @@ func (notifier *StandardNotifier) run(sender moira.Sender, ch chan NotificationP
 		go func(pkg NotificationPackage) {
 			logger := logging.GetLogger(pkg.Trigger.ID)
 			senderWI, inheritance := sender.(moira.SenderWithInheritance)
+			_, keepsDashboards := sender.(moira.DashboardSender)
 
 			logger.InfoE("Sending notification package", map[string]interface{}{
 				"inheritance": inheritance,
@@ -186,6 +235,8 @@ func (notifier *StandardNotifier) run(sender moira.Sender, ch chan NotificationP
 				// for non-inheritance sender, delete forced notifications
 				// both is done so that users won't receive duplicate notifications
 				eventOK := (inheritance && !event.OverriddenByAncestor) || (!inheritance && !event.IsForceSent)
+				// the events which only update the dashboards are useless for the others
+				eventOK = eventOK && (keepsDashboards || !event.DashboardOnly)
 				if eventOK {
 					eventsFiltered = append(eventsFiltered, event)
 				}
@@ -207,7 +258,7 @@ func (notifier *StandardNotifier) run(sender moira.Sender, ch chan NotificationP
 			}
 
 			// if contact value is macro (duty, deployer) then expand it to the list of actual contacts
-			replacements, err := notifier.contactsDecoder.UnwrapContact(&pkg.Contact, pkg.Events)
+			replacements, err := notifier.contactsDecoder.UnwrapContact(&pkg.Contact, pkg.Events, pkg.Escalated)
 			if err != nil {
 				logger.ErrorE("Failed to unwrap contact", map[string]interface{}{
 					"error_message": err.Error(),
@@ -221,6 +272,12 @@ func (notifier *StandardNotifier) run(sender moira.Sender, ch chan NotificationP
 						logger.Error(fmt.Sprintf("Nobody is on duty (%d attempts exceeded), drop package", maxDutyTries))
 						resend = false
 					}
+				case contacts.ErrNoOnCallContact:
+					logger.Error("Person on duty has no contact of the type, drop the package")
+					resend = false
+				case contacts.ErrUnknownOnCallProvider:
+					logger.Error("Unknown on call provider, drop the package")
+					resend = false
 				case contacts.ErrNoDeployers:
 					logger.Error("Package has no deployers, drop it")
 					resend = false
@@ -73,6 +73,59 @@ func TestFailSendEvent(t *testing.T) {
 	time.Sleep(time.Second * 2)
 }
 
+func TestSendToRotation(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	var eventsData moira.NotificationEvents = []moira.NotificationEvent{event}
+	pkg := NotificationPackage{
+		Events:    eventsData,
+		Contact:   *moira.NewRotationContact("team", "user"),
+		NeedAck:   true,
+		Escalated: true,
+	}
+
+	dataBase.EXPECT().GetOnCallRotation("team").Return(&moira.OnCallRotation{
+		ID: "team",
+		Layers: []moira.OnCallLayer{{Participants: []moira.OnCallParticipant{
+			{Login: "alice", Contacts: map[string]string{"test": "alice", "unknown contact": "alice"}},
+			{Login: "bob", Contacts: map[string]string{"test": "bob", "unknown contact": "bob"}},
+		}, Start: time.Now().Unix()}},
+	}, nil)
+	dataBase.EXPECT().GetUserContactIDs("bob").Return([]string{}, nil)
+	backupContact := moira.ContactData{Type: "test", Value: "bob", ID: "rotation:team", User: "user"}
+	sender.EXPECT().SendEvents(eventsData, backupContact, pkg.Trigger, pkg.Throttled, pkg.NeedAck).Return(nil)
+
+	var wg sync.WaitGroup
+	notif.Send(&pkg, &wg)
+	wg.Wait()
+	time.Sleep(time.Millisecond * 100)
+}
+
+func TestSkipDashboardOnlyEvents(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	removed := moira.NotificationEvent{
+		Metric:        "generate.event.2",
+		State:         moira.OK,
+		OldState:      moira.OK,
+		TriggerID:     event.TriggerID,
+		MetricRemoved: true,
+		DashboardOnly: true,
+	}
+	pkg := NotificationPackage{
+		Events:  []moira.NotificationEvent{event, removed},
+		Contact: moira.ContactData{Type: "test"},
+	}
+	sender.EXPECT().SendEvents(moira.NotificationEvents{event}, pkg.Contact, pkg.Trigger, pkg.Throttled, pkg.NeedAck).Return(nil)
+
+	var wg sync.WaitGroup
+	notif.Send(&pkg, &wg)
+	wg.Wait()
+	time.Sleep(time.Millisecond * 100)
+}
+
 func configureNotifier(t *testing.T) {
 	test_helpers.InitTestLogging()
 	notifierMetrics := metrics.NewNotifierMetrics()
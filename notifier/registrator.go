@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
 	"go.avito.ru/DO/moira/senders/mail"
 	"go.avito.ru/DO/moira/senders/pushover"
 	"go.avito.ru/DO/moira/senders/script"
@@ -16,6 +17,7 @@ import (
 
 // RegisterSenders watch on senders config and register all configured senders
 func (notifier *StandardNotifier) RegisterSenders(connector moira.Database) error {
+	authorizer := api.NewAuthorizer(notifier.config.Authorization, notifier.config.SuperUsers, connector)
 	for _, senderSettings := range notifier.config.Senders {
 		senderSettings["front_uri"] = notifier.config.FrontURL
 		switch senderSettings["type"] {
@@ -36,7 +38,7 @@ func (notifier *StandardNotifier) RegisterSenders(connector moira.Database) erro
 				notifier.logger.Fatal(fmt.Sprintf("Can not register sender %s: %s", senderSettings["type"], err))
 			}
 		case "telegram":
-			if err := notifier.RegisterSender(senderSettings, &telegram.Sender{DataBase: connector}); err != nil {
+			if err := notifier.RegisterSender(senderSettings, &telegram.Sender{DataBase: connector, Authorizer: authorizer}); err != nil {
 				notifier.logger.Fatal(fmt.Sprintf("Can not register sender %s: %s", senderSettings["type"], err))
 			}
 		case "twilio sms":
@@ -63,7 +63,7 @@ func (scheduler *StandardScheduler) GetDeliveryInfo(
 		next = now.Add(scheduler.CalculateBackoff(failCount))
 		throttled = throttledOld
 	} else {
-		if event.State == moira.TEST {
+		if event.State == moira.TEST || event.DashboardOnly {
 			next = now
 			throttled = false
 		} else {
@@ -0,0 +1,257 @@
+package moira
+
+import (
+	"fmt"
+	"time"
+)
+
+// RotationContactType is the type of the contact which stands for the rotation targeted by the subscription or the escalation,
+// the notifier resolves it to the contacts of the person on call when the notification is sent
+const RotationContactType = "rotation"
+
+// NewRotationContact returns the contact which stands for the rotation targeted by the subscription of the user
+func NewRotationContact(rotationID, user string) *ContactData {
+	return &ContactData{
+		Type:  RotationContactType,
+		Value: rotationID,
+		ID:    RotationContactType + ":" + rotationID,
+		User:  user,
+	}
+}
+
+// defaultShiftDays is how long the shift lasts if the layer doesn't tell
+const defaultShiftDays = 7
+
+// OnCallParticipant is the person on call: the login and the values of their contacts by the contact type
+// (slack, telegram, mail, twilio sms, twilio voice), phone is used by both twilio types unless they are set.
+// The subscriptions to the rotation use the Moira contacts of the login first, these values fill in the other types
+type OnCallParticipant struct {
+	Login    string            `json:"login"`
+	Contacts map[string]string `json:"contacts,omitempty"`
+}
+
+// OnCallOverride puts the participant on call instead of the scheduled one in [From, Until)
+type OnCallOverride struct {
+	OnCallParticipant
+	From  int64 `json:"from"`
+	Until int64 `json:"until"`
+}
+
+// OnCallLayer is the group of participants taking turns every ShiftDays starting from the day of Start,
+// the layer can be limited to the daily window [From, Until) such as the business hours or the nights
+type OnCallLayer struct {
+	Name         string              `json:"name,omitempty"`
+	Participants []OnCallParticipant `json:"participants"`
+	Start        int64               `json:"start"`
+	ShiftDays    int                 `json:"shift_days,omitempty"` // a week if not set
+	From         string              `json:"from,omitempty"`       // HH:MM
+	Until        string              `json:"until,omitempty"`      // HH:MM, earlier than From for the windows over midnight
+}
+
+// OnCallRotation is the on call schedule maintained in Moira: the participants take turns every week starting from Start,
+// the shifts are handed off at Handoff in Timezone, the later layers take precedence over the participants and the earlier layers
+// while they are active and the overrides take precedence over all of them
+type OnCallRotation struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name,omitempty"`
+	Timezone     string              `json:"timezone,omitempty"` // IANA name, UTC by default
+	Handoff      string              `json:"handoff,omitempty"`  // HH:MM, the time of Start of every layer by default
+	Participants []OnCallParticipant `json:"participants"`
+	Start        int64               `json:"start"`
+	Layers       []OnCallLayer       `json:"layers,omitempty"`
+	Overrides    []OnCallOverride    `json:"overrides,omitempty"`
+}
+
+// Validate checks that the rotation can be saved
+func (rotation *OnCallRotation) Validate() error {
+	if rotation.ID == "" {
+		return fmt.Errorf("rotation ID is required")
+	}
+	if _, err := time.LoadLocation(rotation.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %s", rotation.Timezone)
+	}
+	if _, err := parseClock(rotation.Handoff); err != nil {
+		return fmt.Errorf("handoff: %v", err)
+	}
+	if len(rotation.Participants) == 0 && len(rotation.Layers) == 0 {
+		return fmt.Errorf("rotation must have participants or layers")
+	}
+	for _, participant := range rotation.Participants {
+		if participant.Login == "" {
+			return fmt.Errorf("participant login is required")
+		}
+	}
+
+	for i, layer := range rotation.Layers {
+		if len(layer.Participants) == 0 {
+			return fmt.Errorf("layer #%d must have participants", i+1)
+		}
+		for _, participant := range layer.Participants {
+			if participant.Login == "" {
+				return fmt.Errorf("layer #%d: participant login is required", i+1)
+			}
+		}
+		if layer.ShiftDays < 0 {
+			return fmt.Errorf("layer #%d: shift days can't be negative", i+1)
+		}
+		if (layer.From == "") != (layer.Until == "") {
+			return fmt.Errorf("layer #%d: both from and until must be set", i+1)
+		}
+		if _, err := parseClock(layer.From); err != nil {
+			return fmt.Errorf("layer #%d: from: %v", i+1, err)
+		}
+		if _, err := parseClock(layer.Until); err != nil {
+			return fmt.Errorf("layer #%d: until: %v", i+1, err)
+		}
+	}
+
+	for _, override := range rotation.Overrides {
+		if override.Login == "" {
+			return fmt.Errorf("override login is required")
+		}
+		if override.From >= override.Until {
+			return fmt.Errorf("override of %s must end after it starts", override.Login)
+		}
+	}
+	return nil
+}
+
+// GetOnCall returns the main and the backup participants on call at ts and when the main one's duty ends:
+// the backup is the next participant of the layer or the scheduled participant if the main one overrides them
+func (rotation *OnCallRotation) GetOnCall(ts int64) (main, backup *OnCallParticipant, end int64) {
+	for i := range rotation.Overrides {
+		override := &rotation.Overrides[i]
+		if override.From <= ts && ts < override.Until {
+			scheduled, _, _ := rotation.getScheduled(ts)
+			if scheduled != nil && scheduled.Login != override.Login {
+				backup = scheduled
+			}
+			return &override.OnCallParticipant, backup, override.Until
+		}
+	}
+
+	main, backup, end = rotation.getScheduled(ts)
+	if main != nil {
+		// the override may start before the shift ends
+		for _, override := range rotation.Overrides {
+			if ts < override.From && override.From < end {
+				end = override.From
+			}
+		}
+	}
+	return main, backup, end
+}
+
+// getScheduled returns who is on call by the layers, nobody is on call before the first shift
+func (rotation *OnCallRotation) getScheduled(ts int64) (main, backup *OnCallParticipant, end int64) {
+	location, err := time.LoadLocation(rotation.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	handoff := -1
+	if rotation.Handoff != "" {
+		handoff, _ = parseClock(rotation.Handoff)
+	}
+	now := time.Unix(ts, 0).In(location)
+
+	// the participants of the rotation are the lowest layer
+	layers := rotation.Layers
+	if len(rotation.Participants) > 0 {
+		layers = append([]OnCallLayer{{Participants: rotation.Participants, Start: rotation.Start}}, layers...)
+	}
+
+	// the higher layer which isn't active yet ends the shift of the lower one when it becomes active
+	nextActive := int64(0)
+	for i := len(layers) - 1; i >= 0; i-- {
+		layerMain, layerBackup, layerEnd, next := layers[i].getOnCall(now, handoff, location)
+		if layerMain != nil {
+			if nextActive > 0 && nextActive < layerEnd {
+				layerEnd = nextActive
+			}
+			return layerMain, layerBackup, layerEnd
+		}
+		if next > 0 && (nextActive == 0 || next < nextActive) {
+			nextActive = next
+		}
+	}
+	return nil, nil, 0
+}
+
+// getOnCall returns who is on call by the layer and when their duty ends, the shifts are handed off at the time of Start
+// unless handoff (minutes since midnight) is given, if the layer isn't active it returns when the layer becomes active
+func (layer *OnCallLayer) getOnCall(now time.Time, handoff int, location *time.Location) (main, backup *OnCallParticipant, end, next int64) {
+	qty := len(layer.Participants)
+	if qty == 0 {
+		return nil, nil, 0, 0
+	}
+	shiftDays := layer.ShiftDays
+	if shiftDays == 0 {
+		shiftDays = defaultShiftDays
+	}
+
+	start := time.Unix(layer.Start, 0).In(location)
+	if handoff < 0 {
+		handoff = start.Hour()*60 + start.Minute()
+	}
+	startDay := getShiftDay(start, handoff)
+	day := getShiftDay(now, handoff)
+	if day < startDay {
+		return nil, nil, 0, getDayClock(startDay, handoff, location).Unix()
+	}
+
+	shift := (day - startDay) / shiftDays
+	shiftEnd := getDayClock(startDay+(shift+1)*shiftDays, handoff, location)
+	if layer.From != "" {
+		from, _ := parseClock(layer.From)
+		until, _ := parseClock(layer.Until)
+		clock := now.Hour()*60 + now.Minute()
+		inWindow := (from <= clock && clock < until) || (until < from && (clock >= from || clock < until))
+		if !inWindow {
+			return nil, nil, 0, getNextClock(now, from).Unix()
+		}
+		if windowEnd := getNextClock(now, until); windowEnd.Before(shiftEnd) {
+			shiftEnd = windowEnd
+		}
+	}
+
+	index := shift % qty
+	main = &layer.Participants[index]
+	if qty > 1 {
+		backup = &layer.Participants[(index+1)%qty]
+	}
+	return main, backup, shiftEnd.Unix(), 0
+}
+
+// getShiftDay returns the number of the day (since epoch) which the shift going at t has started on
+func getShiftDay(t time.Time, handoff int) int {
+	year, month, day := t.Add(-time.Duration(handoff) * time.Minute).Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+}
+
+// getDayClock returns the moment of the day (since epoch) at the clock (minutes since midnight) in the location
+func getDayClock(day, clock int, location *time.Location) time.Time {
+	year, month, date := time.Unix(int64(day)*24*60*60, 0).UTC().Date()
+	return time.Date(year, month, date, clock/60, clock%60, 0, 0, location)
+}
+
+// getNextClock returns the nearest moment after now at the clock (minutes since midnight)
+func getNextClock(now time.Time, clock int) time.Time {
+	year, month, day := now.Date()
+	result := time.Date(year, month, day, clock/60, clock%60, 0, 0, now.Location())
+	if !result.After(now) {
+		result = time.Date(year, month, day+1, clock/60, clock%60, 0, 0, now.Location())
+	}
+	return result
+}
+
+// parseClock parses HH:MM to minutes since midnight, empty clock is midnight
+func parseClock(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not HH:MM", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
@@ -0,0 +1,136 @@
+package moira
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOnCallRotation_Validate(t *testing.T) {
+	layer := OnCallLayer{Participants: []OnCallParticipant{{Login: "alice"}}}
+
+	Convey("Invalid rotations", t, func() {
+		So((&OnCallRotation{Layers: []OnCallLayer{layer}}).Validate(), ShouldNotBeNil)
+		So((&OnCallRotation{ID: "team"}).Validate(), ShouldNotBeNil)
+		So((&OnCallRotation{ID: "team", Layers: []OnCallLayer{layer}, Timezone: "Mars/Olympus"}).Validate(), ShouldNotBeNil)
+		So((&OnCallRotation{ID: "team", Layers: []OnCallLayer{layer}, Handoff: "25:00"}).Validate(), ShouldNotBeNil)
+		So((&OnCallRotation{ID: "team", Layers: []OnCallLayer{{}}}).Validate(), ShouldNotBeNil)
+		So((&OnCallRotation{ID: "team", Layers: []OnCallLayer{{Participants: layer.Participants, From: "09:00"}}}).Validate(), ShouldNotBeNil)
+		So((&OnCallRotation{ID: "team", Layers: []OnCallLayer{layer}, Overrides: []OnCallOverride{
+			{OnCallParticipant: OnCallParticipant{Login: "bob"}, From: 10, Until: 10},
+		}}).Validate(), ShouldNotBeNil)
+	})
+
+	Convey("Valid rotation", t, func() {
+		So((&OnCallRotation{ID: "team", Layers: []OnCallLayer{layer}, Timezone: "Europe/Moscow", Handoff: "10:00"}).Validate(), ShouldBeNil)
+		So((&OnCallRotation{ID: "team", Participants: layer.Participants}).Validate(), ShouldBeNil)
+	})
+}
+
+func TestOnCallRotation_GetOnCall(t *testing.T) {
+	const week = 7 * 24 * 60 * 60
+	rotation := &OnCallRotation{
+		ID:           "team",
+		Participants: []OnCallParticipant{{Login: "alice"}, {Login: "bob"}, {Login: "carol"}},
+		Start:        week,
+		Overrides: []OnCallOverride{
+			{OnCallParticipant: OnCallParticipant{Login: "dave"}, From: 5*week + 100, Until: 5*week + 200},
+		},
+	}
+
+	Convey("Participants take turns every week", t, func() {
+		main, backup, end := rotation.GetOnCall(week)
+		So(main.Login, ShouldEqual, "alice")
+		So(backup.Login, ShouldEqual, "bob")
+		So(end, ShouldEqual, 2*week)
+
+		main, backup, end = rotation.GetOnCall(4*week - 1)
+		So(main.Login, ShouldEqual, "carol")
+		So(backup.Login, ShouldEqual, "alice")
+		So(end, ShouldEqual, 4*week)
+	})
+
+	Convey("Nobody is on call before the first shift", t, func() {
+		main, backup, _ := rotation.GetOnCall(week - 1)
+		So(main, ShouldBeNil)
+		So(backup, ShouldBeNil)
+
+		main, backup, _ = (&OnCallRotation{}).GetOnCall(week)
+		So(main, ShouldBeNil)
+		So(backup, ShouldBeNil)
+	})
+
+	Convey("Override puts the scheduled participant to backup", t, func() {
+		main, _, end := rotation.GetOnCall(5 * week)
+		So(main.Login, ShouldEqual, "bob")
+		So(end, ShouldEqual, 5*week+100)
+
+		main, backup, end := rotation.GetOnCall(5*week + 150)
+		So(main.Login, ShouldEqual, "dave")
+		So(backup.Login, ShouldEqual, "bob")
+		So(end, ShouldEqual, 5*week+200)
+	})
+}
+
+func TestOnCallRotation_GetOnCallByLayers(t *testing.T) {
+	location, _ := time.LoadLocation("Europe/Moscow")
+	at := func(day, hour, minute int) int64 {
+		// Monday, 5 January 2026
+		return time.Date(2026, time.January, 5+day, hour, minute, 0, 0, location).Unix()
+	}
+
+	rotation := &OnCallRotation{
+		ID:       "team",
+		Timezone: "Europe/Moscow",
+		Handoff:  "10:00",
+		Layers: []OnCallLayer{
+			{Participants: []OnCallParticipant{{Login: "alice"}, {Login: "bob"}, {Login: "carol"}}, Start: at(0, 12, 0)},
+			{Participants: []OnCallParticipant{{Login: "night"}}, Start: at(0, 12, 0), ShiftDays: 1, From: "22:00", Until: "07:00"},
+		},
+		Overrides: []OnCallOverride{
+			{OnCallParticipant: OnCallParticipant{Login: "dave"}, From: at(15, 12, 0), Until: at(15, 14, 0)},
+		},
+	}
+
+	Convey("Participants take turns every week at the handoff", t, func() {
+		main, backup, end := rotation.GetOnCall(at(0, 12, 0))
+		So(main.Login, ShouldEqual, "alice")
+		So(backup.Login, ShouldEqual, "bob")
+		So(end, ShouldEqual, at(0, 22, 0))
+
+		main, backup, end = rotation.GetOnCall(at(7, 9, 59))
+		So(main.Login, ShouldEqual, "alice")
+		So(end, ShouldEqual, at(7, 10, 0))
+
+		main, backup, _ = rotation.GetOnCall(at(14, 10, 0))
+		So(main.Login, ShouldEqual, "carol")
+		So(backup.Login, ShouldEqual, "alice")
+	})
+
+	Convey("Nobody is on call before the first shift", t, func() {
+		main, _, _ := rotation.GetOnCall(at(0, 9, 0))
+		So(main, ShouldBeNil)
+	})
+
+	Convey("Higher layer takes precedence within its window", t, func() {
+		main, backup, end := rotation.GetOnCall(at(1, 23, 0))
+		So(main.Login, ShouldEqual, "night")
+		So(backup, ShouldBeNil)
+		So(end, ShouldEqual, at(2, 7, 0))
+
+		main, _, _ = rotation.GetOnCall(at(2, 7, 0))
+		So(main.Login, ShouldEqual, "alice")
+	})
+
+	Convey("Override puts the scheduled participant to backup", t, func() {
+		main, _, end := rotation.GetOnCall(at(15, 11, 0))
+		So(main.Login, ShouldEqual, "carol")
+		So(end, ShouldEqual, at(15, 12, 0))
+
+		main, backup, end := rotation.GetOnCall(at(15, 13, 0))
+		So(main.Login, ShouldEqual, "dave")
+		So(backup.Login, ShouldEqual, "carol")
+		So(end, ShouldEqual, at(15, 14, 0))
+	})
+}
@@ -56,11 +56,12 @@ func BenchmarkProcessIncomingMetric(b *testing.B) {
 func generateMetrics(patterns *filter.PatternStorage, count int) []string {
 	result := make([]string, 0, count)
 	timestamp := time.Now()
+	tree := patterns.GetPatternTree()
 	i := 0
 	for i < count {
 		parts := make([]string, 0, 16)
 
-		node := patterns.PatternTree.Children[rand.Intn(len(patterns.PatternTree.Children))]
+		node := tree.Children[rand.Intn(len(tree.Children))]
 		matched := rand.Float64() < 0.02
 		level := float64(0)
 		for {
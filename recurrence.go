@@ -0,0 +1,230 @@
+package moira
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxOccurrences limits the number of occurrences which can be listed at once
+const maxOccurrences = 1000
+
+// Recurrence makes maintenance window recurring: the window starts at each occurrence
+// of Cron schedule (minute hour day-of-month month day-of-week) in Timezone and lasts Duration seconds
+type Recurrence struct {
+	Cron     string `json:"cron"`
+	Duration int64  `json:"duration"`
+	Timezone string `json:"timezone,omitempty"` // IANA name, UTC by default
+}
+
+// Validate checks that the recurrence can be used
+func (recurrence *Recurrence) Validate() error {
+	if recurrence.Duration <= 0 {
+		return fmt.Errorf("recurrence duration must be positive")
+	}
+	_, err := recurrence.getSchedule()
+	return err
+}
+
+// ActiveAt can tell whether any occurrence covers ts and when the latest of them ends
+func (recurrence *Recurrence) ActiveAt(ts int64) (active bool, until int64) {
+	schedule, err := recurrence.getSchedule()
+	if err != nil {
+		return false, 0
+	}
+
+	limit := time.Unix(ts-recurrence.Duration+1, 0).In(schedule.location)
+	start, ok := schedule.prev(time.Unix(ts, 0).In(schedule.location), limit)
+	if !ok {
+		return false, 0
+	}
+	return true, start.Unix() + recurrence.Duration
+}
+
+// Occurrences returns [start, end) of all occurrences which overlap with [from, until)
+func (recurrence *Recurrence) Occurrences(from, until int64) [][2]int64 {
+	schedule, err := recurrence.getSchedule()
+	if err != nil {
+		return nil
+	}
+
+	result := make([][2]int64, 0)
+	limit := time.Unix(until-1, 0).In(schedule.location)
+	current := time.Unix(from-recurrence.Duration+1, 0).In(schedule.location)
+	for len(result) < maxOccurrences {
+		start, ok := schedule.next(current, limit)
+		if !ok {
+			break
+		}
+		result = append(result, [2]int64{start.Unix(), start.Unix() + recurrence.Duration})
+		current = start.Add(time.Minute)
+	}
+	return result
+}
+
+var (
+	cronSchedulesLock sync.Mutex
+	cronSchedules     = make(map[string]*cronSchedule)
+)
+
+// getSchedule returns parsed schedule, parsed schedules are cached since the same recurrences are checked over and over
+func (recurrence *Recurrence) getSchedule() (*cronSchedule, error) {
+	key := recurrence.Cron + "|" + recurrence.Timezone
+
+	cronSchedulesLock.Lock()
+	defer cronSchedulesLock.Unlock()
+
+	if schedule, ok := cronSchedules[key]; ok {
+		return schedule, nil
+	}
+	schedule, err := parseCronSchedule(recurrence.Cron, recurrence.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	cronSchedules[key] = schedule
+	return schedule, nil
+}
+
+// cronSchedule is parsed cron expression
+type cronSchedule struct {
+	minute, hour, dom, month, dow []bool
+	domAny, dowAny                bool
+	location                      *time.Location
+}
+
+func parseCronSchedule(expression, timezone string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields", expression)
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone '%s'", timezone)
+		}
+	}
+
+	schedule := &cronSchedule{
+		domAny:   fields[2] == "*",
+		dowAny:   fields[4] == "*",
+		location: location,
+	}
+
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// both 0 and 7 stand for Sunday
+	schedule.dow[0] = schedule.dow[0] || schedule.dow[7]
+
+	return schedule, nil
+}
+
+// parseCronField parses comma separated list of values, ranges and steps, e.g. "*/15", "1-5", "0,30"
+func parseCronField(field string, min, max int) ([]bool, error) {
+	result := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if pos := strings.Index(part, "/"); pos >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[pos+1:]); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in cron field '%s'", field)
+			}
+			part = part[:pos]
+		}
+
+		low, high := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("invalid value in cron field '%s'", field)
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid range in cron field '%s'", field)
+				}
+			} else if step > 1 {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("cron field '%s' is out of range %d-%d", field, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			result[value] = true
+		}
+	}
+	return result, nil
+}
+
+// dayMatches applies the standard cron rule: if both day fields are restricted then either of them must match
+func (schedule *cronSchedule) dayMatches(t time.Time) bool {
+	domMatches, dowMatches := schedule.dom[t.Day()], schedule.dow[int(t.Weekday())]
+	if !schedule.domAny && !schedule.dowAny {
+		return domMatches || dowMatches
+	}
+	return domMatches && dowMatches
+}
+
+// prev returns the latest occurrence which is not later than t and not earlier than limit
+func (schedule *cronSchedule) prev(t, limit time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute)
+	for !t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case !schedule.month[int(month)]:
+			t = time.Date(year, month, 1, 0, 0, 0, 0, schedule.location).Add(-time.Minute)
+		case !schedule.dayMatches(t):
+			t = time.Date(year, month, day, 0, 0, 0, 0, schedule.location).Add(-time.Minute)
+		case !schedule.hour[t.Hour()]:
+			t = time.Date(year, month, day, t.Hour(), 0, 0, 0, schedule.location).Add(-time.Minute)
+		case !schedule.minute[t.Minute()]:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// next returns the earliest occurrence which is not earlier than t and not later than limit
+func (schedule *cronSchedule) next(t, limit time.Time) (time.Time, bool) {
+	if truncated := t.Truncate(time.Minute); truncated.Before(t) {
+		t = truncated.Add(time.Minute)
+	}
+	for !t.After(limit) {
+		year, month, day := t.Date()
+		switch {
+		case !schedule.month[int(month)]:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, schedule.location)
+		case !schedule.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, schedule.location)
+		case !schedule.hour[t.Hour()]:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, schedule.location)
+		case !schedule.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
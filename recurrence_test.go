@@ -0,0 +1,146 @@
+package moira
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecurrence(t *testing.T) {
+	// Monday, 5 January 2026
+	monday := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC).Unix()
+	const hour, day = int64(3600), int64(24 * 3600)
+
+	Convey("Invalid recurrences", t, func() {
+		So((&Recurrence{Cron: "0 2 * *", Duration: hour}).Validate(), ShouldNotBeNil)
+		So((&Recurrence{Cron: "60 2 * * *", Duration: hour}).Validate(), ShouldNotBeNil)
+		So((&Recurrence{Cron: "0 2 * * 1-", Duration: hour}).Validate(), ShouldNotBeNil)
+		So((&Recurrence{Cron: "*/0 2 * * *", Duration: hour}).Validate(), ShouldNotBeNil)
+		So((&Recurrence{Cron: "0 2 * * *"}).Validate(), ShouldNotBeNil)
+		So((&Recurrence{Cron: "0 2 * * *", Duration: hour, Timezone: "Mars/Olympus"}).Validate(), ShouldNotBeNil)
+		So((&Recurrence{Cron: "0,30 2-4 1-31/2 * 1-5", Duration: hour, Timezone: "Europe/Moscow"}).Validate(), ShouldBeNil)
+	})
+
+	Convey("Nightly window", t, func() {
+		recurrence := &Recurrence{Cron: "0 2 * * *", Duration: hour}
+
+		active, until := recurrence.ActiveAt(monday + 2*hour + 30*60)
+		So(active, ShouldBeTrue)
+		So(until, ShouldEqual, monday+3*hour)
+
+		active, _ = recurrence.ActiveAt(monday + 3*hour)
+		So(active, ShouldBeFalse)
+		active, _ = recurrence.ActiveAt(monday + 2*hour - 1)
+		So(active, ShouldBeFalse)
+
+		So(recurrence.Occurrences(monday, monday+3*day), ShouldResemble, [][2]int64{
+			{monday + 2*hour, monday + 3*hour},
+			{monday + day + 2*hour, monday + day + 3*hour},
+			{monday + 2*day + 2*hour, monday + 2*day + 3*hour},
+		})
+		// occurrence which has started before the range still overlaps with it
+		So(recurrence.Occurrences(monday+2*hour+1, monday+day), ShouldHaveLength, 1)
+	})
+
+	Convey("Weekend window in timezone", t, func() {
+		// Saturdays and Sundays from 22:00 Moscow time (UTC+3) for 4 hours
+		recurrence := &Recurrence{Cron: "0 22 * * 6,7", Duration: 4 * hour, Timezone: "Europe/Moscow"}
+		saturday := monday + 5*day
+
+		So(recurrence.Occurrences(monday, monday+7*day), ShouldResemble, [][2]int64{
+			{saturday + 19*hour, saturday + 23*hour},
+			{saturday + day + 19*hour, saturday + day + 23*hour},
+		})
+
+		// the window which has started on Sunday lasts until 02:00 of Monday in Moscow
+		active, until := recurrence.ActiveAt(monday + 6*day + 22*hour)
+		So(active, ShouldBeTrue)
+		So(until, ShouldEqual, monday+6*day+23*hour)
+	})
+
+	Convey("Either day of month or day of week matches when both are restricted", t, func() {
+		recurrence := &Recurrence{Cron: "0 0 1 * 1", Duration: hour}
+		occurrences := recurrence.Occurrences(monday, time.Date(2026, time.February, 2, 0, 0, 0, 0, time.UTC).Unix())
+		So(occurrences, ShouldHaveLength, 5) // Mondays 5, 12, 19, 26 of January and 1 February
+	})
+}
+
+func TestScheduledMaintenance(t *testing.T) {
+	const hour, day = int64(3600), int64(24 * 3600)
+	now := time.Now().Unix()
+	midnight := now - now%day
+
+	Convey("Window in the future", t, func() {
+		maintenance := NewMaintenance()
+		maintenance.AddWindow(MaintenanceWindow{ID: "w1", Metric: WildcardMetric, From: now + hour, Until: now + 2*hour})
+
+		maintained, _ := maintenance.Get(WildcardMetric, now)
+		So(maintained, ShouldBeFalse)
+		maintained, until := maintenance.Get(WildcardMetric, now+hour)
+		So(maintained, ShouldBeTrue)
+		So(until, ShouldEqual, now+2*hour)
+		So(maintenance.SnapshotNow(), ShouldBeEmpty)
+		So(maintenance.Snapshot(now+hour), ShouldResemble, map[string]int64{WildcardMetric: now + 2*hour})
+	})
+
+	Convey("Immediate maintenance and windows don't interfere", t, func() {
+		maintenance := NewMaintenance()
+		maintenance.AddWindow(MaintenanceWindow{ID: "w1", Metric: "metric", From: now + day, Until: now + 2*day})
+		maintenance.Add("metric", now+hour)
+
+		maintained, until := maintenance.Get("metric", now)
+		So(maintained, ShouldBeTrue)
+		So(until, ShouldEqual, now+hour)
+
+		maintenance.Del("metric")
+		maintained, _ = maintenance.Get("metric", now+hour)
+		So(maintained, ShouldBeFalse)
+		So(maintenance.Windows(), ShouldHaveLength, 1)
+
+		So(maintenance.DelWindow("metric", "w1"), ShouldBeTrue)
+		So(maintenance.DelWindow("metric", "w1"), ShouldBeFalse)
+		So(maintenance.Windows(), ShouldBeEmpty)
+	})
+
+	Convey("Window with the same ID is replaced", t, func() {
+		maintenance := NewMaintenance()
+		maintenance.AddWindow(MaintenanceWindow{ID: "w1", Metric: "metric", From: now, Until: now + hour})
+		maintenance.AddWindow(MaintenanceWindow{ID: "w1", Metric: "metric", From: now, Until: now + day})
+		So(maintenance.Windows(), ShouldResemble, []MaintenanceWindow{{ID: "w1", Metric: "metric", From: now, Until: now + day}})
+	})
+
+	Convey("Recurring window", t, func() {
+		maintenance := NewMaintenance()
+		maintenance.AddWindow(MaintenanceWindow{
+			ID:         "nightly",
+			Metric:     WildcardMetric,
+			From:       midnight + day,
+			Until:      midnight + 3*day,
+			Recurrence: &Recurrence{Cron: "0 2 * * *", Duration: hour},
+		})
+
+		// occurrences before the window starts don't count
+		maintained, _ := maintenance.Get(WildcardMetric, midnight+2*hour)
+		So(maintained, ShouldBeFalse)
+
+		maintained, until := maintenance.Get(WildcardMetric, midnight+day+2*hour)
+		So(maintained, ShouldBeTrue)
+		So(until, ShouldEqual, midnight+day+3*hour)
+
+		maintained, _ = maintenance.Get(WildcardMetric, midnight+day+4*hour)
+		So(maintained, ShouldBeFalse)
+
+		So(maintenance.Upcoming(midnight, midnight+7*day), ShouldResemble, []MaintenanceOccurrence{
+			{WindowID: "nightly", Metric: WildcardMetric, From: midnight + day + 2*hour, Until: midnight + day + 3*hour, Recurrent: true},
+			{WindowID: "nightly", Metric: WildcardMetric, From: midnight + 2*day + 2*hour, Until: midnight + 2*day + 3*hour, Recurrent: true},
+		})
+	})
+
+	Convey("Scheduled silent pattern", t, func() {
+		pattern := SilentPatternData{ID: "sp", Pattern: "host.*", Created: now, Start: now + hour, Until: now + 2*hour}
+		So(pattern.IsScheduled(), ShouldBeTrue)
+		So(pattern.GetWindow(), ShouldResemble, MaintenanceWindow{ID: "sp", Metric: "host.*", From: now + hour, Until: now + 2*hour})
+		So((&SilentPatternData{Created: now, Until: now + hour}).IsScheduled(), ShouldBeFalse)
+	})
+}
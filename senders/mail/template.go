@@ -8,6 +8,7 @@ const defaultTemplate = `
 			table th, table td { padding: 0.5em; }
 			tr.OK { background-color: #33cc99; color: white; }
 			tr.WARN { background-color: #cccc32; color: white; }
+			tr.FLAPPING { background-color: #ff9933; color: white; }
 			tr.ERROR { background-color: #cc0032; color: white; }
 			tr.NODATA { background-color: #d3d3d3; color: black; }
 			tr.EXCEPTION { background-color: #e14f4f; color: white; }
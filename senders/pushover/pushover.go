@@ -50,7 +50,7 @@ func (sender *Sender) SendEvents(events moira.NotificationEvents, contact moira.
 		if event.State == moira.ERROR || event.State == moira.EXCEPTION {
 			priority = pushover.PriorityEmergency
 		}
-		if priority != pushover.PriorityEmergency && (event.State == moira.WARN || event.State == moira.NODATA) {
+		if priority != pushover.PriorityEmergency && (event.State == moira.WARN || event.State == moira.FLAPPING || event.State == moira.NODATA) {
 			priority = pushover.PriorityHigh
 		}
 		value := strconv.FormatFloat(moira.UseFloat64(event.Value), 'f', -1, 64)
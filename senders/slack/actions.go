@@ -0,0 +1,221 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// interactive actions which are attached to the messages and handled by api
+const (
+	ActionsBlockPrefix = "moira-actions:" // followed by trigger ID and contact value
+	ActionAck          = "ack"
+	ActionMaintenance  = "maintenance"
+	ActionSilence      = "silence"
+
+	// scopes of maintenance and silence actions
+	ActionScopeTrigger = "trigger"
+	ActionScopeMetrics = "metrics"
+)
+
+// legacyAckCallbackPrefix is followed by trigger ID in the callback ID of the ack button attachment
+// of the messages sent before the actions blocks, the buttons of such messages are still handled
+const legacyAckCallbackPrefix = "trigger_escalation_ack:"
+
+// maxActionValueLength is the limit of button value accepted in Slack
+const maxActionValueLength = 2000
+
+// ActionValue is the value of the ack button, the other actions take metrics from it as well
+type ActionValue struct {
+	Metrics []string `json:"metrics"`
+}
+
+// ActionTarget tells what the actions block refers to
+type ActionTarget struct {
+	TriggerID string
+	Contact   string
+}
+
+var actionDurations = []struct {
+	text    string
+	seconds int64
+}{
+	{"1 hour", 3600},
+	{"4 hours", 4 * 3600},
+	{"1 day", 24 * 3600},
+	{"1 week", 7 * 24 * 3600},
+}
+
+// newActionsBlock creates the block with ack button (if needed) and maintenance and silence menus
+func newActionsBlock(target ActionTarget, metrics []string, withAck, withMenus bool) (*slack.ActionBlock, error) {
+	valueEncoded, err := json.Marshal(ActionValue{Metrics: metrics})
+	if err == nil && len(valueEncoded) >= maxActionValueLength {
+		err = fmt.Errorf("exceeded max value length accepted in Slack")
+	}
+
+	elements := make([]slack.BlockElement, 0, 3)
+	if withAck {
+		ackButton := slack.NewButtonBlockElement(ActionAck, string(valueEncoded), plainText("Acknowledge"))
+		ackButton.Style = slack.StylePrimary
+		elements = append(elements, ackButton)
+	}
+	if withMenus {
+		elements = append(elements,
+			newActionMenu(ActionMaintenance, "Maintenance"),
+			newActionMenu(ActionSilence, "Silence"),
+		)
+	}
+
+	return slack.NewActionBlock(ActionsBlockPrefix+target.TriggerID+":"+target.Contact, elements...), err
+}
+
+func newActionMenu(actionID, placeholder string) *slack.SelectBlockElement {
+	groups := make([]*slack.OptionGroupBlockObject, 0, 2)
+	for _, scope := range []string{ActionScopeTrigger, ActionScopeMetrics} {
+		options := make([]*slack.OptionBlockObject, 0, len(actionDurations))
+		for _, duration := range actionDurations {
+			value := scope + ":" + strconv.FormatInt(duration.seconds, 10)
+			options = append(options, slack.NewOptionBlockObject(value, plainText(duration.text), nil))
+		}
+		label := "Whole trigger"
+		if scope == ActionScopeMetrics {
+			label = "These metrics"
+		}
+		groups = append(groups, slack.NewOptionGroupBlockElement(plainText(label), options...))
+	}
+	return slack.NewOptionsGroupSelectBlockElement(slack.OptTypeStatic, plainText(placeholder), actionID, groups...)
+}
+
+func plainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
+
+// ParseActionTarget parses block ID of the actions block
+func ParseActionTarget(blockID string) (ActionTarget, error) {
+	if !strings.HasPrefix(blockID, ActionsBlockPrefix) {
+		return ActionTarget{}, fmt.Errorf("block '%s' is not Moira actions block", blockID)
+	}
+	split := strings.SplitN(strings.TrimPrefix(blockID, ActionsBlockPrefix), ":", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return ActionTarget{}, fmt.Errorf("failed to parse block ID '%s'", blockID)
+	}
+	return ActionTarget{TriggerID: split[0], Contact: split[1]}, nil
+}
+
+// ParseLegacyAck parses the callback of the legacy ack button attachment: its ID holds trigger ID,
+// the button value holds metrics of the message
+func ParseLegacyAck(callbackID string, actions []*slack.AttachmentAction) (string, *ActionValue, error) {
+	if !strings.HasPrefix(callbackID, legacyAckCallbackPrefix) {
+		return "", nil, fmt.Errorf("callback '%s' is not Moira ack callback", callbackID)
+	}
+	triggerID := strings.TrimPrefix(callbackID, legacyAckCallbackPrefix)
+	if triggerID == "" {
+		return "", nil, fmt.Errorf("failed to parse callback ID '%s'", callbackID)
+	}
+
+	value := &ActionValue{}
+	for _, action := range actions {
+		if action.Value == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(action.Value), value); err != nil {
+			return "", nil, fmt.Errorf("failed to parse value of callback '%s': %v", callbackID, err)
+		}
+		break
+	}
+	return triggerID, value, nil
+}
+
+// ParseActionOption parses value of the selected maintenance or silence option
+func ParseActionOption(value string) (scope string, duration int64, err error) {
+	split := strings.SplitN(value, ":", 2)
+	if len(split) != 2 || (split[0] != ActionScopeTrigger && split[0] != ActionScopeMetrics) {
+		return "", 0, fmt.Errorf("failed to parse option '%s'", value)
+	}
+	if duration, err = strconv.ParseInt(split[1], 10, 64); err != nil || duration <= 0 {
+		return "", 0, fmt.Errorf("failed to parse duration of option '%s'", value)
+	}
+	return split[0], duration, nil
+}
+
+// FormatActionDuration returns human readable duration of the option
+func FormatActionDuration(seconds int64) string {
+	for _, duration := range actionDurations {
+		if duration.seconds == seconds {
+			return duration.text
+		}
+	}
+	return fmt.Sprintf("%d seconds", seconds)
+}
+
+// FindActionValue extracts value of the ack button from the message attachments
+func FindActionValue(attachments []slack.Attachment, blockID string) *ActionValue {
+	for _, attachment := range attachments {
+		for _, block := range attachment.Blocks.BlockSet {
+			actionBlock, ok := block.(*slack.ActionBlock)
+			if !ok || actionBlock.BlockID != blockID {
+				continue
+			}
+			for _, element := range actionBlock.Elements.ElementSet {
+				button, ok := element.(*slack.ButtonBlockElement)
+				if !ok || button.ActionID != ActionAck || button.Value == "" {
+					continue
+				}
+				value := &ActionValue{}
+				if err := json.Unmarshal([]byte(button.Value), value); err != nil {
+					return nil
+				}
+				return value
+			}
+		}
+	}
+	return nil
+}
+
+// MarkActionDone updates the attachments of the message after the action has been handled:
+// the note is shown under the actions block, ack button turns to the plain one
+func MarkActionDone(attachments []slack.Attachment, blockID, actionID, note string) []slack.Attachment {
+	result := make([]slack.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		result[i] = attachment
+		for j, block := range attachment.Blocks.BlockSet {
+			actionBlock, ok := block.(*slack.ActionBlock)
+			if !ok || actionBlock.BlockID != blockID {
+				continue
+			}
+
+			if actionID == ActionAck {
+				for _, element := range actionBlock.Elements.ElementSet {
+					if button, ok := element.(*slack.ButtonBlockElement); ok && button.ActionID == ActionAck {
+						button.Text = plainText("Acknowledged")
+						button.Style = ""
+					}
+				}
+			}
+
+			blocks := make([]slack.Block, 0, len(attachment.Blocks.BlockSet)+1)
+			blocks = append(blocks, attachment.Blocks.BlockSet[:j+1]...)
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, note, false, false)))
+			blocks = append(blocks, attachment.Blocks.BlockSet[j+1:]...)
+			result[i].Blocks = slack.Blocks{BlockSet: blocks}
+			break
+		}
+	}
+	return result
+}
+
+// MarkLegacyAckDone replaces the legacy ack button attachment with the note
+func MarkLegacyAckDone(attachments []slack.Attachment, callbackID, note string) []slack.Attachment {
+	result := make([]slack.Attachment, len(attachments))
+	for i, attachment := range attachments {
+		result[i] = attachment
+		if attachment.CallbackID == callbackID {
+			result[i].Actions = nil
+			result[i].Text = note
+		}
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package slack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestActionsBlock(t *testing.T) {
+	target := ActionTarget{TriggerID: "trigger-id", Contact: "#alerts"}
+
+	// the message comes back to api from Slack as JSON
+	roundTrip := func(block *slack.ActionBlock) []slack.Attachment {
+		encoded, err := json.Marshal([]slack.Attachment{{Blocks: slack.Blocks{BlockSet: []slack.Block{block}}}})
+		So(err, ShouldBeNil)
+		attachments := make([]slack.Attachment, 0)
+		So(json.Unmarshal(encoded, &attachments), ShouldBeNil)
+		return attachments
+	}
+
+	Convey("Block ID refers to the trigger and the contact", t, func() {
+		block, err := newActionsBlock(target, []string{"metric1"}, true, true)
+		So(err, ShouldBeNil)
+		So(block.Elements.ElementSet, ShouldHaveLength, 3)
+
+		parsed, err := ParseActionTarget(block.BlockID)
+		So(err, ShouldBeNil)
+		So(parsed, ShouldResemble, target)
+
+		_, err = ParseActionTarget("dbaas-metric-control:service:#alerts")
+		So(err, ShouldNotBeNil)
+		_, err = ParseActionTarget(ActionsBlockPrefix + "trigger-id")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Metrics are taken from the ack button", t, func() {
+		block, _ := newActionsBlock(target, []string{"metric1", "metric2"}, true, false)
+		attachments := roundTrip(block)
+
+		So(FindActionValue(attachments, block.BlockID), ShouldResemble, &ActionValue{Metrics: []string{"metric1", "metric2"}})
+		So(FindActionValue(attachments, ActionsBlockPrefix+"other:#alerts"), ShouldBeNil)
+
+		block, _ = newActionsBlock(target, []string{"metric1"}, false, true)
+		So(FindActionValue(roundTrip(block), block.BlockID), ShouldBeNil)
+	})
+
+	Convey("Too many metrics", t, func() {
+		metrics := make([]string, 0, 100)
+		for len(metrics) < 100 {
+			metrics = append(metrics, strings.Repeat("m", 30))
+		}
+		_, err := newActionsBlock(target, metrics, true, true)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Options", t, func() {
+		scope, duration, err := ParseActionOption("metrics:3600")
+		So(err, ShouldBeNil)
+		So(scope, ShouldEqual, ActionScopeMetrics)
+		So(duration, ShouldEqual, 3600)
+		So(FormatActionDuration(duration), ShouldEqual, "1 hour")
+
+		for _, value := range []string{"", "metrics", "host:3600", "trigger:-1", "trigger:hour"} {
+			_, _, err = ParseActionOption(value)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Handled action is marked", t, func() {
+		block, _ := newActionsBlock(target, []string{"metric1"}, true, true)
+		attachments := MarkActionDone(roundTrip(block), block.BlockID, ActionAck, "Acknowledged by user")
+
+		blocks := attachments[0].Blocks.BlockSet
+		So(blocks, ShouldHaveLength, 2)
+		button := blocks[0].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement)
+		So(button.Text.Text, ShouldEqual, "Acknowledged")
+		So(blocks[1].(*slack.ContextBlock).ContextElements.Elements[0].(*slack.TextBlockObject).Text, ShouldEqual, "Acknowledged by user")
+
+		// metrics are still available for the other actions
+		So(FindActionValue(attachments, block.BlockID), ShouldResemble, &ActionValue{Metrics: []string{"metric1"}})
+	})
+}
+
+func TestLegacyAck(t *testing.T) {
+	callbackID := legacyAckCallbackPrefix + "trigger-id"
+
+	Convey("Legacy ack callback refers to the trigger and holds the metrics", t, func() {
+		actions := []*slack.AttachmentAction{{Name: "trigger_id", Value: `{"metrics": ["metric1", "metric2"]}`}}
+		triggerID, value, err := ParseLegacyAck(callbackID, actions)
+		So(err, ShouldBeNil)
+		So(triggerID, ShouldEqual, "trigger-id")
+		So(value.Metrics, ShouldResemble, []string{"metric1", "metric2"})
+
+		triggerID, value, err = ParseLegacyAck(callbackID, []*slack.AttachmentAction{{Name: "trigger_id"}})
+		So(err, ShouldBeNil)
+		So(triggerID, ShouldEqual, "trigger-id")
+		So(value.Metrics, ShouldBeEmpty)
+
+		_, _, err = ParseLegacyAck("other:trigger-id", actions)
+		So(err, ShouldNotBeNil)
+		_, _, err = ParseLegacyAck(legacyAckCallbackPrefix, actions)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Ack button is replaced with the note", t, func() {
+		attachments := []slack.Attachment{
+			{ImageURL: "http://example.com/plot.png"},
+			{CallbackID: callbackID, Actions: []slack.AttachmentAction{{Name: "trigger_id", Type: "button"}}},
+		}
+		result := MarkLegacyAckDone(attachments, callbackID, "Acknowledged by user")
+		So(result[0], ShouldResemble, attachments[0])
+		So(result[1].Actions, ShouldBeEmpty)
+		So(result[1].Text, ShouldEqual, "Acknowledged by user")
+		So(attachments[1].Actions, ShouldHaveLength, 1)
+	})
+}
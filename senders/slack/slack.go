@@ -25,12 +25,12 @@ type Sender struct {
 	location      *time.Location
 	webdav        *grafana.WebdavUploader
 	ackWithButton bool
+	withActions   bool
 }
 
 const (
 	colorGood            = "good"
 	colorDanger          = "danger"
-	ackCallbackID        = "trigger_escalation_ack:"
 	messenger            = "slack"
 	maxMetricsPerMessage = 10
 )
@@ -62,6 +62,10 @@ func (sender *Sender) Init(senderSettings map[string]string, location *time.Loca
 		sender.ackWithButton = true
 		logger.Info("Ack with button is enabled")
 	}
+	if senderSettings["interactive_actions"] == "true" {
+		sender.withActions = true
+		logger.Info("Maintenance and silence actions are enabled")
+	}
 
 	webdavURL := senderSettings["webdav_url"]
 	if webdavURL == "" {
@@ -87,13 +91,26 @@ func (sender *Sender) Init(senderSettings map[string]string, location *time.Loca
 	return nil
 }
 
+// KeepsDashboards implements DashboardSender interface: the dashboards of the metrics are kept in the threads
+func (sender *Sender) KeepsDashboards() {}
+
 // SendEvents implements Sender interface Send
 func (sender *Sender) SendEvents(events moira.NotificationEvents, contact moira.ContactData, trigger moira.TriggerData, throttled, needAck bool) error {
 	logger := logging.GetLogger(trigger.ID)
 	api := NewSlack(sender.APIToken, logger)
 
-	state := events.GetSubjectState()
-	msgOptions := sender.formatStartingMessage(events, &trigger, state, needAck, throttled)
+	// the events which only update the dashboards are not sent
+	notified := events.WithoutDashboardOnly()
+	if len(notified) == 0 {
+		channelId, err := sender.DataBase.GetIDByUsername(messenger, contact.Value)
+		if err != nil {
+			return fmt.Errorf("Failed to get Slack channel ID from username [%s]: %s", contact.Value, err.Error())
+		}
+		return sender.updateDashboards(events, &contact, &trigger, "", channelId, moira.OK, needAck, throttled)
+	}
+
+	state := notified.GetSubjectState()
+	msgOptions := sender.formatStartingMessage(notified, &contact, &trigger, state, needAck, throttled)
 
 	var (
 		err       error
@@ -147,7 +164,7 @@ func (sender *Sender) SendEvents(events moira.NotificationEvents, contact moira.
 		return nil
 	}
 
-	err = sender.startNewThread(events, &contact, &trigger, messageTs)
+	err = sender.startNewThread(notified, &contact, &trigger, messageTs)
 	if err != nil {
 		return err
 	}
@@ -155,7 +172,7 @@ func (sender *Sender) SendEvents(events moira.NotificationEvents, contact moira.
 }
 
 func (sender *Sender) formatStartingMessage(
-	events moira.NotificationEvents, trigger *moira.TriggerData,
+	events moira.NotificationEvents, contact *moira.ContactData, trigger *moira.TriggerData,
 	state string, needAck bool, throttled bool,
 ) []slack.MsgOption {
 	logger := logging.GetLogger(trigger.ID)
@@ -223,32 +240,22 @@ func (sender *Sender) formatStartingMessage(
 			}
 		}
 	}
-	if needAck && sender.ackWithButton {
-		ackValue := map[string]interface{}{
-			"metrics": extractFailedMetrics(events),
-		}
-		ackValueEncoded, err := json.Marshal(ackValue)
-		if len(ackValueEncoded) >= 2000 {
-			err = fmt.Errorf("exceeded max value length accepted in Slack")
-		}
+	withAck := needAck && sender.ackWithButton
+	withMenus := sender.withActions && state != moira.OK
+	if withAck || withMenus {
+		target := ActionTarget{TriggerID: trigger.ID, Contact: contact.Value}
+		actionsBlock, err := newActionsBlock(target, extractFailedMetrics(events), withAck, withMenus)
 		if err != nil {
 			logger.ErrorE("Could not create value for Slack", map[string]interface{}{
 				"Trigger": trigger,
 				"Events":  events,
+				"Error":   err.Error(),
 			})
-			ackValueEncoded = nil
+			actionsBlock, _ = newActionsBlock(target, nil, withAck, withMenus)
 		}
 
 		attachments = append(attachments, slack.Attachment{
-			CallbackID: ackCallbackID + trigger.ID,
-			Actions: []slack.AttachmentAction{{
-				Name:  "trigger_id",
-				Text:  "Acknowledge",
-				Type:  "button",
-				Style: "primary",
-
-				Value: string(ackValueEncoded),
-			}},
+			Blocks: slack.Blocks{BlockSet: []slack.Block{actionsBlock}},
 		})
 	}
 
@@ -488,24 +495,26 @@ func (sender *Sender) updateDashboard(
 			}
 		}
 
-		message := strings.Builder{}
-		sender.writeEventMessages(&message, changes)
-		for _, change := range changes {
-			delete(oksWithoutDashboards, change)
-		}
+		// nothing is told about the removal of the metrics which were OK
+		if len(changes) > 0 {
+			message := strings.Builder{}
+			sender.writeEventMessages(&message, changes)
+			for _, change := range changes {
+				delete(oksWithoutDashboards, change)
+			}
 
-		threadParams := baseParams
-		threadParams.ThreadTimestamp = threadTs
+			threadParams := baseParams
+			threadParams.ThreadTimestamp = threadTs
 
-		_, _, err = api.PostMessage(
-			contact.Value,
-			slack.MsgOptionText(message.String(), false),
-			slack.MsgOptionPostMessageParameters(threadParams),
-		)
-		if err != nil {
-			return false, fmt.Errorf("Failed to send message to slack [%s]: %s", contact.Value, err.Error())
+			_, _, err = api.PostMessage(
+				contact.Value,
+				slack.MsgOptionText(message.String(), false),
+				slack.MsgOptionPostMessageParameters(threadParams),
+			)
+			if err != nil {
+				return false, fmt.Errorf("Failed to send message to slack [%s]: %s", contact.Value, err.Error())
+			}
 		}
-
 	}
 
 	if !dashboard.IsEverythingOK() {
@@ -528,7 +537,7 @@ func (sender *Sender) updateDashboards(
 	completedDashboards := make([]string, 0)
 	oksWithoutDashboards := make(map[moira.NotificationEvent]bool)
 	for _, event := range events {
-		if event.State == moira.OK {
+		if event.State == moira.OK && !event.DashboardOnly {
 			oksWithoutDashboards[event] = true
 		}
 	}
@@ -555,7 +564,7 @@ func (sender *Sender) updateDashboards(
 			eventsWithoutDashboards = append(eventsWithoutDashboards, key)
 		}
 
-		msgOptions := sender.formatStartingMessage(eventsWithoutDashboards, trigger, state, needAck, throttled)
+		msgOptions := sender.formatStartingMessage(eventsWithoutDashboards, contact, trigger, state, needAck, throttled)
 		_, _, err := api.PostMessage(contact.Value, msgOptions...)
 		if err != nil {
 			return fmt.Errorf("Failed to send message to slack [%s]: %s", contact.Value, err.Error())
@@ -661,6 +670,12 @@ func (sender *Sender) SendEventsWithInheritance(
 		return err
 	}
 
+	// the events which only update the dashboards don't start the new ones
+	events = events.WithoutDashboardOnly()
+	if len(events) == 0 {
+		return nil
+	}
+
 	threadLinks, err := sender.DataBase.GetAllSlackThreadLinks(ancestorTriggerID)
 	if err != nil {
 		return fmt.Errorf("could not get Slack threads(%v): %s", trigger.ID, err.Error())
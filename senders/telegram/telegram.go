@@ -10,6 +10,8 @@ import (
 	"github.com/tucnak/telebot"
 
 	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/api"
+	"go.avito.ru/DO/moira/chatops"
 	"go.avito.ru/DO/moira/logging"
 )
 
@@ -18,22 +20,25 @@ const messenger = "telegram"
 var (
 	telegramMessageLimit = 4096
 	emojiStates          = map[string]string{
-		moira.OK:     "\xe2\x9c\x85",
-		moira.WARN:   "\xe2\x9a\xa0",
-		moira.ERROR:  "\xe2\xad\x95",
-		moira.NODATA: "\xf0\x9f\x92\xa3",
-		moira.TEST:   "\xf0\x9f\x98\x8a",
+		moira.OK:       "\xe2\x9c\x85",
+		moira.WARN:     "\xe2\x9a\xa0",
+		moira.ERROR:    "\xe2\xad\x95",
+		moira.NODATA:   "\xf0\x9f\x92\xa3",
+		moira.TEST:     "\xf0\x9f\x98\x8a",
+		moira.FLAPPING: "\xf0\x9f\x94\x81",
 	}
 )
 
 // Sender implements moira sender interface via telegram
 type Sender struct {
-	DataBase moira.Database
-	APIToken string
-	FrontURI string
-	logger   *logging.Logger
-	bot      *telebot.Bot
-	location *time.Location
+	DataBase   moira.Database
+	Authorizer *api.Authorizer // checks permissions of chat commands
+	APIToken   string
+	FrontURI   string
+	logger     *logging.Logger
+	bot        *telebot.Bot
+	location   *time.Location
+	chatOps    *chatops.Executor
 }
 
 type recipient struct {
@@ -54,6 +59,7 @@ func (sender *Sender) Init(senderSettings map[string]string, location *time.Loca
 	sender.logger = logging.GetLogger("")
 	sender.FrontURI = senderSettings["front_uri"]
 	sender.location = location
+	sender.chatOps = chatops.NewExecutor(sender.DataBase, sender.Authorizer, sender.FrontURI)
 
 	err := sender.StartTelebot()
 	if err != nil {
@@ -192,6 +198,15 @@ func (sender *Sender) handleMessage(message telebot.Message) error {
 	username := message.Chat.Username
 	chatType := message.Chat.Type
 	switch {
+	case chatops.IsCommand(message.Text):
+		// commands are run on behalf of Moira user who has the sender among the contacts
+		login, err := chatops.GetLoginByContact(sender.DataBase, messenger, "@"+message.Sender.Username)
+		if err != nil {
+			return err
+		}
+		answer := sender.chatOps.Execute(login, message.Text)
+		sender.logger.InfoF("Telegram user @%s (%s) has run command %s", message.Sender.Username, login, message.Text)
+		return sender.bot.SendMessage(message.Chat, answer, options)
 	case chatType == "private" && message.Text == "/start":
 		if username == "" {
 			sender.bot.SendMessage(message.Chat, "Username is empty. Please add username in Telegram.", options)
@@ -0,0 +1,81 @@
+package silencer
+
+import (
+	"regexp"
+
+	"go.avito.ru/DO/moira"
+)
+
+// Subject describes the event (or the whole trigger) which might be silenced
+// empty Metric means the whole trigger, empty State means that the state is not known yet
+type Subject struct {
+	TriggerID string
+	Tags      []string
+	Metric    string
+	State     string
+}
+
+// matcher is compiled moira.SilentMatcher
+type matcher struct {
+	*moira.SilentMatcher
+	metricRegex *regexp.Regexp
+}
+
+func compileMatcher(silentMatcher *moira.SilentMatcher) (*matcher, error) {
+	result := &matcher{SilentMatcher: silentMatcher}
+	if silentMatcher.MetricRegex != "" {
+		metricRegex, err := regexp.Compile(silentMatcher.MetricRegex)
+		if err != nil {
+			return nil, err
+		}
+		result.metricRegex = metricRegex
+	}
+	return result, nil
+}
+
+// isMatched can tell whether or not all conditions of the matcher hold for the subject
+func (matcher *matcher) isMatched(subject Subject) bool {
+	if matcher.TriggerID != "" && matcher.TriggerID != subject.TriggerID {
+		return false
+	}
+	if matcher.metricRegex != nil && (subject.Metric == "" || !matcher.metricRegex.MatchString(subject.Metric)) {
+		return false
+	}
+	if len(matcher.States) > 0 && !containsString(matcher.States, subject.State) {
+		return false
+	}
+	for _, tag := range matcher.Tags {
+		if !containsString(subject.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPatternMatched can tell whether or not the subject matches the given silent pattern of any type
+// timing of the pattern is not checked
+func IsPatternMatched(pattern *moira.SilentPatternData, subject Subject) bool {
+	switch pattern.Type {
+	case moira.SPTMetric:
+		return subject.Metric != "" && isPatternMatched(subject.Metric, pattern.Pattern)
+	case moira.SPTTag:
+		return containsString(subject.Tags, pattern.Pattern)
+	case moira.SPTMatcher:
+		if pattern.Matcher == nil {
+			return false
+		}
+		compiled, err := compileMatcher(pattern.Matcher)
+		return err == nil && compiled.isMatched(subject)
+	default:
+		return false
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
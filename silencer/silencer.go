@@ -10,21 +10,30 @@ import (
 
 	"go.avito.ru/DO/moira"
 	"go.avito.ru/DO/moira/logging"
-	"go.avito.ru/DO/moira/netbox"
 )
 
 // name of user that creates silent patterns based on inactive servers info
 const (
 	autoCreateSPDuration = 60 * time.Minute
 	autoCreateSPUser     = "moira-cmdb-auto"
+	autoCreateSPComment  = "Host is inactive in CMDB"
 	checkInterval        = 10 // seconds
 	silencerLockKey      = "moira-silencer-full-update"
 )
 
 var (
+	// metric path parts which might precede host names, they are skipped when the metric is matched
 	blacklist = []string{"servers", "network", "containers", "resources", "apps", "products", "complex", "offices"}
 )
 
+// SetBlacklist replaces the list of metric path parts which are skipped when the metric is matched,
+// it must be called before the silencer is used
+func SetBlacklist(prefixes []string) {
+	if len(prefixes) > 0 {
+		blacklist = prefixes
+	}
+}
+
 var (
 	initLock sync.Mutex
 	silencer *Silencer
@@ -38,16 +47,19 @@ type Silencer struct {
 	metrics      moira.Maintenance
 	tags         moira.Maintenance
 
-	database moira.Database
-	logger   *logging.Logger
-	netbox   *netbox.Client
+	matchers            map[string]*matcher
+	matchersMaintenance moira.Maintenance
+
+	database  moira.Database
+	logger    *logging.Logger
+	inventory moira.InventoryProvider
 
 	tomb tomb.Tomb
 }
 
 // NewSilencer returns Silencer singleton instance
 // it also creates it if necessary
-func NewSilencer(database moira.Database, config *netbox.Config) *Silencer {
+func NewSilencer(database moira.Database, inventory moira.InventoryProvider) *Silencer {
 	if silencer == nil {
 		initLock.Lock()
 		defer initLock.Unlock()
@@ -58,14 +70,16 @@ func NewSilencer(database moira.Database, config *netbox.Config) *Silencer {
 				tags:     moira.NewMaintenance(),
 				database: database,
 				logger:   logging.GetLogger(""),
+
+				matchers:            make(map[string]*matcher),
+				matchersMaintenance: moira.NewMaintenance(),
 			}
 		}
 	}
 
-	if config != nil && !silencer.isFullUpdate {
+	if inventory != nil && !silencer.isFullUpdate {
 		silencer.isFullUpdate = true
-		silencer.netbox = netbox.CreateClient(config)
-		silencer.netbox.SetTimeout(5 * time.Second)
+		silencer.inventory = inventory
 	}
 
 	return silencer
@@ -89,6 +103,11 @@ func (worker *Silencer) Start() {
 	})
 }
 
+// Update loads the silent patterns at once, it is needed where the silencer is not started (e.g. for one-off checks)
+func (worker *Silencer) Update() {
+	worker.updateSilentPatterns()
+}
+
 // Stop ends the lifecycle of the Silencer
 func (worker *Silencer) Stop() error {
 	worker.tomb.Kill(nil)
@@ -105,7 +124,7 @@ func (worker *Silencer) IsMetricSilenced(metric string, ts int64) bool {
 		}
 
 		// if it does then make sure that timing is correct
-		maintained, _ := metrics.Get(metric, ts)
+		maintained, _ := metrics.Get(pattern, ts)
 		if maintained {
 			return true
 		}
@@ -124,30 +143,36 @@ func (worker *Silencer) IsTagsSilenced(tags []string, ts int64) bool {
 	return false
 }
 
-// getInactiveDeviceList requests the list of inactive devices using netbox client
-// it also handles panic
-func (worker *Silencer) getInactiveDeviceList() netbox.DeviceBriefList {
-	defer func() {
-		if r := recover(); r != nil {
-			worker.logger.Error(fmt.Sprintf("Silencer recovered from netbox client panic: %v (%T)", r, r))
+// IsSubjectSilenced can tell whether or not the subject matches any silent pattern of matcher type
+func (worker *Silencer) IsSubjectSilenced(subject Subject, ts int64) bool {
+	matchers, maintenance := worker.matchers, worker.matchersMaintenance
+	for pattern, matcher := range matchers {
+		if !matcher.isMatched(subject) {
+			continue
 		}
-	}()
-
-	return worker.netbox.InactiveDeviceList()
+		if maintained, _ := maintenance.Get(pattern, ts); maintained {
+			return true
+		}
+	}
+	return false
 }
 
 // getSilentMetricsQtyEstimate returns estimated quantity of silent metrics
-// (both devices and containers) by inactive devices data
-func (worker *Silencer) getSilentMetricsQtyEstimate(list netbox.DeviceBriefList) int {
-	result := 2 * len(list.List)
-	for _, deviceInfo := range list.List {
-		result += len(deviceInfo.Containers)
+// (both hosts and containers) by inactive hosts data
+func (worker *Silencer) getSilentMetricsQtyEstimate(hosts []moira.InventoryHost) int {
+	result := 2 * len(hosts)
+	for _, host := range hosts {
+		result += len(host.Containers)
 	}
 	return result
 }
 
 // isPatternMatched can tell whether or not the given metric matches the given silent pattern
 func (worker *Silencer) isPatternMatched(metricName, silentPattern string) bool {
+	return isPatternMatched(metricName, silentPattern)
+}
+
+func isPatternMatched(metricName, silentPattern string) bool {
 	metricParts := strings.Split(metricName, ".")
 	patternParts := strings.Split(silentPattern, ".")
 	if len(patternParts) > len(metricParts) {
@@ -196,9 +221,14 @@ func (worker *Silencer) silentMetricsFullUpdate() error {
 		return err
 	}
 
-	// get inactive containers and servers data from netbox
-	inactiveDeviceList := worker.getInactiveDeviceList()
-	inactiveDeviceQty := worker.getSilentMetricsQtyEstimate(inactiveDeviceList)
+	// get inactive containers and servers data from CMDB
+	// if it is not available then auto-created patterns must not be considered obsolete, only the expired ones are removed
+	inactiveHosts, err := worker.inventory.InactiveHosts()
+	inventoryAvailable := err == nil
+	if !inventoryAvailable {
+		worker.logger.ErrorF("Failed to get inactive hosts, auto-created silent patterns are left as is: %v", err)
+	}
+	inactiveDeviceQty := worker.getSilentMetricsQtyEstimate(inactiveHosts)
 
 	if err := worker.database.LockSilentPatterns(moira.SPTMetric); err != nil {
 		return err
@@ -212,13 +242,13 @@ func (worker *Silencer) silentMetricsFullUpdate() error {
 	result := make([]string, 0, len(currentMetrics)+inactiveDeviceQty)
 
 	// transform servers-and-containers list to the flat map
-	for _, deviceInfo := range inactiveDeviceList.List {
-		inactiveNames[deviceInfo.Name] = true // name of the server
-		if deviceInfo.NamePrevious != "" && deviceInfo.NamePrevious != deviceInfo.Name {
-			inactiveNames[deviceInfo.NamePrevious] = true // and its previous name
+	for _, host := range inactiveHosts {
+		inactiveNames[host.Name] = true // name of the server
+		if host.PreviousName != "" && host.PreviousName != host.Name {
+			inactiveNames[host.PreviousName] = true // and its previous name
 		}
-		for _, containerInfo := range deviceInfo.Containers {
-			inactiveNames[containerInfo.Name] = true // names of its containers
+		for _, container := range host.Containers {
+			inactiveNames[container] = true // names of its containers
 		}
 	}
 
@@ -232,7 +262,7 @@ func (worker *Silencer) silentMetricsFullUpdate() error {
 			// leave only those patterns which aren't expired yet
 			expired = time.Now().Unix() > currentMetric.Until
 			// also pattern could be auto-created based on inactive device list, but this device is inactive no longer
-			obsolete = currentMetric.Login == autoCreateSPUser && !inactiveNames[currentMetric.Pattern]
+			obsolete = inventoryAvailable && currentMetric.Login == autoCreateSPUser && !inactiveNames[currentMetric.Pattern]
 
 			pattern = currentMetric.Pattern
 		)
@@ -270,6 +300,7 @@ func (worker *Silencer) silentMetricsFullUpdate() error {
 				Pattern: inactiveName,
 				Login:   autoCreateSPUser,
 				Created: autoPatternCreated,
+				Comment: autoCreateSPComment,
 			}
 			result = append(result, inactiveName)
 		}
@@ -294,8 +325,9 @@ func (worker *Silencer) silentMetricsFullUpdate() error {
 // updateSilentMetrics refreshes silent metrics list and returns new list
 func (worker *Silencer) updateSilentMetrics(isFullUpdate bool) (moira.Maintenance, error) {
 	if isFullUpdate {
+		// the patterns which are already saved are still loaded
 		if err := worker.silentMetricsFullUpdate(); err != nil {
-			return nil, err
+			worker.logger.ErrorF("Failed to make full update of silent metrics: %v", err)
 		}
 	}
 	return worker.database.GetOrCreateMaintenanceSilent(moira.SPTMetric)
@@ -340,6 +372,51 @@ func (worker *Silencer) updateSilentTags(isFullUpdate bool) (moira.Maintenance,
 	return worker.database.GetOrCreateMaintenanceSilent(moira.SPTTag)
 }
 
+// updateSilentMatchers refreshes silent matchers and returns new map of compiled matchers and their maintenance
+func (worker *Silencer) updateSilentMatchers(isFullUpdate bool) (map[string]*matcher, moira.Maintenance, error) {
+	silentMatchers, err := worker.database.GetSilentPatternsTyped(moira.SPTMatcher)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// set the lock in case matchers are going to be updated
+	if isFullUpdate {
+		if err := worker.database.LockSilentPatterns(moira.SPTMatcher); err != nil {
+			return nil, nil, err
+		}
+		defer worker.database.UnlockSilentPatterns(moira.SPTMatcher)
+	}
+
+	matchers := make(map[string]*matcher, len(silentMatchers))
+	removeMatchers := make([]*moira.SilentPatternData, 0, len(silentMatchers))
+	for _, silentMatcher := range silentMatchers {
+		if isFullUpdate && time.Now().Unix() > silentMatcher.Until {
+			worker.logger.InfoE("Remove silent matcher (obsolete)", silentMatcher)
+			removeMatchers = append(removeMatchers, silentMatcher)
+			continue
+		}
+		if silentMatcher.Matcher == nil {
+			continue
+		}
+
+		compiled, err := compileMatcher(silentMatcher.Matcher)
+		if err != nil {
+			worker.logger.ErrorE(fmt.Sprintf("Failed to compile silent matcher: %v", err), silentMatcher)
+			continue
+		}
+		matchers[silentMatcher.Pattern] = compiled
+	}
+
+	if len(removeMatchers) > 0 {
+		if err = worker.database.RemoveSilentPatterns(moira.SPTMatcher, removeMatchers...); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	maintenance, err := worker.database.GetOrCreateMaintenanceSilent(moira.SPTMatcher)
+	return matchers, maintenance, err
+}
+
 // updateSilentPatterns refreshes silent patterns data
 func (worker *Silencer) updateSilentPatterns() {
 	worker.mu.Lock()
@@ -350,17 +427,22 @@ func (worker *Silencer) updateSilentPatterns() {
 		isFullUpdate, _ = worker.database.SetLock(silencerLockKey, checkInterval)
 	}
 
-	metrics, err := worker.updateSilentMetrics(isFullUpdate)
-	if err != nil {
+	// every kind of patterns is updated on its own, the previous data is kept if the update fails
+	if metrics, err := worker.updateSilentMetrics(isFullUpdate); err != nil {
 		worker.logger.ErrorF("Failed to update silent metrics: %v", err)
-		return
+	} else {
+		worker.metrics = metrics
 	}
-	worker.metrics = metrics
 
-	tags, err := worker.updateSilentTags(isFullUpdate)
-	if err != nil {
+	if tags, err := worker.updateSilentTags(isFullUpdate); err != nil {
 		worker.logger.ErrorF("Failed to update silent tags: %v", err)
-		return
+	} else {
+		worker.tags = tags
+	}
+
+	if matchers, matchersMaintenance, err := worker.updateSilentMatchers(isFullUpdate); err != nil {
+		worker.logger.ErrorF("Failed to update silent matchers: %v", err)
+	} else {
+		worker.matchers, worker.matchersMaintenance = matchers, matchersMaintenance
 	}
-	worker.tags = tags
 }
@@ -1,10 +1,20 @@
 package silencer
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"go.avito.ru/DO/moira"
+	"go.avito.ru/DO/moira/inventory"
+	"go.avito.ru/DO/moira/logging"
+	"go.avito.ru/DO/moira/mock/moira-alert"
 	"go.avito.ru/DO/moira/test-helpers"
 )
 
@@ -61,3 +71,193 @@ func TestPatternMatch(t *testing.T) {
 		So(silencer.isPatternMatched("xservers", "xservers"), ShouldBeTrue)
 	})
 }
+
+func TestSilentMatcher(t *testing.T) {
+	subject := Subject{
+		TriggerID: "trigger",
+		Tags:      []string{"prod", "mysql"},
+		Metric:    "db.mysql01.cpu",
+		State:     moira.ERROR,
+	}
+
+	Convey("Matcher of metric regex", t, func() {
+		pattern := &moira.SilentPatternData{Type: moira.SPTMatcher, Matcher: &moira.SilentMatcher{MetricRegex: `^db\.mysql0[1-3]\.`}}
+		So(IsPatternMatched(pattern, subject), ShouldBeTrue)
+		So(IsPatternMatched(pattern, Subject{TriggerID: "trigger", Metric: "db.mysql04.cpu"}), ShouldBeFalse)
+		// the whole trigger is not silenced by the metric regex
+		So(IsPatternMatched(pattern, Subject{TriggerID: "trigger"}), ShouldBeFalse)
+	})
+
+	Convey("All conditions must hold", t, func() {
+		matcher := &moira.SilentMatcher{Tags: []string{"mysql", "prod"}, TriggerID: "trigger", States: []string{moira.WARN, moira.ERROR}}
+		pattern := &moira.SilentPatternData{Type: moira.SPTMatcher, Matcher: matcher}
+		So(IsPatternMatched(pattern, subject), ShouldBeTrue)
+		So(IsPatternMatched(pattern, Subject{TriggerID: "trigger", Tags: []string{"prod"}, State: moira.ERROR}), ShouldBeFalse)
+		So(IsPatternMatched(pattern, Subject{TriggerID: "other", Tags: subject.Tags, State: moira.ERROR}), ShouldBeFalse)
+		// state is unknown
+		So(IsPatternMatched(pattern, Subject{TriggerID: "trigger", Tags: subject.Tags}), ShouldBeFalse)
+	})
+
+	Convey("Metric and tag patterns", t, func() {
+		So(IsPatternMatched(&moira.SilentPatternData{Type: moira.SPTMetric, Pattern: "mysql01"}, subject), ShouldBeTrue)
+		So(IsPatternMatched(&moira.SilentPatternData{Type: moira.SPTTag, Pattern: "mysql"}, subject), ShouldBeTrue)
+		So(IsPatternMatched(&moira.SilentPatternData{Type: moira.SPTTag, Pattern: "dev"}, subject), ShouldBeFalse)
+	})
+
+	Convey("Silenced by matcher in time", t, func() {
+		silentMatcher := &moira.SilentMatcher{States: []string{moira.NODATA}}
+		compiled, err := compileMatcher(silentMatcher)
+		So(err, ShouldBeNil)
+
+		maintenance := moira.NewMaintenance()
+		maintenance.AddWindow(moira.MaintenanceWindow{ID: "sp", Metric: silentMatcher.String(), From: 100, Until: 200})
+		worker := &Silencer{
+			matchers:            map[string]*matcher{silentMatcher.String(): compiled},
+			matchersMaintenance: maintenance,
+		}
+
+		nodata := Subject{TriggerID: "trigger", Metric: "metric", State: moira.NODATA}
+		So(worker.IsSubjectSilenced(nodata, 150), ShouldBeTrue)
+		So(worker.IsSubjectSilenced(nodata, 250), ShouldBeFalse)
+		So(worker.IsSubjectSilenced(subject, 150), ShouldBeFalse)
+	})
+}
+
+func TestSilentMetricsFullUpdate(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	inventoryProvider := mock_moira_alert.NewMockInventoryProvider(mockCtrl)
+	worker := &Silencer{
+		database:  database,
+		inventory: inventoryProvider,
+		logger:    logging.GetLogger(""),
+	}
+
+	until := time.Now().Add(time.Hour).Unix()
+	obsolete := &moira.SilentPatternData{Pattern: "avi-old01", Login: autoCreateSPUser, Until: until}
+	manual := &moira.SilentPatternData{Pattern: "avi-web01", Login: "user", Until: until}
+
+	Convey("Patterns of inactive hosts are created, obsolete ones are removed", t, func() {
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return([]*moira.SilentPatternData{obsolete, manual}, nil)
+		inventoryProvider.EXPECT().InactiveHosts().Return([]moira.InventoryHost{
+			{Name: "avi-web01", Inactive: true},
+			{Name: "avi-db01", PreviousName: "avi-db-old01", Containers: []string{"avi-db01-mysql"}, Inactive: true},
+		}, nil)
+		database.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+		database.EXPECT().UnlockSilentPatterns(moira.SPTMetric).Return(nil)
+		database.EXPECT().RemoveSilentPatterns(moira.SPTMetric, obsolete).Return(nil)
+		database.EXPECT().SaveSilentPatterns(moira.SPTMetric, gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(_ moira.SilentPatternType, spl ...*moira.SilentPatternData) {
+				patterns := make([]string, 0, len(spl))
+				for _, sp := range spl {
+					So(sp.Login, ShouldEqual, autoCreateSPUser)
+					So(sp.Comment, ShouldEqual, autoCreateSPComment)
+					patterns = append(patterns, sp.Pattern)
+				}
+				sort.Strings(patterns)
+				// manually created pattern is left intact
+				So(patterns, ShouldResemble, []string{"avi-db-old01", "avi-db01", "avi-db01-mysql"})
+			},
+		).Return(nil)
+
+		So(worker.silentMetricsFullUpdate(), ShouldBeNil)
+	})
+
+	Convey("Only expired patterns are removed if CMDB is not available", t, func() {
+		expired := &moira.SilentPatternData{Pattern: "avi-web02", Login: "user", Until: time.Now().Add(-time.Hour).Unix()}
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return([]*moira.SilentPatternData{obsolete, manual, expired}, nil)
+		inventoryProvider.EXPECT().InactiveHosts().Return(nil, fmt.Errorf("connection refused"))
+		database.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+		database.EXPECT().UnlockSilentPatterns(moira.SPTMetric).Return(nil)
+		database.EXPECT().RemoveSilentPatterns(moira.SPTMetric, expired).Return(nil)
+
+		So(worker.silentMetricsFullUpdate(), ShouldBeNil)
+	})
+
+	Convey("Auto-created patterns survive if the list endpoint of CMDB is missing", t, func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		httpInventory, err := inventory.NewProvider(&inventory.Config{Provider: inventory.ProviderHTTP, HTTPURL: server.URL})
+		So(err, ShouldBeNil)
+		httpWorker := &Silencer{
+			database:  database,
+			inventory: httpInventory,
+			logger:    logging.GetLogger(""),
+		}
+
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return([]*moira.SilentPatternData{obsolete, manual}, nil)
+		database.EXPECT().LockSilentPatterns(moira.SPTMetric).Return(nil)
+		database.EXPECT().UnlockSilentPatterns(moira.SPTMetric).Return(nil)
+
+		So(httpWorker.silentMetricsFullUpdate(), ShouldBeNil)
+	})
+}
+
+func TestUpdateSilentPatterns(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	inventoryProvider := mock_moira_alert.NewMockInventoryProvider(mockCtrl)
+	worker := &Silencer{
+		database:     database,
+		inventory:    inventoryProvider,
+		isFullUpdate: true,
+		logger:       logging.GetLogger(""),
+	}
+
+	Convey("Silent patterns are loaded if CMDB is not available", t, func() {
+		metricsMaintenance, tagsMaintenance, matchersMaintenance := moira.NewMaintenance(), moira.NewMaintenance(), moira.NewMaintenance()
+		metricsMaintenance.AddWindow(moira.MaintenanceWindow{ID: "sp", Metric: "avi-web01", From: 100, Until: 200})
+
+		database.EXPECT().SetLock(silencerLockKey, checkInterval).Return(true, nil)
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTMetric).Return([]*moira.SilentPatternData{}, nil)
+		inventoryProvider.EXPECT().InactiveHosts().Return(nil, fmt.Errorf("connection refused"))
+		for _, spt := range []moira.SilentPatternType{moira.SPTMetric, moira.SPTTag, moira.SPTMatcher} {
+			database.EXPECT().LockSilentPatterns(spt).Return(nil)
+			database.EXPECT().UnlockSilentPatterns(spt).Return(nil)
+		}
+		database.EXPECT().GetOrCreateMaintenanceSilent(moira.SPTMetric).Return(metricsMaintenance, nil)
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTTag).Return([]*moira.SilentPatternData{}, nil)
+		database.EXPECT().GetOrCreateMaintenanceSilent(moira.SPTTag).Return(tagsMaintenance, nil)
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTMatcher).Return([]*moira.SilentPatternData{}, nil)
+		database.EXPECT().GetOrCreateMaintenanceSilent(moira.SPTMatcher).Return(matchersMaintenance, nil)
+
+		worker.updateSilentPatterns()
+		So(worker.metrics, ShouldEqual, metricsMaintenance)
+		So(worker.tags, ShouldEqual, tagsMaintenance)
+		So(worker.matchersMaintenance, ShouldEqual, matchersMaintenance)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	test_helpers.InitTestLogging()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	worker := &Silencer{
+		database: database,
+		logger:   logging.GetLogger(""),
+	}
+
+	Convey("Silent patterns are loaded without the lock by the silencer which is not started", t, func() {
+		metricsMaintenance, tagsMaintenance, matchersMaintenance := moira.NewMaintenance(), moira.NewMaintenance(), moira.NewMaintenance()
+		metricsMaintenance.AddWindow(moira.MaintenanceWindow{ID: "sp", Metric: "avi-web01", From: 100, Until: 200})
+
+		database.EXPECT().GetOrCreateMaintenanceSilent(moira.SPTMetric).Return(metricsMaintenance, nil)
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTTag).Return([]*moira.SilentPatternData{}, nil)
+		database.EXPECT().GetOrCreateMaintenanceSilent(moira.SPTTag).Return(tagsMaintenance, nil)
+		database.EXPECT().GetSilentPatternsTyped(moira.SPTMatcher).Return([]*moira.SilentPatternData{}, nil)
+		database.EXPECT().GetOrCreateMaintenanceSilent(moira.SPTMatcher).Return(matchersMaintenance, nil)
+
+		worker.Update()
+		So(worker.metrics, ShouldEqual, metricsMaintenance)
+		So(worker.tags, ShouldEqual, tagsMaintenance)
+		So(worker.matchersMaintenance, ShouldEqual, matchersMaintenance)
+	})
+}
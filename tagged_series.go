@@ -0,0 +1,260 @@
+package moira
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TagQueryPrefix is the beginning of the patterns which select the tagged series by tags
+const TagQueryPrefix = "seriesByTag("
+
+// TaggedSeriesNameTag is the tag which holds the name of the series
+const TaggedSeriesNameTag = "name"
+
+// Tag query operators
+const (
+	TagOperatorEqual      = "="
+	TagOperatorNotEqual   = "!="
+	TagOperatorMatch      = "=~"
+	TagOperatorNotMatch   = "!=~"
+	tagForbiddenNameChars = ";!^=~"
+)
+
+// TaggedSeries is the metric in Graphite tagged series format: name;tag1=value1;tag2=value2.
+// The plain metric is the series without tags
+type TaggedSeries struct {
+	Name string
+	Tags map[string]string
+}
+
+// TagQueryTerm is the condition of seriesByTag on the value of one tag
+type TagQueryTerm struct {
+	Tag      string
+	Operator string
+	Value    string
+	regex    *regexp.Regexp
+}
+
+// TagQuery is the parsed seriesByTag('tag1=value1', 'tag2!=~value2') pattern, the series must match all the terms
+type TagQuery struct {
+	Terms []*TagQueryTerm
+}
+
+// IsTaggedSeries returns true if the metric has tags
+func IsTaggedSeries(metric string) bool {
+	return strings.IndexByte(metric, ';') >= 0
+}
+
+// ParseTaggedSeries parses the metric name, the plain metrics are accepted too
+func ParseTaggedSeries(metric string) (*TaggedSeries, error) {
+	parts := strings.Split(metric, ";")
+	if parts[0] == "" {
+		return nil, fmt.Errorf("series name is empty: '%s'", metric)
+	}
+
+	series := &TaggedSeries{
+		Name: parts[0],
+		Tags: make(map[string]string, len(parts)-1),
+	}
+	for _, part := range parts[1:] {
+		tag, value := split2(part, "=")
+		if tag == "" || strings.ContainsAny(tag, tagForbiddenNameChars) || tag == TaggedSeriesNameTag {
+			return nil, fmt.Errorf("invalid tag '%s' of series '%s'", tag, metric)
+		}
+		if value == "" || strings.HasPrefix(value, "~") {
+			return nil, fmt.Errorf("invalid value '%s' of tag '%s' of series '%s'", value, tag, metric)
+		}
+		if _, ok := series.Tags[tag]; ok {
+			return nil, fmt.Errorf("tag '%s' of series '%s' is duplicated", tag, metric)
+		}
+		series.Tags[tag] = value
+	}
+	return series, nil
+}
+
+// Path returns the normalized name of the series: the tags are sorted as Graphite does
+func (series *TaggedSeries) Path() string {
+	if len(series.Tags) == 0 {
+		return series.Name
+	}
+
+	tags := make([]string, 0, len(series.Tags))
+	for tag := range series.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var builder strings.Builder
+	builder.WriteString(series.Name)
+	for _, tag := range tags {
+		builder.WriteByte(';')
+		builder.WriteString(tag)
+		builder.WriteByte('=')
+		builder.WriteString(series.Tags[tag])
+	}
+	return builder.String()
+}
+
+// GetTag returns the value of the tag, the name of the series is the value of the "name" tag
+func (series *TaggedSeries) GetTag(tag string) string {
+	if tag == TaggedSeriesNameTag {
+		return series.Name
+	}
+	return series.Tags[tag]
+}
+
+// GetAllTags returns the tags of the series including the "name" tag
+func (series *TaggedSeries) GetAllTags() map[string]string {
+	result := make(map[string]string, len(series.Tags)+1)
+	for tag, value := range series.Tags {
+		result[tag] = value
+	}
+	result[TaggedSeriesNameTag] = series.Name
+	return result
+}
+
+// IsTagQuery returns true if the pattern is seriesByTag call
+func IsTagQuery(pattern string) bool {
+	return strings.HasPrefix(pattern, TagQueryPrefix)
+}
+
+// ParseTagQuery parses seriesByTag('tag1=value1', "tag2=~value2") pattern.
+// As in Graphite at least one term must require non-empty value
+func ParseTagQuery(pattern string) (*TagQuery, error) {
+	if !IsTagQuery(pattern) || !strings.HasSuffix(pattern, ")") {
+		return nil, fmt.Errorf("'%s' is not seriesByTag call", pattern)
+	}
+	args, err := parseTagQueryArgs(pattern[len(TagQueryPrefix) : len(pattern)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid seriesByTag arguments: %v", err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("seriesByTag requires at least one tag expression")
+	}
+
+	query := &TagQuery{Terms: make([]*TagQueryTerm, 0, len(args))}
+	for _, arg := range args {
+		term, err := parseTagQueryTerm(arg)
+		if err != nil {
+			return nil, err
+		}
+		query.Terms = append(query.Terms, term)
+	}
+	if query.GetIndexTerm() == nil {
+		return nil, fmt.Errorf("at least one tag expression of seriesByTag must require non-empty value")
+	}
+	return query, nil
+}
+
+// IsMatched checks the series against all the terms of the query
+func (query *TagQuery) IsMatched(series *TaggedSeries) bool {
+	for _, term := range query.Terms {
+		if !term.IsMatched(series.GetTag(term.Tag)) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetIndexTerm returns the term which helps to find the series in the index of tags:
+// it doesn't match the missing tag, exact values are preferred
+func (query *TagQuery) GetIndexTerm() *TagQueryTerm {
+	var result *TagQueryTerm
+	for _, term := range query.Terms {
+		if term.IsMatched("") {
+			continue
+		}
+		if term.Operator == TagOperatorEqual {
+			return term
+		}
+		if result == nil {
+			result = term
+		}
+	}
+	return result
+}
+
+// IsMatched checks the value of the tag, the missing tag has empty value
+func (term *TagQueryTerm) IsMatched(value string) bool {
+	switch term.Operator {
+	case TagOperatorEqual:
+		return value == term.Value
+	case TagOperatorNotEqual:
+		return value != term.Value
+	case TagOperatorMatch:
+		return term.regex.MatchString(value)
+	case TagOperatorNotMatch:
+		return !term.regex.MatchString(value)
+	}
+	return false
+}
+
+func parseTagQueryTerm(expression string) (*TagQueryTerm, error) {
+	index := strings.IndexByte(expression, '=')
+	if index <= 0 {
+		return nil, fmt.Errorf("invalid tag expression '%s'", expression)
+	}
+
+	term := &TagQueryTerm{Tag: expression[:index], Operator: TagOperatorEqual, Value: expression[index+1:]}
+	if strings.HasSuffix(term.Tag, "!") {
+		term.Tag = term.Tag[:len(term.Tag)-1]
+		term.Operator = TagOperatorNotEqual
+	}
+	if strings.HasPrefix(term.Value, "~") {
+		term.Value = term.Value[1:]
+		term.Operator += "~"
+	}
+	if term.Tag == "" || strings.ContainsAny(term.Tag, tagForbiddenNameChars) {
+		return nil, fmt.Errorf("invalid tag expression '%s'", expression)
+	}
+
+	if term.Operator == TagOperatorMatch || term.Operator == TagOperatorNotMatch {
+		// regular expressions are anchored at the start of the value
+		regex, err := regexp.Compile("^(?:" + term.Value + ")")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression of tag expression '%s': %v", expression, err)
+		}
+		term.regex = regex
+	}
+	return term, nil
+}
+
+// parseTagQueryArgs splits the arguments of seriesByTag, every argument is a quoted string
+func parseTagQueryArgs(args string) ([]string, error) {
+	result := make([]string, 0)
+	for {
+		args = strings.TrimSpace(args)
+		if args == "" {
+			return result, nil
+		}
+
+		quote := args[0]
+		if quote != '\'' && quote != '"' {
+			return nil, fmt.Errorf("tag expression must be quoted: %s", args)
+		}
+		end := strings.IndexByte(args[1:], quote)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated tag expression: %s", args)
+		}
+		result = append(result, args[1:end+1])
+
+		args = strings.TrimSpace(args[end+2:])
+		if args == "" {
+			return result, nil
+		}
+		if args[0] != ',' {
+			return nil, fmt.Errorf("tag expressions must be separated by comma: %s", args)
+		}
+		args = args[1:]
+	}
+}
+
+func split2(s, sep string) (string, string) {
+	splitResult := strings.SplitN(s, sep, 2)
+	if len(splitResult) < 2 {
+		return splitResult[0], ""
+	}
+	return splitResult[0], splitResult[1]
+}
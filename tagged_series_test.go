@@ -0,0 +1,109 @@
+package moira
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTaggedSeries(t *testing.T) {
+	Convey("Tagged series is parsed and normalized", t, func() {
+		series, err := ParseTaggedSeries("cpu.load;host=a;env=prod")
+		So(err, ShouldBeNil)
+		So(series, ShouldResemble, &TaggedSeries{Name: "cpu.load", Tags: map[string]string{"host": "a", "env": "prod"}})
+		So(series.Path(), ShouldEqual, "cpu.load;env=prod;host=a")
+		So(series.GetTag("name"), ShouldEqual, "cpu.load")
+		So(series.GetTag("host"), ShouldEqual, "a")
+		So(series.GetTag("dc"), ShouldBeEmpty)
+		So(series.GetAllTags(), ShouldResemble, map[string]string{"name": "cpu.load", "host": "a", "env": "prod"})
+
+		series, err = ParseTaggedSeries("cpu.load")
+		So(err, ShouldBeNil)
+		So(series.Path(), ShouldEqual, "cpu.load")
+		So(IsTaggedSeries("cpu.load"), ShouldBeFalse)
+		So(IsTaggedSeries("cpu.load;env=prod"), ShouldBeTrue)
+	})
+
+	Convey("Invalid tagged series", t, func() {
+		for _, metric := range []string{
+			";env=prod",
+			"cpu.load;env",
+			"cpu.load;=prod",
+			"cpu.load;env=",
+			"cpu.load;env=~prod",
+			"cpu.load;e!nv=prod",
+			"cpu.load;name=other",
+			"cpu.load;env=prod;env=dev",
+		} {
+			_, err := ParseTaggedSeries(metric)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestTagQuery(t *testing.T) {
+	Convey("Query is parsed", t, func() {
+		So(IsTagQuery("seriesByTag('name=cpu')"), ShouldBeTrue)
+		So(IsTagQuery("cpu.*"), ShouldBeFalse)
+
+		query, err := ParseTagQuery(`seriesByTag('name=cpu.load', "env!=dev", 'host=~web-\d+' ,'dc!=~eu')`)
+		So(err, ShouldBeNil)
+		So(query.Terms, ShouldHaveLength, 4)
+		So(*query.Terms[0], ShouldResemble, TagQueryTerm{Tag: "name", Operator: TagOperatorEqual, Value: "cpu.load"})
+		So(*query.Terms[1], ShouldResemble, TagQueryTerm{Tag: "env", Operator: TagOperatorNotEqual, Value: "dev"})
+		So(query.Terms[2].Operator, ShouldEqual, TagOperatorMatch)
+		So(query.Terms[2].Value, ShouldEqual, `web-\d+`)
+		So(query.Terms[3].Operator, ShouldEqual, TagOperatorNotMatch)
+		So(query.GetIndexTerm(), ShouldEqual, query.Terms[0])
+	})
+
+	Convey("Series are matched", t, func() {
+		query, err := ParseTagQuery(`seriesByTag('name=cpu.load', 'env!=dev', 'host=~web-\d+')`)
+		So(err, ShouldBeNil)
+
+		matches := func(metric string) bool {
+			series, err := ParseTaggedSeries(metric)
+			So(err, ShouldBeNil)
+			return query.IsMatched(series)
+		}
+		So(matches("cpu.load;host=web-1"), ShouldBeTrue)
+		So(matches("cpu.load;host=web-12;env=prod"), ShouldBeTrue)
+		So(matches("cpu.load;host=web-1;env=dev"), ShouldBeFalse)
+		So(matches("cpu.load;host=db-web-1"), ShouldBeFalse)
+		So(matches("cpu.load"), ShouldBeFalse)
+		So(matches("cpu.load2;host=web-1"), ShouldBeFalse)
+
+		query, err = ParseTagQuery("seriesByTag('name=cpu.load')")
+		So(err, ShouldBeNil)
+		So(matches("cpu.load"), ShouldBeTrue)
+	})
+
+	Convey("Index term doesn't match missing tag", t, func() {
+		query, err := ParseTagQuery("seriesByTag('env=', 'host=~web.*', 'dc!=')")
+		So(err, ShouldBeNil)
+		So(query.GetIndexTerm(), ShouldEqual, query.Terms[1])
+
+		query, err = ParseTagQuery("seriesByTag('env=', 'dc!=')")
+		So(err, ShouldBeNil)
+		So(query.GetIndexTerm(), ShouldEqual, query.Terms[1])
+	})
+
+	Convey("Invalid queries", t, func() {
+		for query, expected := range map[string]string{
+			"seriesByTag()":                 "seriesByTag requires at least one tag expression",
+			"seriesByTag(name=cpu)":         "invalid seriesByTag arguments: tag expression must be quoted: name=cpu",
+			"seriesByTag('name=cpu)":        "invalid seriesByTag arguments: unterminated tag expression: 'name=cpu",
+			"seriesByTag('name=cpu' 'a=b')": "invalid seriesByTag arguments: tag expressions must be separated by comma: 'a=b'",
+			"seriesByTag('cpu')":            "invalid tag expression 'cpu'",
+			"seriesByTag('!=cpu')":          "invalid tag expression '!=cpu'",
+			"seriesByTag('name=~cpu(')":     "invalid regular expression of tag expression 'name=~cpu(': error parsing regexp: missing closing ): `^(?:cpu()`",
+			"seriesByTag('env!=dev')":       "at least one tag expression of seriesByTag must require non-empty value",
+			"seriesByTag('env=', 'dc=~.*')": "at least one tag expression of seriesByTag must require non-empty value",
+			"sumSeries(seriesByTag('a=b'))": "'sumSeries(seriesByTag('a=b'))' is not seriesByTag call",
+		} {
+			_, err := ParseTagQuery(query)
+			So(err, ShouldResemble, fmt.Errorf(expected))
+		}
+	})
+}
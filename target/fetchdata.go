@@ -2,6 +2,7 @@ package target
 
 import (
 	"math"
+	"sort"
 
 	pb "github.com/go-graphite/carbonapi/carbonzipperpb3"
 	et "github.com/go-graphite/carbonapi/expr/types"
@@ -11,7 +12,7 @@ import (
 
 // FetchData gets values of given pattern metrics from given interval and returns values and all found pattern metrics
 func FetchData(database moira.Database, pattern string, from int64, until int64, allowRealTimeAlerting bool) ([]*et.MetricData, []string, error) {
-	metrics, err := database.GetPatternMetrics(pattern)
+	metrics, err := getPatternMetrics(database, pattern)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -39,6 +40,54 @@ func FetchData(database moira.Database, pattern string, from int64, until int64,
 	return metricDatas, metrics, nil
 }
 
+// getPatternMetrics returns the metrics of the pattern, seriesByTag patterns are resolved with the index of tags
+func getPatternMetrics(database moira.Database, pattern string) ([]string, error) {
+	if !moira.IsTagQuery(pattern) {
+		return database.GetPatternMetrics(pattern)
+	}
+
+	query, err := moira.ParseTagQuery(pattern)
+	if err != nil {
+		return nil, ErrParseExpr{internalError: err, target: pattern}
+	}
+	return getTaggedSeries(database, query)
+}
+
+// getTaggedSeries looks up the candidates by one of the terms and checks them against the whole query
+func getTaggedSeries(database moira.Database, query *moira.TagQuery) ([]string, error) {
+	term := query.GetIndexTerm()
+	values := []string{term.Value}
+	if term.Operator != moira.TagOperatorEqual {
+		allValues, err := database.GetSeriesTagValues(term.Tag)
+		if err != nil {
+			return nil, err
+		}
+		values = values[:0]
+		for _, value := range allValues {
+			if term.IsMatched(value) {
+				values = append(values, value)
+			}
+		}
+	}
+
+	candidates, err := database.GetSeriesByTagValues(term.Tag, values)
+	if err != nil {
+		return nil, err
+	}
+	metrics := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		series, err := moira.ParseTaggedSeries(candidate)
+		if err != nil {
+			continue
+		}
+		if query.IsMatched(series) {
+			metrics = append(metrics, candidate)
+		}
+	}
+	sort.Strings(metrics)
+	return metrics, nil
+}
+
 func createMetricData(metric string, from int64, until int64, retention int64, values []float64) *et.MetricData {
 	fetchResponse := pb.FetchResponse{
 		Name:      metric,
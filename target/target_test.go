@@ -127,4 +127,41 @@ func TestEvaluateTarget(t *testing.T) {
 			Patterns: []string{"super.puper.pattern"},
 		})
 	})
+
+	Convey("Test tagged series", t, func() {
+		web1, web2 := "cpu.load;host=web1", "cpu.load;host=web2"
+		taggedDataList := map[string][]*moira.MetricValue{web1: dataList[metric], web2: dataList[metric]}
+
+		Convey("Series are selected by tags", func() {
+			dataBase.EXPECT().GetSeriesByTagValues("name", []string{"cpu.load"}).Return([]string{web2, "cpu.load;host=db1", web1}, nil)
+			dataBase.EXPECT().GetMetricRetention(web1).Return(retention, nil)
+			dataBase.EXPECT().GetMetricsValues([]string{web1, web2}, from, until).Return(taggedDataList, nil)
+			result, err := EvaluateTarget(dataBase, "sumSeries(seriesByTag('name=cpu.load', 'host=~web'))", from, until, true)
+			So(err, ShouldBeNil)
+			So(result.Metrics, ShouldResemble, []string{web1, web2})
+			So(result.Patterns, ShouldResemble, []string{"seriesByTag('name=cpu.load', 'host=~web')"})
+			So(result.TimeSeries, ShouldHaveLength, 1)
+			So(result.TimeSeries[0].Values, ShouldResemble, []float64{0, 2, 4, 6, 8})
+		})
+
+		Convey("Values of the tag are matched by regular expression", func() {
+			dataBase.EXPECT().GetSeriesTagValues("host").Return([]string{"web1", "db1", "web2"}, nil)
+			dataBase.EXPECT().GetSeriesByTagValues("host", []string{"web1", "web2"}).Return([]string{web1, web2}, nil)
+			dataBase.EXPECT().GetMetricRetention(web1).Return(retention, nil)
+			dataBase.EXPECT().GetMetricsValues([]string{web1, web2}, from, until).Return(taggedDataList, nil)
+			result, err := EvaluateTarget(dataBase, "seriesByTag('host=~web')", from, until, true)
+			So(err, ShouldBeNil)
+			So(result.Metrics, ShouldResemble, []string{web1, web2})
+			So(result.TimeSeries, ShouldHaveLength, 2)
+		})
+
+		Convey("Invalid tag query", func() {
+			result, err := EvaluateTarget(dataBase, "seriesByTag('host!=web')", from, until, true)
+			So(err, ShouldResemble, ErrParseExpr{
+				target:        "seriesByTag('host!=web')",
+				internalError: fmt.Errorf("at least one tag expression of seriesByTag must require non-empty value"),
+			})
+			So(result, ShouldBeNil)
+		})
+	})
 }
@@ -0,0 +1,200 @@
+package moira
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	templateParamRegex       = regexp.MustCompile(`^\w+$`)
+	templatePlaceholderRegex = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+)
+
+// TriggerTemplate describes the triggers which differ only in parameters (e.g. name of the service and thresholds).
+// Placeholders {{param}} of the template trigger are replaced with the values of the parameters of the instance
+type TriggerTemplate struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Params  []string        `json:"params"`
+	Trigger TemplateTrigger `json:"trigger"`
+}
+
+// TemplateTrigger is the trigger of the template, the thresholds are strings so that they could be placeholders
+type TemplateTrigger struct {
+	Name            string        `json:"name"`
+	Desc            string        `json:"desc,omitempty"`
+	Targets         []string      `json:"targets"`
+	Expression      string        `json:"expression,omitempty"`
+	WarnValue       string        `json:"warn_value,omitempty"`
+	ErrorValue      string        `json:"error_value,omitempty"`
+	Tags            []string      `json:"tags"`
+	Saturation      []Saturation  `json:"saturation,omitempty"`
+	TTLState        *string       `json:"ttl_state,omitempty"`
+	TTL             int64         `json:"ttl,omitempty"`
+	Schedule        *ScheduleData `json:"sched,omitempty"`
+	PendingInterval int64         `json:"pending_interval"`
+	Team            string        `json:"team,omitempty"`
+}
+
+// TriggerTemplateLink links the trigger to the template it is instantiated from
+type TriggerTemplateLink struct {
+	ID     string            `json:"id"`
+	Params map[string]string `json:"params"`
+}
+
+// Validate checks that the template can be saved: all the placeholders must be declared as parameters
+func (template *TriggerTemplate) Validate() error {
+	if template.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	declared := make(map[string]bool, len(template.Params))
+	for _, param := range template.Params {
+		if !templateParamRegex.MatchString(param) {
+			return fmt.Errorf("invalid parameter name '%s'", param)
+		}
+		if declared[param] {
+			return fmt.Errorf("parameter '%s' is declared twice", param)
+		}
+		declared[param] = true
+	}
+
+	for _, text := range template.Trigger.texts() {
+		for _, match := range templatePlaceholderRegex.FindAllStringSubmatch(text, -1) {
+			if !declared[match[1]] {
+				return fmt.Errorf("parameter '%s' is not declared", match[1])
+			}
+		}
+	}
+	return nil
+}
+
+// Render instantiates the trigger with given values of the parameters, every parameter must be given.
+// The fields of the instance which the template doesn't describe (e.g. parents, SLO or check interval) are kept
+func (template *TriggerTemplate) Render(instance *Trigger, params map[string]string) (*Trigger, error) {
+	declared := make(map[string]bool, len(template.Params))
+	for _, param := range template.Params {
+		declared[param] = true
+		if _, ok := params[param]; !ok {
+			return nil, fmt.Errorf("parameter '%s' is required", param)
+		}
+	}
+	for param := range params {
+		if !declared[param] {
+			return nil, fmt.Errorf("unknown parameter '%s'", param)
+		}
+	}
+
+	replace := func(text string, escape bool) string {
+		return templatePlaceholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+			value := params[templatePlaceholderRegex.FindStringSubmatch(placeholder)[1]]
+			if escape {
+				// the value is put into JSON string
+				encoded, _ := json.Marshal(value)
+				return string(encoded[1 : len(encoded)-1])
+			}
+			return value
+		})
+	}
+	replaceAll := func(texts []string) []string {
+		result := make([]string, len(texts))
+		for i, text := range texts {
+			result[i] = replace(text, false)
+		}
+		return result
+	}
+
+	source := &template.Trigger
+	trigger := *instance
+	trigger.Name = replace(source.Name, false)
+	trigger.Desc = nil
+	trigger.Targets = replaceAll(source.Targets)
+	trigger.Tags = replaceAll(source.Tags)
+	trigger.Expression = nil
+	trigger.Saturation = nil
+	trigger.TTLState = source.TTLState
+	trigger.TTL = source.TTL
+	trigger.Schedule = source.Schedule
+	trigger.PendingInterval = source.PendingInterval
+	trigger.Team = replace(source.Team, false)
+	trigger.Template = &TriggerTemplateLink{
+		ID:     template.ID,
+		Params: params,
+	}
+	if source.Desc != "" {
+		desc := replace(source.Desc, false)
+		trigger.Desc = &desc
+	}
+	if source.Expression != "" {
+		expression := replace(source.Expression, false)
+		trigger.Expression = &expression
+	}
+
+	var err error
+	if trigger.WarnValue, err = renderThreshold("warn_value", replace(source.WarnValue, false)); err != nil {
+		return nil, err
+	}
+	if trigger.ErrorValue, err = renderThreshold("error_value", replace(source.ErrorValue, false)); err != nil {
+		return nil, err
+	}
+
+	if source.Saturation != nil {
+		trigger.Saturation = make([]Saturation, len(source.Saturation))
+		for i, saturation := range source.Saturation {
+			trigger.Saturation[i] = Saturation{
+				Type:     saturation.Type,
+				Fallback: replace(saturation.Fallback, false),
+			}
+			if saturation.ExtraParameters != nil {
+				extra := json.RawMessage(replace(string(saturation.ExtraParameters), true))
+				if !json.Valid(extra) {
+					return nil, fmt.Errorf("extra parameters of saturation #%d are not valid JSON", i+1)
+				}
+				trigger.Saturation[i].ExtraParameters = extra
+			}
+		}
+	}
+	return &trigger, nil
+}
+
+// texts returns all the fields which can contain placeholders
+func (trigger *TemplateTrigger) texts() []string {
+	result := []string{trigger.Name, trigger.Desc, trigger.Expression, trigger.WarnValue, trigger.ErrorValue, trigger.Team}
+	result = append(result, trigger.Targets...)
+	result = append(result, trigger.Tags...)
+	for _, saturation := range trigger.Saturation {
+		result = append(result, saturation.Fallback, string(saturation.ExtraParameters))
+	}
+	return result
+}
+
+func renderThreshold(name, value string) (*float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: '%s' is not a number", name, value)
+	}
+	return &threshold, nil
+}
+
+// GetTemplateParams returns the names of the parameters used by the template trigger, it helps to fill Params
+func GetTemplateParams(trigger *TemplateTrigger) []string {
+	found := make(map[string]bool)
+	for _, text := range trigger.texts() {
+		for _, match := range templatePlaceholderRegex.FindAllStringSubmatch(text, -1) {
+			found[match[1]] = true
+		}
+	}
+	result := make([]string, 0, len(found))
+	for param := range found {
+		result = append(result, param)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,114 @@
+package moira
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTriggerTemplate(t *testing.T) {
+	template := &TriggerTemplate{
+		ID:     "http-errors",
+		Name:   "HTTP errors",
+		Params: []string{"service", "warn", "error"},
+		Trigger: TemplateTrigger{
+			Name:       "{{service}}: 5xx",
+			Desc:       "Errors of {{ service }}",
+			Targets:    []string{"sumSeries(services.{{service}}.*.http.5xx)"},
+			WarnValue:  "{{warn}}",
+			ErrorValue: "{{error}}",
+			Tags:       []string{"http", "{{service}}"},
+			Saturation: []Saturation{{
+				Type:            SatRenderDescription,
+				Fallback:        "{{service}}",
+				ExtraParameters: json.RawMessage(`{"service":"{{service}}"}`),
+			}},
+			TTL: 600,
+		},
+	}
+
+	Convey("Template is validated", t, func() {
+		So(template.Validate(), ShouldBeNil)
+
+		invalid := *template
+		invalid.Params = []string{"service", "warn"}
+		So(invalid.Validate(), ShouldResemble, fmt.Errorf("parameter 'error' is not declared"))
+
+		invalid.Params = []string{"service", "warn", "error", "warn"}
+		So(invalid.Validate(), ShouldResemble, fmt.Errorf("parameter 'warn' is declared twice"))
+
+		invalid.Params = []string{"service name"}
+		So(invalid.Validate(), ShouldResemble, fmt.Errorf("invalid parameter name 'service name'"))
+
+		invalid.Name = ""
+		So(invalid.Validate(), ShouldResemble, fmt.Errorf("template name is required"))
+	})
+
+	Convey("Parameters are detected", t, func() {
+		So(GetTemplateParams(&template.Trigger), ShouldResemble, []string{"error", "service", "warn"})
+	})
+
+	Convey("Trigger is rendered", t, func() {
+		params := map[string]string{"service": `api"v2`, "warn": "10", "error": " 20.5"}
+		trigger, err := template.Render(&Trigger{ID: "trigger"}, params)
+		So(err, ShouldBeNil)
+
+		warnValue, errorValue, desc := 10.0, 20.5, `Errors of api"v2`
+		So(trigger, ShouldResemble, &Trigger{
+			ID:         "trigger",
+			Name:       `api"v2: 5xx`,
+			Desc:       &desc,
+			Targets:    []string{`sumSeries(services.api"v2.*.http.5xx)`},
+			WarnValue:  &warnValue,
+			ErrorValue: &errorValue,
+			Tags:       []string{"http", `api"v2`},
+			Saturation: []Saturation{{
+				Type:            SatRenderDescription,
+				Fallback:        `api"v2`,
+				ExtraParameters: json.RawMessage(`{"service":"api\"v2"}`),
+			}},
+			TTL:      600,
+			Template: &TriggerTemplateLink{ID: "http-errors", Params: params},
+		})
+	})
+
+	Convey("Fields which the template doesn't describe are kept", t, func() {
+		params := map[string]string{"service": "api", "warn": "10", "error": "20"}
+		oldDesc := "Old description"
+		instance := &Trigger{
+			ID:            "trigger",
+			Name:          "old: 5xx",
+			Desc:          &oldDesc,
+			Parents:       []string{"parent"},
+			Dashboard:     "https://grafana/d/api",
+			CheckInterval: 120,
+			Priority:      TriggerPriorityHigh,
+			Quorum:        &QuorumSettings{Percent: 50},
+			Managed:       &ManagedBy{Source: "repo"},
+		}
+		trigger, err := template.Render(instance, params)
+		So(err, ShouldBeNil)
+		So(trigger.Name, ShouldEqual, "api: 5xx")
+		So(*trigger.Desc, ShouldEqual, "Errors of api")
+		So(trigger.Parents, ShouldResemble, instance.Parents)
+		So(trigger.Dashboard, ShouldEqual, instance.Dashboard)
+		So(trigger.CheckInterval, ShouldEqual, instance.CheckInterval)
+		So(trigger.Priority, ShouldEqual, instance.Priority)
+		So(trigger.Quorum, ShouldEqual, instance.Quorum)
+		So(trigger.Managed, ShouldEqual, instance.Managed)
+		So(instance.Name, ShouldEqual, "old: 5xx")
+	})
+
+	Convey("Parameters are checked", t, func() {
+		_, err := template.Render(&Trigger{ID: "trigger"}, map[string]string{"service": "api", "warn": "10"})
+		So(err, ShouldResemble, fmt.Errorf("parameter 'error' is required"))
+
+		_, err = template.Render(&Trigger{ID: "trigger"}, map[string]string{"service": "api", "warn": "10", "error": "20", "host": "a"})
+		So(err, ShouldResemble, fmt.Errorf("unknown parameter 'host'"))
+
+		_, err = template.Render(&Trigger{ID: "trigger"}, map[string]string{"service": "api", "warn": "ten", "error": "20"})
+		So(err, ShouldResemble, fmt.Errorf("warn_value: 'ten' is not a number"))
+	})
+}